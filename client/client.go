@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"slices"
 	"sync"
@@ -27,6 +28,17 @@ type Client struct {
 	samplingHandler    SamplingHandler
 	rootsHandler       RootsHandler
 	elicitationHandler ElicitationHandler
+
+	health                connectionHealth
+	degradedThreshold     int
+	connectionLostMu      sync.RWMutex
+	connectionLostHandler func(error)
+
+	coalescer    *requestCoalescer
+	resync       *resyncState
+	experimental map[string]any
+
+	structuredContentCompat bool
 }
 
 type ClientOption func(*Client)
@@ -38,6 +50,39 @@ func WithClientCapabilities(capabilities mcp.ClientCapabilities) ClientOption {
 	}
 }
 
+// WithExperimentalCapability declares an experimental client capability
+// during initialization, in addition to those of the initialize request,
+// which take precedence. Use mcp.ExperimentalCapability to read the
+// server's.
+func WithExperimentalCapability(name string, value any) ClientOption {
+	return func(c *Client) {
+		if c.experimental == nil {
+			c.experimental = make(map[string]any)
+		}
+		c.experimental[name] = value
+	}
+}
+
+// WithContentEncoding declares that the client decodes gzip-compressed
+// blobs and images, so servers created with server.WithContentEncoding
+// compress them for this session. Results are decoded transparently.
+func WithContentEncoding() ClientOption {
+	return WithExperimentalCapability(mcp.ExperimentalContentEncoding, mcp.ContentEncodingCapability{
+		Encodings: []string{mcp.ContentEncodingGzip},
+	})
+}
+
+// WithStructuredContentCompat parses the JSON object of tool results that
+// hold only a text part into their StructuredContent, when the server
+// negotiated a protocol version before structured results
+// (mcp.StructuredContentProtocolVersion). Code reading structured results
+// then works the same with older servers.
+func WithStructuredContentCompat() ClientOption {
+	return func(c *Client) {
+		c.structuredContentCompat = true
+	}
+}
+
 // WithSamplingHandler sets the sampling handler for the client.
 // When set, the client will declare sampling capability during initialization.
 func WithSamplingHandler(handler SamplingHandler) ClientOption {
@@ -80,7 +125,8 @@ func WithSession() ClientOption {
 //	}
 func NewClient(transport transport.Interface, options ...ClientOption) *Client {
 	client := &Client{
-		transport: transport,
+		transport:         transport,
+		degradedThreshold: defaultDegradedThreshold,
 	}
 
 	for _, opt := range options {
@@ -97,9 +143,12 @@ func (c *Client) Start(ctx context.Context) error {
 		return fmt.Errorf("transport is nil")
 	}
 
+	c.setConnectionState(ConnectionStateConnecting, "starting transport", nil)
+
 	// Start is idempotent - transports handle being called multiple times
 	err := c.transport.Start(ctx)
 	if err != nil {
+		c.setConnectionState(ConnectionStateClosed, "failed to start transport", err)
 		return err
 	}
 
@@ -116,12 +165,21 @@ func (c *Client) Start(ctx context.Context) error {
 		bidirectional.SetRequestHandler(c.handleIncomingRequest)
 	}
 
+	c.installConnectionLostHandler()
+
+	if c.initialized {
+		c.setConnectionState(ConnectionStateReady, "session already initialized", nil)
+	}
+
 	return nil
 }
 
 // Close shuts down the client and closes the transport.
 func (c *Client) Close() error {
-	return c.transport.Close()
+	c.resync.stop()
+	err := c.transport.Close()
+	c.setConnectionState(ConnectionStateClosed, "client closed", err)
+	return err
 }
 
 // OnNotification registers a handler function to be called when notifications are received.
@@ -136,12 +194,20 @@ func (c *Client) OnNotification(
 
 // OnConnectionLost registers a handler function to be called when the connection is lost.
 // This is useful for handling HTTP2 idle timeout disconnections that should not be treated as errors.
+// The connection state also moves to ConnectionStateReconnecting.
 func (c *Client) OnConnectionLost(handler func(error)) {
+	c.connectionLostMu.Lock()
+	c.connectionLostHandler = handler
+	c.connectionLostMu.Unlock()
+	c.installConnectionLostHandler()
+}
+
+func (c *Client) installConnectionLostHandler() {
 	type connectionLostSetter interface {
 		SetConnectionLostHandler(func(error))
 	}
 	if setter, ok := c.transport.(connectionLostSetter); ok {
-		setter.SetConnectionLostHandler(handler)
+		setter.SetConnectionLostHandler(c.handleConnectionLost)
 	}
 }
 
@@ -157,6 +223,21 @@ func (c *Client) sendRequest(
 		return nil, fmt.Errorf("client not initialized")
 	}
 
+	if key, ok := c.coalescer.coalesceKey(method, params, c.coalesceHeader(ctx, header)); ok {
+		return c.coalescer.do(ctx, key, func(ctx context.Context) (*json.RawMessage, error) {
+			return c.roundTrip(ctx, method, params, header)
+		})
+	}
+	return c.roundTrip(ctx, method, params, header)
+}
+
+// roundTrip sends a request to the server and waits for its response.
+func (c *Client) roundTrip(
+	ctx context.Context,
+	method string,
+	params any,
+	header http.Header,
+) (*json.RawMessage, error) {
 	id := c.requestID.Add(1)
 
 	request := transport.JSONRPCRequest{
@@ -168,6 +249,7 @@ func (c *Client) sendRequest(
 	}
 
 	response, err := c.transport.SendRequest(ctx, request)
+	c.recordRequestOutcome(ctx, method, err)
 	if err != nil {
 		return nil, transport.NewError(err)
 	}
@@ -201,6 +283,11 @@ func (c *Client) Initialize(
 	if c.elicitationHandler != nil {
 		capabilities.Elicitation = &mcp.ElicitationCapability{}
 	}
+	if len(c.experimental) > 0 {
+		experimental := maps.Clone(c.experimental)
+		maps.Copy(experimental, capabilities.Experimental)
+		capabilities.Experimental = experimental
+	}
 
 	// Ensure we send a params object with all required fields
 	params := struct {
@@ -259,6 +346,7 @@ func (c *Client) Initialize(
 	}
 
 	c.initialized = true
+	c.setConnectionState(ConnectionStateReady, "session initialized", nil)
 	return &result, nil
 }
 
@@ -357,7 +445,11 @@ func (c *Client) Subscribe(
 	request mcp.SubscribeRequest,
 ) error {
 	_, err := c.sendRequest(ctx, "resources/subscribe", request.Params, request.Header)
-	return err
+	if err != nil {
+		return err
+	}
+	c.resync.subscribed(request)
+	return nil
 }
 
 func (c *Client) Unsubscribe(
@@ -365,7 +457,11 @@ func (c *Client) Unsubscribe(
 	request mcp.UnsubscribeRequest,
 ) error {
 	_, err := c.sendRequest(ctx, "resources/unsubscribe", request.Params, request.Header)
-	return err
+	if err != nil {
+		return err
+	}
+	c.resync.unsubscribed(request.Params.URI)
+	return nil
 }
 
 func (c *Client) ListPromptsByPage(
@@ -452,6 +548,26 @@ func (c *Client) ListTools(
 	return result, nil
 }
 
+// SearchTools finds the tools relevant to a query with the tools/search
+// extension method, which servers declare with the
+// mcp.ExperimentalToolSearch capability.
+func (c *Client) SearchTools(
+	ctx context.Context,
+	request mcp.SearchToolsRequest,
+) (*mcp.SearchToolsResult, error) {
+	response, err := c.sendRequest(ctx, string(mcp.MethodToolsSearch), request.Params, request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.SearchToolsResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
 func (c *Client) CallTool(
 	ctx context.Context,
 	request mcp.CallToolRequest,
@@ -461,6 +577,29 @@ func (c *Client) CallTool(
 		return nil, err
 	}
 
+	result, err := mcp.ParseCallToolResult(response)
+	if err != nil {
+		return nil, err
+	}
+	if c.structuredContentCompat && !mcp.SupportsStructuredContent(c.protocolVersion) {
+		result = mcp.TextToStructuredContent(result)
+	}
+	return result, nil
+}
+
+// ContinueResult returns the next page of a paginated tool result with the
+// tools/continueResult extension method, which servers declare with the
+// mcp.ExperimentalResultPagination capability. The token is found with
+// mcp.ContinuationToken on the previous page.
+func (c *Client) ContinueResult(
+	ctx context.Context,
+	request mcp.ContinueResultRequest,
+) (*mcp.CallToolResult, error) {
+	response, err := c.sendRequest(ctx, string(mcp.MethodToolsContinueResult), request.Params, request.Header)
+	if err != nil {
+		return nil, err
+	}
+
 	return mcp.ParseCallToolResult(response)
 }
 
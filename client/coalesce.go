@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultCoalescedMethods are the read-only methods WithRequestCoalescing
+// applies to by default.
+var defaultCoalescedMethods = []mcp.MCPMethod{
+	mcp.MethodResourcesRead,
+	mcp.MethodResourcesList,
+	mcp.MethodResourcesTemplatesList,
+	mcp.MethodPromptsList,
+	mcp.MethodPromptsGet,
+	mcp.MethodToolsList,
+	mcp.MethodToolsSearch,
+}
+
+// WithRequestCoalescing coalesces identical concurrent requests into a single
+// request to the server whose result, or error, is returned to every caller.
+// Requests are identical when they have the same method, parameters and
+// headers; parameters are compared as JSON, so field order does not matter.
+// The headers include those the transport computes from the caller's
+// context (see transport.ContextHeaderSource), so requests made on behalf of
+// different tenants or with different credentials are never coalesced.
+//
+// It applies to the given methods, or to the read-only list, get, read and
+// search methods if none are given. Only pass methods without side effects:
+// a coalesced request reaches the server once, however many callers made it.
+//
+// A caller whose context ends stops waiting with the context's error. The
+// request to the server is canceled once no caller is waiting for it.
+func WithRequestCoalescing(methods ...mcp.MCPMethod) ClientOption {
+	return func(c *Client) {
+		if len(methods) == 0 {
+			methods = defaultCoalescedMethods
+		}
+		c.coalescer = &requestCoalescer{
+			methods:  make(map[string]bool, len(methods)),
+			inFlight: make(map[string]*coalescedCall),
+		}
+		for _, method := range methods {
+			c.coalescer.methods[string(method)] = true
+		}
+	}
+}
+
+type requestCoalescer struct {
+	methods map[string]bool
+
+	mu       sync.Mutex
+	inFlight map[string]*coalescedCall
+}
+
+// coalescedCall is a request to the server shared by waiters callers.
+type coalescedCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	result  *json.RawMessage
+	err     error
+}
+
+// coalesceHeader returns the headers a request would be sent with: header,
+// and those the transport computes from ctx.
+func (c *Client) coalesceHeader(ctx context.Context, header http.Header) http.Header {
+	if c.coalescer == nil {
+		return header
+	}
+	source, ok := c.transport.(transport.ContextHeaderSource)
+	if !ok {
+		return header
+	}
+	merged := header.Clone()
+	if merged == nil {
+		merged = make(http.Header)
+	}
+	for k, v := range source.ContextHeaders(ctx) {
+		merged[k] = v
+	}
+	return merged
+}
+
+// coalesceKey returns the key identifying a request, or false if the request
+// is not coalesced.
+func (r *requestCoalescer) coalesceKey(method string, params any, header http.Header) (string, bool) {
+	if r == nil || !r.methods[method] {
+		return "", false
+	}
+	// Round-tripping through a generic value sorts object keys.
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	var canonical any
+	if err := json.Unmarshal(raw, &canonical); err != nil {
+		return "", false
+	}
+	key, err := json.Marshal([]any{method, canonical, header})
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
+// do runs send for the first caller with key and shares its outcome with the
+// callers that arrive while it is in flight.
+func (r *requestCoalescer) do(
+	ctx context.Context,
+	key string,
+	send func(context.Context) (*json.RawMessage, error),
+) (*json.RawMessage, error) {
+	r.mu.Lock()
+	call, ok := r.inFlight[key]
+	if !ok {
+		// The shared request outlives the caller that started it, as long
+		// as another caller waits for it.
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		r.inFlight[key] = call
+		go func() {
+			call.result, call.err = send(callCtx)
+			r.mu.Lock()
+			if r.inFlight[key] == call {
+				delete(r.inFlight, key)
+			}
+			r.mu.Unlock()
+			cancel()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		r.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Later callers start a new request rather than join a
+			// canceled one.
+			if r.inFlight[key] == call {
+				delete(r.inFlight, key)
+			}
+			call.cancel()
+		}
+		r.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
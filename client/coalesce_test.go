@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// gatedTransport holds every request until release is closed and counts the
+// requests by method.
+type gatedTransport struct {
+	healthTransport
+	release chan struct{}
+
+	mu    sync.Mutex
+	sent  map[string]int
+	calls atomic.Int32
+}
+
+func newGatedTransport() *gatedTransport {
+	return &gatedTransport{release: make(chan struct{}), sent: make(map[string]int)}
+}
+
+func (g *gatedTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	g.mu.Lock()
+	g.sent[request.Method]++
+	g.mu.Unlock()
+	g.calls.Add(1)
+
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	result := json.RawMessage(`{"contents":[{"uri":"file:///a","text":"hello"}]}`)
+	return &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: result}, nil
+}
+
+func (g *gatedTransport) count(method string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.sent[method]
+}
+
+func readRequest(uri string) mcp.ReadResourceRequest {
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	return request
+}
+
+func TestWithRequestCoalescing(t *testing.T) {
+	tr := newGatedTransport()
+	c := NewClient(tr, WithSession(), WithRequestCoalescing())
+
+	var wg sync.WaitGroup
+	results := make([]*mcp.ReadResourceResult, 5)
+	errs := make([]error, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = c.ReadResource(context.Background(), readRequest("file:///a"))
+		}()
+	}
+	require.Eventually(t, func() bool { return tr.calls.Load() == 1 }, time.Second, time.Millisecond)
+	// Give the other callers time to join the request in flight.
+	time.Sleep(20 * time.Millisecond)
+	close(tr.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, tr.count(string(mcp.MethodResourcesRead)))
+	for i := range results {
+		require.NoError(t, errs[i])
+		require.Len(t, results[i].Contents, 1)
+		assert.Equal(t, "hello", results[i].Contents[0].(mcp.TextResourceContents).Text)
+	}
+
+	// Requests that are no longer in flight are sent again.
+	_, err := c.ReadResource(context.Background(), readRequest("file:///a"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, tr.count(string(mcp.MethodResourcesRead)))
+}
+
+func TestRequestCoalescingKeys(t *testing.T) {
+	r := &requestCoalescer{methods: map[string]bool{"resources/read": true}}
+
+	a, ok := r.coalesceKey("resources/read", map[string]any{"uri": "file:///a", "_meta": map[string]any{"x": 1, "y": 2}}, nil)
+	require.True(t, ok)
+	b, _ := r.coalesceKey("resources/read", map[string]any{"_meta": map[string]any{"y": 2, "x": 1}, "uri": "file:///a"}, nil)
+	assert.Equal(t, a, b, "parameter order does not matter")
+
+	c, _ := r.coalesceKey("resources/read", map[string]any{"uri": "file:///b"}, nil)
+	assert.NotEqual(t, a, c)
+	d, _ := r.coalesceKey("resources/read", map[string]any{"uri": "file:///a", "_meta": map[string]any{"x": 1, "y": 2}}, map[string][]string{"X-Tenant": {"1"}})
+	assert.NotEqual(t, a, d, "headers are part of the key")
+
+	_, ok = r.coalesceKey("tools/call", map[string]any{"name": "delete"}, nil)
+	assert.False(t, ok, "methods not listed are never coalesced")
+
+	var disabled *requestCoalescer
+	_, ok = disabled.coalesceKey("resources/read", nil, nil)
+	assert.False(t, ok)
+}
+
+func TestRequestCoalescingCancellation(t *testing.T) {
+	tr := newGatedTransport()
+	c := NewClient(tr, WithSession(), WithRequestCoalescing(mcp.MethodResourcesRead))
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := c.ReadResource(first, readRequest("file:///a"))
+		firstErr <- err
+	}()
+	require.Eventually(t, func() bool { return tr.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := c.ReadResource(context.Background(), readRequest("file:///a"))
+		secondErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first caller gives up; the request continues for the second.
+	cancelFirst()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(tr.release)
+	require.NoError(t, <-secondErr)
+	assert.Equal(t, 1, tr.count(string(mcp.MethodResourcesRead)))
+}
+
+type tenantKey struct{}
+
+func TestRequestCoalescingContextHeaders(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	tenants := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request transport.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tenant := r.Header.Get("X-Tenant")
+		mu.Lock()
+		tenants[tenant]++
+		mu.Unlock()
+		<-release
+		id, _ := json.Marshal(request.ID)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"contents":[{"uri":"file:///a","text":%q}]}}`, id, tenant)
+	}))
+	defer srv.Close()
+
+	tr, err := transport.NewStreamableHTTP(srv.URL, transport.WithHeaderProvider(func(ctx context.Context) http.Header {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return http.Header{"X-Tenant": {tenant}}
+	}))
+	require.NoError(t, err)
+	c := NewClient(tr, WithSession(), WithRequestCoalescing())
+
+	var wg sync.WaitGroup
+	texts := make([]string, 4)
+	for i := range texts {
+		tenant := []string{"acme", "globex"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+			result, err := c.ReadResource(ctx, readRequest("file:///a"))
+			if assert.NoError(t, err) && assert.Len(t, result.Contents, 1) {
+				texts[i] = result.Contents[0].(mcp.TextResourceContents).Text
+			}
+		}()
+	}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(tenants) == 2
+	}, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"acme", "globex", "acme", "globex"}, texts, "each tenant gets its own result")
+	assert.Equal(t, map[string]int{"acme": 1, "globex": 1}, tenants, "requests of the same tenant are coalesced")
+}
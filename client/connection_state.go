@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ConnectionState is the health of the client's connection to the server.
+type ConnectionState string
+
+const (
+	// ConnectionStateConnecting means the transport is starting or the
+	// session is being initialized.
+	ConnectionStateConnecting ConnectionState = "connecting"
+	// ConnectionStateReady means the session is initialized and the last
+	// request reached the server.
+	ConnectionStateReady ConnectionState = "ready"
+	// ConnectionStateDegraded means recent requests failed at the transport
+	// level, although the connection has not been reported lost.
+	ConnectionStateDegraded ConnectionState = "degraded"
+	// ConnectionStateReconnecting means the transport reported the
+	// connection lost. The next successful request makes the client ready.
+	ConnectionStateReconnecting ConnectionState = "reconnecting"
+	// ConnectionStateClosed means the client was never started, failed to
+	// start or was closed.
+	ConnectionStateClosed ConnectionState = "closed"
+)
+
+// defaultDegradedThreshold is how many consecutive transport failures move
+// a ready client to degraded.
+const defaultDegradedThreshold = 1
+
+// ConnectionStateChange describes a transition between connection states.
+type ConnectionStateChange struct {
+	From   ConnectionState
+	To     ConnectionState
+	Reason string
+	// Err is the error that caused the transition, if any.
+	Err  error
+	Time time.Time
+}
+
+// WithDegradedThreshold sets how many consecutive requests must fail at the
+// transport level before a ready client reports ConnectionStateDegraded.
+// The default is 1.
+func WithDegradedThreshold(failures int) ClientOption {
+	return func(c *Client) {
+		c.degradedThreshold = max(failures, 1)
+	}
+}
+
+// connectionHealth tracks the connection state and its subscribers.
+type connectionHealth struct {
+	mu          sync.Mutex
+	state       ConnectionState
+	failures    int
+	nextID      int
+	subscribers map[int]func(ConnectionStateChange)
+}
+
+// ConnectionState returns the current connection state.
+func (c *Client) ConnectionState() ConnectionState {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	if c.health.state == "" {
+		return ConnectionStateClosed
+	}
+	return c.health.state
+}
+
+// OnConnectionStateChange registers a handler called after every connection
+// state transition, e.g. to show server health in a UI. Handlers are called
+// synchronously from the goroutine that caused the transition and must not
+// block. The returned function unsubscribes the handler.
+func (c *Client) OnConnectionStateChange(handler func(ConnectionStateChange)) (unsubscribe func()) {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	if c.health.subscribers == nil {
+		c.health.subscribers = make(map[int]func(ConnectionStateChange))
+	}
+	id := c.health.nextID
+	c.health.nextID++
+	c.health.subscribers[id] = handler
+
+	return func() {
+		c.health.mu.Lock()
+		defer c.health.mu.Unlock()
+		delete(c.health.subscribers, id)
+	}
+}
+
+// setConnectionState moves to state and notifies subscribers. Moving to the
+// current state does nothing.
+func (c *Client) setConnectionState(to ConnectionState, reason string, err error) {
+	c.health.mu.Lock()
+	from := c.health.state
+	if from == "" {
+		from = ConnectionStateClosed
+	}
+	if from == to {
+		c.health.mu.Unlock()
+		return
+	}
+	c.health.state = to
+	if to != ConnectionStateDegraded {
+		c.health.failures = 0
+	}
+	handlers := make([]func(ConnectionStateChange), 0, len(c.health.subscribers))
+	for _, handler := range c.health.subscribers {
+		handlers = append(handlers, handler)
+	}
+	c.health.mu.Unlock()
+
+	change := ConnectionStateChange{
+		From:   from,
+		To:     to,
+		Reason: reason,
+		Err:    err,
+		Time:   time.Now(),
+	}
+	for _, handler := range handlers {
+		handler(change)
+	}
+}
+
+// recordRequestOutcome updates the connection state after a request. err is
+// the transport error, if the request did not reach the server.
+func (c *Client) recordRequestOutcome(ctx context.Context, method string, err error) {
+	if err == nil {
+		c.health.mu.Lock()
+		c.health.failures = 0
+		c.health.mu.Unlock()
+
+		switch c.ConnectionState() {
+		case ConnectionStateDegraded:
+			c.setConnectionState(ConnectionStateReady, method+" request succeeded", nil)
+		case ConnectionStateReconnecting:
+			c.setConnectionState(ConnectionStateReady, method+" request succeeded", nil)
+			c.startResync()
+		}
+		return
+	}
+	// Requests abandoned by the caller say nothing about the connection.
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+
+	c.health.mu.Lock()
+	c.health.failures++
+	degrade := c.health.state == ConnectionStateReady && c.health.failures >= c.degradedThreshold
+	c.health.mu.Unlock()
+	if degrade {
+		c.setConnectionState(ConnectionStateDegraded, method+" request failed", err)
+	}
+}
+
+// handleConnectionLost is installed as the transport's connection lost
+// handler, for transports that report one.
+func (c *Client) handleConnectionLost(err error) {
+	if c.ConnectionState() != ConnectionStateClosed {
+		c.setConnectionState(ConnectionStateReconnecting, "connection lost", err)
+	}
+
+	c.connectionLostMu.RLock()
+	handler := c.connectionLostHandler
+	c.connectionLostMu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// healthTransport answers every request with an empty result unless sendErr
+// is set, and lets tests report the connection as lost.
+type healthTransport struct {
+	mu             sync.Mutex
+	startErr       error
+	sendErr        error
+	connectionLost func(error)
+}
+
+func (h *healthTransport) Start(ctx context.Context) error {
+	return h.startErr
+}
+
+func (h *healthTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sendErr != nil {
+		return nil, h.sendErr
+	}
+	result := json.RawMessage(`{}`)
+	if request.Method == "initialize" {
+		result = json.RawMessage(`{"protocolVersion":"` + mcp.LATEST_PROTOCOL_VERSION + `","capabilities":{},"serverInfo":{"name":"test","version":"1.0.0"}}`)
+	}
+	return &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: result}, nil
+}
+
+func (h *healthTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return nil
+}
+
+func (h *healthTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+}
+
+func (h *healthTransport) SetConnectionLostHandler(handler func(error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.connectionLost = handler
+}
+
+func (h *healthTransport) Close() error {
+	return nil
+}
+
+func (h *healthTransport) GetSessionId() string {
+	return ""
+}
+
+func (h *healthTransport) setSendErr(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sendErr = err
+}
+
+func (h *healthTransport) loseConnection(err error) {
+	h.mu.Lock()
+	handler := h.connectionLost
+	h.mu.Unlock()
+	handler(err)
+}
+
+func TestClient_ConnectionState(t *testing.T) {
+	tr := &healthTransport{}
+	c := NewClient(tr)
+	assert.Equal(t, ConnectionStateClosed, c.ConnectionState())
+
+	var changes []ConnectionStateChange
+	c.OnConnectionStateChange(func(change ConnectionStateChange) {
+		changes = append(changes, change)
+	})
+
+	var lost []error
+	c.OnConnectionLost(func(err error) {
+		lost = append(lost, err)
+	})
+
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+	assert.Equal(t, ConnectionStateConnecting, c.ConnectionState())
+
+	_, err := c.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, ConnectionStateReady, c.ConnectionState())
+
+	sendErr := errors.New("connection refused")
+	tr.setSendErr(sendErr)
+	require.Error(t, c.Ping(ctx))
+	assert.Equal(t, ConnectionStateDegraded, c.ConnectionState())
+
+	tr.setSendErr(nil)
+	require.NoError(t, c.Ping(ctx))
+	assert.Equal(t, ConnectionStateReady, c.ConnectionState())
+
+	lostErr := errors.New("stream closed")
+	tr.loseConnection(lostErr)
+	assert.Equal(t, ConnectionStateReconnecting, c.ConnectionState())
+	assert.Equal(t, []error{lostErr}, lost, "user handler still called")
+
+	require.NoError(t, c.Ping(ctx))
+	require.NoError(t, c.Close())
+	assert.Equal(t, ConnectionStateClosed, c.ConnectionState())
+
+	type transition struct {
+		from, to ConnectionState
+		reason   string
+		err      error
+	}
+	var got []transition
+	for _, change := range changes {
+		assert.False(t, change.Time.IsZero())
+		got = append(got, transition{change.From, change.To, change.Reason, change.Err})
+	}
+	assert.Equal(t, []transition{
+		{ConnectionStateClosed, ConnectionStateConnecting, "starting transport", nil},
+		{ConnectionStateConnecting, ConnectionStateReady, "session initialized", nil},
+		{ConnectionStateReady, ConnectionStateDegraded, "ping request failed", sendErr},
+		{ConnectionStateDegraded, ConnectionStateReady, "ping request succeeded", nil},
+		{ConnectionStateReady, ConnectionStateReconnecting, "connection lost", lostErr},
+		{ConnectionStateReconnecting, ConnectionStateReady, "ping request succeeded", nil},
+		{ConnectionStateReady, ConnectionStateClosed, "client closed", nil},
+	}, got)
+}
+
+func TestClient_ConnectionStateDegradedThreshold(t *testing.T) {
+	tr := &healthTransport{}
+	c := NewClient(tr, WithDegradedThreshold(3))
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+	_, err := c.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	tr.setSendErr(errors.New("timeout"))
+	for range 2 {
+		require.Error(t, c.Ping(ctx))
+	}
+	assert.Equal(t, ConnectionStateReady, c.ConnectionState())
+
+	// A success resets the count.
+	tr.setSendErr(nil)
+	require.NoError(t, c.Ping(ctx))
+	tr.setSendErr(errors.New("timeout"))
+	for range 2 {
+		require.Error(t, c.Ping(ctx))
+	}
+	assert.Equal(t, ConnectionStateReady, c.ConnectionState())
+
+	require.Error(t, c.Ping(ctx))
+	assert.Equal(t, ConnectionStateDegraded, c.ConnectionState())
+}
+
+func TestClient_ConnectionStateIgnoresCancelledRequests(t *testing.T) {
+	tr := &healthTransport{}
+	c := NewClient(tr)
+	require.NoError(t, c.Start(context.Background()))
+	_, err := c.Initialize(context.Background(), mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tr.setSendErr(context.Canceled)
+	require.Error(t, c.Ping(ctx))
+	assert.Equal(t, ConnectionStateReady, c.ConnectionState())
+}
+
+func TestClient_ConnectionStateStartFailure(t *testing.T) {
+	startErr := errors.New("no such binary")
+	c := NewClient(&healthTransport{startErr: startErr})
+
+	var last ConnectionStateChange
+	unsubscribe := c.OnConnectionStateChange(func(change ConnectionStateChange) {
+		last = change
+	})
+
+	require.ErrorIs(t, c.Start(context.Background()), startErr)
+	assert.Equal(t, ConnectionStateClosed, c.ConnectionState())
+	assert.Equal(t, ConnectionStateConnecting, last.From)
+	assert.ErrorIs(t, last.Err, startErr)
+
+	unsubscribe()
+	c.transport = &healthTransport{}
+	require.NoError(t, c.Start(context.Background()))
+	assert.Equal(t, ConnectionStateClosed, last.To, "unsubscribed handler not called")
+}
+
+func TestClient_ConnectionStateWithSession(t *testing.T) {
+	c := NewClient(&healthTransport{}, WithSession())
+	require.NoError(t, c.Start(context.Background()))
+	assert.Equal(t, ConnectionStateReady, c.ConnectionState())
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// initializeTransport records the initialize request and answers it with
+// the given server capabilities.
+type initializeTransport struct {
+	healthTransport
+	capabilities mcp.ServerCapabilities
+	params       mcp.InitializeParams
+}
+
+func (i *initializeTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if request.Method != "initialize" {
+		return i.healthTransport.SendRequest(ctx, request)
+	}
+	data, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &i.params); err != nil {
+		return nil, err
+	}
+	result, err := json.Marshal(mcp.InitializeResult{
+		ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+		Capabilities:    i.capabilities,
+		ServerInfo:      mcp.Implementation{Name: "test", Version: "1.0.0"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: result}, nil
+}
+
+func TestWithExperimentalCapability(t *testing.T) {
+	canvas := mcp.NewExperimentalCapability[mcp.VersionedCapability]("x-canvas")
+	tr := &initializeTransport{}
+	canvas.Set(&tr.capabilities.Experimental, mcp.VersionedCapability{Versions: []string{"2", "1"}})
+
+	c := NewClient(tr,
+		WithExperimentalCapability(canvas.Name, mcp.VersionedCapability{Versions: []string{"1"}}),
+		WithExperimentalCapability("x-other", map[string]any{"mine": true}),
+	)
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+
+	request := mcp.InitializeRequest{}
+	request.Params.Capabilities.Experimental = map[string]any{"x-other": map[string]any{"request": true}}
+	_, err := c.Initialize(ctx, request)
+	require.NoError(t, err)
+
+	declared, ok, err := canvas.Get(tr.params.Capabilities.Experimental)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"1"}, declared.Versions)
+	assert.Equal(t, map[string]any{"request": true}, tr.params.Capabilities.Experimental["x-other"], "the request takes precedence")
+
+	serverCanvas, ok, err := canvas.Get(c.GetServerCapabilities().Experimental)
+	require.NoError(t, err)
+	require.True(t, ok)
+	version, ok := mcp.NegotiateVersion(declared, serverCanvas)
+	assert.True(t, ok)
+	assert.Equal(t, "1", version)
+}
+
+func TestWithContentEncoding(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("id,name\n", 1000)))
+	s := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, false), server.WithContentEncoding(0))
+	var clientEncodings mcp.ContentEncodingCapability
+	s.AddResource(mcp.NewResource("file:///rows.csv", "rows"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		clientEncodings, _, _ = mcp.ContentEncodings.Get(server.ClientExperimentalCapabilities(ctx))
+		return []mcp.ResourceContents{mcp.BlobResourceContents{URI: "file:///rows.csv", MIMEType: "text/csv", Blob: blob}}, nil
+	})
+
+	httpServer := server.NewTestStreamableHTTPServer(s)
+	defer httpServer.Close()
+	tr, err := transport.NewStreamableHTTP(httpServer.URL)
+	require.NoError(t, err)
+	c := NewClient(tr, WithContentEncoding())
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+	defer c.Close()
+	_, err = c.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "file:///rows.csv"
+	result, err := c.ReadResource(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mcp.ContentEncodingGzip}, clientEncodings.Encodings)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, mcp.BlobResourceContents{URI: "file:///rows.csv", MIMEType: "text/csv", Blob: blob}, result.Contents[0])
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -419,3 +420,47 @@ func TestInProcessMCPClient(t *testing.T) {
 		}
 	})
 }
+
+func TestInProcessNotifications(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("notify"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := mcpServer.SendNotificationToClient(ctx, "notifications/message", map[string]any{"data": "hello"}); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("sent"), nil
+	})
+
+	client := NewInProcessClientWithElicitationHandler(mcpServer, &MockElicitationHandler{})
+	defer client.Close()
+	notifications := make(chan mcp.JSONRPCNotification, 1)
+	client.OnNotification(func(notification mcp.JSONRPCNotification) {
+		notifications <- notification
+	})
+
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "notify"
+	if _, err := client.CallTool(context.Background(), request); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	select {
+	case notification := <-notifications:
+		if notification.Method != "notifications/message" {
+			t.Errorf("Expected notifications/message, got %s", notification.Method)
+		}
+		if data := notification.Params.AdditionalFields["data"]; data != "hello" {
+			t.Errorf("Expected data hello, got %v", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Notification was not delivered to the client")
+	}
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClientContinueResult(t *testing.T) {
+	rows := []int{1, 2, 3, 4, 5}
+	page := func(offset int) *mcp.CallToolResult {
+		end := min(offset+2, len(rows))
+		next := ""
+		if end < len(rows) {
+			next = strconv.Itoa(end)
+		}
+		return mcp.NewToolResultPage(map[string]any{"rows": rows[offset:end]}, next)
+	}
+	s := server.NewMCPServer("test", "1.0.0", server.WithResultPagination())
+	s.AddTool(mcp.NewTool("query"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return page(0), nil
+	})
+	s.AddResultContinuation("query", func(ctx context.Context, request mcp.ContinueResultRequest) (*mcp.CallToolResult, error) {
+		offset, err := strconv.Atoi(request.Params.ContinuationToken)
+		if err != nil || offset >= len(rows) {
+			return nil, fmt.Errorf("token %q: %w", request.Params.ContinuationToken, server.ErrInvalidContinuation)
+		}
+		return page(offset), nil
+	})
+
+	c, err := NewInProcessClient(s)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+	result, err := c.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Capabilities.Experimental, mcp.ExperimentalResultPagination)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "query"
+	page0, err := c.CallTool(ctx, request)
+	require.NoError(t, err)
+
+	var got []any
+	got = append(got, page0.StructuredContent.(map[string]any)["rows"].([]any)...)
+	token, ok := mcp.ContinuationToken(page0)
+	for ok {
+		next := mcp.ContinueResultRequest{}
+		next.Params.Name = "query"
+		next.Params.ContinuationToken = token
+		p, err := c.ContinueResult(ctx, next)
+		require.NoError(t, err)
+		got = append(got, p.StructuredContent.(map[string]any)["rows"].([]any)...)
+		token, ok = mcp.ContinuationToken(p)
+	}
+	assert.Equal(t, []any{1.0, 2.0, 3.0, 4.0, 5.0}, got)
+
+	bad := mcp.ContinueResultRequest{}
+	bad.Params.Name = "query"
+	bad.Params.ContinuationToken = "99"
+	_, err = c.ContinueResult(ctx, bad)
+	assert.Error(t, err)
+}
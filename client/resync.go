@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resyncTimeout bounds how long a resync after a reconnect may take.
+const resyncTimeout = 30 * time.Second
+
+// ResyncReport describes what the client restored after a reconnect.
+type ResyncReport struct {
+	// Resubscribed lists the resource URIs subscribed to again.
+	Resubscribed []string
+	// SubscriptionErrors holds, by URI, the subscriptions that could not be
+	// restored.
+	SubscriptionErrors map[string]error
+	// ResumedTasks lists the IDs of the tasks whose AwaitTask polls resumed.
+	ResumedTasks []string
+	// TaskErrors holds, by task ID, the awaited tasks the server could not
+	// report on, e.g. because it lost them while the connection was down.
+	TaskErrors map[string]error
+	// Time is when the resync finished.
+	Time time.Time
+}
+
+// OK reports whether everything was restored.
+func (r ResyncReport) OK() bool {
+	return len(r.SubscriptionErrors) == 0 && len(r.TaskErrors) == 0
+}
+
+// WithReconnectResync makes the client track its resource subscriptions and
+// AwaitTask calls, and restore them when the connection comes back after the
+// transport reported it lost: subscriptions are re-issued and awaited tasks
+// are checked to still exist. handler, if not nil, receives a report of each
+// resync. A resync after a reconnect gives up after 30 seconds, or when the
+// client is closed.
+//
+// With this option AwaitTask keeps polling through transport errors until
+// its context is done, instead of returning the first one.
+func WithReconnectResync(handler func(ResyncReport)) ClientOption {
+	return func(c *Client) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.resync = &resyncState{
+			handler:       handler,
+			ctx:           ctx,
+			cancel:        cancel,
+			subscriptions: make(map[string]mcp.SubscribeRequest),
+			awaits:        make(map[string]int),
+		}
+	}
+}
+
+type resyncState struct {
+	handler func(ResyncReport)
+	// ctx is the parent of background resyncs, cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu            sync.Mutex
+	subscriptions map[string]mcp.SubscribeRequest // by URI
+	awaits        map[string]int                  // AwaitTask calls by task ID
+	running       bool
+}
+
+func (r *resyncState) subscribed(request mcp.SubscribeRequest) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions[request.Params.URI] = request
+}
+
+func (r *resyncState) unsubscribed(uri string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscriptions, uri)
+}
+
+// awaiting records an AwaitTask call until the returned function is called.
+func (r *resyncState) awaiting(taskID string) (done func()) {
+	if r == nil {
+		return func() {}
+	}
+	r.mu.Lock()
+	r.awaits[taskID]++
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.awaits[taskID]--; r.awaits[taskID] == 0 {
+			delete(r.awaits, taskID)
+		}
+	}
+}
+
+// stop cancels the running background resync, if any.
+func (r *resyncState) stop() {
+	if r != nil {
+		r.cancel()
+	}
+}
+
+// retries reports whether AwaitTask polls again after err.
+func (r *resyncState) retries(err error) bool {
+	var transportErr *transport.Error
+	return r != nil && errors.As(err, &transportErr)
+}
+
+// Resync restores the tracked subscriptions and checks the awaited tasks, as
+// done automatically after a reconnect, and reports the outcome to the
+// handler of WithReconnectResync. It is useful after re-initializing a
+// session the server dropped. Without WithReconnectResync there is nothing
+// to restore and the report is empty.
+func (c *Client) Resync(ctx context.Context) ResyncReport {
+	if c.resync == nil {
+		return ResyncReport{
+			SubscriptionErrors: make(map[string]error),
+			TaskErrors:         make(map[string]error),
+			Time:               time.Now(),
+		}
+	}
+	subscriptions, taskIDs := c.resync.snapshot()
+	return c.restore(ctx, subscriptions, taskIDs)
+}
+
+// snapshot returns the tracked subscriptions and awaited tasks.
+func (r *resyncState) snapshot() (map[string]mcp.SubscribeRequest, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.subscriptions), slices.Sorted(maps.Keys(r.awaits))
+}
+
+func (c *Client) restore(ctx context.Context, subscriptions map[string]mcp.SubscribeRequest, taskIDs []string) ResyncReport {
+	report := ResyncReport{
+		SubscriptionErrors: make(map[string]error),
+		TaskErrors:         make(map[string]error),
+	}
+	for _, uri := range slices.Sorted(maps.Keys(subscriptions)) {
+		request := subscriptions[uri]
+		if _, err := c.sendRequest(ctx, "resources/subscribe", request.Params, request.Header); err != nil {
+			report.SubscriptionErrors[uri] = err
+			continue
+		}
+		report.Resubscribed = append(report.Resubscribed, uri)
+	}
+	for _, taskID := range taskIDs {
+		if _, err := c.GetTask(ctx, mcp.GetTaskRequest{Params: mcp.GetTaskParams{TaskId: taskID}}); err != nil {
+			report.TaskErrors[taskID] = err
+			continue
+		}
+		report.ResumedTasks = append(report.ResumedTasks, taskID)
+	}
+
+	report.Time = time.Now()
+	if c.resync.handler != nil {
+		c.resync.handler(report)
+	}
+	return report
+}
+
+// startResync resyncs in the background after a reconnect, unless a resync
+// is already running.
+func (c *Client) startResync() {
+	if c.resync == nil {
+		return
+	}
+	c.resync.mu.Lock()
+	if c.resync.running {
+		c.resync.mu.Unlock()
+		return
+	}
+	c.resync.running = true
+	c.resync.mu.Unlock()
+
+	// Snapshot now, so an await that the reconnecting request itself ends
+	// is still reported.
+	subscriptions, taskIDs := c.resync.snapshot()
+	go func() {
+		defer func() {
+			c.resync.mu.Lock()
+			c.resync.running = false
+			c.resync.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(c.resync.ctx, resyncTimeout)
+		defer cancel()
+		c.restore(ctx, subscriptions, taskIDs)
+	}()
+}
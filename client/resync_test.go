@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resyncTransport serves subscriptions and the tasks in its task map, and
+// fails every request while down. While hanging, subscriptions wait for
+// their context to be done.
+type resyncTransport struct {
+	healthTransport
+
+	mu         sync.Mutex
+	down       bool
+	hanging    bool
+	subscribed []string
+	tasks      map[string]mcp.TaskStatus
+}
+
+func (r *resyncTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return nil, errors.New("connection refused")
+	}
+	if r.hanging && request.Method == "resources/subscribe" {
+		r.mu.Unlock()
+		<-ctx.Done()
+		r.mu.Lock()
+		return nil, ctx.Err()
+	}
+
+	response := &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: json.RawMessage(`{}`)}
+	switch request.Method {
+	case "resources/subscribe":
+		params := request.Params.(mcp.SubscribeParams)
+		r.subscribed = append(r.subscribed, params.URI)
+	case "tasks/get":
+		params := request.Params.(mcp.GetTaskParams)
+		status, ok := r.tasks[params.TaskId]
+		if !ok {
+			response.Error = &mcp.JSONRPCErrorDetails{Code: mcp.INVALID_PARAMS, Message: "task not found"}
+			break
+		}
+		pollInterval := int64(1)
+		response.Result, _ = json.Marshal(mcp.GetTaskResult{Task: mcp.Task{TaskId: params.TaskId, Status: status, PollInterval: &pollInterval}})
+	}
+	return response, nil
+}
+
+func (r *resyncTransport) set(f func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f()
+}
+
+func TestWithReconnectResync(t *testing.T) {
+	tr := &resyncTransport{tasks: map[string]mcp.TaskStatus{"t1": mcp.TaskStatusWorking, "t2": mcp.TaskStatusWorking}}
+	reports := make(chan ResyncReport, 1)
+	c := NewClient(tr, WithSession(), WithReconnectResync(func(report ResyncReport) { reports <- report }))
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+
+	for _, uri := range []string{"file:///a", "file:///b", "file:///c"} {
+		request := mcp.SubscribeRequest{}
+		request.Params.URI = uri
+		require.NoError(t, c.Subscribe(ctx, request))
+	}
+	unsubscribe := mcp.UnsubscribeRequest{}
+	unsubscribe.Params.URI = "file:///c"
+	require.NoError(t, c.Unsubscribe(ctx, unsubscribe))
+
+	awaited := make(map[string]chan error)
+	for _, id := range []string{"t1", "t2"} {
+		done := make(chan error, 1)
+		awaited[id] = done
+		go func() {
+			_, err := c.AwaitTask(ctx, id)
+			done <- err
+		}()
+	}
+	require.Eventually(t, func() bool {
+		c.resync.mu.Lock()
+		defer c.resync.mu.Unlock()
+		return len(c.resync.awaits) == 2
+	}, time.Second, time.Millisecond)
+
+	// The connection drops; the server loses t2 meanwhile.
+	tr.set(func() {
+		tr.down = true
+		tr.subscribed = nil
+		delete(tr.tasks, "t2")
+	})
+	tr.loseConnection(errors.New("stream closed"))
+	time.Sleep(10 * time.Millisecond)
+	tr.set(func() { tr.down = false })
+
+	var report ResyncReport
+	select {
+	case report = <-reports:
+	case <-time.After(time.Second):
+		t.Fatal("no resync report")
+	}
+	assert.Equal(t, []string{"file:///a", "file:///b"}, report.Resubscribed)
+	assert.Empty(t, report.SubscriptionErrors)
+	assert.Equal(t, []string{"t1"}, report.ResumedTasks)
+	assert.Contains(t, report.TaskErrors, "t2")
+	assert.False(t, report.OK())
+	tr.set(func() { assert.Equal(t, []string{"file:///a", "file:///b"}, tr.subscribed) })
+
+	// The await of the lost task fails; t1's polls resumed and complete.
+	select {
+	case err := <-awaited["t2"]:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("await of lost task did not return")
+	}
+	tr.set(func() { tr.tasks["t1"] = mcp.TaskStatusCompleted })
+	select {
+	case err := <-awaited["t1"]:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("await of resumed task did not return")
+	}
+}
+
+func TestResyncStopsOnClose(t *testing.T) {
+	tr := &resyncTransport{}
+	reports := make(chan ResyncReport, 1)
+	c := NewClient(tr, WithSession(), WithReconnectResync(func(report ResyncReport) { reports <- report }))
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+
+	request := mcp.SubscribeRequest{}
+	request.Params.URI = "file:///a"
+	require.NoError(t, c.Subscribe(ctx, request))
+
+	tr.loseConnection(errors.New("stream closed"))
+	tr.set(func() { tr.hanging = true })
+	require.NoError(t, c.Ping(ctx), "the first request after the loss starts a resync")
+
+	require.NoError(t, c.Close())
+	select {
+	case report := <-reports:
+		assert.ErrorIs(t, report.SubscriptionErrors["file:///a"], context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the resync did not stop when the client closed")
+	}
+}
+
+func TestAwaitTaskWithoutResync(t *testing.T) {
+	tr := &resyncTransport{down: true}
+	c := NewClient(tr, WithSession())
+	_, err := c.AwaitTask(context.Background(), "t1")
+	assert.Error(t, err, "transport errors end the await")
+
+	report := c.Resync(context.Background())
+	assert.True(t, report.OK())
+	assert.Empty(t, report.Resubscribed)
+}
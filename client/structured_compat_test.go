@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestWithStructuredContentCompat(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("weather"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if mcp.SupportsStructuredContent(server.ClientProtocolVersion(ctx)) {
+			return mcp.NewToolResultStructuredOnly(map[string]any{"temperature": 21.5}), nil
+		}
+		// What a server without structured results sends.
+		return mcp.NewToolResultText(`{"temperature": 21.5}`), nil
+	})
+
+	tests := []struct {
+		name    string
+		version string
+		options []ClientOption
+		want    any
+	}{
+		{"legacy server", "2025-03-26", []ClientOption{WithStructuredContentCompat()}, map[string]any{"temperature": 21.5}},
+		{"legacy server without compat", "2025-03-26", nil, nil},
+		{"current server", mcp.LATEST_PROTOCOL_VERSION, []ClientOption{WithStructuredContentCompat()}, map[string]any{"temperature": 21.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpServer := server.NewTestStreamableHTTPServer(s)
+			defer httpServer.Close()
+			tr, err := transport.NewStreamableHTTP(httpServer.URL)
+			require.NoError(t, err)
+			c := NewClient(tr, tt.options...)
+			ctx := context.Background()
+			require.NoError(t, c.Start(ctx))
+			defer c.Close()
+
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = tt.version
+			_, err = c.Initialize(ctx, initRequest)
+			require.NoError(t, err)
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "weather"
+			result, err := c.CallTool(ctx, request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.StructuredContent)
+		})
+	}
+}
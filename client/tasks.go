@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultTaskPollInterval is used by AwaitTask when the server does not
+// suggest a poll interval for the task.
+const defaultTaskPollInterval = time.Second
+
+// CallToolAsTask sends a task-augmented tools/call request.
+// The server accepts the call and returns the task that tracks it; the tool
+// result is retrieved later with GetToolTaskResult.
+// If request.Params.Task is nil, a task without an explicit TTL is requested.
+func (c *Client) CallToolAsTask(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CreateTaskResult, error) {
+	if request.Params.Task == nil {
+		request.Params.Task = &mcp.TaskParams{}
+	}
+
+	response, err := c.sendRequest(ctx, "tools/call", request.Params, request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.CreateTaskResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetTask retrieves the current state of a task.
+func (c *Client) GetTask(
+	ctx context.Context,
+	request mcp.GetTaskRequest,
+) (*mcp.GetTaskResult, error) {
+	response, err := c.sendRequest(ctx, string(mcp.MethodTasksGet), request.Params, request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.GetTaskResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListTasksByPage manually list tasks by page.
+func (c *Client) ListTasksByPage(
+	ctx context.Context,
+	request mcp.ListTasksRequest,
+) (*mcp.ListTasksResult, error) {
+	result, err := listByPage[mcp.ListTasksResult](ctx, c, request.PaginatedRequest, string(mcp.MethodTasksList))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListTasks lists all tasks visible to this client, following pagination cursors.
+func (c *Client) ListTasks(
+	ctx context.Context,
+	request mcp.ListTasksRequest,
+) (*mcp.ListTasksResult, error) {
+	result, err := c.ListTasksByPage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for result.NextCursor != "" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			request.Params.Cursor = result.NextCursor
+			newPageRes, err := c.ListTasksByPage(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			result.Tasks = append(result.Tasks, newPageRes.Tasks...)
+			result.NextCursor = newPageRes.NextCursor
+		}
+	}
+	return result, nil
+}
+
+// CancelTask asks the server to cancel a task and returns its updated state.
+func (c *Client) CancelTask(
+	ctx context.Context,
+	request mcp.CancelTaskRequest,
+) (*mcp.CancelTaskResult, error) {
+	response, err := c.sendRequest(ctx, string(mcp.MethodTasksCancel), request.Params, request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.CancelTaskResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetToolTaskResult retrieves the result of a task created by CallToolAsTask.
+// The server blocks until the task reaches a terminal status.
+func (c *Client) GetToolTaskResult(
+	ctx context.Context,
+	request mcp.TaskResultRequest,
+) (*mcp.CallToolResult, error) {
+	response, err := c.sendRequest(ctx, string(mcp.MethodTasksResult), request.Params, request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.ParseCallToolResult(response)
+}
+
+// AwaitTask polls tasks/get until the task reaches a terminal status or ctx
+// is done. The poll interval suggested by the server is honoured; when the
+// server does not provide one, a one second interval is used. With
+// WithReconnectResync, polling continues through transport errors.
+func (c *Client) AwaitTask(ctx context.Context, taskID string) (*mcp.Task, error) {
+	defer c.resync.awaiting(taskID)()
+
+	request := mcp.GetTaskRequest{Params: mcp.GetTaskParams{TaskId: taskID}}
+	interval := defaultTaskPollInterval
+	for {
+		result, err := c.GetTask(ctx, request)
+		switch {
+		case err != nil && (ctx.Err() != nil || !c.resync.retries(err)):
+			return nil, err
+		case err != nil:
+			// Poll again at the last interval until the connection is back.
+		case result.Status.IsTerminal():
+			return &result.Task, nil
+		case result.PollInterval != nil && *result.PollInterval > 0:
+			interval = time.Duration(*result.PollInterval) * time.Millisecond
+		default:
+			interval = defaultTaskPollInterval
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
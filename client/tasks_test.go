@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTaskTransport replies to each method with the next scripted response.
+type mockTaskTransport struct {
+	mu        sync.Mutex
+	responses map[string][]string
+	requests  []transport.JSONRPCRequest
+}
+
+func (m *mockTaskTransport) Start(ctx context.Context) error { return nil }
+
+func (m *mockTaskTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, request)
+
+	queue := m.responses[request.Method]
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("no mock response for method %s", request.Method)
+	}
+	response := queue[0]
+	if len(queue) > 1 {
+		m.responses[request.Method] = queue[1:]
+	}
+	return transport.NewJSONRPCResultResponse(request.ID, json.RawMessage(response)), nil
+}
+
+func (m *mockTaskTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return nil
+}
+
+func (m *mockTaskTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+}
+
+func (m *mockTaskTransport) Close() error { return nil }
+
+func (m *mockTaskTransport) GetSessionId() string { return "" }
+
+func newTaskTestClient(t *testing.T, responses map[string][]string) (*Client, *mockTaskTransport) {
+	t.Helper()
+	mock := &mockTaskTransport{responses: responses}
+	c := NewClient(mock, WithSession())
+	require.NoError(t, c.Start(context.Background()))
+	return c, mock
+}
+
+func TestClient_CallToolAsTask(t *testing.T) {
+	c, mock := newTaskTestClient(t, map[string][]string{
+		"tools/call": {`{"task":{"taskId":"task-1","status":"working","createdAt":"2025-01-01T00:00:00Z","ttl":60000}}`},
+	})
+
+	result, err := c.CallToolAsTask(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "long_running"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", result.Task.TaskId)
+	assert.Equal(t, mcp.TaskStatusWorking, result.Task.Status)
+
+	require.Len(t, mock.requests, 1)
+	params, ok := mock.requests[0].Params.(mcp.CallToolParams)
+	require.True(t, ok)
+	assert.NotNil(t, params.Task, "task params should be added when missing")
+}
+
+func TestClient_TaskLifecycle(t *testing.T) {
+	c, _ := newTaskTestClient(t, map[string][]string{
+		"tasks/get":    {`{"taskId":"task-1","status":"working","createdAt":"2025-01-01T00:00:00Z","ttl":null}`},
+		"tasks/list":   {`{"tasks":[{"taskId":"task-1","status":"working","createdAt":"2025-01-01T00:00:00Z","ttl":null}],"nextCursor":"abc"}`, `{"tasks":[{"taskId":"task-2","status":"completed","createdAt":"2025-01-01T00:00:00Z","ttl":null}]}`},
+		"tasks/cancel": {`{"taskId":"task-1","status":"cancelled","createdAt":"2025-01-01T00:00:00Z","ttl":null}`},
+		"tasks/result": {`{"content":[{"type":"text","text":"done"}]}`},
+	})
+	ctx := context.Background()
+
+	task, err := c.GetTask(ctx, mcp.GetTaskRequest{Params: mcp.GetTaskParams{TaskId: "task-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, mcp.TaskStatusWorking, task.Status)
+
+	list, err := c.ListTasks(ctx, mcp.ListTasksRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Tasks, 2)
+	assert.Equal(t, "task-2", list.Tasks[1].TaskId)
+
+	cancelled, err := c.CancelTask(ctx, mcp.CancelTaskRequest{Params: mcp.CancelTaskParams{TaskId: "task-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, mcp.TaskStatusCancelled, cancelled.Status)
+
+	toolResult, err := c.GetToolTaskResult(ctx, mcp.TaskResultRequest{Params: mcp.TaskResultParams{TaskId: "task-1"}})
+	require.NoError(t, err)
+	require.Len(t, toolResult.Content, 1)
+	assert.Equal(t, "done", toolResult.Content[0].(mcp.TextContent).Text)
+}
+
+func TestClient_AwaitTask(t *testing.T) {
+	t.Run("polls until terminal", func(t *testing.T) {
+		c, mock := newTaskTestClient(t, map[string][]string{
+			"tasks/get": {
+				`{"taskId":"task-1","status":"working","createdAt":"2025-01-01T00:00:00Z","ttl":null,"pollInterval":5}`,
+				`{"taskId":"task-1","status":"input_required","createdAt":"2025-01-01T00:00:00Z","ttl":null,"pollInterval":5}`,
+				`{"taskId":"task-1","status":"completed","createdAt":"2025-01-01T00:00:00Z","ttl":null}`,
+			},
+		})
+
+		task, err := c.AwaitTask(context.Background(), "task-1")
+		require.NoError(t, err)
+		assert.Equal(t, mcp.TaskStatusCompleted, task.Status)
+		assert.Len(t, mock.requests, 3)
+	})
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		c, _ := newTaskTestClient(t, map[string][]string{
+			"tasks/get": {`{"taskId":"task-1","status":"working","createdAt":"2025-01-01T00:00:00Z","ttl":null,"pollInterval":10000}`},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := c.AwaitTask(ctx, "task-1")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -22,6 +22,8 @@ type InProcessTransport struct {
 	notifyMu       sync.RWMutex
 	started        bool
 	startedMu      sync.Mutex
+	done           chan struct{}
+	closeOnce      sync.Once
 }
 
 type InProcessOption func(*InProcessTransport)
@@ -47,6 +49,7 @@ func WithRootsHandler(handler server.RootsHandler) InProcessOption {
 func NewInProcessTransport(server *server.MCPServer) *InProcessTransport {
 	return &InProcessTransport{
 		server: server,
+		done:   make(chan struct{}),
 	}
 }
 
@@ -54,6 +57,7 @@ func NewInProcessTransportWithOptions(server *server.MCPServer, opts ...InProces
 	t := &InProcessTransport{
 		server:    server,
 		sessionID: server.GenerateInProcessSessionID(),
+		done:      make(chan struct{}),
 	}
 
 	for _, opt := range opts {
@@ -81,10 +85,29 @@ func (c *InProcessTransport) Start(ctx context.Context) error {
 			c.startedMu.Unlock()
 			return fmt.Errorf("failed to register session: %w", err)
 		}
+		go c.forwardNotifications()
 	}
 	return nil
 }
 
+// forwardNotifications passes the server's notifications to the session on
+// to the notification handler until the transport is closed.
+func (c *InProcessTransport) forwardNotifications() {
+	for {
+		select {
+		case notification := <-c.session.Notifications():
+			c.notifyMu.RLock()
+			handler := c.onNotification
+			c.notifyMu.RUnlock()
+			if handler != nil {
+				handler(notification)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
 func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
@@ -129,6 +152,7 @@ func (c *InProcessTransport) SetNotificationHandler(handler func(notification mc
 }
 
 func (c *InProcessTransport) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
 	if c.session != nil {
 		c.server.UnregisterSession(context.Background(), c.sessionID)
 	}
@@ -13,6 +13,21 @@ import (
 // as HTTP headers in outgoing requests.
 type HTTPHeaderFunc func(context.Context) map[string]string
 
+// HTTPHeaderProvider computes headers for an outgoing HTTP request from the
+// request's context. It is called for every request, so it can supply
+// short-lived credentials or per-tenant headers, and may return headers with
+// several values.
+type HTTPHeaderProvider func(context.Context) http.Header
+
+// ContextHeaderSource is implemented by transports that add headers computed
+// from the request's context, e.g. with an HTTPHeaderFunc or an
+// HTTPHeaderProvider. ContextHeaders returns the headers a request sent with
+// ctx would carry, so that requests for different tenants or credentials
+// are told apart.
+type ContextHeaderSource interface {
+	ContextHeaders(ctx context.Context) http.Header
+}
+
 // Interface for the transport layer.
 type Interface interface {
 	// Start the connection. Start should only be called once.
@@ -636,6 +636,17 @@ func (c *SSE) GetEndpoint() *url.URL {
 	return c.endpoint
 }
 
+// ContextHeaders returns the headers computed from ctx by the header func.
+func (c *SSE) ContextHeaders(ctx context.Context) http.Header {
+	header := make(http.Header)
+	if c.headerFunc != nil {
+		for k, v := range c.headerFunc(ctx) {
+			header.Set(k, v)
+		}
+	}
+	return header
+}
+
 // GetBaseURL returns the base URL set in the SSE constructor.
 func (c *SSE) GetBaseURL() *url.URL {
 	return c.baseURL
@@ -11,6 +11,7 @@ import (
 	"mime"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -54,6 +55,17 @@ func WithHTTPHeaderFunc(headerFunc HTTPHeaderFunc) StreamableHTTPCOption {
 	}
 }
 
+// WithHeaderProvider sets a function that computes headers for every HTTP
+// request sent by the transport, including the standalone GET stream and the
+// DELETE that closes the session. It is evaluated per request, after the
+// static headers, the OAuth authorization and the HTTPHeaderFunc, and its
+// headers replace theirs.
+func WithHeaderProvider(provider HTTPHeaderProvider) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.headerProvider = provider
+	}
+}
+
 // WithHTTPTimeout sets the timeout for a HTTP request and stream.
 func WithHTTPTimeout(timeout time.Duration) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
@@ -112,6 +124,7 @@ type StreamableHTTP struct {
 	httpClient          *http.Client
 	headers             map[string]string
 	headerFunc          HTTPHeaderFunc
+	headerProvider      HTTPHeaderProvider
 	host                string
 	logger              util.Logger
 	getListeningEnabled bool
@@ -228,6 +241,8 @@ func (c *StreamableHTTP) Close() error {
 				}
 			}
 
+			c.applyHeaderProvider(ctx, req)
+
 			// Set custom Host header if provided
 			if c.host != "" {
 				req.Host = c.host
@@ -359,6 +374,37 @@ func (c *StreamableHTTP) SendRequest(
 	}
 }
 
+// ContextHeaders returns the headers computed from ctx by the HTTPHeaderFunc
+// and the header provider.
+func (c *StreamableHTTP) ContextHeaders(ctx context.Context) http.Header {
+	header := make(http.Header)
+	if c.headerFunc != nil {
+		for k, v := range c.headerFunc(ctx) {
+			header.Set(k, v)
+		}
+	}
+	if c.headerProvider != nil {
+		for k, v := range c.headerProvider(ctx) {
+			header[http.CanonicalHeaderKey(k)] = slices.Clone(v)
+		}
+	}
+	return header
+}
+
+// applyHeaderProvider sets the headers computed by the header provider on req.
+func (c *StreamableHTTP) applyHeaderProvider(ctx context.Context, req *http.Request) {
+	if c.headerProvider == nil {
+		return
+	}
+	for k, v := range c.headerProvider(ctx) {
+		if len(v) == 0 {
+			req.Header.Del(k)
+			continue
+		}
+		req.Header[http.CanonicalHeaderKey(k)] = slices.Clone(v)
+	}
+}
+
 func (c *StreamableHTTP) sendHTTP(
 	ctx context.Context,
 	method string,
@@ -419,6 +465,7 @@ func (c *StreamableHTTP) sendHTTP(
 			req.Header.Set(k, v)
 		}
 	}
+	c.applyHeaderProvider(ctx, req)
 
 	// Send request
 	resp, err = c.httpClient.Do(req)
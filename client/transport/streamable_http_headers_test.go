@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type tenantKey struct{}
+
+func TestStreamableHTTP_WithHeaderProvider(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.Method] = append(received[r.Method], r.Header.Clone())
+		mu.Unlock()
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": "ok"})
+	}))
+	defer server.Close()
+
+	var tokens atomic.Int32
+	trans, err := NewStreamableHTTP(server.URL,
+		WithSession("session-1"),
+		WithHTTPHeaders(map[string]string{"Authorization": "Bearer static", "X-Static": "yes"}),
+		WithHeaderProvider(func(ctx context.Context) http.Header {
+			header := http.Header{}
+			header.Set("Authorization", fmt.Sprintf("Bearer jwt-%d", tokens.Add(1)))
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				header.Set("X-Tenant", tenant)
+			}
+			header.Add("X-Scope", "read")
+			header.Add("X-Scope", "write")
+			return header
+		}),
+	)
+	require.NoError(t, err)
+
+	for i := range 2 {
+		ctx := context.WithValue(context.Background(), tenantKey{}, fmt.Sprintf("tenant-%d", i))
+		_, err := trans.SendRequest(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: mcp.NewRequestId(1), Method: "ping"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, trans.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	posts := received[http.MethodPost]
+	require.Len(t, posts, 2)
+	for i, header := range posts {
+		assert.Equal(t, fmt.Sprintf("Bearer jwt-%d", i+1), header.Get("Authorization"))
+		assert.Equal(t, fmt.Sprintf("tenant-%d", i), header.Get("X-Tenant"))
+		assert.Equal(t, []string{"read", "write"}, header.Values("X-Scope"))
+		assert.Equal(t, "yes", header.Get("X-Static"))
+	}
+
+	deletes := received[http.MethodDelete]
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "Bearer jwt-3", deletes[0].Get("Authorization"))
+}
@@ -0,0 +1,583 @@
+// Package clientgen generates typed Go client packages from the tool catalog
+// of an MCP server, so that Go services calling internal MCP servers get
+// compile-time checked arguments and results.
+//
+// The generated package has a Client wrapping a *client.Client, with one
+// method per tool, an arguments struct built from the tool's input schema
+// and, for tools with an output schema, a result struct decoded from the
+// structured content:
+//
+//	src, err := clientgen.GenerateFromServer(ctx, c, clientgen.Config{Package: "search"})
+//
+// The mcpclientgen command does the same from the command line, e.g. in a
+// go:generate directive.
+package clientgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultPackage is the name of the generated package unless set in Config.
+const DefaultPackage = "mcpclient"
+
+// ErrUnknownTool is returned by Generate for tools listed in Config.Tools
+// that the catalog does not have.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// ErrUnsafeName is returned by Generate for tool and property names that
+// cannot be written into the generated source as they are, so that a server
+// cannot inject code through its catalog.
+var ErrUnsafeName = errors.New("unsafe name")
+
+// Config configures the generated package.
+type Config struct {
+	// Package is the name of the generated package. Defaults to
+	// DefaultPackage.
+	Package string
+	// Source describes where the catalog comes from, such as the server's
+	// URL or command, in the header of the generated file.
+	Source string
+	// Tools limits the generated methods to the named tools. All tools are
+	// generated by default.
+	Tools []string
+}
+
+// GenerateFromServer lists the tools of the server c is connected to and
+// generates their client package with Generate. c must be initialized.
+func GenerateFromServer(ctx context.Context, c *client.Client, config Config) ([]byte, error) {
+	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("list tools: %w", err)
+	}
+	return Generate(result.Tools, config)
+}
+
+// Generate returns the gofmt-ed source of a client package for tools.
+func Generate(tools []mcp.Tool, config Config) ([]byte, error) {
+	if config.Package == "" {
+		config.Package = DefaultPackage
+	}
+	if !isIdentifier(config.Package) {
+		return nil, fmt.Errorf("invalid package name %q", config.Package)
+	}
+	if strings.ContainsFunc(config.Source, unicode.IsControl) {
+		return nil, fmt.Errorf("invalid source %q", config.Source)
+	}
+	selected, err := selectTools(tools, config.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{names: map[string]bool{"Client": true, "New": true, "ToolError": true}}
+	methods := map[string]bool{}
+	for _, tool := range selected {
+		if err := g.addTool(tool, methods); err != nil {
+			return nil, fmt.Errorf("tool %s: %w", tool.Name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by mcpclientgen")
+	if config.Source != "" {
+		fmt.Fprintf(&out, " from %s", config.Source)
+	}
+	out.WriteString(". DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s calls the tools of an MCP server with typed arguments and\n// results.\n", config.Package)
+	fmt.Fprintf(&out, "package %s\n\n", config.Package)
+	out.WriteString(header)
+	for _, decl := range g.decls {
+		out.WriteString(decl)
+	}
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+func selectTools(tools []mcp.Tool, names []string) ([]mcp.Tool, error) {
+	selected := append([]mcp.Tool(nil), tools...)
+	if len(names) > 0 {
+		byName := make(map[string]mcp.Tool, len(tools))
+		for _, tool := range tools {
+			byName[tool.Name] = tool
+		}
+		selected = selected[:0]
+		for _, name := range names {
+			tool, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownTool, name)
+			}
+			selected = append(selected, tool)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+	return selected, nil
+}
+
+// header declares the Client and the helpers of the generated methods.
+const header = `import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Client calls the tools of an MCP server.
+type Client struct {
+	client *client.Client
+}
+
+// New returns a Client calling tools through c, which must be initialized.
+func New(c *client.Client) *Client {
+	return &Client{client: c}
+}
+
+// ToolError is returned for tool results flagged as errors.
+type ToolError struct {
+	Tool   string
+	Result *mcp.CallToolResult
+}
+
+func (e *ToolError) Error() string {
+	var texts []string
+	for _, content := range e.Result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, strings.Join(texts, "\n"))
+}
+
+func (c *Client) call(ctx context.Context, name string, args any) (*mcp.CallToolResult, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := c.client.CallTool(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		return nil, &ToolError{Tool: name, Result: result}
+	}
+	return result, nil
+}
+
+// decodeStructured decodes the structured content of a result into out,
+// falling back to the JSON text content of servers that only send text.
+func decodeStructured(result *mcp.CallToolResult, out any) error {
+	if result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, out)
+	}
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			return json.Unmarshal([]byte(text.Text), out)
+		}
+	}
+	return errors.New("result has no structured content")
+}
+
+`
+
+type generator struct {
+	decls []string
+	// names are the package-level identifiers taken so far.
+	names map[string]bool
+	// refs maps the $refs of the schema being generated to their types.
+	refs map[string]string
+	// err is the first unsafe name found in the schemas.
+	err error
+}
+
+func (g *generator) addTool(tool mcp.Tool, methods map[string]bool) error {
+	if err := checkToolName(tool.Name); err != nil {
+		return err
+	}
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return err
+	}
+	var schemas struct {
+		InputSchema  map[string]any `json:"inputSchema"`
+		OutputSchema map[string]any `json:"outputSchema"`
+	}
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return err
+	}
+
+	method := unique(exportedName(tool.Name), methods)
+	var argsType, resultType string
+	if props, _ := schemas.InputSchema["properties"].(map[string]any); len(props) > 0 {
+		argsType = g.namedType(method+"Args", fmt.Sprintf("are the arguments of the %s tool.", tool.Name), schemas.InputSchema)
+	}
+	if schemas.OutputSchema != nil {
+		resultType = g.namedType(method+"Result", fmt.Sprintf("is the structured result of the %s tool.", tool.Name), schemas.OutputSchema)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s calls the %s tool.\n", method, tool.Name)
+	if tool.Description != "" {
+		b.WriteString("//\n")
+		writeComment(&b, "", tool.Description)
+	}
+	params, args := "ctx context.Context", "nil"
+	if argsType != "" {
+		params, args = fmt.Sprintf("ctx context.Context, args %s", argsType), "args"
+	}
+	if resultType == "" {
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) (*mcp.CallToolResult, error) {\n", method, params)
+		fmt.Fprintf(&b, "\treturn c.call(ctx, %q, %s)\n}\n\n", tool.Name, args)
+	} else {
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) (*%s, error) {\n", method, params, resultType)
+		fmt.Fprintf(&b, "\tresult, err := c.call(ctx, %q, %s)\n", tool.Name, args)
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(&b, "\tvar out %s\n", resultType)
+		fmt.Fprintf(&b, "\tif err := decodeStructured(result, &out); err != nil {\n\t\treturn nil, fmt.Errorf(\"decode %s result: %%w\", err)\n\t}\n", tool.Name)
+		b.WriteString("\treturn &out, nil\n}\n\n")
+	}
+	g.decls = append(g.decls, b.String())
+	return g.err
+}
+
+// namedType declares the type of a top-level schema under name, whose
+// $refs are resolved against it.
+func (g *generator) namedType(name, doc string, root map[string]any) string {
+	g.refs = map[string]string{}
+	schema := root
+	if ref, ok := root["$ref"].(string); ok {
+		if target := resolveRef(ref, root); target != nil {
+			schema = target
+			if isObject(target) {
+				name = unique(name, g.names)
+				g.refs[ref] = name
+				return g.structType(name, name+" "+doc, target, root)
+			}
+		}
+	}
+	if isObject(schema) {
+		name = unique(name, g.names)
+		return g.structType(name, name+" "+doc, schema, root)
+	}
+	// Not an object with properties: alias its type, e.g. map[string]any.
+	typ := g.typeOf(schema, name, "part of "+name, root)
+	name = unique(name, g.names)
+	var b strings.Builder
+	writeComment(&b, "", name+" "+doc)
+	fmt.Fprintf(&b, "type %s = %s\n\n", name, typ)
+	g.declare(b.String())
+	return name
+}
+
+func (g *generator) declare(decl string) int {
+	g.decls = append(g.decls, decl)
+	return len(g.decls) - 1
+}
+
+// typeOf returns the Go type of a schema, declaring the structs it needs
+// with names starting with name; of tells what the schema describes.
+func (g *generator) typeOf(schema map[string]any, name, of string, root map[string]any) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		if typ, ok := g.refs[ref]; ok {
+			return typ
+		}
+		target := resolveRef(ref, root)
+		if target == nil {
+			return "any"
+		}
+		if !isObject(target) {
+			typ := g.typeOf(target, name, of, root)
+			g.refs[ref] = typ
+			return typ
+		}
+		// Register the struct before declaring its fields, which may
+		// refer back to it.
+		defName := ref[strings.LastIndex(ref, "/")+1:]
+		refName := unique(exportedName(defName), g.names)
+		g.refs[ref] = refName
+		return g.structType(refName, structDoc(refName, "the "+defName+" schema definition", target), target, root)
+	}
+
+	typ, nullable := schemaType(schema)
+	var goType string
+	switch typ {
+	case "string":
+		goType = "string"
+	case "integer":
+		goType = "int64"
+	case "number":
+		goType = "float64"
+	case "boolean":
+		goType = "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return "[]any"
+		}
+		return "[]" + g.typeOf(items, name+"Item", "an item of "+of, root)
+	case "object":
+		if !isObject(schema) {
+			if values, ok := schema["additionalProperties"].(map[string]any); ok {
+				return "map[string]" + g.typeOf(values, name+"Value", "a value of "+of, root)
+			}
+			return "map[string]any"
+		}
+		name = unique(name, g.names)
+		goType = g.structType(name, structDoc(name, of, schema), schema, root)
+	default:
+		return "any"
+	}
+	if nullable {
+		return "*" + goType
+	}
+	return goType
+}
+
+// structType declares the struct of an object schema.
+func (g *generator) structType(name, doc string, schema, root map[string]any) string {
+	// Reserve the declaration's place, so that it comes before the
+	// structs of its fields.
+	index := g.declare("")
+	props, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	if list, ok := schema["required"].([]any); ok {
+		for _, key := range list {
+			if key, ok := key.(string); ok {
+				required[key] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		if err := checkPropertyName(key); err != nil && g.err == nil {
+			g.err = err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	if doc != "" {
+		writeComment(&b, "", doc)
+	}
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, key := range keys {
+		prop, _ := props[key].(map[string]any)
+		field := unique(exportedName(key), fields)
+		typ := g.typeOf(prop, name+field, "the "+key+" property of "+name, root)
+		tag := key
+		if !required[key] {
+			tag += ",omitempty"
+			if isStruct(typ) || isScalar(typ) {
+				typ = "*" + typ
+			}
+		}
+		if doc := description(prop); doc != "" {
+			writeComment(&b, "\t", doc)
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	b.WriteString("}\n\n")
+	g.decls[index] = b.String()
+	return name
+}
+
+// structDoc returns the doc comment of a nested struct: what it is,
+// followed by its description.
+func structDoc(name, of string, schema map[string]any) string {
+	doc := name + " is " + of + "."
+	if description := description(schema); description != "" {
+		doc += "\n\n" + description
+	}
+	return doc
+}
+
+// isObject reports whether a schema is an object with properties, which
+// becomes a struct.
+func isObject(schema map[string]any) bool {
+	typ, _ := schemaType(schema)
+	props, _ := schema["properties"].(map[string]any)
+	return typ == "object" && len(props) > 0
+}
+
+// schemaType returns the JSON type of a schema, and whether it may be null.
+func schemaType(schema map[string]any) (string, bool) {
+	switch typ := schema["type"].(type) {
+	case string:
+		return typ, false
+	case []any:
+		var types []string
+		nullable := false
+		for _, t := range typ {
+			if t == "null" {
+				nullable = true
+			} else if t, ok := t.(string); ok {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 1 {
+			return types[0], nullable
+		}
+		return "", false
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object", false
+	}
+	return "", false
+}
+
+// resolveRef returns the schema a local $ref such as "#/$defs/Item" points
+// to, or nil.
+func resolveRef(ref string, root map[string]any) map[string]any {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+	var node any = root
+	for _, part := range strings.Split(path, "/") {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	target, _ := node.(map[string]any)
+	return target
+}
+
+// description returns the description of a schema, followed by the values
+// of its enum.
+func description(schema map[string]any) string {
+	doc, _ := schema["description"].(string)
+	if values, ok := schema["enum"].([]any); ok && len(values) > 0 {
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			data, _ := json.Marshal(value)
+			quoted[i] = string(data)
+		}
+		if doc != "" {
+			doc = strings.TrimRight(doc, ".") + ". "
+		}
+		doc += "One of " + strings.Join(quoted, ", ") + "."
+	}
+	return doc
+}
+
+func writeComment(b *strings.Builder, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			fmt.Fprintf(b, "%s//\n", indent)
+		} else {
+			fmt.Fprintf(b, "%s// %s\n", indent, line)
+		}
+	}
+}
+
+func isStruct(typ string) bool {
+	return typ != "" && unicode.IsUpper(rune(typ[0]))
+}
+
+func isScalar(typ string) bool {
+	switch typ {
+	case "string", "int64", "float64", "bool":
+		return true
+	}
+	return false
+}
+
+// initialisms are written in upper case in Go names, as golint suggests.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "sql": true, "ttl": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// exportedName turns a tool or property name such as "list_files",
+// "get-user" or "maxResults" into an exported Go identifier.
+func exportedName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// unique returns name, or name followed by the first free number, and
+// marks it as taken.
+func unique(name string, taken map[string]bool) string {
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// checkToolName rejects tool names that would need escaping in the comments
+// and string literals of the generated code.
+func checkToolName(name string) error {
+	unsafe := strings.ContainsFunc(name, func(r rune) bool {
+		return r == '`' || r == '"' || r == '\\' || r == ',' || !unicode.IsPrint(r)
+	})
+	if name == "" || unsafe {
+		return fmt.Errorf("%w: tool name %q", ErrUnsafeName, name)
+	}
+	return nil
+}
+
+// checkPropertyName rejects property names that are not valid names in
+// json struct tags, which encoding/json would ignore and which could close
+// the tag.
+func checkPropertyName(name string) error {
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r) {
+			return fmt.Errorf("%w: property name %q", ErrUnsafeName, name)
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("%w: empty property name", ErrUnsafeName)
+	}
+	return nil
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
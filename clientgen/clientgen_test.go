@@ -0,0 +1,247 @@
+package clientgen_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/clientgen"
+	"github.com/mark3labs/mcp-go/clientgen/internal/example"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var update = flag.Bool("update", false, "regenerate the example client")
+
+const examplePath = "internal/example/client.go"
+
+type searchHit struct {
+	Title string  `json:"title"`
+	URL   string  `json:"url"`
+	Score float64 `json:"score"`
+}
+
+type searchOutput struct {
+	Hits  []searchHit `json:"hits"`
+	Total int         `json:"total"`
+}
+
+type searchArgs struct {
+	Query   string `json:"query"`
+	Limit   int    `json:"limit"`
+	Filters struct {
+		Language string   `json:"language"`
+		Tags     []string `json:"tags"`
+	} `json:"filters"`
+}
+
+const statsSchema = `{
+	"type": "object",
+	"properties": {
+		"count": {"type": "integer", "description": "Number of indexed documents."},
+		"latest": {"$ref": "#/$defs/Entry"}
+	},
+	"required": ["count"],
+	"$defs": {
+		"Entry": {
+			"type": "object",
+			"properties": {"name": {"type": ["string", "null"]}}
+		}
+	}
+}`
+
+// exampleServer serves the tools the example client is generated from.
+func exampleServer() *server.MCPServer {
+	s := server.NewMCPServer("docs", "1.0.0")
+	s.AddTool(mcp.NewTool("search_docs",
+		mcp.WithDescription("Searches the documentation.\nHits are ranked by relevance."),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Full-text query.")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of hits.")),
+		mcp.WithObject("filters", mcp.Properties(map[string]any{
+			"language": map[string]any{"type": "string", "enum": []any{"go", "python"}},
+			"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		})),
+		mcp.WithOutputSchema[searchOutput](),
+	), mcp.NewStructuredToolHandler(func(ctx context.Context, request mcp.CallToolRequest, args searchArgs) (searchOutput, error) {
+		return searchOutput{
+			Hits:  []searchHit{{Title: args.Query + " in " + args.Filters.Language, URL: "https://docs.example/1", Score: 0.5}},
+			Total: args.Limit,
+		}, nil
+	}))
+	s.AddTool(mcp.NewTool("get-user",
+		mcp.WithString("user_id", mcp.Required()),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := request.GetString("user_id", "")
+		if id == "" {
+			return mcp.NewToolResultError("user_id is empty"), nil
+		}
+		return mcp.NewToolResultText("user " + id), nil
+	})
+	s.AddTool(mcp.NewTool("stats", mcp.WithRawOutputSchema(json.RawMessage(statsSchema))),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultStructuredOnly(map[string]any{"count": 3, "latest": map[string]any{"name": "intro"}}), nil
+		})
+	return s
+}
+
+func connect(t *testing.T, s *server.MCPServer) *client.Client {
+	t.Helper()
+	c, err := client.NewInProcessClient(s)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, request)
+	require.NoError(t, err)
+	return c
+}
+
+func TestGenerateFromServer_Example(t *testing.T) {
+	src, err := clientgen.GenerateFromServer(context.Background(), connect(t, exampleServer()), clientgen.Config{
+		Package: "example",
+		Source:  "the tools of clientgen_test.go",
+	})
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, os.WriteFile(examplePath, src, 0o644))
+	}
+	want, err := os.ReadFile(examplePath)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(src), "the example client is out of date; run go test ./clientgen -update")
+}
+
+func TestExampleClient(t *testing.T) {
+	ctx := context.Background()
+	docs := example.New(connect(t, exampleServer()))
+
+	limit := 7.0
+	language := "go"
+	result, err := docs.SearchDocs(ctx, example.SearchDocsArgs{
+		Query:   "contexts",
+		Limit:   &limit,
+		Filters: &example.SearchDocsArgsFilters{Language: &language},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 1)
+	assert.Equal(t, "contexts in go", result.Hits[0].Title)
+	assert.Equal(t, int64(7), result.Total)
+
+	user, err := docs.GetUser(ctx, example.GetUserArgs{UserID: "42"})
+	require.NoError(t, err)
+	text, ok := mcp.AsTextContent(user.Content[0])
+	require.True(t, ok)
+	assert.Equal(t, "user 42", text.Text)
+
+	_, err = docs.GetUser(ctx, example.GetUserArgs{})
+	var toolErr *example.ToolError
+	require.True(t, errors.As(err, &toolErr), "got %v", err)
+	assert.Equal(t, "tool get-user failed: user_id is empty", err.Error())
+
+	stats, err := docs.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Count)
+	require.NotNil(t, stats.Latest)
+	assert.Equal(t, "intro", *stats.Latest.Name)
+}
+
+func TestGenerate(t *testing.T) {
+	tools := []mcp.Tool{
+		mcp.NewTool("list_files", mcp.WithString("dir")),
+		mcp.NewTool("list-files", mcp.WithBoolean("recursive", mcp.Required())),
+		mcp.NewTool("2fa"),
+	}
+
+	src, err := clientgen.Generate(tools, clientgen.Config{})
+	require.NoError(t, err)
+	code := string(src)
+	assert.Contains(t, code, "package mcpclient\n")
+	assert.Contains(t, code, "// Code generated by mcpclientgen. DO NOT EDIT.")
+	assert.Contains(t, code, "func (c *Client) ListFiles(ctx context.Context, args ListFilesArgs) (*mcp.CallToolResult, error)")
+	assert.Contains(t, code, "func (c *Client) ListFiles2(ctx context.Context, args ListFiles2Args) (*mcp.CallToolResult, error)")
+	assert.Contains(t, code, "func (c *Client) X2fa(ctx context.Context) (*mcp.CallToolResult, error)")
+	assert.Contains(t, code, "Dir *string `json:\"dir,omitempty\"`")
+	assert.Contains(t, code, "Recursive bool `json:\"recursive\"`")
+
+	src, err = clientgen.Generate(tools, clientgen.Config{Tools: []string{"2fa"}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(src), "ListFiles")
+
+	_, err = clientgen.Generate(tools, clientgen.Config{Tools: []string{"missing"}})
+	assert.ErrorIs(t, err, clientgen.ErrUnknownTool)
+
+	_, err = clientgen.Generate(tools, clientgen.Config{Package: "not-a-package"})
+	assert.Error(t, err)
+}
+
+func TestGenerate_RecursiveRef(t *testing.T) {
+	tool := mcp.NewTool("tree", mcp.WithRawOutputSchema(json.RawMessage(`{
+		"$ref": "#/$defs/Node",
+		"$defs": {"Node": {
+			"type": "object",
+			"properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/Node"}}}
+		}}
+	}`)))
+
+	src, err := clientgen.Generate([]mcp.Tool{tool}, clientgen.Config{})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "type TreeResult struct {\n\tChildren []TreeResult `json:\"children,omitempty\"`\n}")
+	assert.Contains(t, string(src), "func (c *Client) Tree(ctx context.Context) (*TreeResult, error)")
+}
+
+func TestGenerate_UnsafeNames(t *testing.T) {
+	tests := []struct {
+		name string
+		tool mcp.Tool
+	}{
+		{
+			name: "backtick in property",
+			tool: mcp.NewTool("tool", mcp.WithString("x` }; func init() { println(1) }; type Zz struct { Y string `y")),
+		},
+		{
+			name: "comma in property",
+			tool: mcp.NewTool("tool", mcp.WithString("x,string")),
+		},
+		{
+			name: "quote in nested property",
+			tool: mcp.NewTool("tool", mcp.WithObject("filter", mcp.Properties(map[string]any{
+				`a"b`: map[string]any{"type": "string"},
+			}))),
+		},
+		{
+			name: "newline in tool name",
+			tool: mcp.NewTool("a\nfunc init() { println(2) }\n//"),
+		},
+		{
+			name: "quote in tool name",
+			tool: mcp.NewTool(`a"b`),
+		},
+		{
+			name: "control character in tool name",
+			tool: mcp.NewTool("a\u2028b"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := clientgen.Generate([]mcp.Tool{tt.tool}, clientgen.Config{})
+			assert.ErrorIs(t, err, clientgen.ErrUnsafeName)
+			assert.Nil(t, src)
+		})
+	}
+
+	_, err := clientgen.Generate(nil, clientgen.Config{Source: "server\nfunc init() {}"})
+	assert.Error(t, err)
+
+	src, err := clientgen.Generate([]mcp.Tool{mcp.NewTool("get-user.v2", mcp.WithString("$filter:name"))}, clientgen.Config{})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "`json:\"$filter:name,omitempty\"`")
+}
@@ -0,0 +1,154 @@
+// Code generated by mcpclientgen from the tools of clientgen_test.go. DO NOT EDIT.
+
+// Package example calls the tools of an MCP server with typed arguments and
+// results.
+package example
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Client calls the tools of an MCP server.
+type Client struct {
+	client *client.Client
+}
+
+// New returns a Client calling tools through c, which must be initialized.
+func New(c *client.Client) *Client {
+	return &Client{client: c}
+}
+
+// ToolError is returned for tool results flagged as errors.
+type ToolError struct {
+	Tool   string
+	Result *mcp.CallToolResult
+}
+
+func (e *ToolError) Error() string {
+	var texts []string
+	for _, content := range e.Result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, strings.Join(texts, "\n"))
+}
+
+func (c *Client) call(ctx context.Context, name string, args any) (*mcp.CallToolResult, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := c.client.CallTool(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		return nil, &ToolError{Tool: name, Result: result}
+	}
+	return result, nil
+}
+
+// decodeStructured decodes the structured content of a result into out,
+// falling back to the JSON text content of servers that only send text.
+func decodeStructured(result *mcp.CallToolResult, out any) error {
+	if result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, out)
+	}
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			return json.Unmarshal([]byte(text.Text), out)
+		}
+	}
+	return errors.New("result has no structured content")
+}
+
+// GetUserArgs are the arguments of the get-user tool.
+type GetUserArgs struct {
+	UserID string `json:"user_id"`
+}
+
+// GetUser calls the get-user tool.
+func (c *Client) GetUser(ctx context.Context, args GetUserArgs) (*mcp.CallToolResult, error) {
+	return c.call(ctx, "get-user", args)
+}
+
+// SearchDocsArgs are the arguments of the search_docs tool.
+type SearchDocsArgs struct {
+	Filters *SearchDocsArgsFilters `json:"filters,omitempty"`
+	// Maximum number of hits.
+	Limit *float64 `json:"limit,omitempty"`
+	// Full-text query.
+	Query string `json:"query"`
+}
+
+// SearchDocsArgsFilters is the filters property of SearchDocsArgs.
+type SearchDocsArgsFilters struct {
+	// One of "go", "python".
+	Language *string  `json:"language,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// SearchDocsResult is the structured result of the search_docs tool.
+type SearchDocsResult struct {
+	Hits  []SearchDocsResultHitsItem `json:"hits"`
+	Total int64                      `json:"total"`
+}
+
+// SearchDocsResultHitsItem is an item of the hits property of SearchDocsResult.
+type SearchDocsResultHitsItem struct {
+	Score float64 `json:"score"`
+	Title string  `json:"title"`
+	URL   string  `json:"url"`
+}
+
+// SearchDocs calls the search_docs tool.
+//
+// Searches the documentation.
+// Hits are ranked by relevance.
+func (c *Client) SearchDocs(ctx context.Context, args SearchDocsArgs) (*SearchDocsResult, error) {
+	result, err := c.call(ctx, "search_docs", args)
+	if err != nil {
+		return nil, err
+	}
+	var out SearchDocsResult
+	if err := decodeStructured(result, &out); err != nil {
+		return nil, fmt.Errorf("decode search_docs result: %w", err)
+	}
+	return &out, nil
+}
+
+// StatsResult is the structured result of the stats tool.
+type StatsResult struct {
+	// Number of indexed documents.
+	Count  int64  `json:"count"`
+	Latest *Entry `json:"latest,omitempty"`
+}
+
+// Entry is the Entry schema definition.
+type Entry struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// Stats calls the stats tool.
+func (c *Client) Stats(ctx context.Context) (*StatsResult, error) {
+	result, err := c.call(ctx, "stats", nil)
+	if err != nil {
+		return nil, err
+	}
+	var out StatsResult
+	if err := decodeStructured(result, &out); err != nil {
+		return nil, fmt.Errorf("decode stats result: %w", err)
+	}
+	return &out, nil
+}
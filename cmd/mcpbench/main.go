@@ -0,0 +1,136 @@
+// Command mcpbench runs load scenarios against an MCP server over stdio or
+// streamable HTTP and prints latency percentiles, error rates and resource
+// growth.
+//
+// A scenario is either loaded from a JSON file (see mcpbench.ScenarioConfig)
+// or built from flags:
+//
+//	mcpbench -http http://localhost:8080/mcp -sessions 20 -duration 30s -call 'search={"q":"coffee"}'
+//	mcpbench -stdio './server' -scenario mixed.json -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcpbench"
+)
+
+// toolFlags collects repeated "tool" or "tool={json}" flags.
+type toolFlags []mcpbench.OperationConfig
+
+func (t *toolFlags) String() string { return fmt.Sprint(len(*t)) }
+
+func (t *toolFlags) Set(value string) error {
+	name, rawArgs, hasArgs := strings.Cut(value, "=")
+	op := mcpbench.OperationConfig{Tool: name}
+	if hasArgs {
+		if err := json.Unmarshal([]byte(rawArgs), &op.Arguments); err != nil {
+			return fmt.Errorf("invalid arguments for %s: %w", name, err)
+		}
+	}
+	*t = append(*t, op)
+	return nil
+}
+
+func main() {
+	var (
+		stdio      = flag.String("stdio", "", "command to launch a stdio server; one process is started per session")
+		httpURL    = flag.String("http", "", "URL of a streamable HTTP server")
+		file       = flag.String("scenario", "", "JSON scenario file")
+		sessions   = flag.Int("sessions", 10, "number of concurrent sessions")
+		duration   = flag.Duration("duration", 10*time.Second, "how long each session runs")
+		iterations = flag.Int("iterations", 0, "operations per session (0 means limited by -duration only)")
+		thinkTime  = flag.Duration("think", 0, "pause between operations in a session")
+		seed       = flag.Int64("seed", 1, "seed for the operation mix")
+		asJSON     = flag.Bool("json", false, "print the report as JSON")
+		calls      toolFlags
+		tasks      toolFlags
+	)
+	flag.Var(&calls, "call", "tool to call, optionally as tool={json-arguments} (repeatable)")
+	flag.Var(&tasks, "task", "tool to call as a task, optionally as tool={json-arguments} (repeatable)")
+	flag.Parse()
+
+	if (*stdio == "") == (*httpURL == "") {
+		fmt.Fprintln(os.Stderr, "mcpbench: exactly one of -stdio or -http must be given")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var scenario mcpbench.Scenario
+	var err error
+	if *file != "" {
+		scenario, err = loadScenarioFile(*file)
+	} else {
+		config := mcpbench.ScenarioConfig{
+			Sessions:   *sessions,
+			Iterations: *iterations,
+			ThinkTime:  thinkTime.String(),
+			Seed:       *seed,
+		}
+		if *iterations == 0 {
+			config.Duration = duration.String()
+		}
+		for _, op := range calls {
+			op.Type = "call"
+			config.Operations = append(config.Operations, op)
+		}
+		for _, op := range tasks {
+			op.Type = "task"
+			config.Operations = append(config.Operations, op)
+		}
+		if len(config.Operations) == 0 {
+			config.Operations = append(config.Operations, mcpbench.OperationConfig{Type: "list_tools"})
+		}
+		scenario, err = config.Scenario()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcpbench: %v\n", err)
+		os.Exit(2)
+	}
+
+	var factory mcpbench.ClientFactory
+	if *stdio != "" {
+		fields := strings.Fields(*stdio)
+		factory = mcpbench.Stdio(fields[0], nil, fields[1:]...)
+	} else {
+		factory = mcpbench.StreamableHTTP(*httpURL, transport.WithHTTPTimeout(time.Minute))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := mcpbench.Run(ctx, scenario, factory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcpbench: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcpbench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func loadScenarioFile(path string) (mcpbench.Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return mcpbench.Scenario{}, err
+	}
+	defer f.Close()
+	return mcpbench.LoadScenario(f)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// cli holds the connected client and the streams used by the commands.
+type cli struct {
+	client   *client.Client
+	in       *prompter
+	out      io.Writer
+	errOut   io.Writer
+	opts     options
+	watching bool
+}
+
+// connect creates, starts and initializes a client for the configured transport.
+func (c *cli) connect(ctx context.Context) (*client.Client, error) {
+	var trans transport.Interface
+	if c.opts.stdio != "" {
+		fields := strings.Fields(c.opts.stdio)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid stdio command")
+		}
+		trans = transport.NewStdio(fields[0], c.opts.env, fields[1:]...)
+	} else {
+		httpOptions := []transport.StreamableHTTPCOption{
+			transport.WithHTTPHeaders(c.opts.headers),
+		}
+		if c.watching {
+			httpOptions = append(httpOptions, transport.WithContinuousListening())
+		}
+		httpTransport, err := transport.NewStreamableHTTP(c.opts.httpURL, httpOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP transport: %w", err)
+		}
+		trans = httpTransport
+	}
+
+	mcpClient := client.NewClient(trans, client.WithElicitationHandler(c))
+	mcpClient.OnNotification(c.printNotification)
+
+	if err := mcpClient.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start client: %w", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcpcli",
+		Version: "1.0.0",
+	}
+	result, err := mcpClient.Initialize(ctx, initRequest)
+	if err != nil {
+		mcpClient.Close()
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	fmt.Fprintf(c.errOut, "connected to %s %s (protocol %s)\n",
+		result.ServerInfo.Name, result.ServerInfo.Version, result.ProtocolVersion)
+
+	return mcpClient, nil
+}
+
+func (c *cli) listTools(ctx context.Context) error {
+	result, err := c.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return err
+	}
+	for _, tool := range result.Tools {
+		fmt.Fprintf(c.out, "%s\t%s\n", tool.Name, tool.Description)
+	}
+	return nil
+}
+
+func (c *cli) listResources(ctx context.Context) error {
+	resources, err := c.client.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources.Resources {
+		fmt.Fprintf(c.out, "%s\t%s\t%s\n", resource.URI, resource.Name, resource.MIMEType)
+	}
+
+	templates, err := c.client.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		// Servers are not required to offer templates.
+		return nil
+	}
+	for _, template := range templates.ResourceTemplates {
+		fmt.Fprintf(c.out, "%s\t%s\t(template)\n", template.URITemplate.Raw(), template.Name)
+	}
+	return nil
+}
+
+func (c *cli) listPrompts(ctx context.Context) error {
+	result, err := c.client.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		return err
+	}
+	for _, prompt := range result.Prompts {
+		fmt.Fprintf(c.out, "%s\t%s\n", prompt.Name, prompt.Description)
+	}
+	return nil
+}
+
+func (c *cli) callTool(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	fs.SetOutput(c.errOut)
+	interactive := fs.Bool("i", false, "prompt for arguments using the tool's input schema")
+	asTask := fs.Bool("task", false, "run the call as a task and wait for its result")
+	ttl := fs.Int64("ttl", 0, "task TTL in milliseconds (with -task)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: call [-i] [-task] <tool> [json-arguments]")
+	}
+	name := fs.Arg(0)
+
+	var arguments map[string]any
+	switch {
+	case fs.NArg() > 1:
+		if err := json.Unmarshal([]byte(fs.Arg(1)), &arguments); err != nil {
+			return fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+	case *interactive:
+		tool, err := c.findTool(ctx, name)
+		if err != nil {
+			return err
+		}
+		arguments, err = c.in.promptObject(tool.InputSchema.Properties, tool.InputSchema.Required)
+		if err != nil {
+			return err
+		}
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+
+	if !*asTask {
+		result, err := c.client.CallTool(ctx, request)
+		if err != nil {
+			return err
+		}
+		return c.printJSON(result)
+	}
+
+	request.Params.Task = &mcp.TaskParams{}
+	if *ttl > 0 {
+		request.Params.Task.TTL = ttl
+	}
+	created, err := c.client.CallToolAsTask(ctx, request)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.errOut, "task %s created (%s)\n", created.Task.TaskId, created.Task.Status)
+	return c.awaitAndPrintResult(ctx, created.Task.TaskId)
+}
+
+func (c *cli) findTool(ctx context.Context, name string) (*mcp.Tool, error) {
+	result, err := c.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Tools {
+		if result.Tools[i].Name == name {
+			return &result.Tools[i], nil
+		}
+	}
+	return nil, fmt.Errorf("tool %q not found", name)
+}
+
+func (c *cli) readResource(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: read <uri>")
+	}
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = args[0]
+	result, err := c.client.ReadResource(ctx, request)
+	if err != nil {
+		return err
+	}
+	return c.printJSON(result)
+}
+
+func (c *cli) getPrompt(ctx context.Context, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("usage: prompt <name> [json-arguments]")
+	}
+	request := mcp.GetPromptRequest{}
+	request.Params.Name = args[0]
+	if len(args) == 2 {
+		if err := json.Unmarshal([]byte(args[1]), &request.Params.Arguments); err != nil {
+			return fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+	}
+	result, err := c.client.GetPrompt(ctx, request)
+	if err != nil {
+		return err
+	}
+	return c.printJSON(result)
+}
+
+// watch blocks until ctx is done; notifications are printed as they arrive.
+func (c *cli) watch(ctx context.Context) error {
+	fmt.Fprintln(c.errOut, "watching notifications, press Ctrl+C to stop")
+	<-ctx.Done()
+	return nil
+}
+
+func (c *cli) tasks(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: tasks list|get|await|result|cancel [task-id]")
+	}
+	if args[0] == "list" {
+		result, err := c.client.ListTasks(ctx, mcp.ListTasksRequest{})
+		if err != nil {
+			return err
+		}
+		for _, task := range result.Tasks {
+			fmt.Fprintf(c.out, "%s\t%s\t%s\n", task.TaskId, task.Status, task.StatusMessage)
+		}
+		return nil
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: tasks %s <task-id>", args[0])
+	}
+
+	taskID := args[1]
+	switch args[0] {
+	case "get":
+		result, err := c.client.GetTask(ctx, mcp.GetTaskRequest{Params: mcp.GetTaskParams{TaskId: taskID}})
+		if err != nil {
+			return err
+		}
+		return c.printJSON(result)
+	case "await":
+		task, err := c.client.AwaitTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		return c.printJSON(task)
+	case "result":
+		return c.awaitAndPrintResult(ctx, taskID)
+	case "cancel":
+		result, err := c.client.CancelTask(ctx, mcp.CancelTaskRequest{Params: mcp.CancelTaskParams{TaskId: taskID}})
+		if err != nil {
+			return err
+		}
+		return c.printJSON(result)
+	default:
+		return fmt.Errorf("unknown tasks command %q", args[0])
+	}
+}
+
+func (c *cli) awaitAndPrintResult(ctx context.Context, taskID string) error {
+	task, err := c.client.AwaitTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.errOut, "task %s finished (%s)\n", task.TaskId, task.Status)
+	if task.Status != mcp.TaskStatusCompleted {
+		return fmt.Errorf("task %s: %s", task.Status, task.StatusMessage)
+	}
+	result, err := c.client.GetToolTaskResult(ctx, mcp.TaskResultRequest{Params: mcp.TaskResultParams{TaskId: taskID}})
+	if err != nil {
+		return err
+	}
+	return c.printJSON(result)
+}
+
+// Elicit implements client.ElicitationHandler by prompting on stdin.
+func (c *cli) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	fmt.Fprintf(c.errOut, "\nserver asks: %s\n", request.Params.Message)
+	if c.opts.declineElicit {
+		fmt.Fprintln(c.errOut, "declined")
+		return &mcp.ElicitationResult{
+			ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline},
+		}, nil
+	}
+
+	if request.Params.Mode == mcp.ElicitationModeURL {
+		fmt.Fprintf(c.errOut, "open %s to continue\n", request.Params.URL)
+		return &mcp.ElicitationResult{
+			ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept},
+		}, nil
+	}
+
+	properties, required, err := schemaObject(request.Params.RequestedSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requested schema: %w", err)
+	}
+	content, err := c.in.promptObject(properties, required)
+	if err != nil {
+		fmt.Fprintf(c.errOut, "cancelled: %v\n", err)
+		return &mcp.ElicitationResult{
+			ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionCancel},
+		}, nil
+	}
+	return &mcp.ElicitationResult{
+		ElicitationResponse: mcp.ElicitationResponse{
+			Action:  mcp.ElicitationResponseActionAccept,
+			Content: content,
+		},
+	}, nil
+}
+
+func (c *cli) printNotification(notification mcp.JSONRPCNotification) {
+	data, err := json.Marshal(notification.Params)
+	if err != nil {
+		data = []byte("{}")
+	}
+	out := c.errOut
+	if c.watching {
+		out = c.out
+	}
+	fmt.Fprintf(out, "<- %s %s\n", notification.Method, data)
+}
+
+func (c *cli) printJSON(v any) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
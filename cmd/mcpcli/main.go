@@ -0,0 +1,165 @@
+// Command mcpcli is an interactive command line client for exploring MCP
+// servers over stdio or streamable HTTP.
+//
+// Usage:
+//
+//	mcpcli [flags] <command> [arguments]
+//
+// Commands:
+//
+//	tools                    list the tools offered by the server
+//	resources                list resources and resource templates
+//	prompts                  list prompts
+//	call <tool> [json]       call a tool (-i prompts for arguments, -task runs it as a task)
+//	read <uri>               read a resource
+//	prompt <name> [json]     get a prompt
+//	watch                    print notifications until interrupted
+//	tasks list               list tasks
+//	tasks get <id>           show a task
+//	tasks await <id>         poll a task until it finishes
+//	tasks result <id>        fetch the result of a tool task
+//	tasks cancel <id>        cancel a task
+//
+// Elicitation requests sent by the server are answered interactively on
+// stdin, using the requested schema to prompt for each field.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// headerFlags collects repeated -H "Name: value" flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	parts := make([]string, 0, len(h))
+	for k, v := range h {
+		parts = append(parts, k+": "+v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	if !ok {
+		return fmt.Errorf("header must be in the form 'Name: value'")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// envFlags collects repeated -e KEY=VALUE flags.
+type envFlags []string
+
+func (e *envFlags) String() string { return strings.Join(*e, ",") }
+
+func (e *envFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("environment variable must be in the form KEY=VALUE")
+	}
+	*e = append(*e, value)
+	return nil
+}
+
+// options holds the global command line configuration.
+type options struct {
+	stdio         string
+	httpURL       string
+	headers       headerFlags
+	env           envFlags
+	timeout       time.Duration
+	declineElicit bool
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "mcpcli: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	opts := options{headers: headerFlags{}}
+
+	fs := flag.NewFlagSet("mcpcli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.stdio, "stdio", "", "command to launch a stdio server (e.g. 'python server.py')")
+	fs.StringVar(&opts.httpURL, "http", "", "URL of a streamable HTTP server (e.g. 'http://localhost:8080/mcp')")
+	fs.Var(opts.headers, "H", "HTTP header 'Name: value' (repeatable)")
+	fs.Var(&opts.env, "e", "environment variable KEY=VALUE for the stdio server (repeatable)")
+	fs.DurationVar(&opts.timeout, "timeout", 0, "overall timeout for the command (0 means none)")
+	fs.BoolVar(&opts.declineElicit, "decline-elicitation", false, "decline all elicitation requests instead of prompting")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mcpcli [flags] <command> [arguments]")
+		fmt.Fprintln(stderr, "commands: tools, resources, prompts, call, read, prompt, watch, tasks")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (opts.stdio == "") == (opts.httpURL == "") {
+		fs.Usage()
+		return fmt.Errorf("exactly one of -stdio or -http must be given")
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	command, commandArgs := fs.Arg(0), fs.Args()[1:]
+	cli := &cli{
+		in:       newPrompter(stdin, stderr),
+		out:      stdout,
+		errOut:   stderr,
+		opts:     opts,
+		watching: command == "watch",
+	}
+
+	c, err := cli.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	cli.client = c
+
+	switch command {
+	case "tools":
+		return cli.listTools(ctx)
+	case "resources":
+		return cli.listResources(ctx)
+	case "prompts":
+		return cli.listPrompts(ctx)
+	case "call":
+		return cli.callTool(ctx, commandArgs)
+	case "read":
+		return cli.readResource(ctx, commandArgs)
+	case "prompt":
+		return cli.getPrompt(ctx, commandArgs)
+	case "watch":
+		return cli.watch(ctx)
+	case "tasks":
+		return cli.tasks(ctx, commandArgs)
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// prompter asks the user for values described by a JSON schema.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// promptObject asks for each property of an object schema and returns the
+// collected values. Optional properties left empty are omitted, required
+// properties are asked again until a valid value is given.
+func (p *prompter) promptObject(properties map[string]any, required []string) (map[string]any, error) {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	// Required properties first, then alphabetical, so the user is asked the
+	// important questions up front.
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := slices.Contains(required, names[i]), slices.Contains(required, names[j])
+		if ri != rj {
+			return ri
+		}
+		return names[i] < names[j]
+	})
+
+	values := make(map[string]any, len(names))
+	for _, name := range names {
+		schema, _ := properties[name].(map[string]any)
+		isRequired := slices.Contains(required, name)
+		for {
+			value, ok, err := p.promptValue(name, schema, isRequired)
+			if err == io.EOF {
+				if isRequired {
+					return nil, fmt.Errorf("missing value for required property %q", name)
+				}
+				return values, nil
+			}
+			if err != nil {
+				fmt.Fprintf(p.out, "  invalid value: %v\n", err)
+				continue
+			}
+			if ok {
+				values[name] = value
+			}
+			break
+		}
+	}
+	return values, nil
+}
+
+// promptValue asks for a single property. ok is false when the user skipped
+// an optional property.
+func (p *prompter) promptValue(name string, schema map[string]any, required bool) (value any, ok bool, err error) {
+	kind, _ := schema["type"].(string)
+	if kind == "" {
+		kind = "string"
+	}
+
+	label := name
+	if required {
+		label += "*"
+	}
+	hint := kind
+	if enum, isEnum := schema["enum"].([]any); isEnum {
+		options := make([]string, len(enum))
+		for i, option := range enum {
+			options[i] = fmt.Sprint(option)
+		}
+		hint = strings.Join(options, "|")
+	}
+	fmt.Fprintf(p.out, "%s (%s)", label, hint)
+	if description, _ := schema["description"].(string); description != "" {
+		fmt.Fprintf(p.out, " - %s", description)
+	}
+	if def, hasDefault := schema["default"]; hasDefault {
+		fmt.Fprintf(p.out, " [%v]", def)
+	}
+	fmt.Fprint(p.out, ": ")
+
+	line, readErr := p.in.ReadString('\n')
+	if readErr != nil && (readErr != io.EOF || line == "") {
+		return nil, false, readErr
+	}
+	line = strings.TrimSpace(line)
+
+	if line == "" {
+		if def, hasDefault := schema["default"]; hasDefault {
+			return def, true, nil
+		}
+		if required {
+			return nil, false, fmt.Errorf("%s is required", name)
+		}
+		return nil, false, nil
+	}
+
+	value, err = parseSchemaValue(kind, line)
+	if err != nil {
+		return nil, false, err
+	}
+	if enum, isEnum := schema["enum"].([]any); isEnum && !enumContains(enum, value) {
+		return nil, false, fmt.Errorf("%q is not one of the allowed values", line)
+	}
+	return value, true, nil
+}
+
+// parseSchemaValue converts raw user input to the Go value matching a JSON
+// schema type. Arrays and objects are entered as JSON.
+func parseSchemaValue(kind, raw string) (any, error) {
+	switch kind {
+	case "string":
+		return raw, nil
+	case "integer":
+		return strconv.ParseInt(raw, 10, 64)
+	case "number":
+		return strconv.ParseFloat(raw, 64)
+	case "boolean":
+		switch strings.ToLower(raw) {
+		case "y", "yes", "true", "1":
+			return true, nil
+		case "n", "no", "false", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("expected yes or no, got %q", raw)
+	case "array", "object":
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, fmt.Errorf("expected JSON %s: %w", kind, err)
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, option := range enum {
+		if fmt.Sprint(option) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaObject extracts the properties and required list from a schema given
+// either as a map or as any JSON-serializable value.
+func schemaObject(schema any) (map[string]any, []string, error) {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	properties, _ := m["properties"].(map[string]any)
+	var required []string
+	switch r := m["required"].(type) {
+	case []string:
+		required = r
+	case []any:
+		for _, name := range r {
+			if s, ok := name.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+	return properties, required, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchemaValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{name: "string", kind: "string", raw: "hello", want: "hello"},
+		{name: "integer", kind: "integer", raw: "42", want: int64(42)},
+		{name: "invalid integer", kind: "integer", raw: "4.2", wantErr: true},
+		{name: "number", kind: "number", raw: "4.5", want: 4.5},
+		{name: "boolean yes", kind: "boolean", raw: "yes", want: true},
+		{name: "boolean false", kind: "boolean", raw: "false", want: false},
+		{name: "invalid boolean", kind: "boolean", raw: "maybe", wantErr: true},
+		{name: "array", kind: "array", raw: `["a","b"]`, want: []any{"a", "b"}},
+		{name: "invalid object", kind: "object", raw: `{`, wantErr: true},
+		{name: "unknown type", kind: "null", raw: "x", want: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSchemaValue(tt.kind, tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrompter_PromptObject(t *testing.T) {
+	properties := map[string]any{
+		"name":  map[string]any{"type": "string", "description": "Your name"},
+		"count": map[string]any{"type": "integer", "default": float64(1)},
+		"size":  map[string]any{"type": "string", "enum": []any{"small", "large"}},
+	}
+
+	t.Run("required first, defaults and retries", func(t *testing.T) {
+		// name is required and asked first; the first empty answer is rejected.
+		// count falls back to its default; size rejects a value outside the enum.
+		input := strings.Join([]string{"", "Ada", "", "medium", "large"}, "\n") + "\n"
+		var out bytes.Buffer
+		p := newPrompter(strings.NewReader(input), &out)
+
+		values, err := p.promptObject(properties, []string{"name"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "Ada", "count": float64(1), "size": "large"}, values)
+		assert.Contains(t, out.String(), "name* (string) - Your name")
+		assert.Contains(t, out.String(), "small|large")
+		assert.Contains(t, out.String(), "invalid value")
+	})
+
+	t.Run("optional values can be skipped", func(t *testing.T) {
+		p := newPrompter(strings.NewReader("Ada\n\n\n"), &bytes.Buffer{})
+
+		values, err := p.promptObject(map[string]any{
+			"name": map[string]any{"type": "string"},
+			"note": map[string]any{"type": "string"},
+		}, []string{"name"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "Ada"}, values)
+	})
+
+	t.Run("missing required value at end of input", func(t *testing.T) {
+		p := newPrompter(strings.NewReader(""), &bytes.Buffer{})
+
+		_, err := p.promptObject(properties, []string{"name"})
+		assert.ErrorContains(t, err, `required property "name"`)
+	})
+}
+
+func TestSchemaObject(t *testing.T) {
+	properties, required, err := schemaObject(struct {
+		Type       string         `json:"type"`
+		Properties map[string]any `json:"properties"`
+		Required   []string       `json:"required"`
+	}{
+		Type:       "object",
+		Properties: map[string]any{"email": map[string]any{"type": "string"}},
+		Required:   []string{"email"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, properties, "email")
+	assert.Equal(t, []string{"email"}, required)
+}
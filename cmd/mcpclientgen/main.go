@@ -0,0 +1,146 @@
+// Command mcpclientgen connects to an MCP server over stdio or streamable
+// HTTP and generates a typed Go client package for its tools, with one
+// method per tool and structs for their arguments and structured results.
+//
+// Usage:
+//
+//	mcpclientgen [flags]
+//
+// For example, to regenerate a client with go generate:
+//
+//	//go:generate go run github.com/mark3labs/mcp-go/cmd/mcpclientgen -http http://localhost:8080/mcp -package search -o client.go
+//
+// See package clientgen for the shape of the generated code.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/clientgen"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// headerFlags collects repeated -H "Name: value" flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	parts := make([]string, 0, len(h))
+	for k, v := range h {
+		parts = append(parts, k+": "+v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	if !ok {
+		return fmt.Errorf("header must be in the form 'Name: value'")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// envFlags collects repeated -e KEY=VALUE flags.
+type envFlags []string
+
+func (e *envFlags) String() string { return strings.Join(*e, ",") }
+
+func (e *envFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("environment variable must be in the form KEY=VALUE")
+	}
+	*e = append(*e, value)
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "mcpclientgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var (
+		stdio   string
+		httpURL string
+		output  string
+		tools   string
+		timeout time.Duration
+		headers = headerFlags{}
+		env     envFlags
+		config  clientgen.Config
+	)
+	fs := flag.NewFlagSet("mcpclientgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&stdio, "stdio", "", "command to launch a stdio server (e.g. './server -stdio')")
+	fs.StringVar(&httpURL, "http", "", "URL of a streamable HTTP server (e.g. 'http://localhost:8080/mcp')")
+	fs.Var(headers, "H", "HTTP header 'Name: value' (repeatable)")
+	fs.Var(&env, "e", "environment variable KEY=VALUE for the stdio server (repeatable)")
+	fs.StringVar(&config.Package, "package", clientgen.DefaultPackage, "name of the generated package")
+	fs.StringVar(&tools, "tools", "", "comma-separated tools to generate (default all)")
+	fs.StringVar(&output, "o", "", "file to write the generated code to (default stdout)")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "timeout for connecting and listing tools")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (stdio == "") == (httpURL == "") {
+		fs.Usage()
+		return fmt.Errorf("exactly one of -stdio or -http must be given")
+	}
+	if tools != "" {
+		config.Tools = strings.Split(tools, ",")
+	}
+
+	var trans transport.Interface
+	if stdio != "" {
+		fields := strings.Fields(stdio)
+		trans = transport.NewStdio(fields[0], env, fields[1:]...)
+		config.Source = fields[0]
+	} else {
+		httpTransport, err := transport.NewStreamableHTTP(httpURL, transport.WithHTTPHeaders(headers))
+		if err != nil {
+			return fmt.Errorf("failed to create HTTP transport: %w", err)
+		}
+		trans = httpTransport
+		config.Source = httpURL
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c := client.NewClient(trans)
+	if err := c.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start client: %w", err)
+	}
+	defer c.Close()
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcpclientgen", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	src, err := clientgen.GenerateFromServer(ctx, c, config)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
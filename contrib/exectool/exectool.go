@@ -0,0 +1,434 @@
+// Package exectool exposes allowlisted commands as MCP tools.
+//
+// Each Command becomes one tool that runs a fixed executable with argument
+// templates filled from the tool's parameters. Commands run without a
+// shell, with a scrubbed environment, a timeout and bounded output:
+//
+//	provider, err := exectool.New([]exectool.Command{{
+//		Name:        "git_log",
+//		Description: "Recent commits of the repository",
+//		Path:        "git",
+//		Args:        []string{"log", "--oneline", "-n", "{count}"},
+//		Params: []exectool.Param{
+//			{Name: "count", Pattern: regexp.MustCompile(`^[0-9]{1,3}$`), Default: "20"},
+//		},
+//		Effect: exectool.ReadOnly,
+//		Dir:    repoDir,
+//	}})
+//	if err != nil {
+//		return err
+//	}
+//	provider.Register(mcpServer)
+package exectool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultTimeout is how long a command may run, unless set with
+	// WithTimeout or Command.Timeout.
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxOutput is how many bytes of stdout and of stderr are kept,
+	// unless set with WithMaxOutput.
+	DefaultMaxOutput = 64 << 10
+)
+
+// defaultEnv are the variables of the server's environment passed to
+// commands by default.
+var defaultEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TZ", "TMPDIR"}
+
+var (
+	// ErrInvalidCommand is returned by New for commands that cannot be
+	// exposed as tools.
+	ErrInvalidCommand = errors.New("invalid command")
+
+	// ErrInvalidArgument is returned for tool arguments rejected by the
+	// command's parameters.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// Effect declares what a command does to its environment. It sets the
+// tool's annotations and must be given for every command.
+type Effect int
+
+const (
+	// ReadOnly commands do not modify anything.
+	ReadOnly Effect = iota + 1
+	// Additive commands modify their environment without destroying data.
+	Additive
+	// Destructive commands may delete or overwrite data.
+	Destructive
+)
+
+// Param is a parameter of a command, substituted for {Name} in its
+// argument templates.
+type Param struct {
+	Name        string
+	Description string
+	// Required parameters must be given by the caller, and not empty.
+	// Optional ones take Default when the caller leaves them out; arguments
+	// referring to an optional parameter left out without a default are
+	// left out too.
+	Required bool
+	Default  string
+	// Pattern, if set, must match the whole value.
+	Pattern *regexp.Regexp
+	// Enum restricts the parameter to the listed values.
+	Enum []string
+	// AllowFlags lets values start with "-". Without it, such values are
+	// rejected so callers cannot inject options.
+	AllowFlags bool
+}
+
+// Command is an allowlisted command exposed as a tool.
+type Command struct {
+	// Name is the tool name.
+	Name        string
+	Description string
+	// Path is the executable, looked up in PATH unless it contains a
+	// separator. It is resolved by New.
+	Path string
+	// Args are the argument templates. Each becomes one argument, with
+	// {param} replaced by the parameter's value; no shell parses them.
+	Args   []string
+	Params []Param
+	// Effect is mandatory and sets the tool's annotations.
+	Effect Effect
+	// Dir is the working directory.
+	Dir string
+	// Env are extra variables, as "KEY=value", set on top of the scrubbed
+	// environment.
+	Env []string
+	// Timeout overrides the provider's timeout for this command.
+	Timeout time.Duration
+}
+
+// Result is the structured content of a command tool's result.
+type Result struct {
+	ExitCode int    `json:"exitCode"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	// Truncated is set if stdout or stderr exceeded the output limit.
+	Truncated bool `json:"truncated,omitempty"`
+	// TimedOut is set if the command was killed at its timeout.
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithTimeout sets how long a command may run. It defaults to
+// DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Provider) {
+		if d > 0 {
+			p.timeout = d
+		}
+	}
+}
+
+// WithMaxOutput sets how many bytes of stdout and of stderr are kept. It
+// defaults to DefaultMaxOutput.
+func WithMaxOutput(n int) Option {
+	return func(p *Provider) {
+		if n > 0 {
+			p.maxOutput = n
+		}
+	}
+}
+
+// WithEnvPassthrough passes the named variables of the server's
+// environment to commands, in addition to PATH, HOME, LANG, LC_ALL, TZ and
+// TMPDIR. Other variables, such as credentials, are never passed.
+func WithEnvPassthrough(names ...string) Option {
+	return func(p *Provider) {
+		p.passthrough = append(p.passthrough, names...)
+	}
+}
+
+// WithToolPrefix prefixes the names of the generated tools.
+func WithToolPrefix(prefix string) Option {
+	return func(p *Provider) {
+		p.prefix = prefix
+	}
+}
+
+// Provider generates the tools of a set of commands.
+type Provider struct {
+	commands    []Command
+	timeout     time.Duration
+	maxOutput   int
+	passthrough []string
+	prefix      string
+}
+
+var placeholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// New returns the provider of the tools of commands. It fails with
+// ErrInvalidCommand for commands without a name, an Effect or a resolvable
+// executable, with duplicate names or parameters, and with templates
+// referring to undeclared parameters.
+func New(commands []Command, opts ...Option) (*Provider, error) {
+	p := &Provider{
+		timeout:     DefaultTimeout,
+		maxOutput:   DefaultMaxOutput,
+		passthrough: append([]string(nil), defaultEnv...),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	names := make(map[string]bool, len(commands))
+	for _, command := range commands {
+		if command.Name == "" || names[command.Name] {
+			return nil, fmt.Errorf("command %q is unnamed or defined twice: %w", command.Name, ErrInvalidCommand)
+		}
+		names[command.Name] = true
+		if command.Effect < ReadOnly || command.Effect > Destructive {
+			return nil, fmt.Errorf("command %q must declare its Effect: %w", command.Name, ErrInvalidCommand)
+		}
+		path, err := exec.LookPath(command.Path)
+		if err != nil {
+			return nil, fmt.Errorf("command %q: %w: %w", command.Name, ErrInvalidCommand, err)
+		}
+		command.Path = path
+
+		params := make(map[string]bool, len(command.Params))
+		command.Params = append([]Param(nil), command.Params...)
+		for i, param := range command.Params {
+			if param.Name == "" || params[param.Name] {
+				return nil, fmt.Errorf("command %q has an unnamed or duplicate parameter %q: %w", command.Name, param.Name, ErrInvalidCommand)
+			}
+			params[param.Name] = true
+			if param.Pattern != nil {
+				command.Params[i].Pattern = regexp.MustCompile(`^(?:` + param.Pattern.String() + `)$`)
+			}
+		}
+		for _, arg := range command.Args {
+			for _, match := range placeholder.FindAllStringSubmatch(arg, -1) {
+				if !params[match[1]] {
+					return nil, fmt.Errorf("command %q refers to undeclared parameter %q: %w", command.Name, match[1], ErrInvalidCommand)
+				}
+			}
+		}
+		p.commands = append(p.commands, command)
+	}
+	return p, nil
+}
+
+// Tools returns one tool per command.
+func (p *Provider) Tools() []server.ServerTool {
+	tools := make([]server.ServerTool, 0, len(p.commands))
+	for _, command := range p.commands {
+		tools = append(tools, server.ServerTool{
+			Tool:    p.tool(command),
+			Handler: p.handler(command),
+		})
+	}
+	return tools
+}
+
+// Register adds the tools of the commands to s.
+func (p *Provider) Register(s *server.MCPServer) {
+	s.AddTools(p.Tools()...)
+}
+
+func (p *Provider) tool(command Command) mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(command.Description),
+		mcp.WithOutputSchema[Result](),
+		mcp.WithReadOnlyHintAnnotation(command.Effect == ReadOnly),
+		mcp.WithDestructiveHintAnnotation(command.Effect == Destructive),
+		mcp.WithIdempotentHintAnnotation(command.Effect == ReadOnly),
+		mcp.WithOpenWorldHintAnnotation(true),
+	}
+	for _, param := range command.Params {
+		props := []mcp.PropertyOption{mcp.Description(param.Description)}
+		if param.Required {
+			props = append(props, mcp.Required())
+		}
+		if param.Default != "" {
+			props = append(props, mcp.DefaultString(param.Default))
+		}
+		if len(param.Enum) > 0 {
+			props = append(props, mcp.Enum(param.Enum...))
+		}
+		if param.Pattern != nil {
+			props = append(props, mcp.Pattern(param.Pattern.String()))
+		}
+		opts = append(opts, mcp.WithString(param.Name, props...))
+	}
+	return mcp.NewTool(p.prefix+command.Name, opts...)
+}
+
+func (p *Provider) handler(command Command) server.ToolHandlerFunc {
+	timeout := p.timeout
+	if command.Timeout > 0 {
+		timeout = command.Timeout
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := expandArgs(command, request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result, err := p.run(ctx, command, args, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("command %s: %w", command.Name, err)
+		}
+		toolResult := mcp.NewToolResultStructuredOnly(result)
+		toolResult.IsError = result.ExitCode != 0 || result.TimedOut
+		return toolResult, nil
+	}
+}
+
+func (p *Provider) run(ctx context.Context, command Command, args []string, timeout time.Duration) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command.Path, args...)
+	cmd.Dir = command.Dir
+	cmd.Env = append(p.environment(), command.Env...)
+	// Kill the command's children along with it, and do not wait on pipes
+	// they hold open.
+	killProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+	stdout := &limitedBuffer{limit: p.maxOutput}
+	stderr := &limitedBuffer{limit: p.maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	result := &Result{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+		TimedOut:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case result.TimedOut:
+		result.ExitCode = -1
+	default:
+		return nil, err
+	}
+	return result, nil
+}
+
+// environment returns the passed-through variables of the server's
+// environment.
+func (p *Provider) environment() []string {
+	env := make([]string, 0, len(p.passthrough))
+	for _, name := range p.passthrough {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// expandArgs fills the argument templates of command with arguments.
+func expandArgs(command Command, arguments map[string]any) ([]string, error) {
+	values := make(map[string]string, len(command.Params))
+	for _, param := range command.Params {
+		value, ok, err := paramValue(param, arguments[param.Name])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			values[param.Name] = value
+		}
+	}
+
+	args := make([]string, 0, len(command.Args))
+	for _, template := range command.Args {
+		missing := false
+		arg := placeholder.ReplaceAllStringFunc(template, func(match string) string {
+			value, ok := values[match[1:len(match)-1]]
+			if !ok {
+				missing = true
+			}
+			return value
+		})
+		if !missing {
+			args = append(args, arg)
+		}
+	}
+	return args, nil
+}
+
+// paramValue returns the value of param, and whether it has one.
+func paramValue(param Param, argument any) (string, bool, error) {
+	if argument == nil {
+		if param.Required {
+			return "", false, fmt.Errorf("parameter %q is required: %w", param.Name, ErrInvalidArgument)
+		}
+		return param.Default, param.Default != "", nil
+	}
+	value, ok := argument.(string)
+	if !ok {
+		return "", false, fmt.Errorf("parameter %q must be a string: %w", param.Name, ErrInvalidArgument)
+	}
+	if param.Required && value == "" {
+		return "", false, fmt.Errorf("parameter %q must not be empty: %w", param.Name, ErrInvalidArgument)
+	}
+	if !param.AllowFlags && strings.HasPrefix(value, "-") {
+		return "", false, fmt.Errorf("parameter %q must not start with '-': %w", param.Name, ErrInvalidArgument)
+	}
+	if strings.ContainsRune(value, 0) {
+		return "", false, fmt.Errorf("parameter %q must not contain NUL: %w", param.Name, ErrInvalidArgument)
+	}
+	if param.Pattern != nil && !param.Pattern.MatchString(value) {
+		return "", false, fmt.Errorf("parameter %q must match %s: %w", param.Name, param.Pattern, ErrInvalidArgument)
+	}
+	if len(param.Enum) > 0 && !contains(param.Enum, value) {
+		return "", false, fmt.Errorf("parameter %q must be one of %v: %w", param.Name, param.Enum, ErrInvalidArgument)
+	}
+	return value, true, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,198 @@
+package exectool
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+}
+
+func callTool(t *testing.T, s *server.MCPServer, name, arguments string) (mcp.CallToolResult, *Result) {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+arguments+`}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+	structured, _ := result.StructuredContent.(*Result)
+	return result, structured
+}
+
+func TestProviderTools(t *testing.T) {
+	skipWithoutShell(t)
+	t.Setenv("EXECTOOL_SECRET", "s3cret")
+	t.Setenv("EXECTOOL_REGION", "eu")
+
+	provider, err := New([]Command{
+		{
+			Name: "greet",
+			Path: "sh",
+			Args: []string{"-c", `echo "$1 $2 ${EXECTOOL_SECRET:-none} $EXECTOOL_REGION $GREETING"`, "greet", "{name}", "--loud={loud}"},
+			Params: []Param{
+				{Name: "name", Required: true, Pattern: regexp.MustCompile(`[a-z]+`)},
+				{Name: "loud", Enum: []string{"yes", "no"}},
+			},
+			Effect: ReadOnly,
+			Env:    []string{"GREETING=hi"},
+		},
+		{
+			Name:   "fail",
+			Path:   "sh",
+			Args:   []string{"-c", "echo oops >&2; exit 3"},
+			Effect: Destructive,
+		},
+	}, WithEnvPassthrough("EXECTOOL_REGION"), WithToolPrefix("sh_"))
+	require.NoError(t, err)
+
+	tools := provider.Tools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "sh_greet", tools[0].Tool.Name)
+	assert.Equal(t, []string{"name"}, tools[0].Tool.InputSchema.Required)
+	assert.True(t, *tools[0].Tool.Annotations.ReadOnlyHint)
+	assert.False(t, *tools[0].Tool.Annotations.DestructiveHint)
+	assert.True(t, *tools[1].Tool.Annotations.DestructiveHint)
+
+	s := server.NewMCPServer("test", "1.0.0")
+	provider.Register(s)
+
+	result, out := callTool(t, s, "sh_greet", `{"name":"ada"}`)
+	require.False(t, result.IsError)
+	assert.Equal(t, "ada  none eu hi\n", out.Stdout, "optional arguments without value are left out and secrets scrubbed")
+	_, out = callTool(t, s, "sh_greet", `{"name":"ada","loud":"yes"}`)
+	assert.Equal(t, "ada --loud=yes none eu hi\n", out.Stdout)
+
+	result, out = callTool(t, s, "sh_fail", `{}`)
+	assert.True(t, result.IsError)
+	assert.Equal(t, 3, out.ExitCode)
+	assert.Equal(t, "oops\n", out.Stderr)
+
+	tests := []struct {
+		name      string
+		arguments string
+	}{
+		{name: "missing required", arguments: `{}`},
+		{name: "pattern matches only part", arguments: `{"name":"ada;rm"}`},
+		{name: "flag injection", arguments: `{"name":"-rf"}`},
+		{name: "not in enum", arguments: `{"name":"ada","loud":"maybe"}`},
+		{name: "not a string", arguments: `{"name":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, out := callTool(t, s, "sh_greet", tt.arguments)
+			assert.True(t, result.IsError)
+			assert.Nil(t, out, "the command did not run")
+		})
+	}
+}
+
+func TestExpandArgs(t *testing.T) {
+	command := Command{
+		Args: []string{"[{optional}]", "[{defaulted}]", "[{required}]"},
+		Params: []Param{
+			{Name: "optional"},
+			{Name: "defaulted", Default: "main"},
+			{Name: "required", Required: true},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		expected  []string
+		wantErr   bool
+	}{
+		{
+			name:      "absent optional arguments",
+			arguments: map[string]any{"required": "x"},
+			expected:  []string{"[main]", "[x]"},
+		},
+		{
+			name:      "empty optional arguments are kept",
+			arguments: map[string]any{"optional": "", "defaulted": "", "required": "x"},
+			expected:  []string{"[]", "[]", "[x]"},
+		},
+		{
+			name:      "given arguments",
+			arguments: map[string]any{"optional": "a", "defaulted": "b", "required": "c"},
+			expected:  []string{"[a]", "[b]", "[c]"},
+		},
+		{
+			name:      "empty required argument",
+			arguments: map[string]any{"required": ""},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := expandArgs(command, tt.arguments)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidArgument)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+}
+
+func TestProviderLimits(t *testing.T) {
+	skipWithoutShell(t)
+	provider, err := New([]Command{
+		{Name: "noisy", Path: "sh", Args: []string{"-c", "yes | head -c 1000"}, Effect: ReadOnly},
+		// The shell forks sleep, which holds the output pipes open.
+		{Name: "slow", Path: "sh", Args: []string{"-c", "sleep 10; echo done"}, Effect: ReadOnly, Timeout: 50 * time.Millisecond},
+	}, WithMaxOutput(10))
+	require.NoError(t, err)
+	s := server.NewMCPServer("test", "1.0.0")
+	provider.Register(s)
+
+	result, out := callTool(t, s, "noisy", `{}`)
+	assert.False(t, result.IsError)
+	assert.Equal(t, strings.Repeat("y\n", 5), out.Stdout)
+	assert.True(t, out.Truncated)
+
+	start := time.Now()
+	result, out = callTool(t, s, "slow", `{}`)
+	if runtime.GOOS != "windows" {
+		assert.Less(t, time.Since(start), 900*time.Millisecond, "children are killed with the command")
+	}
+	assert.True(t, result.IsError)
+	assert.True(t, out.TimedOut)
+}
+
+func TestNewValidation(t *testing.T) {
+	skipWithoutShell(t)
+	tests := []struct {
+		name    string
+		command Command
+	}{
+		{name: "no name", command: Command{Path: "sh", Effect: ReadOnly}},
+		{name: "no effect", command: Command{Name: "c", Path: "sh"}},
+		{name: "unknown executable", command: Command{Name: "c", Path: "exectool-does-not-exist", Effect: ReadOnly}},
+		{name: "undeclared parameter", command: Command{Name: "c", Path: "sh", Args: []string{"{file}"}, Effect: ReadOnly}},
+		{name: "duplicate parameter", command: Command{Name: "c", Path: "sh", Params: []Param{{Name: "a"}, {Name: "a"}}, Effect: ReadOnly}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([]Command{tt.command})
+			assert.ErrorIs(t, err, ErrInvalidCommand)
+		})
+	}
+
+	_, err := New([]Command{{Name: "c", Path: "sh", Effect: ReadOnly}, {Name: "c", Path: "sh", Effect: ReadOnly}})
+	assert.ErrorIs(t, err, ErrInvalidCommand, "duplicate names")
+}
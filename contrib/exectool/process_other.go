@@ -0,0 +1,9 @@
+//go:build !unix
+
+package exectool
+
+import "os/exec"
+
+// killProcessGroup is a no-op where process groups are not supported; only
+// the command itself is killed.
+func killProcessGroup(cmd *exec.Cmd) {}
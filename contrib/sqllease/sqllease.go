@@ -0,0 +1,180 @@
+// Package sqllease implements server.LeaseStore on a SQL database, so that
+// replicas of a server sharing the database elect one leader to run their
+// singleton jobs:
+//
+//	store, err := sqllease.New(db, sqllease.WithPlaceholders(sqllease.Dollar))
+//	if err != nil {
+//		return err
+//	}
+//	if err := store.CreateTable(ctx); err != nil {
+//		return err
+//	}
+//	mcpServer := server.NewMCPServer("jobs", "1.0.0",
+//		server.WithLeaderElection(store),
+//		server.WithSingletonJob("sweep", sweep),
+//	)
+//	go mcpServer.RunLeaderElection(ctx)
+//
+// Leases live in one table, by default leases, that CreateTable creates
+// with column types PostgreSQL, MySQL and SQLite all accept. Leases are
+// taken with conditional updates, so no database-specific locking is
+// needed, but the replicas' clocks must be synchronized.
+package sqllease
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultTable is the table of the leases unless set with WithTable.
+const DefaultTable = "leases"
+
+// ErrInvalidTable is returned by New for table names that are not plain
+// SQL identifiers.
+var ErrInvalidTable = errors.New("invalid table name")
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Placeholders is the style of the query parameters of a database driver.
+type Placeholders int
+
+const (
+	// Question placeholders (?) are used by MySQL and SQLite drivers.
+	Question Placeholders = iota
+	// Dollar placeholders ($1, $2, ...) are used by PostgreSQL drivers.
+	Dollar
+)
+
+// Store is a server.LeaseStore keeping leases in a SQL table.
+type Store struct {
+	db           *sql.DB
+	table        string
+	placeholders Placeholders
+	clock        server.Clock
+	queries      queries
+}
+
+type queries struct {
+	create, renew, insert, holder, release string
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithTable sets the table of the leases, DefaultTable by default. The name
+// may be qualified with a schema.
+func WithTable(name string) Option {
+	return func(s *Store) {
+		s.table = name
+	}
+}
+
+// WithPlaceholders sets the placeholder style of the database driver,
+// Question by default.
+func WithPlaceholders(p Placeholders) Option {
+	return func(s *Store) {
+		s.placeholders = p
+	}
+}
+
+// WithClock sets the clock leases expire on, the system clock by default.
+func WithClock(clock server.Clock) Option {
+	return func(s *Store) {
+		s.clock = clock
+	}
+}
+
+// New returns a Store keeping leases in db.
+func New(db *sql.DB, opts ...Option) (*Store, error) {
+	s := &Store{db: db, table: DefaultTable, clock: server.SystemClock}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !tableName.MatchString(s.table) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTable, s.table)
+	}
+	s.queries = s.buildQueries()
+	return s, nil
+}
+
+func (s *Store) buildQueries() queries {
+	t := s.table
+	return queries{
+		create: `CREATE TABLE IF NOT EXISTS ` + t + ` (
+	name VARCHAR(255) NOT NULL PRIMARY KEY,
+	holder VARCHAR(255) NOT NULL,
+	expires_at BIGINT NOT NULL
+)`,
+		renew:   s.bind(`UPDATE ` + t + ` SET holder = %s, expires_at = %s WHERE name = %s AND (holder = %s OR expires_at <= %s)`),
+		insert:  s.bind(`INSERT INTO ` + t + ` (name, holder, expires_at) VALUES (%s, %s, %s)`),
+		holder:  s.bind(`SELECT holder FROM ` + t + ` WHERE name = %s`),
+		release: s.bind(`DELETE FROM ` + t + ` WHERE name = %s AND holder = %s`),
+	}
+}
+
+// bind replaces the %s verbs of a query with the store's placeholders.
+func (s *Store) bind(query string) string {
+	n := strings.Count(query, "%s")
+	args := make([]any, n)
+	for i := range args {
+		if s.placeholders == Dollar {
+			args[i] = fmt.Sprintf("$%d", i+1)
+		} else {
+			args[i] = "?"
+		}
+	}
+	return fmt.Sprintf(query, args...)
+}
+
+// CreateTable creates the leases table if it does not exist.
+func (s *Store) CreateTable(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, s.queries.create); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// AcquireLease implements server.LeaseStore. It renews or takes over the
+// lease's row, and creates the row if there is none yet.
+func (s *Store) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := s.clock.Now()
+	expires := now.Add(ttl).UnixMilli()
+	res, err := s.db.ExecContext(ctx, s.queries.renew, holder, expires, name, holder, now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	} else if n > 0 {
+		return true, nil
+	}
+
+	_, insertErr := s.db.ExecContext(ctx, s.queries.insert, name, holder, expires)
+	if insertErr == nil {
+		return true, nil
+	}
+	// The insert fails when another replica holds the lease, or created
+	// its row first; tell that apart from the database being unreachable.
+	var current string
+	if err := s.db.QueryRowContext(ctx, s.queries.holder, name).Scan(&current); err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, insertErr)
+	}
+	return false, nil
+}
+
+// ReleaseLease implements server.LeaseStore.
+func (s *Store) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := s.db.ExecContext(ctx, s.queries.release, name, holder); err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+var _ server.LeaseStore = (*Store)(nil)
@@ -0,0 +1,243 @@
+package sqllease
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeTable runs the store's statements against rows in memory, as a
+// database would.
+type fakeTable struct {
+	mu   sync.Mutex
+	q    queries
+	rows map[string]*fakeRow
+	down bool
+}
+
+type fakeRow struct {
+	holder  string
+	expires int64
+}
+
+func (t *fakeTable) exec(query string, args []driver.Value) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.down {
+		return 0, errors.New("connection refused")
+	}
+	switch query {
+	case t.q.create:
+		return 0, nil
+	case t.q.renew:
+		row, ok := t.rows[args[2].(string)]
+		if !ok || (row.holder != args[3].(string) && row.expires > args[4].(int64)) {
+			return 0, nil
+		}
+		row.holder, row.expires = args[0].(string), args[1].(int64)
+		return 1, nil
+	case t.q.insert:
+		name := args[0].(string)
+		if _, exists := t.rows[name]; exists {
+			return 0, fmt.Errorf("duplicate key %s", name)
+		}
+		t.rows[name] = &fakeRow{holder: args[1].(string), expires: args[2].(int64)}
+		return 1, nil
+	case t.q.release:
+		if row, ok := t.rows[args[0].(string)]; ok && row.holder == args[1].(string) {
+			delete(t.rows, args[0].(string))
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected statement %q", query)
+}
+
+func (t *fakeTable) query(query string, args []driver.Value) (*fakeRows, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.down {
+		return nil, errors.New("connection refused")
+	}
+	if query != t.q.holder {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+	rows := &fakeRows{columns: []string{"holder"}}
+	if row, ok := t.rows[args[0].(string)]; ok {
+		rows.values = [][]driver.Value{{row.holder}}
+	}
+	return rows, nil
+}
+
+func (t *fakeTable) Connect(context.Context) (driver.Conn, error) { return fakeConn{t}, nil }
+func (t *fakeTable) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ t *fakeTable }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, fmt.Errorf("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("not supported") }
+
+func values(args []driver.NamedValue) []driver.Value {
+	out := make([]driver.Value, len(args))
+	for i, arg := range args {
+		out[i] = arg.Value
+	}
+	return out
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	n, err := c.t.exec(query, values(args))
+	return driver.RowsAffected(n), err
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.t.query(query, values(args))
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// settableClock is a server.Clock whose time only moves when set.
+type settableClock struct {
+	server.Clock
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *settableClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *settableClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *fakeTable) {
+	t.Helper()
+	table := &fakeTable{rows: make(map[string]*fakeRow)}
+	db := sql.OpenDB(table)
+	t.Cleanup(func() { db.Close() })
+	s, err := New(db, opts...)
+	require.NoError(t, err)
+	table.q = s.queries
+	require.NoError(t, s.CreateTable(context.Background()))
+	return s, table
+}
+
+func TestStore(t *testing.T) {
+	clock := &settableClock{Clock: server.SystemClock, now: time.UnixMilli(1_700_000_000_000)}
+	s, table := newTestStore(t, WithClock(clock))
+	ctx := context.Background()
+
+	acquired, err := s.AcquireLease(ctx, "leader", "a", 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired, "a free lease is taken")
+	acquired, err = s.AcquireLease(ctx, "leader", "b", 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired, "a held lease is not")
+
+	clock.advance(10 * time.Second)
+	acquired, err = s.AcquireLease(ctx, "leader", "a", 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired, "the holder renews its lease")
+	clock.advance(10 * time.Second)
+	acquired, err = s.AcquireLease(ctx, "leader", "b", 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired, "the renewed lease has not expired")
+
+	clock.advance(5 * time.Second)
+	acquired, err = s.AcquireLease(ctx, "leader", "b", 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired, "an expired lease is taken over")
+
+	require.NoError(t, s.ReleaseLease(ctx, "leader", "a"))
+	assert.Equal(t, "b", table.rows["leader"].holder, "only the holder releases a lease")
+	require.NoError(t, s.ReleaseLease(ctx, "leader", "b"))
+	acquired, err = s.AcquireLease(ctx, "leader", "a", 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	table.down = true
+	_, err = s.AcquireLease(ctx, "leader", "a", 15*time.Second)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestStore_LeaderElection(t *testing.T) {
+	store, _ := newTestStore(t, WithPlaceholders(Dollar), WithTable("mcp.leases"))
+	var mu sync.Mutex
+	running := 0
+	job := func(ctx context.Context) {
+		mu.Lock()
+		running++
+		mu.Unlock()
+		<-ctx.Done()
+	}
+	replicas := make([]*server.MCPServer, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := range replicas {
+		replicas[i] = server.NewMCPServer("test", "1.0.0",
+			server.WithLeaderElection(store, server.WithLeaseHolder(fmt.Sprintf("replica-%d", i)), server.WithLeaseDuration(time.Second)),
+			server.WithSingletonJob("sweep", job),
+		)
+		wg.Add(1)
+		go func(s *server.MCPServer) {
+			defer wg.Done()
+			_ = s.RunLeaderElection(ctx)
+		}(replicas[i])
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return running == 1
+	}, time.Second, time.Millisecond)
+	leaders := 0
+	for _, s := range replicas {
+		if s.IsLeader() {
+			leaders++
+		}
+	}
+	assert.Equal(t, 1, leaders)
+}
+
+func TestNew_InvalidTable(t *testing.T) {
+	for _, name := range []string{"", "leases; DROP TABLE users", "a.b.c", "1leases"} {
+		_, err := New(nil, WithTable(name))
+		assert.ErrorIs(t, err, ErrInvalidTable, name)
+	}
+}
@@ -0,0 +1,299 @@
+// Package sqltaskqueue implements server.TaskQueue on a SQL database, so
+// the jobs of task-augmented tool calls survive restarts and can be run by
+// worker processes other than the server's:
+//
+//	queue, err := sqltaskqueue.New(db, sqltaskqueue.WithPlaceholders(sqltaskqueue.Dollar))
+//	if err != nil {
+//		return err
+//	}
+//	if err := queue.CreateTable(ctx); err != nil {
+//		return err
+//	}
+//	mcpServer := server.NewMCPServer("jobs", "1.0.0",
+//		server.WithTaskCapabilities(true, true, true),
+//		server.WithTaskQueue(queue, 0),
+//	)
+//
+//	// in the worker processes
+//	pool := server.NewTaskWorkerPool(queue)
+//	pool.RegisterTools(tools...)
+//	pool.Run(ctx)
+//
+// Jobs live in one table, by default task_jobs, that CreateTable creates
+// with column types PostgreSQL, MySQL and SQLite all accept. Workers claim
+// jobs with an optimistic update, so no database-specific locking is
+// needed.
+package sqltaskqueue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultTable is the table of the jobs unless set with WithTable.
+const DefaultTable = "task_jobs"
+
+// claimRetries bounds how often Claim retries after other workers claimed
+// the job it picked.
+const claimRetries = 5
+
+// ErrInvalidTable is returned by New for table names that are not plain
+// SQL identifiers.
+var ErrInvalidTable = errors.New("invalid table name")
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Placeholders is the style of the query parameters of a database driver.
+type Placeholders int
+
+const (
+	// Question placeholders (?) are used by MySQL and SQLite drivers.
+	Question Placeholders = iota
+	// Dollar placeholders ($1, $2, ...) are used by PostgreSQL drivers.
+	Dollar
+)
+
+// Queue is a server.TaskQueue storing jobs in a SQL table.
+type Queue struct {
+	db           *sql.DB
+	table        string
+	placeholders Placeholders
+	clock        server.Clock
+	queries      queries
+}
+
+type queries struct {
+	create, enqueue, pick, claim, job, extend, finish, cancel, status, remove string
+}
+
+// Option configures a Queue.
+type Option func(*Queue)
+
+// WithTable sets the table of the jobs, DefaultTable by default. The name
+// may be qualified with a schema.
+func WithTable(name string) Option {
+	return func(q *Queue) {
+		q.table = name
+	}
+}
+
+// WithPlaceholders sets the placeholder style of the database driver,
+// Question by default.
+func WithPlaceholders(p Placeholders) Option {
+	return func(q *Queue) {
+		q.placeholders = p
+	}
+}
+
+// WithClock sets the clock leases are measured on, the system clock by
+// default. Workers and servers sharing a queue need synchronized clocks.
+func WithClock(clock server.Clock) Option {
+	return func(q *Queue) {
+		q.clock = clock
+	}
+}
+
+// New returns a Queue storing jobs in db.
+func New(db *sql.DB, opts ...Option) (*Queue, error) {
+	q := &Queue{db: db, table: DefaultTable, clock: server.SystemClock}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if !tableName.MatchString(q.table) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTable, q.table)
+	}
+	q.queries = q.buildQueries()
+	return q, nil
+}
+
+func (q *Queue) buildQueries() queries {
+	t := q.table
+	claimable := fmt.Sprintf("(state = '%s' OR (state = '%s' AND lease_until <= %%s))", server.TaskJobPending, server.TaskJobRunning)
+	leased := fmt.Sprintf("state = '%s' AND worker = %%s AND lease_until > %%s", server.TaskJobRunning)
+	return queries{
+		create: `CREATE TABLE IF NOT EXISTS ` + t + ` (
+	id VARCHAR(255) NOT NULL PRIMARY KEY,
+	tool VARCHAR(255) NOT NULL,
+	arguments TEXT NOT NULL,
+	principal VARCHAR(255) NOT NULL,
+	session_id VARCHAR(255) NOT NULL,
+	state VARCHAR(32) NOT NULL,
+	worker VARCHAR(255) NOT NULL,
+	lease_until BIGINT NOT NULL,
+	attempts INTEGER NOT NULL,
+	result TEXT NOT NULL,
+	error TEXT NOT NULL,
+	enqueued_at BIGINT NOT NULL
+)`,
+		enqueue: q.bind(`INSERT INTO ` + t + ` (id, tool, arguments, principal, session_id, state, worker, lease_until, attempts, result, error, enqueued_at) ` +
+			`VALUES (%s, %s, %s, %s, %s, '` + string(server.TaskJobPending) + `', '', 0, 0, '', '', %s)`),
+		pick: q.bind(`SELECT id FROM ` + t + ` WHERE ` + claimable + ` ORDER BY enqueued_at, id LIMIT 1`),
+		claim: q.bind(`UPDATE ` + t + ` SET state = '` + string(server.TaskJobRunning) + `', worker = %s, lease_until = %s, attempts = attempts + 1 ` +
+			`WHERE id = %s AND ` + claimable),
+		job:    q.bind(`SELECT tool, arguments, principal, session_id, enqueued_at, attempts FROM ` + t + ` WHERE id = %s`),
+		extend: q.bind(`UPDATE ` + t + ` SET lease_until = %s WHERE id = %s AND ` + leased),
+		finish: q.bind(`UPDATE ` + t + ` SET state = %s, result = %s, error = %s WHERE id = %s AND ` + leased),
+		cancel: q.bind(`UPDATE ` + t + ` SET state = '` + string(server.TaskJobCancelled) + `' ` +
+			`WHERE id = %s AND state IN ('` + string(server.TaskJobPending) + `', '` + string(server.TaskJobRunning) + `')`),
+		status: q.bind(`SELECT state, worker, attempts, result, error FROM ` + t + ` WHERE id = %s`),
+		remove: q.bind(`DELETE FROM ` + t + ` WHERE id = %s`),
+	}
+}
+
+// bind replaces the %s verbs of a query with the queue's placeholders.
+func (q *Queue) bind(query string) string {
+	n := strings.Count(query, "%s")
+	args := make([]any, n)
+	for i := range args {
+		if q.placeholders == Dollar {
+			args[i] = fmt.Sprintf("$%d", i+1)
+		} else {
+			args[i] = "?"
+		}
+	}
+	return fmt.Sprintf(query, args...)
+}
+
+// CreateTable creates the jobs table if it does not exist.
+func (q *Queue) CreateTable(ctx context.Context) error {
+	if _, err := q.db.ExecContext(ctx, q.queries.create); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", q.table, err)
+	}
+	return nil
+}
+
+// Enqueue implements server.TaskQueue.
+func (q *Queue) Enqueue(ctx context.Context, job server.TaskJob) error {
+	arguments := string(job.Arguments)
+	_, err := q.db.ExecContext(ctx, q.queries.enqueue,
+		job.ID, job.Tool, arguments, job.Principal, job.SessionID, job.EnqueuedAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// Claim implements server.TaskQueue.
+func (q *Queue) Claim(ctx context.Context, worker string, lease time.Duration) (server.TaskJob, error) {
+	for i := 0; i < claimRetries; i++ {
+		now := q.clock.Now()
+		var id string
+		err := q.db.QueryRowContext(ctx, q.queries.pick, now.UnixMilli()).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return server.TaskJob{}, server.ErrTaskQueueEmpty
+		}
+		if err != nil {
+			return server.TaskJob{}, fmt.Errorf("failed to find a job: %w", err)
+		}
+
+		res, err := q.db.ExecContext(ctx, q.queries.claim, worker, now.Add(lease).UnixMilli(), id, now.UnixMilli())
+		if err != nil {
+			return server.TaskJob{}, fmt.Errorf("failed to claim job %s: %w", id, err)
+		}
+		if claimed, err := res.RowsAffected(); err != nil || claimed == 0 {
+			// Another worker was faster.
+			continue
+		}
+		return q.load(ctx, id)
+	}
+	return server.TaskJob{}, server.ErrTaskQueueEmpty
+}
+
+// load reads a claimed job.
+func (q *Queue) load(ctx context.Context, id string) (server.TaskJob, error) {
+	job := server.TaskJob{ID: id}
+	var arguments string
+	var enqueuedAt int64
+	err := q.db.QueryRowContext(ctx, q.queries.job, id).
+		Scan(&job.Tool, &arguments, &job.Principal, &job.SessionID, &enqueuedAt, &job.Attempts)
+	if err != nil {
+		return server.TaskJob{}, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+	if arguments != "" {
+		job.Arguments = []byte(arguments)
+	}
+	job.EnqueuedAt = time.UnixMilli(enqueuedAt)
+	return job, nil
+}
+
+// Extend implements server.TaskQueue.
+func (q *Queue) Extend(ctx context.Context, jobID, worker string, lease time.Duration) error {
+	now := q.clock.Now()
+	res, err := q.db.ExecContext(ctx, q.queries.extend, now.Add(lease).UnixMilli(), jobID, worker, now.UnixMilli())
+	return q.checkLeased(ctx, jobID, res, err)
+}
+
+// Finish implements server.TaskQueue.
+func (q *Queue) Finish(ctx context.Context, jobID, worker string, outcome server.TaskJobOutcome) error {
+	state := server.TaskJobCompleted
+	if outcome.Error != "" {
+		state = server.TaskJobFailed
+	}
+	res, err := q.db.ExecContext(ctx, q.queries.finish,
+		string(state), string(outcome.Result), outcome.Error, jobID, worker, q.clock.Now().UnixMilli())
+	return q.checkLeased(ctx, jobID, res, err)
+}
+
+// checkLeased reports why an update of a leased job changed nothing.
+func (q *Queue) checkLeased(ctx context.Context, jobID string, res sql.Result, err error) error {
+	if err != nil {
+		return fmt.Errorf("failed to update job %s: %w", jobID, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update job %s: %w", jobID, err)
+	} else if n > 0 {
+		return nil
+	}
+	status, err := q.Status(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if status.State == server.TaskJobCancelled {
+		return server.ErrTaskJobCancelled
+	}
+	return server.ErrTaskJobLeaseLost
+}
+
+// Cancel implements server.TaskQueue.
+func (q *Queue) Cancel(ctx context.Context, jobID string) error {
+	if _, err := q.db.ExecContext(ctx, q.queries.cancel, jobID); err != nil {
+		return fmt.Errorf("failed to cancel job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// Status implements server.TaskQueue.
+func (q *Queue) Status(ctx context.Context, jobID string) (server.TaskJobStatus, error) {
+	var status server.TaskJobStatus
+	var state, result string
+	err := q.db.QueryRowContext(ctx, q.queries.status, jobID).
+		Scan(&state, &status.Worker, &status.Attempts, &result, &status.Error)
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.TaskJobStatus{}, server.ErrTaskJobNotFound
+	}
+	if err != nil {
+		return server.TaskJobStatus{}, fmt.Errorf("failed to read job %s: %w", jobID, err)
+	}
+	status.State = server.TaskJobState(state)
+	if result != "" {
+		status.Result = []byte(result)
+	}
+	return status, nil
+}
+
+// Remove implements server.TaskQueue.
+func (q *Queue) Remove(ctx context.Context, jobID string) error {
+	if _, err := q.db.ExecContext(ctx, q.queries.remove, jobID); err != nil {
+		return fmt.Errorf("failed to remove job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+var _ server.TaskQueue = (*Queue)(nil)
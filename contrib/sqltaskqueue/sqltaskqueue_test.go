@@ -0,0 +1,329 @@
+package sqltaskqueue
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeTable runs the queue's statements against rows in memory, as a
+// database would.
+type fakeTable struct {
+	mu          sync.Mutex
+	q           queries
+	rows        map[string]*fakeRow
+	beforeClaim func(id string)
+}
+
+type fakeRow struct {
+	id, tool, arguments, principal, sessionID, state, worker, result, error string
+	leaseUntil, attempts, enqueuedAt                                        int64
+}
+
+func (r *fakeRow) claimable(now int64) bool {
+	return r.state == string(server.TaskJobPending) || (r.state == string(server.TaskJobRunning) && r.leaseUntil <= now)
+}
+
+func (r *fakeRow) leased(worker string, now int64) bool {
+	return r.state == string(server.TaskJobRunning) && r.worker == worker && r.leaseUntil > now
+}
+
+func (t *fakeTable) exec(query string, args []driver.Value) (int64, error) {
+	if query == t.q.claim && t.beforeClaim != nil {
+		t.beforeClaim(args[2].(string))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch query {
+	case t.q.create:
+		return 0, nil
+	case t.q.enqueue:
+		id := args[0].(string)
+		if _, exists := t.rows[id]; exists {
+			return 0, fmt.Errorf("duplicate key %s", id)
+		}
+		t.rows[id] = &fakeRow{id: id, tool: args[1].(string), arguments: args[2].(string), principal: args[3].(string),
+			sessionID: args[4].(string), state: string(server.TaskJobPending), enqueuedAt: args[5].(int64)}
+		return 1, nil
+	case t.q.claim:
+		row, ok := t.rows[args[2].(string)]
+		if !ok || !row.claimable(args[3].(int64)) {
+			return 0, nil
+		}
+		row.state, row.worker, row.leaseUntil = string(server.TaskJobRunning), args[0].(string), args[1].(int64)
+		row.attempts++
+		return 1, nil
+	case t.q.extend:
+		row, ok := t.rows[args[1].(string)]
+		if !ok || !row.leased(args[2].(string), args[3].(int64)) {
+			return 0, nil
+		}
+		row.leaseUntil = args[0].(int64)
+		return 1, nil
+	case t.q.finish:
+		row, ok := t.rows[args[3].(string)]
+		if !ok || !row.leased(args[4].(string), args[5].(int64)) {
+			return 0, nil
+		}
+		row.state, row.result, row.error = args[0].(string), args[1].(string), args[2].(string)
+		return 1, nil
+	case t.q.cancel:
+		row, ok := t.rows[args[0].(string)]
+		if !ok || (row.state != string(server.TaskJobPending) && row.state != string(server.TaskJobRunning)) {
+			return 0, nil
+		}
+		row.state = string(server.TaskJobCancelled)
+		return 1, nil
+	case t.q.remove:
+		delete(t.rows, args[0].(string))
+		return 1, nil
+	}
+	return 0, fmt.Errorf("unexpected statement %q", query)
+}
+
+func (t *fakeTable) query(query string, args []driver.Value) (*fakeRows, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch query {
+	case t.q.pick:
+		var candidates []*fakeRow
+		for _, row := range t.rows {
+			if row.claimable(args[0].(int64)) {
+				candidates = append(candidates, row)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].enqueuedAt != candidates[j].enqueuedAt {
+				return candidates[i].enqueuedAt < candidates[j].enqueuedAt
+			}
+			return candidates[i].id < candidates[j].id
+		})
+		rows := &fakeRows{columns: []string{"id"}}
+		if len(candidates) > 0 {
+			rows.values = [][]driver.Value{{candidates[0].id}}
+		}
+		return rows, nil
+	case t.q.job:
+		rows := &fakeRows{columns: []string{"tool", "arguments", "principal", "session_id", "enqueued_at", "attempts"}}
+		if row, ok := t.rows[args[0].(string)]; ok {
+			rows.values = [][]driver.Value{{row.tool, row.arguments, row.principal, row.sessionID, row.enqueuedAt, row.attempts}}
+		}
+		return rows, nil
+	case t.q.status:
+		rows := &fakeRows{columns: []string{"state", "worker", "attempts", "result", "error"}}
+		if row, ok := t.rows[args[0].(string)]; ok {
+			rows.values = [][]driver.Value{{row.state, row.worker, row.attempts, row.result, row.error}}
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func (t *fakeTable) Connect(context.Context) (driver.Conn, error) { return fakeConn{t}, nil }
+func (t *fakeTable) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ t *fakeTable }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, fmt.Errorf("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("not supported") }
+
+func values(args []driver.NamedValue) []driver.Value {
+	out := make([]driver.Value, len(args))
+	for i, arg := range args {
+		out[i] = arg.Value
+	}
+	return out
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	n, err := c.t.exec(query, values(args))
+	return driver.RowsAffected(n), err
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.t.query(query, values(args))
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// settableClock is a server.Clock whose time only moves when set.
+type settableClock struct {
+	server.Clock
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *settableClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *settableClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestQueue(t *testing.T, opts ...Option) (*Queue, *fakeTable) {
+	t.Helper()
+	table := &fakeTable{rows: make(map[string]*fakeRow)}
+	db := sql.OpenDB(table)
+	t.Cleanup(func() { db.Close() })
+	q, err := New(db, opts...)
+	require.NoError(t, err)
+	table.q = q.queries
+	require.NoError(t, q.CreateTable(context.Background()))
+	return q, table
+}
+
+func TestQueue(t *testing.T) {
+	clock := &settableClock{Clock: server.SystemClock, now: time.UnixMilli(1_700_000_000_000)}
+	q, _ := newTestQueue(t, WithClock(clock))
+	ctx := context.Background()
+
+	enqueuedAt := clock.Now()
+	require.NoError(t, q.Enqueue(ctx, server.TaskJob{ID: "a", Tool: "build", Arguments: json.RawMessage(`{"target":"x"}`), Principal: "alice", EnqueuedAt: enqueuedAt}))
+	require.NoError(t, q.Enqueue(ctx, server.TaskJob{ID: "b", Tool: "build", EnqueuedAt: enqueuedAt.Add(time.Millisecond)}))
+	assert.Error(t, q.Enqueue(ctx, server.TaskJob{ID: "a"}))
+
+	job, err := q.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, server.TaskJob{ID: "a", Tool: "build", Arguments: json.RawMessage(`{"target":"x"}`), Principal: "alice", EnqueuedAt: enqueuedAt, Attempts: 1}, job)
+	job, err = q.Claim(ctx, "w2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", job.ID)
+	assert.Nil(t, job.Arguments)
+	_, err = q.Claim(ctx, "w3", time.Minute)
+	assert.ErrorIs(t, err, server.ErrTaskQueueEmpty)
+
+	clock.advance(45 * time.Second)
+	require.NoError(t, q.Extend(ctx, "a", "w1", time.Minute))
+	clock.advance(30 * time.Second)
+	job, err = q.Claim(ctx, "w3", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", job.ID)
+	assert.Equal(t, 2, job.Attempts)
+	assert.ErrorIs(t, q.Extend(ctx, "b", "w2", time.Minute), server.ErrTaskJobLeaseLost)
+
+	require.NoError(t, q.Finish(ctx, "a", "w1", server.TaskJobOutcome{Result: json.RawMessage(`{"content":[]}`)}))
+	require.NoError(t, q.Finish(ctx, "b", "w3", server.TaskJobOutcome{Error: "boom"}))
+	status, err := q.Status(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, server.TaskJobStatus{State: server.TaskJobCompleted, Worker: "w1", Attempts: 1, Result: json.RawMessage(`{"content":[]}`)}, status)
+	status, err = q.Status(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, server.TaskJobStatus{State: server.TaskJobFailed, Worker: "w3", Attempts: 2, Error: "boom"}, status)
+
+	require.NoError(t, q.Enqueue(ctx, server.TaskJob{ID: "c", Tool: "build", EnqueuedAt: clock.Now()}))
+	_, err = q.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, q.Cancel(ctx, "c"))
+	assert.ErrorIs(t, q.Finish(ctx, "c", "w1", server.TaskJobOutcome{}), server.ErrTaskJobCancelled)
+
+	require.NoError(t, q.Remove(ctx, "c"))
+	_, err = q.Status(ctx, "c")
+	assert.ErrorIs(t, err, server.ErrTaskJobNotFound)
+	assert.ErrorIs(t, q.Extend(ctx, "c", "w1", time.Minute), server.ErrTaskJobNotFound)
+}
+
+func TestQueue_ClaimRace(t *testing.T) {
+	q, table := newTestQueue(t)
+	ctx := context.Background()
+	require.NoError(t, q.Enqueue(ctx, server.TaskJob{ID: "a", Tool: "build", EnqueuedAt: time.UnixMilli(1)}))
+	require.NoError(t, q.Enqueue(ctx, server.TaskJob{ID: "b", Tool: "build", EnqueuedAt: time.UnixMilli(2)}))
+
+	// Another worker claims a between our pick and our update.
+	table.beforeClaim = func(id string) {
+		table.beforeClaim = nil
+		_, err := q.Claim(ctx, "other", time.Minute)
+		require.NoError(t, err)
+	}
+	job, err := q.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", job.ID)
+	status, err := q.Status(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "other", status.Worker)
+}
+
+func TestQueue_WithServer(t *testing.T) {
+	q, _ := newTestQueue(t, WithPlaceholders(Dollar), WithTable("jobs"))
+	s := server.NewMCPServer("test", "1.0.0",
+		server.WithToolCapabilities(false),
+		server.WithTaskCapabilities(true, true, true),
+		server.WithTaskQueue(q, 5*time.Millisecond),
+	)
+	tool := server.ServerTool{
+		Tool: mcp.NewTool("greet"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("hello " + request.GetString("name", "")), nil
+		},
+	}
+	s.AddTools(tool)
+
+	pool := server.NewTaskWorkerPool(q, server.WithTaskWorkerPollInterval(time.Millisecond))
+	pool.RegisterTools(tool)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = pool.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"greet","arguments":{"name":"ada"},"task":{}}}`))
+	created, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CreateTaskResult)
+	require.True(t, ok, "unexpected response %#v", response)
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tasks/result","params":{"taskId":"`+created.Task.TaskId+`"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+	var result mcp.CallToolResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "hello ada", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestNew_InvalidTable(t *testing.T) {
+	for _, name := range []string{"", "jobs; DROP TABLE users", "a.b.c", "1jobs"} {
+		_, err := New(nil, WithTable(name))
+		assert.ErrorIs(t, err, ErrInvalidTable, name)
+	}
+	_, err := New(nil, WithTable("mcp.jobs"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,444 @@
+// Package sqltool exposes named, parameterized SQL queries as MCP tools.
+//
+// Each Query becomes one tool whose input schema is generated from the
+// query's parameters and whose structured result holds the returned rows:
+//
+//	provider, err := sqltool.New(db, []sqltool.Query{{
+//		Name:        "orders_by_customer",
+//		Description: "Orders of a customer, newest first",
+//		SQL:         "SELECT id, total, created_at FROM orders WHERE customer_id = $1 ORDER BY created_at DESC",
+//		Params: []sqltool.Param{
+//			{Name: "customer_id", Type: sqltool.Integer, Required: true},
+//		},
+//	}})
+//	if err != nil {
+//		return err
+//	}
+//	provider.Register(mcpServer)
+//
+// Queries are read-only unless the provider is created with AllowWrites.
+package sqltool
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultMaxRows is the number of rows a query returns at most, unless set
+// with WithMaxRows or Query.MaxRows.
+const DefaultMaxRows = 1000
+
+var (
+	// ErrInvalidQuery is returned by New for queries that cannot be exposed
+	// as tools.
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidArgument is returned for tool arguments that do not fit the
+	// query's parameters.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// ParamType is the JSON Schema type of a query parameter.
+type ParamType string
+
+const (
+	String  ParamType = "string"
+	Number  ParamType = "number"
+	Integer ParamType = "integer"
+	Boolean ParamType = "boolean"
+)
+
+// Param is a parameter of a query, bound to its placeholders in the order
+// of Query.Params.
+type Param struct {
+	Name        string
+	Type        ParamType
+	Description string
+	// Required parameters must be given by the caller. Optional ones are
+	// bound to Default, or to NULL without one.
+	Required bool
+	Default  any
+	// Enum restricts string parameters to the listed values.
+	Enum []string
+}
+
+// Query is a named SQL query exposed as a tool.
+type Query struct {
+	// Name is the tool name.
+	Name        string
+	Description string
+	// SQL is the statement, with placeholders in the syntax of the driver,
+	// e.g. $1 or ?, bound to Params in order.
+	SQL    string
+	Params []Param
+	// MaxRows overrides the provider's row limit for this query.
+	MaxRows int
+}
+
+// Result is the structured content of a query tool's result.
+type Result struct {
+	Columns []string         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+	// Truncated is set if the query returned more rows than its limit.
+	Truncated bool `json:"truncated,omitempty"`
+	// RowsAffected is the number of rows changed by a write statement.
+	RowsAffected *int64 `json:"rowsAffected,omitempty"`
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithMaxRows sets how many rows a query returns at most. It defaults to
+// DefaultMaxRows.
+func WithMaxRows(n int) Option {
+	return func(p *Provider) {
+		if n > 0 {
+			p.maxRows = n
+		}
+	}
+}
+
+// WithQueryTimeout bounds how long a query may run.
+func WithQueryTimeout(d time.Duration) Option {
+	return func(p *Provider) {
+		p.timeout = d
+	}
+}
+
+// WithToolPrefix prefixes the names of the generated tools.
+func WithToolPrefix(prefix string) Option {
+	return func(p *Provider) {
+		p.prefix = prefix
+	}
+}
+
+// AllowWrites lets queries modify the database. Without it, queries must
+// be read-only statements and run in read-only transactions.
+func AllowWrites() Option {
+	return func(p *Provider) {
+		p.readOnly = false
+	}
+}
+
+// Provider generates the tools of a set of queries on a database.
+type Provider struct {
+	db       *sql.DB
+	queries  []Query
+	maxRows  int
+	timeout  time.Duration
+	prefix   string
+	readOnly bool
+}
+
+// New returns the provider of the tools of queries, run on db. It fails
+// with ErrInvalidQuery for queries without a name or SQL, with duplicate
+// names or parameters, and, unless AllowWrites is given, for statements
+// that are not read-only.
+func New(db *sql.DB, queries []Query, opts ...Option) (*Provider, error) {
+	p := &Provider{
+		db:       db,
+		queries:  queries,
+		maxRows:  DefaultMaxRows,
+		readOnly: true,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	names := make(map[string]bool, len(queries))
+	for _, query := range queries {
+		if query.Name == "" || strings.TrimSpace(query.SQL) == "" {
+			return nil, fmt.Errorf("query %q needs a name and SQL: %w", query.Name, ErrInvalidQuery)
+		}
+		if names[query.Name] {
+			return nil, fmt.Errorf("query %q is defined twice: %w", query.Name, ErrInvalidQuery)
+		}
+		names[query.Name] = true
+		if p.readOnly && !isReadOnlyStatement(query.SQL) {
+			return nil, fmt.Errorf("query %q is not a read-only statement: %w", query.Name, ErrInvalidQuery)
+		}
+		params := make(map[string]bool, len(query.Params))
+		for _, param := range query.Params {
+			if param.Name == "" || params[param.Name] {
+				return nil, fmt.Errorf("query %q has an unnamed or duplicate parameter %q: %w", query.Name, param.Name, ErrInvalidQuery)
+			}
+			params[param.Name] = true
+			switch param.Type {
+			case String, Number, Integer, Boolean:
+			default:
+				return nil, fmt.Errorf("query %q parameter %q has unsupported type %q: %w", query.Name, param.Name, param.Type, ErrInvalidQuery)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Tools returns one tool per query.
+func (p *Provider) Tools() []server.ServerTool {
+	tools := make([]server.ServerTool, 0, len(p.queries))
+	for _, query := range p.queries {
+		tools = append(tools, server.ServerTool{
+			Tool:    p.tool(query),
+			Handler: p.handler(query),
+		})
+	}
+	return tools
+}
+
+// Register adds the tools of the queries to s.
+func (p *Provider) Register(s *server.MCPServer) {
+	s.AddTools(p.Tools()...)
+}
+
+func (p *Provider) tool(query Query) mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(query.Description),
+		mcp.WithOutputSchema[Result](),
+		mcp.WithReadOnlyHintAnnotation(p.readOnly),
+		mcp.WithDestructiveHintAnnotation(!p.readOnly),
+		mcp.WithOpenWorldHintAnnotation(false),
+	}
+	for _, param := range query.Params {
+		opts = append(opts, paramOption(param))
+	}
+	return mcp.NewTool(p.prefix+query.Name, opts...)
+}
+
+func paramOption(param Param) mcp.ToolOption {
+	props := []mcp.PropertyOption{mcp.Description(param.Description)}
+	if param.Required {
+		props = append(props, mcp.Required())
+	}
+	if param.Default != nil {
+		props = append(props, func(schema map[string]any) { schema["default"] = param.Default })
+	}
+	switch param.Type {
+	case String:
+		if len(param.Enum) > 0 {
+			props = append(props, mcp.Enum(param.Enum...))
+		}
+		return mcp.WithString(param.Name, props...)
+	case Integer:
+		props = append(props, func(schema map[string]any) { schema["type"] = "integer" })
+		return mcp.WithNumber(param.Name, props...)
+	case Boolean:
+		return mcp.WithBoolean(param.Name, props...)
+	default:
+		return mcp.WithNumber(param.Name, props...)
+	}
+}
+
+func (p *Provider) handler(query Query) server.ToolHandlerFunc {
+	maxRows := p.maxRows
+	if query.MaxRows > 0 {
+		maxRows = query.MaxRows
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := bindArguments(query.Params, request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if p.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+		}
+		result, err := p.run(ctx, query.SQL, args, maxRows)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", query.Name, err)
+		}
+		return mcp.NewToolResultStructuredOnly(result), nil
+	}
+}
+
+// run executes statement in a transaction, read-only unless writes are
+// allowed, and collects at most maxRows rows.
+func (p *Provider) run(ctx context.Context, statement string, args []any, maxRows int) (*Result, error) {
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: p.readOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Read-only statements are queried; others report the rows they changed.
+	if !isReadOnlyStatement(statement) {
+		res, err := tx.ExecContext(ctx, statement, args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return &Result{Columns: []string{}, Rows: []map[string]any{}, RowsAffected: &affected}, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Columns: columns, Rows: []map[string]any{}}
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if len(result.Rows) == maxRows {
+			result.Truncated = true
+			break
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[column] = jsonValue(values[i])
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// bindArguments returns the values bound to the placeholders of a query
+// with params, converted to the parameter types.
+func bindArguments(params []Param, arguments map[string]any) ([]any, error) {
+	args := make([]any, len(params))
+	for i, param := range params {
+		value, ok := arguments[param.Name]
+		if !ok || value == nil {
+			if param.Required {
+				return nil, fmt.Errorf("parameter %q is required: %w", param.Name, ErrInvalidArgument)
+			}
+			value = param.Default
+			if value == nil {
+				continue
+			}
+		}
+		converted, err := convert(param, value)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = converted
+	}
+	return args, nil
+}
+
+func convert(param Param, value any) (any, error) {
+	switch param.Type {
+	case String:
+		s, ok := value.(string)
+		if !ok {
+			break
+		}
+		if len(param.Enum) > 0 && !contains(param.Enum, s) {
+			return nil, fmt.Errorf("parameter %q must be one of %v: %w", param.Name, param.Enum, ErrInvalidArgument)
+		}
+		return s, nil
+	case Boolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case Number:
+		if n, ok := toFloat(value); ok {
+			return n, nil
+		}
+	case Integer:
+		switch n := value.(type) {
+		case int:
+			return int64(n), nil
+		case int64:
+			return n, nil
+		}
+		if n, ok := toFloat(value); ok && n == math.Trunc(n) {
+			// -2^63 and 2^63 are exact as floats; converting values outside
+			// them to int64 is implementation-defined.
+			if n < math.MinInt64 || n >= -math.MinInt64 {
+				return nil, fmt.Errorf("parameter %q is out of the integer range: %w", param.Name, ErrInvalidArgument)
+			}
+			return int64(n), nil
+		}
+	}
+	return nil, fmt.Errorf("parameter %q must be a %s: %w", param.Name, param.Type, ErrInvalidArgument)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue converts a scanned column value to a JSON-friendly one: byte
+// slices, as returned by many drivers for text columns, become strings.
+func jsonValue(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+var (
+	sqlComment       = regexp.MustCompile(`(?s)^\s*(--[^\n]*\n|/\*.*?\*/)`)
+	readOnlyKeywords = []string{"select", "with", "values", "show", "explain", "describe", "table"}
+	writeKeyword     = regexp.MustCompile(`(?i)\b(insert|update|delete|merge|drop|create|alter|truncate|grant|revoke|replace)\b`)
+)
+
+// isReadOnlyStatement reports whether statement starts with a keyword of a
+// read-only statement and, for WITH queries, contains no data-modifying
+// keyword. Transactions are still opened read-only, so the database has the
+// final word.
+func isReadOnlyStatement(statement string) bool {
+	for {
+		stripped := sqlComment.ReplaceAllString(statement, "")
+		if stripped == statement {
+			break
+		}
+		statement = stripped
+	}
+	fields := strings.Fields(strings.ToLower(statement))
+	if len(fields) == 0 || !contains(readOnlyKeywords, strings.TrimRight(fields[0], "(")) {
+		return false
+	}
+	if fields[0] == "with" && writeKeyword.MatchString(statement) {
+		return false
+	}
+	return strings.Count(strings.TrimRight(strings.TrimSpace(statement), ";"), ";") == 0
+}
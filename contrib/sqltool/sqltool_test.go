@@ -0,0 +1,260 @@
+package sqltool
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeDriver serves canned rows for each statement and records the
+// arguments and transaction modes it sees.
+type fakeDriver struct {
+	mu       sync.Mutex
+	rows     map[string][][]driver.Value
+	columns  map[string][]string
+	args     [][]driver.Value
+	readOnly []bool
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.readOnly = append(c.d.readOnly, opts.ReadOnly)
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) record(args []driver.NamedValue) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.args = append(c.d.args, values)
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(args)
+	columns, ok := c.d.columns[query]
+	if !ok {
+		return nil, fmt.Errorf("unknown query %q", query)
+	}
+	return &fakeRows{columns: columns, rows: c.d.rows[query]}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(args)
+	return driver.RowsAffected(3), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var registerOnce sync.Once
+var testDriver = &fakeDriver{}
+
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDriver) {
+	t.Helper()
+	registerOnce.Do(func() { sql.Register("sqltool-fake", testDriver) })
+	testDriver.mu.Lock()
+	testDriver.rows = map[string][][]driver.Value{
+		"SELECT id, name FROM users WHERE team = ? AND active = ?": {
+			{int64(1), []byte("ada")},
+			{int64(2), []byte("grace")},
+			{int64(3), []byte("linus")},
+		},
+	}
+	testDriver.columns = map[string][]string{
+		"SELECT id, name FROM users WHERE team = ? AND active = ?": {"id", "name"},
+	}
+	testDriver.args, testDriver.readOnly = nil, nil
+	testDriver.mu.Unlock()
+	db, err := sql.Open("sqltool-fake", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, testDriver
+}
+
+var usersQuery = Query{
+	Name:        "team_users",
+	Description: "Users of a team",
+	SQL:         "SELECT id, name FROM users WHERE team = ? AND active = ?",
+	Params: []Param{
+		{Name: "team", Type: String, Required: true, Enum: []string{"core", "web"}},
+		{Name: "active", Type: Boolean, Default: true},
+	},
+}
+
+func callTool(t *testing.T, s *server.MCPServer, name, arguments string) mcp.CallToolResult {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+arguments+`}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	return resp.Result.(mcp.CallToolResult)
+}
+
+func TestProviderTools(t *testing.T) {
+	db, fake := openFakeDB(t)
+	provider, err := New(db, []Query{usersQuery}, WithMaxRows(2), WithToolPrefix("db_"))
+	require.NoError(t, err)
+
+	tools := provider.Tools()
+	require.Len(t, tools, 1)
+	tool := tools[0].Tool
+	assert.Equal(t, "db_team_users", tool.Name)
+	assert.Equal(t, []string{"team"}, tool.InputSchema.Required)
+	assert.Equal(t, map[string]any{"type": "string", "description": "", "enum": []string{"core", "web"}}, tool.InputSchema.Properties["team"])
+	assert.Equal(t, true, tool.InputSchema.Properties["active"].(map[string]any)["default"])
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+	assert.NotNil(t, tool.OutputSchema.Properties["rows"])
+
+	s := server.NewMCPServer("test", "1.0.0")
+	provider.Register(s)
+
+	result := callTool(t, s, "db_team_users", `{"team":"core"}`)
+	require.False(t, result.IsError)
+	var rows Result
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &rows))
+	assert.Equal(t, []string{"id", "name"}, rows.Columns)
+	assert.Equal(t, []map[string]any{{"id": 1.0, "name": "ada"}, {"id": 2.0, "name": "grace"}}, rows.Rows)
+	assert.True(t, rows.Truncated)
+	assert.Equal(t, [][]driver.Value{{"core", true}}, fake.args)
+	assert.Equal(t, []bool{true}, fake.readOnly)
+
+	tests := []struct {
+		name      string
+		arguments string
+	}{
+		{name: "missing required", arguments: `{}`},
+		{name: "not in enum", arguments: `{"team":"ops"}`},
+		{name: "wrong type", arguments: `{"team":"core","active":"yes"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, callTool(t, s, "db_team_users", tt.arguments).IsError)
+		})
+	}
+}
+
+func TestProviderWrites(t *testing.T) {
+	db, fake := openFakeDB(t)
+	archive := Query{
+		Name: "archive_user",
+		SQL:  "UPDATE users SET archived = true WHERE id = ?",
+		Params: []Param{
+			{Name: "id", Type: Integer, Required: true},
+		},
+	}
+	_, err := New(db, []Query{archive})
+	require.ErrorIs(t, err, ErrInvalidQuery, "writes are rejected by default")
+
+	provider, err := New(db, []Query{archive}, AllowWrites())
+	require.NoError(t, err)
+	tool := provider.Tools()[0].Tool
+	assert.False(t, *tool.Annotations.ReadOnlyHint)
+	assert.Equal(t, "integer", tool.InputSchema.Properties["id"].(map[string]any)["type"])
+
+	s := server.NewMCPServer("test", "1.0.0")
+	provider.Register(s)
+	assert.True(t, callTool(t, s, "archive_user", `{"id":1.5}`).IsError)
+	result := callTool(t, s, "archive_user", `{"id":7}`)
+	require.False(t, result.IsError)
+	assert.Equal(t, int64(3), *result.StructuredContent.(*Result).RowsAffected)
+	assert.Equal(t, [][]driver.Value{{int64(7)}}, fake.args)
+	assert.Equal(t, []bool{false}, fake.readOnly)
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+	}{
+		{name: "no name", query: Query{SQL: "SELECT 1"}},
+		{name: "no sql", query: Query{Name: "q"}},
+		{name: "duplicate param", query: Query{Name: "q", SQL: "SELECT ?, ?", Params: []Param{{Name: "a", Type: String}, {Name: "a", Type: String}}}},
+		{name: "unknown type", query: Query{Name: "q", SQL: "SELECT ?", Params: []Param{{Name: "a", Type: "date"}}}},
+		{name: "multiple statements", query: Query{Name: "q", SQL: "SELECT 1; DROP TABLE users"}},
+		{name: "modifying cte", query: Query{Name: "q", SQL: "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(nil, []Query{tt.query})
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+		})
+	}
+
+	_, err := New(nil, []Query{{Name: "q", SQL: "SELECT 1"}, {Name: "q", SQL: "SELECT 2"}})
+	assert.ErrorIs(t, err, ErrInvalidQuery, "duplicate names")
+	_, err = New(nil, []Query{{Name: "q", SQL: "-- recent first\n/* users */ SELECT * FROM users;"}})
+	assert.NoError(t, err)
+}
+
+func TestConvertInteger(t *testing.T) {
+	param := Param{Name: "id", Type: Integer}
+	tests := []struct {
+		value any
+		want  int64
+		valid bool
+	}{
+		{value: float64(7), want: 7, valid: true},
+		{value: int64(math.MaxInt64), want: math.MaxInt64, valid: true},
+		{value: float64(math.MinInt64), want: math.MinInt64, valid: true},
+		{value: 1.5},
+		{value: 1e300},
+		{value: -1e300},
+		{value: math.Exp2(63)},
+		{value: math.Inf(1)},
+		{value: math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.value), func(t *testing.T) {
+			got, err := convert(param, tt.value)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidArgument)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -122,7 +122,8 @@ func demoElicitationHandler(s *server.MCPServer) server.ToolHandlerFunc {
 
 var requestCount atomic.Int32
 
-func main() {
+// newServer creates the demo server with its tools.
+func newServer() *server.MCPServer {
 	// Create server with elicitation capability
 	mcpServer := server.NewMCPServer(
 		"elicitation-demo-server",
@@ -333,7 +334,11 @@ func main() {
 		},
 	)
 
-	stdioServer := server.NewStdioServer(mcpServer)
+	return mcpServer
+}
+
+func main() {
+	stdioServer := server.NewStdioServer(newServer())
 
 	// Handle graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
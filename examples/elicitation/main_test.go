@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/examples/runner"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCreateProject(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer *mcp.ElicitationResult
+		want   string
+	}{
+		{
+			name:   "accepted",
+			answer: runner.Accept(map[string]any{"projectName": "demo", "framework": "vue", "includeTests": false}),
+			want:   "Created project 'demo' with framework: vue, tests: false",
+		},
+		{
+			name:   "defaults",
+			answer: runner.Accept(map[string]any{"projectName": "demo"}),
+			want:   "Created project 'demo' with framework: none, tests: true",
+		},
+		{
+			name:   "declined",
+			answer: runner.Decline(),
+			want:   "Project creation cancelled - user declined to provide information",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := runner.Start(t, newServer())
+			session.AnswerElicitation(tt.answer)
+
+			assert.Equal(t, tt.want, runner.Text(session.CallTool("create_project", nil)))
+			require.Len(t, session.Elicitations(), 1)
+		})
+	}
+}
+
+func TestProcessData(t *testing.T) {
+	session := runner.Start(t, newServer())
+
+	// Short data is processed without asking.
+	assert.Contains(t, runner.Text(session.CallTool("process_data", map[string]any{"data": "short"})), "Processed 5 characters of data")
+	assert.Empty(t, session.Elicitations())
+
+	long := strings.Repeat("x", 101)
+	session.AnswerElicitation(runner.Accept(map[string]any{"proceed": false, "reason": "too big"}))
+	assert.Equal(t, "Processing declined: too big", runner.Text(session.CallTool("process_data", map[string]any{"data": long})))
+
+	session.AnswerElicitation(runner.Accept(map[string]any{"proceed": true}))
+	assert.Contains(t, runner.Text(session.CallTool("process_data", map[string]any{"data": long})), "Processed 101 characters of data")
+	assert.Len(t, session.Elicitations(), 2)
+}
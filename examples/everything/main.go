@@ -65,6 +65,7 @@ func NewMCPServer() *server.MCPServer {
 		"example-servers/everything",
 		"1.0.0",
 		server.WithResourceCapabilities(true, true),
+		server.WithResourceSubscriptions(),
 		server.WithPromptCapabilities(true),
 		server.WithToolCapabilities(true),
 		server.WithLogging(),
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/examples/runner"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResourceUpdated(t *testing.T) {
+	session := runner.Start(t, NewMCPServer())
+
+	contents := session.ReadResource("test://static/resource")
+	require.Len(t, contents, 1)
+	text, ok := contents[0].(mcp.TextResourceContents)
+	require.True(t, ok, "unexpected contents %#v", contents[0])
+	assert.Equal(t, "This is a sample resource", text.Text)
+
+	// Subscribed clients hear about changes to resources made by the server.
+	session.Subscribe("test://static/resource")
+	require.NoError(t, session.Server().TouchResource(context.Background(), "test://static/resource"))
+	notification := session.AwaitNotification(mcp.MethodNotificationResourceUpdated)
+	assert.Equal(t, "test://static/resource", notification.Params.AdditionalFields["uri"])
+
+	session.Unsubscribe("test://static/resource")
+	require.NoError(t, session.Server().TouchResource(context.Background(), "test://static/resource"))
+	session.AwaitNoNotification(mcp.MethodNotificationResourceUpdated, 100*time.Millisecond)
+}
+
+func TestNotify(t *testing.T) {
+	session := runner.Start(t, NewMCPServer())
+
+	result := session.CallTool("notify", nil)
+	assert.Equal(t, "notification sent successfully", runner.Text(result))
+	notification := session.AwaitNotification("notifications/progress")
+	assert.EqualValues(t, 10, notification.Params.AdditionalFields["progress"])
+}
+
+func TestAdd(t *testing.T) {
+	session := runner.Start(t, NewMCPServer())
+
+	result := session.CallTool(string(ADD), map[string]any{"a": 2, "b": 3.5})
+	assert.Equal(t, "The sum of 2.000000 and 3.500000 is 5.500000.", runner.Text(result))
+}
@@ -0,0 +1,472 @@
+// Package runner runs example servers in-process against a scripted client,
+// so that the examples double as integration tests and their interactions
+// are verified patterns to copy from.
+//
+// A test starts the example's server, queues the answers the user and the
+// model would give, and drives the client through the interaction:
+//
+//	func TestExample(t *testing.T) {
+//		session := runner.Start(t, newServer())
+//		session.AnswerElicitation(runner.Accept(map[string]any{"strength": "single"}))
+//		taskID := session.StartTask("make_espresso", nil)
+//		result := session.TaskResult(taskID)
+//		assert.Contains(t, runner.Text(result), "Your single espresso is ready")
+//	}
+//
+// Answers pass through JSON before they reach the server, as they would
+// from a remote client. Every request, answer and notification is written to
+// the session's transcript, which is logged when the test fails.
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultTimeout bounds how long a Session waits for the server before
+// failing the test.
+const DefaultTimeout = 5 * time.Second
+
+// ErrNoScriptedAnswer is returned to the server for elicitation and sampling
+// requests the test queued no answer for.
+var ErrNoScriptedAnswer = errors.New("no scripted answer")
+
+// Session is a client connected in-process to an example server, answering
+// the server's elicitation and sampling requests from a script.
+type Session struct {
+	t       testing.TB
+	server  *server.MCPServer
+	client  *client.Client
+	ctx     context.Context
+	timeout time.Duration
+
+	elicitationAnswers chan *mcp.ElicitationResult
+	samplingAnswers    chan *mcp.CreateMessageResult
+	notifications      chan mcp.JSONRPCNotification
+
+	mu               sync.Mutex
+	elicitations     []mcp.ElicitationRequest
+	samplingRequests []mcp.CreateMessageRequest
+	transcript       []string
+}
+
+// Option configures a Session.
+type Option func(*Session)
+
+// WithTimeout sets how long the session waits for the server, DefaultTimeout
+// by default.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Session) {
+		if d > 0 {
+			s.timeout = d
+		}
+	}
+}
+
+// Start connects a client to srv and initializes it, declaring support for
+// elicitation and sampling. The session is closed when the test ends, which
+// fails if scripted answers were left unused.
+func Start(t testing.TB, srv *server.MCPServer, opts ...Option) *Session {
+	t.Helper()
+	s := &Session{
+		t:                  t,
+		server:             srv,
+		ctx:                context.Background(),
+		timeout:            DefaultTimeout,
+		elicitationAnswers: make(chan *mcp.ElicitationResult, 100),
+		samplingAnswers:    make(chan *mcp.CreateMessageResult, 100),
+		notifications:      make(chan mcp.JSONRPCNotification, 100),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	handlers := scriptedHandlers{s}
+	inProcess := transport.NewInProcessTransportWithOptions(srv,
+		transport.WithElicitationHandler(handlers),
+		transport.WithSamplingHandler(handlers),
+	)
+	s.client = client.NewClient(inProcess,
+		client.WithElicitationHandler(handlers),
+		client.WithSamplingHandler(handlers),
+	)
+	s.client.OnNotification(func(notification mcp.JSONRPCNotification) {
+		s.record("<- %s %s", notification.Method, marshal(notification.Params.AdditionalFields))
+		select {
+		case s.notifications <- notification:
+		default:
+			s.t.Errorf("notification %s dropped: too many notifications not awaited", notification.Method)
+		}
+	})
+
+	ctx, cancel := s.context()
+	defer cancel()
+	if err := s.client.Start(ctx); err != nil {
+		t.Fatalf("client.Start(): %v", err)
+	}
+	var initRequest mcp.InitializeRequest
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "example-runner", Version: "1.0.0"}
+	if _, err := s.client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("client.Initialize(): %v", err)
+	}
+
+	t.Cleanup(func() {
+		if n := len(s.elicitationAnswers) + len(s.samplingAnswers); n > 0 {
+			t.Errorf("%d scripted answers were not used", n)
+		}
+		if t.Failed() {
+			t.Logf("transcript:\n%s", strings.Join(s.Transcript(), "\n"))
+		}
+		_ = s.client.Close()
+	})
+	return s
+}
+
+// Client returns the session's client, for requests the session has no
+// helper for.
+func (s *Session) Client() *client.Client { return s.client }
+
+// Server returns the server the session is connected to.
+func (s *Session) Server() *server.MCPServer { return s.server }
+
+// CallTool calls a tool and returns its result, failing the test if the call
+// fails. Tool errors reported in the result do not fail it.
+func (s *Session) CallTool(name string, arguments map[string]any) *mcp.CallToolResult {
+	s.t.Helper()
+	result, err := s.callTool(name, arguments)
+	if err != nil {
+		s.t.Fatalf("tools/call %s: %v", name, err)
+	}
+	return result
+}
+
+// CallToolError calls a tool that is expected to fail and returns the
+// error, failing the test if the call succeeds.
+func (s *Session) CallToolError(name string, arguments map[string]any) error {
+	s.t.Helper()
+	result, err := s.callTool(name, arguments)
+	if err == nil {
+		s.t.Fatalf("tools/call %s: expected an error, got %s", name, marshal(result))
+	}
+	return err
+}
+
+func (s *Session) callTool(name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	s.record("-> tools/call %s %s", name, marshal(arguments))
+	result, err := s.client.CallTool(ctx, request)
+	if err != nil {
+		s.record("<- error %v", err)
+		return nil, err
+	}
+	s.record("<- %s", marshal(result))
+	return result, nil
+}
+
+// StartTask calls a tool as a task and returns the ID of the task.
+func (s *Session) StartTask(name string, arguments map[string]any) string {
+	s.t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	s.record("-> tools/call %s %s as task", name, marshal(arguments))
+	result, err := s.client.CallToolAsTask(ctx, request)
+	if err != nil {
+		s.t.Fatalf("tools/call %s as task: %v", name, err)
+	}
+	s.record("<- task %s %s", result.Task.TaskId, result.Task.Status)
+	return result.Task.TaskId
+}
+
+// AwaitTask waits for a task to reach a terminal status and returns it.
+func (s *Session) AwaitTask(taskID string) *mcp.Task {
+	s.t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	task, err := s.client.AwaitTask(ctx, taskID)
+	if err != nil {
+		s.t.Fatalf("awaiting task %s: %v", taskID, err)
+	}
+	s.record("<- task %s %s", taskID, task.Status)
+	return task
+}
+
+// TaskResult waits for a task started with StartTask to finish and returns
+// the tool's result.
+func (s *Session) TaskResult(taskID string) *mcp.CallToolResult {
+	s.t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	s.record("-> tasks/result %s", taskID)
+	result, err := s.client.GetToolTaskResult(ctx, mcp.TaskResultRequest{Params: mcp.TaskResultParams{TaskId: taskID}})
+	if err != nil {
+		s.t.Fatalf("tasks/result %s: %v", taskID, err)
+	}
+	s.record("<- %s", marshal(result))
+	return result
+}
+
+// ReadResource reads a resource and returns its contents.
+func (s *Session) ReadResource(uri string) []mcp.ResourceContents {
+	s.t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	var request mcp.ReadResourceRequest
+	request.Params.URI = uri
+	s.record("-> resources/read %s", uri)
+	result, err := s.client.ReadResource(ctx, request)
+	if err != nil {
+		s.t.Fatalf("resources/read %s: %v", uri, err)
+	}
+	s.record("<- %s", marshal(result))
+	return result.Contents
+}
+
+// Subscribe subscribes the session to notifications/resources/updated for
+// the resource.
+func (s *Session) Subscribe(uri string) {
+	s.t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	var request mcp.SubscribeRequest
+	request.Params.URI = uri
+	s.record("-> resources/subscribe %s", uri)
+	if err := s.client.Subscribe(ctx, request); err != nil {
+		s.t.Fatalf("resources/subscribe %s: %v", uri, err)
+	}
+	s.record("<- subscribed")
+}
+
+// Unsubscribe cancels a subscription made with Subscribe.
+func (s *Session) Unsubscribe(uri string) {
+	s.t.Helper()
+	ctx, cancel := s.context()
+	defer cancel()
+	var request mcp.UnsubscribeRequest
+	request.Params.URI = uri
+	s.record("-> resources/unsubscribe %s", uri)
+	if err := s.client.Unsubscribe(ctx, request); err != nil {
+		s.t.Fatalf("resources/unsubscribe %s: %v", uri, err)
+	}
+	s.record("<- unsubscribed")
+}
+
+// AwaitNoNotification fails the test if a notification with the method
+// arrives within the wait, skipping notifications with other methods.
+func (s *Session) AwaitNoNotification(method string, wait time.Duration) {
+	s.t.Helper()
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	for {
+		select {
+		case notification := <-s.notifications:
+			if notification.Method == method {
+				s.t.Fatalf("unexpected %s notification %s", method, marshal(notification.Params.AdditionalFields))
+			}
+		case <-timeout.C:
+			return
+		}
+	}
+}
+
+// AwaitNotification waits for a notification with the method, skipping
+// notifications with other methods.
+func (s *Session) AwaitNotification(method string) mcp.JSONRPCNotification {
+	s.t.Helper()
+	timeout := time.NewTimer(s.timeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case notification := <-s.notifications:
+			if notification.Method == method {
+				return notification
+			}
+		case <-timeout.C:
+			s.t.Fatalf("no %s notification within %v", method, s.timeout)
+		}
+	}
+}
+
+// AnswerElicitation queues the answer to the next elicitation request of
+// the server.
+func (s *Session) AnswerElicitation(result *mcp.ElicitationResult) {
+	s.elicitationAnswers <- result
+}
+
+// AnswerSampling queues the answer to the next sampling request of the
+// server.
+func (s *Session) AnswerSampling(result *mcp.CreateMessageResult) {
+	s.samplingAnswers <- result
+}
+
+// Elicitations returns the elicitation requests the server made so far.
+func (s *Session) Elicitations() []mcp.ElicitationRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]mcp.ElicitationRequest(nil), s.elicitations...)
+}
+
+// SamplingRequests returns the sampling requests the server made so far.
+func (s *Session) SamplingRequests() []mcp.CreateMessageRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]mcp.CreateMessageRequest(nil), s.samplingRequests...)
+}
+
+// Transcript returns the interaction so far, one line per message: "->"
+// for what the client sent and "<-" for what it received.
+func (s *Session) Transcript() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.transcript...)
+}
+
+func (s *Session) record(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transcript = append(s.transcript, fmt.Sprintf(format, args...))
+}
+
+func (s *Session) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(s.ctx, s.timeout)
+}
+
+// scriptedHandlers answers the server's requests from the session's queued
+// answers. It is both the client's and the in-process transport's handler.
+type scriptedHandlers struct{ s *Session }
+
+func (h scriptedHandlers) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s := h.s
+	s.mu.Lock()
+	s.elicitations = append(s.elicitations, request)
+	s.mu.Unlock()
+	s.record("<- elicitation/create %q", request.Params.Message)
+
+	answer, err := awaitAnswer(ctx, s, s.elicitationAnswers, "elicitation")
+	if err != nil {
+		return nil, err
+	}
+	s.record("-> %s %s", answer.Action, marshal(answer.Content))
+	return overTheWire(answer)
+}
+
+func (h scriptedHandlers) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	s := h.s
+	s.mu.Lock()
+	s.samplingRequests = append(s.samplingRequests, request)
+	s.mu.Unlock()
+	s.record("<- sampling/createMessage %s", marshal(request.Messages))
+
+	answer, err := awaitAnswer(ctx, s, s.samplingAnswers, "sampling")
+	if err != nil {
+		return nil, err
+	}
+	s.record("-> %s", marshal(answer))
+	return overTheWire(answer)
+}
+
+// awaitAnswer takes the next queued answer, waiting for the test to queue
+// one for up to the session's timeout.
+func awaitAnswer[T any](ctx context.Context, s *Session, answers chan T, kind string) (T, error) {
+	var zero T
+	timeout := time.NewTimer(s.timeout)
+	defer timeout.Stop()
+	select {
+	case answer := <-answers:
+		return answer, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-timeout.C:
+		s.t.Errorf("the server made a %s request but no answer was queued", kind)
+		return zero, fmt.Errorf("%w for %s request", ErrNoScriptedAnswer, kind)
+	}
+}
+
+// overTheWire passes an answer through JSON, as it would reach a server
+// from a remote client, so that handlers see e.g. numbers as float64 rather
+// than the Go types a test used.
+func overTheWire[T any](answer *T) (*T, error) {
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return nil, err
+	}
+	var decoded T
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+// Accept is the answer of a user submitting content.
+func Accept(content map[string]any) *mcp.ElicitationResult {
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: content,
+	}}
+}
+
+// Decline is the answer of a user declining to answer.
+func Decline() *mcp.ElicitationResult {
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+		Action: mcp.ElicitationResponseActionDecline,
+	}}
+}
+
+// Cancel is the answer of a user dismissing the question.
+func Cancel() *mcp.ElicitationResult {
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+		Action: mcp.ElicitationResponseActionCancel,
+	}}
+}
+
+// Reply is the answer of a model replying with text.
+func Reply(model, text string) *mcp.CreateMessageResult {
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent(text),
+		},
+		Model:      model,
+		StopReason: "endTurn",
+	}
+}
+
+// Text returns the text contents of a tool result, joined by newlines.
+func Text(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func marshal(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
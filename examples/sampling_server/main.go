@@ -10,7 +10,8 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func main() {
+// newServer creates the example server with its tools.
+func newServer() *server.MCPServer {
 	// Create a new MCP server
 	mcpServer := server.NewMCPServer("sampling-example-server", "1.0.0")
 
@@ -119,9 +120,13 @@ func main() {
 		}, nil
 	})
 
+	return mcpServer
+}
+
+func main() {
 	// Start the stdio server
 	log.Println("Starting sampling example server...")
-	if err := server.ServeStdio(mcpServer); err != nil {
+	if err := server.ServeStdio(newServer()); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/examples/runner"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestAskLLM(t *testing.T) {
+	session := runner.Start(t, newServer())
+	session.AnswerSampling(runner.Reply("test-model", "Paris."))
+
+	result := session.CallTool("ask_llm", map[string]any{
+		"question":      "What is the capital of France?",
+		"system_prompt": "Answer in one word.",
+	})
+	assert.False(t, result.IsError)
+	assert.Equal(t, "LLM Response (model: test-model): Paris.", runner.Text(result))
+
+	requests := session.SamplingRequests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "Answer in one word.", requests[0].SystemPrompt)
+	require.Len(t, requests[0].Messages, 1)
+	assert.Equal(t, mcp.RoleUser, requests[0].Messages[0].Role)
+	assert.Equal(t, "What is the capital of France?", mcp.GetTextFromContent(requests[0].Messages[0].Content))
+}
+
+func TestGreet(t *testing.T) {
+	session := runner.Start(t, newServer())
+
+	result := session.CallTool("greet", map[string]any{"name": "Ada"})
+	assert.Equal(t, "Hello, Ada! This server supports sampling - try using the ask_llm tool!", runner.Text(result))
+	assert.Empty(t, session.SamplingRequests())
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// brewTime is how long a shot takes to pull.
+const brewTime = 3 * time.Second
+
+// orderDialog asks for the strength of the espresso and, for doubles, how
+// much sugar to add. Answers that are not on the menu are asked again.
+func orderDialog() *server.Dialog {
+	dialog, err := server.NewDialog([]server.DialogStep{
+		{
+			Name:    "strength",
+			Message: "How strong would you like your espresso?",
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"strength": map[string]any{
+						"type": "string",
+						"enum": []string{"single", "double", "ristretto"},
+					},
+				},
+				"required": []string{"strength"},
+			},
+			Validate: func(answer map[string]any, _ server.DialogAnswers) error {
+				switch answer["strength"] {
+				case "single", "double", "ristretto":
+					return nil
+				}
+				return fmt.Errorf("%v is not on the menu", answer["strength"])
+			},
+			Next: func(answers server.DialogAnswers) string {
+				if answers["strength"]["strength"] == "double" {
+					return "sugar"
+				}
+				return ""
+			},
+		},
+		{
+			Name:    "sugar",
+			Message: "How many sugars with your double?",
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"sugars": map[string]any{"type": "integer", "minimum": 0, "maximum": 3},
+				},
+				"required": []string{"sugars"},
+			},
+			// Declining means no sugar.
+			Optional: true,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Invalid dialog: %v", err)
+	}
+	return dialog
+}
+
+// makeEspresso brews an espresso. It is meant to be called as a task: the
+// client gets a task ID back immediately and polls tasks/get until the shot
+// is ready. When the strength is not given, the user is asked for it, which
+// moves the task to input_required until they answer.
+func makeEspresso(s *server.MCPServer) server.ToolHandlerFunc {
+	dialog := orderDialog()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		strength := request.GetString("strength", "")
+		order := strength
+		if strength == "" {
+			result, err := dialog.Run(ctx, s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to take the order: %w", err)
+			}
+			switch result.Action {
+			case mcp.ElicitationResponseActionAccept:
+			case mcp.ElicitationResponseActionDecline:
+				return mcp.NewToolResultText("No espresso then."), nil
+			default:
+				return nil, fmt.Errorf("espresso order cancelled")
+			}
+			strength, _ = result.Answers["strength"]["strength"].(string)
+			order = strength
+			if sugars, ok := result.Answers["sugar"]["sugars"].(float64); ok && sugars > 0 {
+				order = fmt.Sprintf("%s with %d sugar(s)", strength, int(sugars))
+			}
+		}
+
+		// Waiting on the server's clock lets tests brew without the wait.
+		timer := s.Clock().NewTimer(brewTime)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			// The task was cancelled with tasks/cancel.
+			return nil, ctx.Err()
+		}
+
+		if taskID := server.TaskIDFromContext(ctx); taskID != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("Your %s espresso is ready (task %s).", order, taskID)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Your %s espresso is ready.", order)), nil
+	}
+}
+
+// newServer creates the demo server; tests pass extra options, e.g. a fake
+// clock.
+func newServer(opts ...server.ServerOption) *server.MCPServer {
+	opts = append([]server.ServerOption{
+		server.WithToolCapabilities(false),
+		server.WithElicitation(),
+		// Allow tools/call to run as a task, and tasks to be listed and cancelled.
+		server.WithTaskCapabilities(true, true, true),
+		// Clients that cannot answer questions get a single shot.
+		server.WithInputFallback(server.DefaultAnswersInputFallback(map[string]any{
+			"strength": "single",
+			"sugars":   0,
+		})),
+	}, opts...)
+	mcpServer := server.NewMCPServer("task-augmented-tools-demo", "1.0.0", opts...)
+
+	mcpServer.AddTool(
+		mcp.NewTool(
+			"make_espresso",
+			mcp.WithDescription("Brews an espresso. Call it as a task to avoid waiting on the response."),
+			mcp.WithString("strength",
+				mcp.Description("Strength of the espresso; asked for when omitted"),
+				mcp.Enum("single", "double", "ristretto"),
+			),
+		),
+		makeEspresso(mcpServer),
+	)
+	return mcpServer
+}
+
+func main() {
+	if err := server.ServeStdio(newServer()); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
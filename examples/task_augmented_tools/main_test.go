@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/examples/runner"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// brew lets the clock run until the espresso of a running task is ready.
+func brew(t *testing.T, clock *mcptest.FakeClock) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), runner.DefaultTimeout)
+	defer cancel()
+	require.NoError(t, clock.BlockUntil(ctx, 1), "the espresso is not brewing")
+	clock.Advance(brewTime)
+}
+
+func TestMakeEspresso(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		answers   []*mcp.ElicitationResult
+		questions []string
+		want      string
+	}{
+		{
+			name:      "strength given",
+			arguments: map[string]any{"strength": "ristretto"},
+			want:      "Your ristretto espresso is ready",
+		},
+		{
+			name:      "strength asked",
+			answers:   []*mcp.ElicitationResult{runner.Accept(map[string]any{"strength": "single"})},
+			questions: []string{"How strong would you like your espresso?"},
+			want:      "Your single espresso is ready",
+		},
+		{
+			name: "double with sugar",
+			answers: []*mcp.ElicitationResult{
+				runner.Accept(map[string]any{"strength": "double"}),
+				runner.Accept(map[string]any{"sugars": 2}),
+			},
+			questions: []string{"How strong would you like your espresso?", "How many sugars with your double?"},
+			want:      "Your double with 2 sugar(s) espresso is ready",
+		},
+		{
+			name: "strength off the menu",
+			answers: []*mcp.ElicitationResult{
+				runner.Accept(map[string]any{"strength": "lungo"}),
+				runner.Accept(map[string]any{"strength": "double"}),
+				runner.Decline(),
+			},
+			questions: []string{
+				"How strong would you like your espresso?",
+				"lungo is not on the menu\n\nHow strong would you like your espresso?",
+				"How many sugars with your double?",
+			},
+			want: "Your double espresso is ready",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := mcptest.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			session := runner.Start(t, newServer(server.WithClock(clock)))
+			for _, answer := range tt.answers {
+				session.AnswerElicitation(answer)
+			}
+
+			taskID := session.StartTask("make_espresso", tt.arguments)
+			brew(t, clock)
+			result := session.TaskResult(taskID)
+
+			assert.Contains(t, runner.Text(result), tt.want)
+			var questions []string
+			for _, request := range session.Elicitations() {
+				questions = append(questions, request.Params.Message)
+			}
+			assert.Equal(t, tt.questions, questions)
+		})
+	}
+}
+
+func TestMakeEspresso_Declined(t *testing.T) {
+	session := runner.Start(t, newServer())
+	session.AnswerElicitation(runner.Decline())
+
+	taskID := session.StartTask("make_espresso", nil)
+	assert.Equal(t, mcp.TaskStatusCompleted, session.AwaitTask(taskID).Status)
+	assert.Equal(t, "No espresso then.", runner.Text(session.TaskResult(taskID)))
+}
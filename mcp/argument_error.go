@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Constraints reported by ArgumentError.
+const (
+	// ArgumentConstraintType means the argument has the wrong JSON type.
+	ArgumentConstraintType = "type"
+	// ArgumentConstraintFormat means a string argument is not in the format
+	// of its Go type, such as a duration or a time.
+	ArgumentConstraintFormat = "format"
+	// ArgumentConstraintSyntax means the arguments are not valid JSON.
+	ArgumentConstraintSyntax = "syntax"
+)
+
+// ArgumentError describes a tool argument that could not be bound to the
+// handler's argument type. Its fields are meant for host applications that
+// show the problem to end users, e.g. to highlight the field or to translate
+// the message with a MessageCatalog.
+type ArgumentError struct {
+	// Field is the path of the argument, e.g. "windows[1]". It is empty
+	// when the problem concerns the arguments as a whole.
+	Field string `json:"field,omitempty"`
+	// Constraint is the violated constraint, one of the ArgumentConstraint
+	// constants.
+	Constraint string `json:"constraint"`
+	// Got is the offending value or, for type errors, its JSON type.
+	Got any `json:"got,omitempty"`
+	// Want is the expected JSON type or format.
+	Want string `json:"want,omitempty"`
+
+	err error
+}
+
+func (e *ArgumentError) Error() string {
+	switch e.Constraint {
+	case ArgumentConstraintType:
+		if e.Field == "" {
+			return fmt.Sprintf("arguments: got %v, want %s", e.Got, e.Want)
+		}
+		return fmt.Sprintf("argument %s: got %v, want %s", e.Field, e.Got, e.Want)
+	case ArgumentConstraintFormat:
+		return fmt.Sprintf("argument %s: invalid %s %q", e.Field, e.Want, e.Got)
+	default:
+		if e.err != nil {
+			return fmt.Sprintf("arguments are not valid JSON: %v", e.err)
+		}
+		return "arguments are not valid JSON"
+	}
+}
+
+// Unwrap returns the underlying decoding error, if any.
+func (e *ArgumentError) Unwrap() error {
+	return e.err
+}
+
+// asArgumentError converts the errors of decoding arguments into
+// *ArgumentError. Other errors are returned as is.
+func asArgumentError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &ArgumentError{
+			Field:      typeErr.Field,
+			Constraint: ArgumentConstraintType,
+			Got:        typeErr.Value,
+			Want:       jsonTypeName(typeErr.Type),
+			err:        err,
+		}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &ArgumentError{Constraint: ArgumentConstraintSyntax, err: err}
+	}
+	return err
+}
+
+// jsonTypeName returns the JSON type that decodes into t.
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return t.String()
+	}
+}
+
+// MessageCatalog translates argument errors into messages for end users,
+// typically in a locale taken from ctx. It returns "" to keep the default
+// English message.
+type MessageCatalog func(ctx context.Context, err *ArgumentError) string
+
+type messageCatalogKey struct{}
+
+// WithMessageCatalog returns a context whose argument errors are translated
+// with catalog by the typed tool handlers.
+func WithMessageCatalog(ctx context.Context, catalog MessageCatalog) context.Context {
+	return context.WithValue(ctx, messageCatalogKey{}, catalog)
+}
+
+// MessageCatalogFromContext returns the catalog set with WithMessageCatalog,
+// or nil.
+func MessageCatalogFromContext(ctx context.Context) MessageCatalog {
+	catalog, _ := ctx.Value(messageCatalogKey{}).(MessageCatalog)
+	return catalog
+}
+
+// NewArgumentErrorResult returns the tool result for arguments that could not
+// be bound. For an *ArgumentError the result carries it as structured content
+// under "error", and its text is translated by the context's MessageCatalog if
+// there is one.
+func NewArgumentErrorResult(ctx context.Context, err error) *CallToolResult {
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) {
+		return NewToolResultError(fmt.Sprintf("failed to bind arguments: %v", err))
+	}
+
+	message := fmt.Sprintf("failed to bind arguments: %v", err)
+	if catalog := MessageCatalogFromContext(ctx); catalog != nil {
+		if translated := catalog(ctx, argErr); translated != "" {
+			message = translated
+		}
+	}
+	result := NewToolResultError(message)
+	result.StructuredContent = map[string]any{
+		"error": struct {
+			*ArgumentError
+			Message string `json:"message"`
+		}{argErr, message},
+	}
+	return result
+}
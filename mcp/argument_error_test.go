@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindArguments_ArgumentError(t *testing.T) {
+	type forecastArgs struct {
+		City  string `json:"city"`
+		Days  int    `json:"days"`
+		Query struct {
+			Units string `json:"units"`
+		} `json:"query"`
+	}
+
+	tests := []struct {
+		name      string
+		arguments any
+		want      ArgumentError
+		message   string
+	}{
+		{
+			name:      "wrong type",
+			arguments: map[string]any{"city": "Oslo", "days": "three"},
+			want:      ArgumentError{Field: "days", Constraint: ArgumentConstraintType, Got: "string", Want: "integer"},
+			message:   "argument days: got string, want integer",
+		},
+		{
+			name:      "nested field",
+			arguments: map[string]any{"query": map[string]any{"units": 3}},
+			want:      ArgumentError{Field: "query.units", Constraint: ArgumentConstraintType, Got: "number", Want: "string"},
+			message:   "argument query.units: got number, want string",
+		},
+		{
+			name:      "not an object",
+			arguments: json.RawMessage(`["Oslo"]`),
+			want:      ArgumentError{Constraint: ArgumentConstraintType, Got: "array", Want: "object"},
+			message:   "arguments: got array, want object",
+		},
+		{
+			name:      "malformed JSON",
+			arguments: json.RawMessage(`{"city":`),
+			want:      ArgumentError{Constraint: ArgumentConstraintSyntax},
+			message:   "arguments are not valid JSON",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request CallToolRequest
+			request.Params.Arguments = tt.arguments
+			var args forecastArgs
+			err := request.BindArguments(&args)
+
+			var argErr *ArgumentError
+			require.ErrorAs(t, err, &argErr)
+			assert.Equal(t, tt.want.Field, argErr.Field)
+			assert.Equal(t, tt.want.Constraint, argErr.Constraint)
+			assert.Equal(t, tt.want.Got, argErr.Got)
+			assert.Equal(t, tt.want.Want, argErr.Want)
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
+
+func TestNewArgumentErrorResult(t *testing.T) {
+	argErr := &ArgumentError{Field: "every", Constraint: ArgumentConstraintFormat, Got: "soon", Want: "duration"}
+
+	result := NewArgumentErrorResult(context.Background(), argErr)
+	assert.True(t, result.IsError)
+	assert.Equal(t, `failed to bind arguments: argument every: invalid duration "soon"`, result.Content[0].(TextContent).Text)
+	data, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error":{"field":"every","constraint":"format","got":"soon","want":"duration","message":"failed to bind arguments: argument every: invalid duration \"soon\""}}`, string(data))
+
+	ctx := WithMessageCatalog(context.Background(), func(ctx context.Context, err *ArgumentError) string {
+		if err.Constraint == ArgumentConstraintFormat {
+			return "Ungültige Dauer für " + err.Field
+		}
+		return ""
+	})
+	result = NewArgumentErrorResult(ctx, argErr)
+	assert.Equal(t, "Ungültige Dauer für every", result.Content[0].(TextContent).Text)
+
+	// Other errors keep the plain message.
+	result = NewArgumentErrorResult(ctx, errors.New("boom"))
+	assert.Equal(t, "failed to bind arguments: boom", result.Content[0].(TextContent).Text)
+	assert.Nil(t, result.StructuredContent)
+}
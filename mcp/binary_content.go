@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// BinaryContentSchema returns the JSON Schema of an elicitation field whose
+// answer is an image or audio content part, such as a photo of a receipt.
+// kind is ContentTypeImage or ContentTypeAudio; mimeTypes, if given, limits
+// the accepted MIME types and may use wildcards such as "image/*".
+func BinaryContentSchema(kind string, mimeTypes ...string) map[string]any {
+	mimeType := map[string]any{"type": "string"}
+	if len(mimeTypes) > 0 {
+		alternatives := make([]string, len(mimeTypes))
+		for i, pattern := range mimeTypes {
+			if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+				alternatives[i] = regexp.QuoteMeta(prefix) + "/[^/]+"
+			} else {
+				alternatives[i] = regexp.QuoteMeta(pattern)
+			}
+		}
+		mimeType["pattern"] = "^(" + strings.Join(alternatives, "|") + ")$"
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"type":     map[string]any{"type": "string", "const": kind},
+			"data":     map[string]any{"type": "string", "contentEncoding": "base64"},
+			"mimeType": mimeType,
+		},
+		"required": []string{"type", "data", "mimeType"},
+	}
+}
+
+// BinaryContentLimits restricts the image and audio content exchanged in
+// sampling and elicitation messages. Zero fields are unlimited.
+type BinaryContentLimits struct {
+	// MaxBytes limits the decoded size of a single content part.
+	MaxBytes int
+	// MIMETypes lists the accepted MIME types, e.g. "image/png" or "audio/*".
+	MIMETypes []string
+}
+
+// ValidateBinaryContent checks an image or audio content part: its data must
+// be valid base64 within the size limit, its MIME type must be accepted, and
+// its data must not look like a different kind of media than it declares.
+// Other content is valid.
+func ValidateBinaryContent(content any, limits BinaryContentLimits) error {
+	var kind, data, mimeType string
+	switch c := content.(type) {
+	case ImageContent:
+		kind, data, mimeType = ContentTypeImage, c.Data, c.MIMEType
+	case *ImageContent:
+		kind, data, mimeType = ContentTypeImage, c.Data, c.MIMEType
+	case AudioContent:
+		kind, data, mimeType = ContentTypeAudio, c.Data, c.MIMEType
+	case *AudioContent:
+		kind, data, mimeType = ContentTypeAudio, c.Data, c.MIMEType
+	default:
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return fmt.Errorf("%s content: invalid MIME type %q: %w", kind, mimeType, err)
+	}
+	if !strings.HasPrefix(mediaType, kind+"/") {
+		return fmt.Errorf("%s content: MIME type %s is not an %s type", kind, mediaType, kind)
+	}
+	if len(limits.MIMETypes) > 0 && !matchMIMEType(mediaType, limits.MIMETypes) {
+		return fmt.Errorf("%s content: MIME type %s is not accepted", kind, mediaType)
+	}
+	// Reject oversized data before decoding it; DecodedLen counts up to two
+	// bytes of padding.
+	if limits.MaxBytes > 0 && base64.StdEncoding.DecodedLen(len(data)) > limits.MaxBytes+2 {
+		return fmt.Errorf("%s content: larger than %d bytes", kind, limits.MaxBytes)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("%s content: invalid base64 data: %w", kind, err)
+	}
+	if limits.MaxBytes > 0 && len(decoded) > limits.MaxBytes {
+		return fmt.Errorf("%s content: %d bytes is larger than %d bytes", kind, len(decoded), limits.MaxBytes)
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(decoded))
+	if major, _, _ := strings.Cut(sniffed, "/"); (major == "image" || major == "audio" || major == "video") && !sniffedAs(kind, major) {
+		return fmt.Errorf("%s content: data looks like %s", kind, sniffed)
+	}
+	return nil
+}
+
+// sniffedAs reports whether data of the given kind can be sniffed as the
+// major type. Containers such as MP4 and WebM hold audio as well as video,
+// and are sniffed as video.
+func sniffedAs(kind, major string) bool {
+	return major == kind || (kind == ContentTypeAudio && major == "video")
+}
+
+// BinaryContentField returns the image or audio content part answered for
+// an elicitation field declared with BinaryContentSchema. It returns nil if
+// the field is missing or not a content part.
+func (r ElicitationResponse) BinaryContentField(name string) (Content, error) {
+	fields, ok := r.Content.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	part, ok := fields[name].(map[string]any)
+	if !ok || !IsBinaryContentPart(part) {
+		return nil, nil
+	}
+	return ParseContent(part)
+}
+
+// IsBinaryContentPart reports whether a decoded JSON object is an image or
+// audio content part.
+func IsBinaryContentPart(part map[string]any) bool {
+	kind := ExtractString(part, "type")
+	return kind == ContentTypeImage || kind == ContentTypeAudio
+}
+
+func matchMIMEType(mediaType string, accepted []string) bool {
+	for _, pattern := range accepted {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if strings.EqualFold(pattern, mediaType) {
+			return true
+		}
+	}
+	return false
+}
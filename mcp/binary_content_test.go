@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testPNG = base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	testWAV = base64.StdEncoding.EncodeToString([]byte("RIFF\x24\x00\x00\x00WAVEfmt "))
+	// MP4 and WebM containers are sniffed as video/mp4 and video/webm.
+	testMP4  = base64.StdEncoding.EncodeToString([]byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00mp42isom"))
+	testWebM = base64.StdEncoding.EncodeToString([]byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01"))
+)
+
+func TestValidateBinaryContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content any
+		limits  BinaryContentLimits
+		err     string
+	}{
+		{name: "image", content: NewImageContent(testPNG, "image/png")},
+		{name: "audio pointer", content: &AudioContent{Type: ContentTypeAudio, Data: testWAV, MIMEType: "audio/wav"}},
+		{name: "text is ignored", content: NewTextContent("hello"), limits: BinaryContentLimits{MaxBytes: 1}},
+		{name: "accepted wildcard", content: NewImageContent(testPNG, "image/png"), limits: BinaryContentLimits{MIMETypes: []string{"image/*"}}},
+		{
+			name:    "not accepted",
+			content: NewImageContent(testPNG, "image/png"),
+			limits:  BinaryContentLimits{MIMETypes: []string{"image/jpeg"}},
+			err:     "image content: MIME type image/png is not accepted",
+		},
+		{
+			name:    "too large",
+			content: NewImageContent(testPNG, "image/png"),
+			limits:  BinaryContentLimits{MaxBytes: 8},
+			err:     "image content: larger than 8 bytes",
+		},
+		{
+			name:    "wrong kind of MIME type",
+			content: NewImageContent(testPNG, "audio/wav"),
+			err:     "image content: MIME type audio/wav is not an image type",
+		},
+		{
+			name:    "invalid base64",
+			content: NewAudioContent("not base64!", "audio/wav"),
+			err:     "audio content: invalid base64 data",
+		},
+		{
+			name:    "data of another kind",
+			content: NewAudioContent(testPNG, "audio/wav"),
+			err:     "audio content: data looks like image/png",
+		},
+		{name: "audio in an MP4 container", content: NewAudioContent(testMP4, "audio/mp4")},
+		{name: "audio in a WebM container", content: NewAudioContent(testWebM, "audio/webm")},
+		{
+			name:    "image in a video container",
+			content: NewImageContent(testMP4, "image/png"),
+			err:     "image content: data looks like video/mp4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBinaryContent(tt.content, tt.limits)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestBinaryContentSchema(t *testing.T) {
+	schema := BinaryContentSchema(ContentTypeImage, "image/*", "application/pdf")
+	mimeType := schema["properties"].(map[string]any)["mimeType"].(map[string]any)
+	pattern := regexp.MustCompile(mimeType["pattern"].(string))
+	assert.True(t, pattern.MatchString("image/jpeg"))
+	assert.True(t, pattern.MatchString("application/pdf"))
+	assert.False(t, pattern.MatchString("audio/wav"))
+	assert.Empty(t, CheckSchema(map[string]any{"type": "object", "properties": map[string]any{"receipt": schema}}))
+}
+
+func TestElicitationResponse_BinaryContentField(t *testing.T) {
+	response := ElicitationResponse{
+		Action: ElicitationResponseActionAccept,
+		Content: map[string]any{
+			"note":    "lunch",
+			"receipt": map[string]any{"type": "image", "data": testPNG, "mimeType": "image/png"},
+		},
+	}
+
+	content, err := response.BinaryContentField("receipt")
+	require.NoError(t, err)
+	assert.Equal(t, NewImageContent(testPNG, "image/png"), content)
+
+	content, err = response.BinaryContentField("note")
+	require.NoError(t, err)
+	assert.Nil(t, content)
+}
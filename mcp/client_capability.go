@@ -0,0 +1,51 @@
+package mcp
+
+// ClientCapability names a capability a client declares during
+// initialization. Names other than the standard ones below refer to keys of
+// ClientCapabilities.Experimental.
+type ClientCapability string
+
+const (
+	ClientCapabilityRoots       ClientCapability = "roots"
+	ClientCapabilitySampling    ClientCapability = "sampling"
+	ClientCapabilityElicitation ClientCapability = "elicitation"
+	ClientCapabilityTasks       ClientCapability = "tasks"
+)
+
+// Has reports whether the client declared the capability.
+func (c ClientCapabilities) Has(capability ClientCapability) bool {
+	switch capability {
+	case ClientCapabilityRoots:
+		return c.Roots != nil
+	case ClientCapabilitySampling:
+		return c.Sampling != nil
+	case ClientCapabilityElicitation:
+		return c.Elicitation != nil
+	case ClientCapabilityTasks:
+		return c.Tasks != nil
+	default:
+		_, ok := c.Experimental[string(capability)]
+		return ok
+	}
+}
+
+// WithRequiredClientCapabilities declares the client capabilities the tool
+// needs, e.g. a tool that asks the user for input needs elicitation. Servers
+// hide the tool from clients that did not declare them and reject its calls.
+func WithRequiredClientCapabilities(capabilities ...ClientCapability) ToolOption {
+	return func(t *Tool) {
+		t.RequiredClientCapabilities = append(t.RequiredClientCapabilities, capabilities...)
+	}
+}
+
+// MissingClientCapabilities returns the capabilities the tool requires that
+// the client did not declare.
+func (t Tool) MissingClientCapabilities(capabilities ClientCapabilities) []ClientCapability {
+	var missing []ClientCapability
+	for _, capability := range t.RequiredClientCapabilities {
+		if !capabilities.Has(capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,292 @@
+package mcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+const (
+	// ExperimentalContentEncoding is the experimental capability with which
+	// clients and servers declare the encodings of binary data they can
+	// decode, see ContentEncodingCapability.
+	ExperimentalContentEncoding = "contentEncoding"
+
+	// ContentEncodingMeta is the _meta key naming the encoding of the data
+	// of an image content part or of the blob of resource contents. Data
+	// without it is not encoded.
+	ContentEncodingMeta = "contentEncoding"
+
+	// ContentEncodingGzip is the gzip content encoding: the base64 of the
+	// gzip-compressed data.
+	ContentEncodingGzip = "gzip"
+)
+
+// maxDecodedContentSize bounds the decompressed size of encoded data, so a
+// small payload cannot expand without limit.
+const maxDecodedContentSize = 256 << 20
+
+// ErrUnsupportedContentEncoding is returned when decoding data of an
+// encoding this package does not implement.
+var ErrUnsupportedContentEncoding = errors.New("unsupported content encoding")
+
+// ContentEncodingCapability is the payload of the ExperimentalContentEncoding
+// capability: the encodings a side can decode, preferred first.
+type ContentEncodingCapability struct {
+	Encodings []string `json:"encodings"`
+}
+
+// ContentEncodings is the typed ExperimentalContentEncoding capability.
+var ContentEncodings = NewExperimentalCapability[ContentEncodingCapability](ExperimentalContentEncoding)
+
+// NegotiateContentEncoding returns the first of local's encodings that
+// remote can decode, or false if they share none.
+func NegotiateContentEncoding(local, remote ContentEncodingCapability) (string, bool) {
+	for _, encoding := range local.Encodings {
+		if slices.Contains(remote.Encodings, encoding) {
+			return encoding, true
+		}
+	}
+	return "", false
+}
+
+// EncodeContent returns content with its data encoded with encoding, for
+// image content parts and embedded blob resources of at least minSize
+// decoded bytes. Other content, content that is already encoded and
+// content that encoding would not make smaller is returned as is.
+func EncodeContent(content Content, encoding string, minSize int) Content {
+	switch c := content.(type) {
+	case ImageContent:
+		if metaString(c.Meta, ContentEncodingMeta) != "" {
+			return c
+		}
+		data, ok := encodeData(c.Data, encoding, minSize)
+		if !ok {
+			return c
+		}
+		c.Data = data
+		c.Meta = withMetaField(c.Meta, ContentEncodingMeta, encoding)
+		return c
+	case *ImageContent:
+		if c == nil {
+			return c
+		}
+		encoded := EncodeContent(*c, encoding, minSize).(ImageContent)
+		return &encoded
+	case EmbeddedResource:
+		c.Resource = EncodeResourceContents(c.Resource, encoding, minSize)
+		return c
+	case *EmbeddedResource:
+		if c == nil {
+			return c
+		}
+		encoded := EncodeContent(*c, encoding, minSize).(EmbeddedResource)
+		return &encoded
+	default:
+		return content
+	}
+}
+
+// EncodeResourceContents returns contents with its blob encoded with
+// encoding if it has at least minSize decoded bytes. Text contents,
+// contents that are already encoded and contents that encoding would not
+// make smaller are returned as is.
+func EncodeResourceContents(contents ResourceContents, encoding string, minSize int) ResourceContents {
+	switch c := contents.(type) {
+	case BlobResourceContents:
+		if s, _ := c.Meta[ContentEncodingMeta].(string); s != "" {
+			return c
+		}
+		blob, ok := encodeData(c.Blob, encoding, minSize)
+		if !ok {
+			return c
+		}
+		c.Blob = blob
+		c.Meta = maps.Clone(c.Meta)
+		if c.Meta == nil {
+			c.Meta = make(map[string]any, 1)
+		}
+		c.Meta[ContentEncodingMeta] = encoding
+		return c
+	case *BlobResourceContents:
+		if c == nil {
+			return c
+		}
+		encoded := EncodeResourceContents(*c, encoding, minSize).(BlobResourceContents)
+		return &encoded
+	default:
+		return contents
+	}
+}
+
+// DecodeContent returns content with the data of image content parts and
+// embedded blob resources decoded as their ContentEncodingMeta declares,
+// removing the key from their _meta. Content that is not encoded is
+// returned as is.
+func DecodeContent(content Content) (Content, error) {
+	switch c := content.(type) {
+	case ImageContent:
+		encoding := metaString(c.Meta, ContentEncodingMeta)
+		if encoding == "" {
+			return c, nil
+		}
+		data, err := decodeData(c.Data, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("image content: %w", err)
+		}
+		c.Data = data
+		c.Meta = withoutMetaField(c.Meta, ContentEncodingMeta)
+		return c, nil
+	case *ImageContent:
+		if c == nil {
+			return c, nil
+		}
+		decoded, err := DecodeContent(*c)
+		if err != nil {
+			return nil, err
+		}
+		image := decoded.(ImageContent)
+		return &image, nil
+	case EmbeddedResource:
+		resource, err := DecodeResourceContents(c.Resource)
+		if err != nil {
+			return nil, err
+		}
+		c.Resource = resource
+		return c, nil
+	case *EmbeddedResource:
+		if c == nil {
+			return c, nil
+		}
+		decoded, err := DecodeContent(*c)
+		if err != nil {
+			return nil, err
+		}
+		embedded := decoded.(EmbeddedResource)
+		return &embedded, nil
+	default:
+		return content, nil
+	}
+}
+
+// DecodeResourceContents returns contents with its blob decoded as its
+// ContentEncodingMeta declares, removing the key from its _meta. Contents
+// that are not encoded are returned as is.
+func DecodeResourceContents(contents ResourceContents) (ResourceContents, error) {
+	switch c := contents.(type) {
+	case BlobResourceContents:
+		encoding, _ := c.Meta[ContentEncodingMeta].(string)
+		if encoding == "" {
+			return c, nil
+		}
+		blob, err := decodeData(c.Blob, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s: %w", c.URI, err)
+		}
+		c.Blob = blob
+		c.Meta = maps.Clone(c.Meta)
+		delete(c.Meta, ContentEncodingMeta)
+		if len(c.Meta) == 0 {
+			c.Meta = nil
+		}
+		return c, nil
+	case *BlobResourceContents:
+		if c == nil {
+			return c, nil
+		}
+		decoded, err := DecodeResourceContents(*c)
+		if err != nil {
+			return nil, err
+		}
+		blob := decoded.(BlobResourceContents)
+		return &blob, nil
+	default:
+		return contents, nil
+	}
+}
+
+// encodeData encodes base64 data with encoding, reporting false if it is
+// smaller than minSize, invalid, or would not get smaller.
+func encodeData(data, encoding string, minSize int) (string, bool) {
+	if encoding != ContentEncodingGzip {
+		return "", false
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(raw) < minSize {
+		return "", false
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return "", false
+	}
+	if err := zw.Close(); err != nil {
+		return "", false
+	}
+	if buf.Len() >= len(raw) {
+		return "", false
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), true
+}
+
+// decodeData decodes base64 data of the given encoding back to the base64
+// of the original data.
+func decodeData(data, encoding string) (string, error) {
+	if encoding != ContentEncodingGzip {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedContentEncoding, encoding)
+	}
+	compressed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s data: %w", encoding, err)
+	}
+	raw, err := io.ReadAll(io.LimitReader(zr, maxDecodedContentSize+1))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s data: %w", encoding, err)
+	}
+	if len(raw) > maxDecodedContentSize {
+		return "", fmt.Errorf("%s data decodes to more than %d bytes", encoding, maxDecodedContentSize)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func metaString(meta *Meta, key string) string {
+	if meta == nil {
+		return ""
+	}
+	s, _ := meta.AdditionalFields[key].(string)
+	return s
+}
+
+// withMetaField returns a copy of meta with key set to value.
+func withMetaField(meta *Meta, key string, value any) *Meta {
+	out := &Meta{}
+	if meta != nil {
+		out.ProgressToken = meta.ProgressToken
+		out.AdditionalFields = maps.Clone(meta.AdditionalFields)
+	}
+	if out.AdditionalFields == nil {
+		out.AdditionalFields = make(map[string]any, 1)
+	}
+	out.AdditionalFields[key] = value
+	return out
+}
+
+// withoutMetaField returns a copy of meta without key, or nil if nothing
+// is left.
+func withoutMetaField(meta *Meta, key string) *Meta {
+	out := &Meta{ProgressToken: meta.ProgressToken, AdditionalFields: maps.Clone(meta.AdditionalFields)}
+	delete(out.AdditionalFields, key)
+	if out.ProgressToken == nil && len(out.AdditionalFields) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeResourceContents(t *testing.T) {
+	text := strings.Repeat(`{"id":1,"name":"row"},`, 200)
+	blob := base64.StdEncoding.EncodeToString([]byte(text))
+	contents := BlobResourceContents{URI: "file:///rows.json", MIMEType: "application/json", Blob: blob, Meta: map[string]any{"k": "v"}}
+
+	encoded := EncodeResourceContents(contents, ContentEncodingGzip, 100).(BlobResourceContents)
+	assert.Equal(t, ContentEncodingGzip, encoded.Meta[ContentEncodingMeta])
+	assert.Equal(t, "v", encoded.Meta["k"])
+	assert.Less(t, len(encoded.Blob)*5, len(blob), "expected at least 5x smaller")
+	assert.NotContains(t, contents.Meta, ContentEncodingMeta, "input must not be modified")
+
+	// Encoding twice is a no-op.
+	assert.Equal(t, encoded, EncodeResourceContents(encoded, ContentEncodingGzip, 100))
+
+	decoded, err := DecodeResourceContents(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, contents, decoded)
+
+	tests := []struct {
+		name     string
+		contents ResourceContents
+		encoding string
+		minSize  int
+	}{
+		{"below min size", contents, ContentEncodingGzip, len(text) + 1},
+		{"unknown encoding", contents, "br", 0},
+		{"incompressible", BlobResourceContents{URI: "x", Blob: base64.StdEncoding.EncodeToString([]byte("ab"))}, ContentEncodingGzip, 0},
+		{"text", TextResourceContents{URI: "x", Text: text}, ContentEncodingGzip, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.contents, EncodeResourceContents(tt.contents, tt.encoding, tt.minSize))
+		})
+	}
+}
+
+func TestEncodeContent(t *testing.T) {
+	svg := base64.StdEncoding.EncodeToString([]byte(`<svg>` + strings.Repeat(`<rect width="1" height="1"/>`, 100) + `</svg>`))
+	image := NewImageContent(svg, "image/svg+xml")
+
+	encoded := EncodeContent(&image, ContentEncodingGzip, 0).(*ImageContent)
+	require.NotNil(t, encoded.Meta)
+	assert.Equal(t, ContentEncodingGzip, encoded.Meta.AdditionalFields[ContentEncodingMeta])
+	assert.Nil(t, image.Meta)
+
+	decoded, err := DecodeContent(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, &image, decoded)
+
+	text := NewTextContent(strings.Repeat("a", 1000))
+	assert.Equal(t, text, EncodeContent(text, ContentEncodingGzip, 0))
+}
+
+func TestParseContent_DecodesEncodedData(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("col1,col2\n", 100)))
+	result := NewToolResultResource("rows", BlobResourceContents{URI: "file:///rows.csv", MIMEType: "text/csv", Blob: blob})
+	result.Content[1] = EncodeContent(result.Content[1], ContentEncodingGzip, 0)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"contentEncoding":"gzip"`)
+
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	require.NoError(t, err)
+	require.Len(t, parsed.Content, 2)
+	embedded, ok := parsed.Content[1].(EmbeddedResource)
+	require.True(t, ok, "got %T", parsed.Content[1])
+	assert.Equal(t, BlobResourceContents{URI: "file:///rows.csv", MIMEType: "text/csv", Blob: blob}, embedded.Resource)
+}
+
+func TestDecodeContent_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content BlobResourceContents
+		wantErr error
+	}{
+		{"unsupported encoding", BlobResourceContents{URI: "x", Blob: "YQ==", Meta: map[string]any{ContentEncodingMeta: "br"}}, ErrUnsupportedContentEncoding},
+		{"not gzip", BlobResourceContents{URI: "x", Blob: "YQ==", Meta: map[string]any{ContentEncodingMeta: ContentEncodingGzip}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeResourceContents(tt.content)
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNegotiateContentEncoding(t *testing.T) {
+	encoding, ok := NegotiateContentEncoding(
+		ContentEncodingCapability{Encodings: []string{"zstd", ContentEncodingGzip}},
+		ContentEncodingCapability{Encodings: []string{ContentEncodingGzip}},
+	)
+	assert.True(t, ok)
+	assert.Equal(t, ContentEncodingGzip, encoding)
+
+	_, ok = NegotiateContentEncoding(ContentEncodingCapability{Encodings: []string{ContentEncodingGzip}}, ContentEncodingCapability{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CustomContent is embedded by vendor content types to make them Content,
+// carrying their type name:
+//
+//	type ModelContent struct {
+//		mcp.CustomContent
+//		URI    string `json:"uri"`
+//		Format string `json:"format"`
+//	}
+//
+//	mcp.RegisterContentType("x-3dmodel", func() mcp.Content { return &ModelContent{} })
+//
+// Set Type to the registered name when creating values.
+type CustomContent struct {
+	Type        string       `json:"type"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+	Meta        *Meta        `json:"_meta,omitempty"`
+}
+
+func (CustomContent) isContent() {}
+
+// ContentType returns the name the content type is registered under.
+func (c CustomContent) ContentType() string { return c.Type }
+
+// ContentFactory returns a new value of a registered content type, which
+// parsed JSON is unmarshaled into. It must be a pointer, usually to a struct
+// embedding CustomContent.
+type ContentFactory func() Content
+
+// ContentTypeOption configures a content type registered with
+// RegisterContentType.
+type ContentTypeOption func(*contentType)
+
+// WithContentValidator sets a function checking parsed values of the
+// content type, and values in tool results under strict validation.
+// Content failing it is rejected as if it were malformed.
+func WithContentValidator(validate func(Content) error) ContentTypeOption {
+	return func(t *contentType) {
+		t.validate = validate
+	}
+}
+
+type contentType struct {
+	factory  ContentFactory
+	validate func(Content) error
+}
+
+var (
+	contentTypesMu sync.RWMutex
+	contentTypes   = make(map[string]contentType)
+)
+
+// RegisterContentType registers a vendor content type, so that
+// UnmarshalContent and ParseContent, and with them the parsing of tool and
+// prompt results, sampling messages and notifications, return it as the
+// value made by factory instead of failing on an unknown type. Register
+// content types at init time, on both clients and servers.
+//
+// It panics if name is empty, a built-in content type, or registered
+// twice, or if factory is nil.
+func RegisterContentType(name string, factory ContentFactory, opts ...ContentTypeOption) {
+	if factory == nil {
+		panic("mcp: RegisterContentType factory is nil")
+	}
+	switch name {
+	case "", ContentTypeText, ContentTypeImage, ContentTypeAudio, ContentTypeLink, ContentTypeResource:
+		panic(fmt.Sprintf("mcp: cannot register content type %q", name))
+	}
+	t := contentType{factory: factory}
+	for _, opt := range opts {
+		opt(&t)
+	}
+
+	contentTypesMu.Lock()
+	defer contentTypesMu.Unlock()
+	if _, ok := contentTypes[name]; ok {
+		panic(fmt.Sprintf("mcp: content type %q registered twice", name))
+	}
+	contentTypes[name] = t
+}
+
+func lookupContentType(name string) (contentType, bool) {
+	contentTypesMu.RLock()
+	defer contentTypesMu.RUnlock()
+	t, ok := contentTypes[name]
+	return t, ok
+}
+
+// unmarshalCustomContent decodes content of a registered type, reporting
+// false for types that are not registered.
+func unmarshalCustomContent(name string, data []byte) (Content, bool, error) {
+	t, ok := lookupContentType(name)
+	if !ok {
+		return nil, false, nil
+	}
+	content := t.factory()
+	if err := json.Unmarshal(data, content); err != nil {
+		return nil, true, fmt.Errorf("invalid %s content: %w", name, err)
+	}
+	if err := validateCustomContent(name, t, content); err != nil {
+		return nil, true, err
+	}
+	return content, true, nil
+}
+
+func validateCustomContent(name string, t contentType, content Content) error {
+	if t.validate == nil {
+		return nil
+	}
+	if err := t.validate(content); err != nil {
+		return fmt.Errorf("invalid %s content: %w", name, err)
+	}
+	return nil
+}
+
+// validateRegisteredContent checks content of a registered type, reporting
+// false for other content.
+func validateRegisteredContent(content Content) (bool, error) {
+	typed, ok := content.(interface{ ContentType() string })
+	if !ok {
+		return false, nil
+	}
+	name := typed.ContentType()
+	t, ok := lookupContentType(name)
+	if !ok {
+		return true, fmt.Errorf("content type %q is not registered", name)
+	}
+	return true, validateCustomContent(name, t, content)
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type modelContent struct {
+	CustomContent
+	URI    string `json:"uri"`
+	Format string `json:"format"`
+}
+
+func init() {
+	RegisterContentType("x-3dmodel", func() Content { return &modelContent{} },
+		WithContentValidator(func(content Content) error {
+			if content.(*modelContent).Format != "gltf" {
+				return errors.New("only gltf models are supported")
+			}
+			return nil
+		}))
+}
+
+func TestRegisterContentType_RoundTrip(t *testing.T) {
+	model := &modelContent{CustomContent: CustomContent{Type: "x-3dmodel"}, URI: "file:///teapot.gltf", Format: "gltf"}
+	result := &CallToolResult{Content: []Content{NewTextContent("here"), model}}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"here"},{"type":"x-3dmodel","uri":"file:///teapot.gltf","format":"gltf"}]}`, string(data))
+
+	var decoded CallToolResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded.Content, 2)
+	assert.Equal(t, model, decoded.Content[1])
+	assert.NoError(t, decoded.Validate())
+
+	parsed, err := ParseContent(map[string]any{"type": "x-3dmodel", "uri": "file:///teapot.gltf", "format": "gltf"})
+	require.NoError(t, err)
+	assert.Equal(t, model, parsed)
+
+	raw := json.RawMessage(`{"messages":[{"role":"assistant","content":{"type":"x-3dmodel","uri":"file:///teapot.gltf","format":"gltf"}}]}`)
+	prompt, err := ParseGetPromptResult(&raw)
+	require.NoError(t, err)
+	assert.Equal(t, model, prompt.Messages[0].Content)
+}
+
+func TestRegisterContentType_Validation(t *testing.T) {
+	_, err := UnmarshalContent([]byte(`{"type":"x-3dmodel","uri":"file:///teapot.obj","format":"obj"}`))
+	assert.ErrorContains(t, err, "only gltf models are supported")
+	_, err = ParseContent(map[string]any{"type": "x-3dmodel", "format": "obj"})
+	assert.ErrorContains(t, err, "only gltf models are supported")
+
+	result := &CallToolResult{Content: []Content{&modelContent{CustomContent: CustomContent{Type: "x-3dmodel"}, Format: "obj"}}}
+	assert.ErrorIs(t, result.Validate(), ErrInvalidToolResult)
+	result = &CallToolResult{Content: []Content{&modelContent{CustomContent: CustomContent{Type: "x-unknown"}}}}
+	assert.ErrorContains(t, result.Validate(), `content type "x-unknown" is not registered`)
+
+	_, err = UnmarshalContent([]byte(`{"type":"x-unknown"}`))
+	assert.ErrorContains(t, err, "unknown content type")
+}
+
+func TestRegisterContentType_Panics(t *testing.T) {
+	factory := func() Content { return &modelContent{} }
+	assert.Panics(t, func() { RegisterContentType(ContentTypeText, factory) })
+	assert.Panics(t, func() { RegisterContentType("", factory) })
+	assert.Panics(t, func() { RegisterContentType("x-3dmodel", factory) })
+	assert.Panics(t, func() { RegisterContentType("x-other", nil) })
+}
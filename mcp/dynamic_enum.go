@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DynamicEnumKeyword is the schema keyword holding a DynamicEnum until the
+// server resolves it into an enum. Unresolved, it marshals to true, so the
+// property accepts any value.
+const DynamicEnumKeyword = "x-dynamicEnum"
+
+// EnumProvider returns the allowed values of a property declared with
+// WithDynamicEnum, e.g. the projects of the caller identified by ctx.
+type EnumProvider interface {
+	EnumValues(ctx context.Context) ([]string, error)
+}
+
+// EnumProviderFunc adapts a function to an EnumProvider.
+type EnumProviderFunc func(ctx context.Context) ([]string, error)
+
+// EnumValues implements EnumProvider.
+func (f EnumProviderFunc) EnumValues(ctx context.Context) ([]string, error) {
+	return f(ctx)
+}
+
+// EnumChangeNotifier is implemented by EnumProviders that signal when their
+// values may have changed, so that servers drop the cached values and tell
+// clients to list the tools again.
+type EnumChangeNotifier interface {
+	// OnEnumChange registers f to be called on changes and returns a
+	// function unregistering it.
+	OnEnumChange(f func()) (unsubscribe func())
+}
+
+// EnumChanges implements EnumChangeNotifier. Embed it in an EnumProvider
+// and call Changed whenever the values change.
+type EnumChanges struct {
+	mu          sync.Mutex
+	next        int
+	subscribers map[int]func()
+}
+
+// OnEnumChange implements EnumChangeNotifier.
+func (c *EnumChanges) OnEnumChange(f func()) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscribers == nil {
+		c.subscribers = make(map[int]func())
+	}
+	id := c.next
+	c.next++
+	c.subscribers[id] = f
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// Changed calls the registered functions.
+func (c *EnumChanges) Changed() {
+	c.mu.Lock()
+	subscribers := make([]func(), 0, len(c.subscribers))
+	for _, f := range c.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	c.mu.Unlock()
+	for _, f := range subscribers {
+		f()
+	}
+}
+
+// DynamicEnum is the value of DynamicEnumKeyword in a property schema.
+type DynamicEnum struct {
+	Provider EnumProvider
+}
+
+// MarshalJSON implements json.Marshaler.
+func (DynamicEnum) MarshalJSON() ([]byte, error) {
+	return []byte("true"), nil
+}
+
+// WithDynamicEnum declares that the allowed values of a property are
+// returned by provider. Servers resolve them into the property's enum when
+// listing tools, per session, so each caller sees its own values.
+func WithDynamicEnum(provider EnumProvider) PropertyOption {
+	return func(schema map[string]any) {
+		schema[DynamicEnumKeyword] = DynamicEnum{Provider: provider}
+	}
+}
+
+// DynamicEnumProviders returns the providers of the properties of the
+// tool's input schema declared with WithDynamicEnum, by the path of the
+// property, e.g. "/properties/project".
+func DynamicEnumProviders(tool Tool) map[string]EnumProvider {
+	providers := make(map[string]EnumProvider)
+	_, _ = ResolveDynamicEnums(tool, func(path string, provider EnumProvider) ([]string, error) {
+		providers[path] = provider
+		return nil, nil
+	})
+	return providers
+}
+
+// ResolveDynamicEnums returns the tool with the properties declared with
+// WithDynamicEnum given the enum returned by resolve for their path and
+// provider. The input is never modified.
+func ResolveDynamicEnums(tool Tool, resolve func(path string, provider EnumProvider) ([]string, error)) (Tool, error) {
+	properties, changed, err := resolveDynamicEnumNode(tool.InputSchema.Properties, "/properties", resolve)
+	if err != nil {
+		return tool, err
+	}
+	defs, defsChanged, err := resolveDynamicEnumNode(tool.InputSchema.Defs, "/$defs", resolve)
+	if err != nil {
+		return tool, err
+	}
+	if changed {
+		tool.InputSchema.Properties = properties.(map[string]any)
+	}
+	if defsChanged {
+		tool.InputSchema.Defs = defs.(map[string]any)
+	}
+	return tool, nil
+}
+
+// resolveDynamicEnumNode resolves the dynamic enums below node, copying the
+// maps and slices on the way to them.
+func resolveDynamicEnumNode(node any, path string, resolve func(string, EnumProvider) ([]string, error)) (any, bool, error) {
+	switch node := node.(type) {
+	case map[string]any:
+		var resolved map[string]any
+		copyNode := func() {
+			if resolved == nil {
+				resolved = make(map[string]any, len(node))
+				for key, value := range node {
+					resolved[key] = value
+				}
+			}
+		}
+		if dynamic, ok := node[DynamicEnumKeyword].(DynamicEnum); ok {
+			values, err := resolve(path, dynamic.Provider)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to resolve enum of %s: %w", path, err)
+			}
+			copyNode()
+			delete(resolved, DynamicEnumKeyword)
+			resolved["enum"] = values
+		}
+		for key, value := range node {
+			child, changed, err := resolveDynamicEnumNode(value, path+"/"+key, resolve)
+			if err != nil {
+				return nil, false, err
+			}
+			if changed {
+				copyNode()
+				resolved[key] = child
+			}
+		}
+		if resolved == nil {
+			return node, false, nil
+		}
+		return resolved, true, nil
+	case []any:
+		var resolved []any
+		for i, value := range node {
+			child, changed, err := resolveDynamicEnumNode(value, fmt.Sprintf("%s/%d", path, i), resolve)
+			if err != nil {
+				return nil, false, err
+			}
+			if changed {
+				if resolved == nil {
+					resolved = append([]any(nil), node...)
+				}
+				resolved[i] = child
+			}
+		}
+		if resolved == nil {
+			return node, false, nil
+		}
+		return resolved, true, nil
+	}
+	return node, false, nil
+}
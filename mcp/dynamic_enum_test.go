@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDynamicEnums(t *testing.T) {
+	projects := EnumProviderFunc(func(ctx context.Context) ([]string, error) {
+		return []string{"apollo", "gemini"}, nil
+	})
+	tool := NewTool("deploy",
+		WithString("project", Required(), WithDynamicEnum(projects)),
+		WithArray("regions", Items(map[string]any{"type": "string", DynamicEnumKeyword: DynamicEnum{Provider: projects}})),
+		WithString("env", Enum("dev", "prod")),
+	)
+
+	data, err := json.Marshal(tool.InputSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","required":["project"],"properties":{
+		"project":{"type":"string","x-dynamicEnum":true},
+		"regions":{"type":"array","items":{"type":"string","x-dynamicEnum":true}},
+		"env":{"type":"string","enum":["dev","prod"]}}}`, string(data))
+
+	paths := make([]string, 0)
+	for path := range DynamicEnumProviders(tool) {
+		paths = append(paths, path)
+	}
+	assert.ElementsMatch(t, []string{"/properties/project", "/properties/regions/items"}, paths)
+
+	resolved, err := ResolveDynamicEnums(tool, func(path string, provider EnumProvider) ([]string, error) {
+		return provider.EnumValues(context.Background())
+	})
+	require.NoError(t, err)
+	data, err = json.Marshal(resolved.InputSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","required":["project"],"properties":{
+		"project":{"type":"string","enum":["apollo","gemini"]},
+		"regions":{"type":"array","items":{"type":"string","enum":["apollo","gemini"]}},
+		"env":{"type":"string","enum":["dev","prod"]}}}`, string(data))
+
+	// The declared tool is left as is.
+	assert.Contains(t, tool.InputSchema.Properties["project"], DynamicEnumKeyword)
+
+	_, err = ResolveDynamicEnums(tool, func(path string, provider EnumProvider) ([]string, error) {
+		return nil, errors.New("database down")
+	})
+	assert.ErrorContains(t, err, "database down")
+}
+
+func TestEnumChanges(t *testing.T) {
+	var changes EnumChanges
+	var first, second int
+	unsubscribe := changes.OnEnumChange(func() { first++ })
+	changes.OnEnumChange(func() { second++ })
+
+	changes.Changed()
+	unsubscribe()
+	changes.Changed()
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}
@@ -120,6 +120,8 @@ func (e *JSONRPCErrorDetails) AsError() error {
 		}
 		// Fallback if data is missing or invalid
 		return URLElicitationRequiredError{}
+	case TOOL_UNAVAILABLE:
+		return toolUnavailableFromData(e.Data)
 	default:
 		return errors.New(e.Message)
 	}
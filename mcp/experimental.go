@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// ExperimentalCapability is a typed entry of the experimental capabilities
+// that clients and servers declare during initialization, named Name with
+// a payload of type T. Declaring both sides with the same value keeps the
+// name and the payload layout in one place:
+//
+//	var Canvas = mcp.NewExperimentalCapability[mcp.VersionedCapability]("x-canvas")
+//
+//	// when declaring
+//	Canvas.Set(&capabilities.Experimental, mcp.VersionedCapability{Versions: []string{"2", "1"}})
+//	// when reading the peer's
+//	canvas, ok, err := Canvas.Get(result.Capabilities.Experimental)
+type ExperimentalCapability[T any] struct {
+	Name string
+}
+
+// NewExperimentalCapability returns the experimental capability name with
+// a payload of type T.
+func NewExperimentalCapability[T any](name string) ExperimentalCapability[T] {
+	return ExperimentalCapability[T]{Name: name}
+}
+
+// Set declares the capability with value in experimental, allocating the
+// map if needed.
+func (c ExperimentalCapability[T]) Set(experimental *map[string]any, value T) {
+	if *experimental == nil {
+		*experimental = make(map[string]any)
+	}
+	(*experimental)[c.Name] = value
+}
+
+// Declared reports whether experimental declares the capability.
+func (c ExperimentalCapability[T]) Declared(experimental map[string]any) bool {
+	_, ok := experimental[c.Name]
+	return ok
+}
+
+// Get returns the payload of the capability in experimental, and false if
+// it is not declared. Payloads decoded from JSON are converted to T; an
+// error reports a payload that does not fit T.
+func (c ExperimentalCapability[T]) Get(experimental map[string]any) (T, bool, error) {
+	var value T
+	raw, ok := experimental[c.Name]
+	if !ok {
+		return value, false, nil
+	}
+	if typed, isT := raw.(T); isT {
+		return typed, true, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return value, true, fmt.Errorf("experimental capability %s: %w", c.Name, err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, true, fmt.Errorf("experimental capability %s: %w", c.Name, err)
+	}
+	return value, true, nil
+}
+
+// VersionedCapability is a payload for experimental capabilities that
+// evolve: each side lists the versions it supports, preferred first, and
+// NegotiateVersion picks the one to use.
+type VersionedCapability struct {
+	Versions []string `json:"versions"`
+}
+
+// NegotiateVersion returns the first of local's versions that remote also
+// supports, or false if they share none.
+func NegotiateVersion(local, remote VersionedCapability) (string, bool) {
+	for _, version := range local.Versions {
+		if slices.Contains(remote.Versions, version) {
+			return version, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperimentalCapability(t *testing.T) {
+	canvas := NewExperimentalCapability[VersionedCapability]("x-canvas")
+
+	var capabilities ServerCapabilities
+	assert.False(t, canvas.Declared(capabilities.Experimental))
+	_, ok, err := canvas.Get(capabilities.Experimental)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	canvas.Set(&capabilities.Experimental, VersionedCapability{Versions: []string{"2", "1"}})
+	assert.True(t, canvas.Declared(capabilities.Experimental))
+	value, ok, err := canvas.Get(capabilities.Experimental)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"2", "1"}, value.Versions)
+
+	// Payloads received over the wire are decoded into the payload type.
+	data, err := json.Marshal(capabilities)
+	require.NoError(t, err)
+	var decoded ServerCapabilities
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	value, ok, err = canvas.Get(decoded.Experimental)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"2", "1"}, value.Versions)
+
+	_, ok, err = canvas.Get(map[string]any{"x-canvas": map[string]any{"versions": "2"}})
+	assert.True(t, ok)
+	assert.ErrorContains(t, err, "x-canvas")
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		local, remote []string
+		want          string
+		wantOK        bool
+	}{
+		{name: "local preference wins", local: []string{"3", "2", "1"}, remote: []string{"1", "2"}, want: "2", wantOK: true},
+		{name: "no common version", local: []string{"2"}, remote: []string{"1"}},
+		{name: "remote declares none", local: []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NegotiateVersion(VersionedCapability{Versions: tt.local}, VersionedCapability{Versions: tt.remote})
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// MethodToolsContinueResult is the tools/continueResult extension method,
+	// which returns the next page of a paginated tool result. Servers that
+	// support it declare the ExperimentalResultPagination capability.
+	MethodToolsContinueResult MCPMethod = "tools/continueResult"
+
+	// ExperimentalResultPagination is the experimental server capability
+	// declaring support for tools/continueResult.
+	ExperimentalResultPagination = "resultPagination"
+
+	// ContinuationTokenMeta is the _meta key of a CallToolResult holding the
+	// token of the result's next page. Results without it are complete.
+	ContinuationTokenMeta = "continuationToken"
+)
+
+// ContinueResultRequest is sent from the client to get the next page of a
+// paginated tool result.
+type ContinueResultRequest struct {
+	Request
+	Header http.Header          `json:"-"`
+	Params ContinueResultParams `json:"params"`
+}
+
+// ContinueResultParams are the parameters of a tools/continueResult request.
+type ContinueResultParams struct {
+	Meta *Meta `json:"_meta,omitempty"`
+	// Name is the tool that returned the paginated result.
+	Name string `json:"name"`
+	// ContinuationToken is the token of the page to return, as found in the
+	// _meta of the previous page.
+	ContinuationToken string `json:"continuationToken"`
+}
+
+// NewToolResultPage returns a page of a paginated structured tool result,
+// with a JSON text fallback. An empty continuationToken marks the last page.
+func NewToolResultPage(structured any, continuationToken string) *CallToolResult {
+	var result *CallToolResult
+	if data, err := json.Marshal(structured); err != nil {
+		result = NewToolResultStructured(structured, "Error serializing structured content")
+	} else {
+		result = NewToolResultStructured(structured, string(data))
+	}
+	SetContinuationToken(result, continuationToken)
+	return result
+}
+
+// SetContinuationToken sets the token of the next page of result, or
+// removes it if token is empty.
+func SetContinuationToken(result *CallToolResult, token string) {
+	if token == "" {
+		if result.Meta != nil {
+			delete(result.Meta.AdditionalFields, ContinuationTokenMeta)
+		}
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = &Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = make(map[string]any)
+	}
+	result.Meta.AdditionalFields[ContinuationTokenMeta] = token
+}
+
+// ContinuationToken returns the token of the next page of result, or false
+// if result is the last page.
+func ContinuationToken(result *CallToolResult) (string, bool) {
+	if result == nil || result.Meta == nil {
+		return "", false
+	}
+	token, ok := result.Meta.AdditionalFields[ContinuationTokenMeta].(string)
+	return token, ok && token != ""
+}
@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToolResultPage(t *testing.T) {
+	result := NewToolResultPage(map[string]any{"rows": []int{1, 2}}, "next")
+	assert.Equal(t, `{"rows":[1,2]}`, result.Content[0].(TextContent).Text)
+	token, ok := ContinuationToken(result)
+	assert.True(t, ok)
+	assert.Equal(t, "next", token)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	require.NoError(t, err)
+	token, ok = ContinuationToken(parsed)
+	assert.True(t, ok)
+	assert.Equal(t, "next", token)
+
+	SetContinuationToken(result, "")
+	_, ok = ContinuationToken(result)
+	assert.False(t, ok)
+
+	_, ok = ContinuationToken(NewToolResultPage([]int{3}, ""))
+	assert.False(t, ok, "the last page has no token")
+	_, ok = ContinuationToken(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,251 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+)
+
+var schemaTypeNames = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+
+// CheckSchema reports problems that make schema unusable as a JSON Schema:
+// unknown types, patterns that are not valid regular expressions, required
+// properties that are not defined, empty enums and contradictory bounds.
+// schema may be a json.RawMessage, a ToolArgumentsSchema or any value that
+// marshals to JSON. It returns nil for a valid schema.
+func CheckSchema(schema any) []string {
+	node, err := decodeSchema(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	checkSchemaNode(node, "", &problems)
+	return problems
+}
+
+func decodeSchema(schema any) (any, error) {
+	data, ok := schema.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(schema); err != nil {
+			return nil, fmt.Errorf("schema cannot be encoded: %w", err)
+		}
+	}
+	var node any
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+	return node, nil
+}
+
+func checkSchemaNode(node any, path string, problems *[]string) {
+	report := func(format string, args ...any) {
+		where := path
+		if where == "" {
+			where = "schema"
+		}
+		*problems = append(*problems, where+": "+fmt.Sprintf(format, args...))
+	}
+
+	if _, ok := node.(bool); ok {
+		return
+	}
+	object, ok := node.(map[string]any)
+	if !ok {
+		report("expected an object, got %s", jsonKind(node))
+		return
+	}
+
+	switch t := object["type"].(type) {
+	case nil:
+	case string:
+		if !slices.Contains(schemaTypeNames, t) {
+			report("unknown type %q", t)
+		}
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); !ok || !slices.Contains(schemaTypeNames, s) {
+				report("unknown type %v", name)
+			}
+		}
+	default:
+		report("type must be a string or an array of strings")
+	}
+
+	if pattern, ok := object["pattern"]; ok {
+		if s, ok := pattern.(string); !ok {
+			report("pattern must be a string")
+		} else if _, err := regexp.Compile(s); err != nil {
+			report("invalid pattern %q: %v", s, err)
+		}
+	}
+
+	if enum, ok := object["enum"]; ok {
+		if values, ok := enum.([]any); !ok || len(values) == 0 {
+			report("enum must be a non-empty array")
+		}
+	}
+
+	checkBounds := func(min, max string) {
+		lo, okLo := object[min].(float64)
+		hi, okHi := object[max].(float64)
+		if okLo && okHi && lo > hi {
+			report("%s %v is greater than %s %v", min, lo, max, hi)
+		}
+	}
+	checkBounds("minimum", "maximum")
+	checkBounds("minLength", "maxLength")
+	checkBounds("minItems", "maxItems")
+
+	properties, hasProperties := object["properties"].(map[string]any)
+	if _, ok := object["properties"]; ok && !hasProperties {
+		report("properties must be an object")
+	}
+	for _, name := range sortedKeys(properties, nil) {
+		checkSchemaNode(properties[name], joinSchemaPath(path, name), problems)
+	}
+
+	if raw, ok := object["required"]; ok {
+		required, ok := raw.([]any)
+		if !ok {
+			report("required must be an array of property names")
+		}
+		for _, name := range required {
+			s, ok := name.(string)
+			if !ok {
+				report("required must be an array of property names")
+				continue
+			}
+			if hasProperties {
+				if _, defined := properties[s]; !defined {
+					report("required property %q is not defined", s)
+				}
+			}
+		}
+	}
+
+	switch items := object["items"].(type) {
+	case nil:
+	case []any:
+		for i, item := range items {
+			checkSchemaNode(item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	default:
+		checkSchemaNode(items, path+"[]", problems)
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf", "allOf"} {
+		if raw, ok := object[keyword]; ok {
+			list, ok := raw.([]any)
+			if !ok {
+				report("%s must be an array of schemas", keyword)
+				continue
+			}
+			for i, sub := range list {
+				checkSchemaNode(sub, fmt.Sprintf("%s(%s %d)", path, keyword, i), problems)
+			}
+		}
+	}
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// CheckSchemaBinding reports mismatches between an input schema and the
+// struct type a typed handler binds the arguments to: fields the schema does
+// not define, required properties without a field and properties whose type
+// cannot be decoded into the field. It returns nil when argsType is not a
+// struct, as maps accept any arguments.
+func CheckSchemaBinding(schema any, argsType reflect.Type) []string {
+	for argsType.Kind() == reflect.Pointer {
+		argsType = argsType.Elem()
+	}
+	if argsType.Kind() != reflect.Struct {
+		return nil
+	}
+	node, err := decodeSchema(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	object, _ := node.(map[string]any)
+	properties, _ := object["properties"].(map[string]any)
+
+	var problems []string
+	fields := jsonFields(argsType)
+	matched := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		key, ok := matchField(properties, field.name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field %s has no property in the schema", field.name))
+			continue
+		}
+		matched[key] = true
+		property, _ := properties[key].(map[string]any)
+		if schemaType, ok := property["type"].(string); ok && !typeDecodes(schemaType, field.typ) {
+			problems = append(problems, fmt.Sprintf("property %s of type %s cannot be bound to field of type %s", key, schemaType, field.typ))
+		}
+	}
+
+	required, _ := object["required"].([]any)
+	for _, name := range required {
+		if s, ok := name.(string); ok && !matched[s] {
+			problems = append(problems, fmt.Sprintf("required property %s has no field in %s", s, argsType))
+		}
+	}
+	return problems
+}
+
+// typeDecodes reports whether a JSON value of the given schema type can be
+// bound to a field of type t.
+func typeDecodes(schemaType string, t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return schemaType == "string"
+	case durationType, bigIntType:
+		return schemaType == "string" || schemaType == "integer" || schemaType == "number"
+	}
+	if t.Kind() == reflect.Interface || t.Implements(reflect.TypeFor[json.Unmarshaler]()) ||
+		reflect.PointerTo(t).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return true
+	}
+
+	switch schemaType {
+	case "string":
+		return t.Kind() == reflect.String
+	case "integer", "number":
+		// Integral numbers also decode into integer fields, and servers
+		// commonly declare those as numbers.
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+	case "boolean":
+		return t.Kind() == reflect.Bool
+	case "array":
+		return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+	case "object":
+		return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+	case "null":
+		return true
+	}
+	return false
+}
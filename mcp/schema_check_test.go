@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		problems []string
+	}{
+		{
+			name:   "valid",
+			schema: `{"type":"object","properties":{"city":{"type":"string","pattern":"^[A-Z]"},"days":{"type":["integer","null"],"minimum":1,"maximum":7}},"required":["city"]}`,
+		},
+		{
+			name:     "not JSON",
+			schema:   `{"type":`,
+			problems: []string{"schema is not valid JSON: unexpected end of JSON input"},
+		},
+		{
+			name:     "unknown type",
+			schema:   `{"type":"object","properties":{"city":{"type":"text"}}}`,
+			problems: []string{`city: unknown type "text"`},
+		},
+		{
+			name:     "invalid pattern",
+			schema:   `{"type":"object","properties":{"code":{"type":"string","pattern":"[a-"}}}`,
+			problems: []string{"code: invalid pattern \"[a-\": error parsing regexp: missing closing ]: `[a-`"},
+		},
+		{
+			name:     "undefined required property",
+			schema:   `{"type":"object","properties":{"city":{"type":"string"}},"required":["city","country"]}`,
+			problems: []string{`schema: required property "country" is not defined`},
+		},
+		{
+			name:     "empty enum and bad bounds in items",
+			schema:   `{"type":"object","properties":{"tags":{"type":"array","items":{"enum":[],"minLength":3,"maxLength":1}}}}`,
+			problems: []string{"tags[]: enum must be a non-empty array", "tags[]: minLength 3 is greater than maxLength 1"},
+		},
+		{
+			name:     "property is not a schema",
+			schema:   `{"type":"object","properties":{"city":"string"}}`,
+			problems: []string{"city: expected an object, got string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.problems, CheckSchema(json.RawMessage(tt.schema)))
+		})
+	}
+}
+
+func TestCheckSchemaBinding(t *testing.T) {
+	type weatherArgs struct {
+		City  string   `json:"city"`
+		Days  int      `json:"days"`
+		Units string   `json:"units"`
+		Tags  []string `json:"tags"`
+	}
+
+	tool := NewTool("weather",
+		WithString("city", Required()),
+		WithNumber("days"),
+		WithString("units"),
+		WithArray("tags"),
+	)
+	assert.Empty(t, CheckSchemaBinding(tool.InputSchema, reflect.TypeFor[weatherArgs]()))
+	assert.Empty(t, CheckSchemaBinding(tool.InputSchema, reflect.TypeFor[map[string]any]()))
+
+	mismatched := NewTool("weather",
+		WithString("city", Required()),
+		WithString("days"),
+		WithString("units"),
+		WithString("country", Required()),
+	)
+	assert.Equal(t, []string{
+		"property days of type string cannot be bound to field of type int",
+		"field tags has no property in the schema",
+		"required property country has no field in mcp.weatherArgs",
+	}, CheckSchemaBinding(mismatched.InputSchema, reflect.TypeFor[*weatherArgs]()))
+}
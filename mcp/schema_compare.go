@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SchemaChangeKind identifies what changed between two versions of a schema.
+type SchemaChangeKind string
+
+const (
+	SchemaChangePropertyAdded   SchemaChangeKind = "property_added"
+	SchemaChangePropertyRemoved SchemaChangeKind = "property_removed"
+	SchemaChangeRequiredAdded   SchemaChangeKind = "required_added"
+	SchemaChangeRequiredRemoved SchemaChangeKind = "required_removed"
+	SchemaChangeTypeChanged     SchemaChangeKind = "type_changed"
+	SchemaChangeEnumNarrowed    SchemaChangeKind = "enum_narrowed"
+	SchemaChangeEnumWidened     SchemaChangeKind = "enum_widened"
+)
+
+// SchemaChange is a single difference between two versions of a tool input
+// schema. A change is breaking when arguments that were valid for the old
+// schema may be rejected by the new one.
+type SchemaChange struct {
+	// Path locates the changed schema, e.g. "properties.address.properties.zip",
+	// or is empty for the root schema.
+	Path        string           `json:"path"`
+	Kind        SchemaChangeKind `json:"kind"`
+	Breaking    bool             `json:"breaking"`
+	Description string           `json:"description"`
+}
+
+func (c SchemaChange) String() string {
+	level := "compatible"
+	if c.Breaking {
+		level = "breaking"
+	}
+	if c.Path == "" {
+		return fmt.Sprintf("%s: %s", level, c.Description)
+	}
+	return fmt.Sprintf("%s: %s: %s", level, c.Path, c.Description)
+}
+
+// SchemaComparison lists the changes between two schemas.
+type SchemaComparison struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+// Compatible reports whether none of the changes are breaking.
+func (c SchemaComparison) Compatible() bool {
+	return len(c.BreakingChanges()) == 0
+}
+
+// BreakingChanges returns only the breaking changes.
+func (c SchemaComparison) BreakingChanges() []SchemaChange {
+	var breaking []SchemaChange
+	for _, change := range c.Changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// CompareSchemas compares two versions of a tool input schema and classifies
+// every difference as compatible or breaking for existing callers. The
+// schemas may be anything that marshals to a JSON Schema object, such as
+// ToolInputSchema, json.RawMessage or map[string]any.
+//
+// Breaking changes are a removed required property, a newly required
+// property, a type that no longer accepts the old type, and an enum that
+// lost values. Added optional properties, dropped requirements and widened
+// enums or types are compatible.
+func CompareSchemas(oldSchema, newSchema any) (SchemaComparison, error) {
+	a, err := schemaObject(oldSchema)
+	if err != nil {
+		return SchemaComparison{}, fmt.Errorf("invalid old schema: %w", err)
+	}
+	b, err := schemaObject(newSchema)
+	if err != nil {
+		return SchemaComparison{}, fmt.Errorf("invalid new schema: %w", err)
+	}
+
+	var comparison SchemaComparison
+	compareSchemaObjects(&comparison, "", a, b)
+	return comparison, nil
+}
+
+func schemaObject(schema any) (map[string]any, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func compareSchemaObjects(c *SchemaComparison, path string, a, b map[string]any) {
+	add := func(path string, kind SchemaChangeKind, breaking bool, format string, args ...any) {
+		c.Changes = append(c.Changes, SchemaChange{
+			Path:        path,
+			Kind:        kind,
+			Breaking:    breaking,
+			Description: fmt.Sprintf(format, args...),
+		})
+	}
+
+	oldTypes, newTypes := schemaTypes(a), schemaTypes(b)
+	if len(oldTypes) > 0 && !reflect.DeepEqual(oldTypes, newTypes) {
+		add(path, SchemaChangeTypeChanged, !typesAccepted(oldTypes, newTypes),
+			"type changed from %s to %s", typeList(oldTypes), typeList(newTypes))
+	}
+
+	compareEnums(path, a["enum"], b["enum"], add)
+
+	oldRequired, newRequired := stringSet(a["required"]), stringSet(b["required"])
+	oldProps, _ := a["properties"].(map[string]any)
+	newProps, _ := b["properties"].(map[string]any)
+
+	for _, name := range sortedKeys(oldProps, newProps) {
+		child := joinSchemaPath(path, "properties", name)
+		oldProp, inOld := oldProps[name].(map[string]any)
+		newProp, inNew := newProps[name].(map[string]any)
+		switch {
+		case inOld && !inNew:
+			add(child, SchemaChangePropertyRemoved, oldRequired[name], "property %q removed", name)
+		case !inOld && inNew:
+			add(child, SchemaChangePropertyAdded, newRequired[name], "property %q added", name)
+		default:
+			compareSchemaObjects(c, child, oldProp, newProp)
+		}
+	}
+
+	for _, name := range sortedKeys(boolMap(oldRequired), boolMap(newRequired)) {
+		_, inOld := oldProps[name]
+		_, inNew := newProps[name]
+		switch {
+		case newRequired[name] && !oldRequired[name] && inOld:
+			add(joinSchemaPath(path, "properties", name), SchemaChangeRequiredAdded, true, "property %q is now required", name)
+		case oldRequired[name] && !newRequired[name] && inNew:
+			add(joinSchemaPath(path, "properties", name), SchemaChangeRequiredRemoved, false, "property %q is no longer required", name)
+		}
+	}
+
+	oldItems, okOld := a["items"].(map[string]any)
+	newItems, okNew := b["items"].(map[string]any)
+	if okOld && okNew {
+		compareSchemaObjects(c, joinSchemaPath(path, "items"), oldItems, newItems)
+	}
+}
+
+func compareEnums(path string, a, b any, add func(string, SchemaChangeKind, bool, string, ...any)) {
+	oldValues, _ := a.([]any)
+	newValues, _ := b.([]any)
+	if b == nil {
+		// Dropping the enum accepts any value.
+		if oldValues != nil {
+			add(path, SchemaChangeEnumWidened, false, "enum removed")
+		}
+		return
+	}
+	if a == nil {
+		add(path, SchemaChangeEnumNarrowed, true, "enum %s added", jsonList(newValues))
+		return
+	}
+
+	var removed, added []any
+	for _, v := range oldValues {
+		if !containsJSON(newValues, v) {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range newValues {
+		if !containsJSON(oldValues, v) {
+			added = append(added, v)
+		}
+	}
+	if len(removed) > 0 {
+		add(path, SchemaChangeEnumNarrowed, true, "enum values %s removed", jsonList(removed))
+	}
+	if len(added) > 0 {
+		add(path, SchemaChangeEnumWidened, false, "enum values %s added", jsonList(added))
+	}
+}
+
+// schemaTypes returns the sorted types a schema allows, from either a single
+// "type" string or a list of them.
+func schemaTypes(schema map[string]any) []string {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// typesAccepted reports whether every old type is still accepted. Integers
+// remain valid where numbers are expected.
+func typesAccepted(oldTypes, newTypes []string) bool {
+	if len(newTypes) == 0 {
+		return true
+	}
+	for _, t := range oldTypes {
+		if slices.Contains(newTypes, t) {
+			continue
+		}
+		if t == "integer" && slices.Contains(newTypes, "number") {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func typeList(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, "|")
+}
+
+func stringSet(v any) map[string]bool {
+	set := make(map[string]bool)
+	values, _ := v.([]any)
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func boolMap(set map[string]bool) map[string]any {
+	m := make(map[string]any, len(set))
+	for k := range set {
+		m[k] = true
+	}
+	return m
+}
+
+func sortedKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinSchemaPath(path string, elems ...string) string {
+	if path == "" {
+		return strings.Join(elems, ".")
+	}
+	return path + "." + strings.Join(elems, ".")
+}
+
+func containsJSON(values []any, v any) bool {
+	return slices.ContainsFunc(values, func(x any) bool {
+		return reflect.DeepEqual(x, v)
+	})
+}
+
+func jsonList(values []any) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(data)
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	base := `{
+		"type": "object",
+		"properties": {
+			"city": {"type": "string"},
+			"units": {"type": "string", "enum": ["metric", "imperial"]},
+			"days": {"type": "integer"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["city"]
+	}`
+
+	tests := []struct {
+		name       string
+		newSchema  string
+		compatible bool
+		changes    []SchemaChange
+	}{
+		{
+			name:       "identical",
+			newSchema:  base,
+			compatible: true,
+		},
+		{
+			name: "optional property added",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"lang": {"type": "string"}
+			}, "required": ["city"]}`,
+			compatible: true,
+			changes: []SchemaChange{
+				{Path: "properties.lang", Kind: SchemaChangePropertyAdded, Description: `property "lang" added`},
+			},
+		},
+		{
+			name: "required property added",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"country": {"type": "string"}
+			}, "required": ["city", "country"]}`,
+			changes: []SchemaChange{
+				{Path: "properties.country", Kind: SchemaChangePropertyAdded, Breaking: true, Description: `property "country" added`},
+			},
+		},
+		{
+			name: "required property removed",
+			newSchema: `{"type": "object", "properties": {
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}}`,
+			changes: []SchemaChange{
+				{Path: "properties.city", Kind: SchemaChangePropertyRemoved, Breaking: true, Description: `property "city" removed`},
+			},
+		},
+		{
+			name: "optional property removed",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			compatible: true,
+			changes: []SchemaChange{
+				{Path: "properties.days", Kind: SchemaChangePropertyRemoved, Description: `property "days" removed`},
+			},
+		},
+		{
+			name: "existing property made required",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city", "days"]}`,
+			changes: []SchemaChange{
+				{Path: "properties.days", Kind: SchemaChangeRequiredAdded, Breaking: true, Description: `property "days" is now required`},
+			},
+		},
+		{
+			name: "requirement dropped",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}}`,
+			compatible: true,
+			changes: []SchemaChange{
+				{Path: "properties.city", Kind: SchemaChangeRequiredRemoved, Description: `property "city" is no longer required`},
+			},
+		},
+		{
+			name: "type changed",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			changes: []SchemaChange{
+				{Path: "properties.days", Kind: SchemaChangeTypeChanged, Breaking: true, Description: "type changed from integer to string"},
+			},
+		},
+		{
+			name: "integer widened to number",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			compatible: true,
+			changes: []SchemaChange{
+				{Path: "properties.days", Kind: SchemaChangeTypeChanged, Description: "type changed from integer to number"},
+			},
+		},
+		{
+			name: "type widened to a list",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": ["string", "null"]},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			compatible: true,
+			changes: []SchemaChange{
+				{Path: "properties.city", Kind: SchemaChangeTypeChanged, Description: "type changed from string to null|string"},
+			},
+		},
+		{
+			name: "enum narrowed",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			changes: []SchemaChange{
+				{Path: "properties.units", Kind: SchemaChangeEnumNarrowed, Breaking: true, Description: `enum values ["imperial"] removed`},
+			},
+		},
+		{
+			name: "enum widened",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial", "kelvin"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			compatible: true,
+			changes: []SchemaChange{
+				{Path: "properties.units", Kind: SchemaChangeEnumWidened, Description: `enum values ["kelvin"] added`},
+			},
+		},
+		{
+			name: "enum added",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string", "enum": ["Paris"]},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}, "required": ["city"]}`,
+			changes: []SchemaChange{
+				{Path: "properties.city", Kind: SchemaChangeEnumNarrowed, Breaking: true, Description: `enum ["Paris"] added`},
+			},
+		},
+		{
+			name: "array item type changed",
+			newSchema: `{"type": "object", "properties": {
+				"city": {"type": "string"},
+				"units": {"type": "string", "enum": ["metric", "imperial"]},
+				"days": {"type": "integer"},
+				"tags": {"type": "array", "items": {"type": "integer"}}
+			}, "required": ["city"]}`,
+			changes: []SchemaChange{
+				{Path: "properties.tags.items", Kind: SchemaChangeTypeChanged, Breaking: true, Description: "type changed from string to integer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison, err := CompareSchemas(json.RawMessage(base), json.RawMessage(tt.newSchema))
+			require.NoError(t, err)
+			assert.Equal(t, tt.changes, comparison.Changes)
+			assert.Equal(t, tt.compatible, comparison.Compatible())
+		})
+	}
+}
+
+func TestCompareSchemas_ToolInputSchema(t *testing.T) {
+	v1 := NewTool("weather", WithString("city", Required()))
+	v2 := NewTool("weather", WithNumber("city", Required()))
+
+	comparison, err := CompareSchemas(v1.InputSchema, v2.InputSchema)
+	require.NoError(t, err)
+	require.Len(t, comparison.BreakingChanges(), 1)
+	assert.Equal(t, "breaking: properties.city: type changed from string to number", comparison.BreakingChanges()[0].String())
+}
+
+func TestCompareSchemas_InvalidSchema(t *testing.T) {
+	_, err := CompareSchemas(json.RawMessage(`[]`), ToolInputSchema{Type: "object"})
+	assert.ErrorContains(t, err, "invalid old schema")
+}
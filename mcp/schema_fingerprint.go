@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ToolSchemaHashMetaKey is the _meta key under which servers publish a
+// tool's schema fingerprint, both on tools/list entries and on the results
+// of calls to that tool.
+const ToolSchemaHashMetaKey = "io.github.mark3labs.mcp-go/schema-hash"
+
+// ToolSchemaHash returns a fingerprint of a tool's input and output schemas.
+// It only changes when the schemas do, ignoring formatting and key order, so
+// clients can tell whether a cached tool definition is stale.
+func ToolSchemaHash(tool Tool) (string, error) {
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return "", err
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return "", err
+	}
+	// Marshaling maps sorts their keys, which makes the encoding canonical.
+	canonical, err := json.Marshal(map[string]any{
+		"inputSchema":  decoded["inputSchema"],
+		"outputSchema": decoded["outputSchema"],
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// ToolSchemaHashFromMeta returns the schema fingerprint carried by _meta, or
+// an empty string.
+func ToolSchemaHashFromMeta(meta *Meta) string {
+	if meta == nil {
+		return ""
+	}
+	hash, _ := meta.AdditionalFields[ToolSchemaHashMetaKey].(string)
+	return hash
+}
+
+// IsToolSchemaStale reports whether result was produced by a different
+// version of tool than the one cached by the client, according to the
+// schema fingerprints both carry. It is false when either has none.
+func IsToolSchemaStale(tool Tool, result *CallToolResult) bool {
+	if result == nil {
+		return false
+	}
+	cached, current := ToolSchemaHashFromMeta(tool.Meta), ToolSchemaHashFromMeta(result.Meta)
+	return cached != "" && current != "" && cached != current
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolSchemaHash(t *testing.T) {
+	base := NewTool("weather", WithDescription("Current weather"), WithString("city", Required()))
+	hash, err := ToolSchemaHash(base)
+	require.NoError(t, err)
+	assert.Len(t, hash, 32)
+
+	tests := []struct {
+		name string
+		tool Tool
+		same bool
+	}{
+		{
+			name: "description changes do not count",
+			tool: NewTool("weather", WithDescription("Weather right now"), WithString("city", Required())),
+			same: true,
+		},
+		{
+			name: "raw schema with other formatting",
+			tool: NewToolWithRawSchema("weather", "", json.RawMessage(`{
+				"required": ["city"],
+				"properties": {"city": {"type": "string"}},
+				"type": "object"
+			}`)),
+			same: true,
+		},
+		{
+			name: "input schema change",
+			tool: NewTool("weather", WithString("city", Required()), WithString("units")),
+		},
+		{
+			name: "output schema change",
+			tool: NewTool("weather", WithString("city", Required()), WithRawOutputSchema(json.RawMessage(`{"type":"object"}`))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other, err := ToolSchemaHash(tt.tool)
+			require.NoError(t, err)
+			if tt.same {
+				assert.Equal(t, hash, other)
+			} else {
+				assert.NotEqual(t, hash, other)
+			}
+		})
+	}
+}
+
+func TestIsToolSchemaStale(t *testing.T) {
+	meta := func(hash string) *Meta {
+		return &Meta{AdditionalFields: map[string]any{ToolSchemaHashMetaKey: hash}}
+	}
+
+	assert.Equal(t, "abc", ToolSchemaHashFromMeta(meta("abc")))
+	assert.Empty(t, ToolSchemaHashFromMeta(nil))
+
+	cached := Tool{Name: "weather", Meta: meta("v1")}
+	assert.False(t, IsToolSchemaStale(cached, &CallToolResult{Result: Result{Meta: meta("v1")}}))
+	assert.True(t, IsToolSchemaStale(cached, &CallToolResult{Result: Result{Meta: meta("v2")}}))
+	assert.False(t, IsToolSchemaStale(cached, &CallToolResult{}), "result without a hash")
+	assert.False(t, IsToolSchemaStale(Tool{Name: "weather"}, &CallToolResult{Result: Result{Meta: meta("v2")}}), "tool without a hash")
+	assert.False(t, IsToolSchemaStale(cached, nil))
+}
@@ -0,0 +1,296 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+// DurationPattern matches durations in the format accepted by
+// time.ParseDuration, such as "300ms", "5m" or "1h30m".
+const DurationPattern = `^-?([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h)(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))*$`
+
+// bigIntPattern matches the decimal integers accepted for *big.Int fields.
+const bigIntPattern = `^-?[0-9]+$`
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	bigIntType   = reflect.TypeFor[big.Int]()
+)
+
+// Format sets the format of a property in the JSON Schema, e.g. "date-time",
+// "date", "email" or "uri".
+func Format(format string) PropertyOption {
+	return func(schema map[string]any) {
+		schema["format"] = format
+	}
+}
+
+// WithDuration adds a duration property to the tool schema. Durations are
+// strings such as "5m" or "1h30m", as accepted by time.ParseDuration, and
+// bind to time.Duration fields in typed handlers.
+func WithDuration(name string, opts ...PropertyOption) ToolOption {
+	return WithString(name, append([]PropertyOption{
+		Format("duration"),
+		Pattern(DurationPattern),
+	}, opts...)...)
+}
+
+// schemaReflector returns the reflector used to generate tool schemas from Go
+// types. It describes time.Duration as a duration string and big.Int as a
+// decimal string, matching what BindArguments accepts.
+func schemaReflector() jsonschema.Reflector {
+	return jsonschema.Reflector{
+		DoNotReference:            true, // Removes $defs map, outputs entire structure inline
+		Anonymous:                 true, // Hides auto-generated Schema IDs
+		AllowAdditionalProperties: true, // Removes additionalProperties: false
+		Mapper: func(t reflect.Type) *jsonschema.Schema {
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			switch t {
+			case durationType:
+				return &jsonschema.Schema{Type: "string", Format: "duration", Pattern: DurationPattern}
+			case bigIntType:
+				return &jsonschema.Schema{Type: "string", Pattern: bigIntPattern}
+			}
+			return nil
+		},
+	}
+}
+
+// needsConversion caches, per type, whether binding arguments to it involves
+// time.Time, time.Duration or big.Int values.
+var needsConversion sync.Map // reflect.Type -> bool
+
+func needsArgumentConversion(t reflect.Type) bool {
+	if cached, ok := needsConversion.Load(t); ok {
+		return cached.(bool)
+	}
+	result := containsConvertedType(t, map[reflect.Type]bool{})
+	needsConversion.Store(t, result)
+	return result
+}
+
+func containsConvertedType(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType, durationType, bigIntType:
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if containsConvertedType(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return containsConvertedType(t.Elem(), seen)
+	}
+	return false
+}
+
+// decodeArguments decodes raw JSON arguments, keeping numbers exact so large
+// integers survive the conversion to big.Int.
+func decodeArguments(raw json.RawMessage) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// convertArguments rewrites value, decoded from JSON, so that it unmarshals
+// into t: duration strings become nanoseconds, decimal strings for big.Int
+// become JSON numbers and dates without a time become RFC 3339 timestamps.
+func convertArguments(value any, t reflect.Type, path string) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case durationType:
+		return convertDuration(value, path)
+	case bigIntType:
+		return convertBigInt(value, path)
+	case timeType:
+		return convertTime(value, path)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value, nil
+		}
+		converted := make(map[string]any, len(object))
+		for key, v := range object {
+			converted[key] = v
+		}
+		for _, field := range jsonFields(t) {
+			key, ok := matchField(converted, field.name)
+			if !ok {
+				continue
+			}
+			v, err := convertArguments(converted[key], field.typ, joinArgumentPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			converted[key] = v
+		}
+		return converted, nil
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]any)
+		if !ok {
+			return value, nil
+		}
+		converted := make([]any, len(list))
+		for i, v := range list {
+			c, err := convertArguments(v, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = c
+		}
+		return converted, nil
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok || t.Key().Kind() != reflect.String {
+			return value, nil
+		}
+		converted := make(map[string]any, len(object))
+		for key, v := range object {
+			c, err := convertArguments(v, t.Elem(), joinArgumentPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			converted[key] = c
+		}
+		return converted, nil
+	}
+	return value, nil
+}
+
+func convertDuration(value any, path string) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		// Numbers are nanoseconds, as with encoding/json.
+		return value, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, &ArgumentError{Field: path, Constraint: ArgumentConstraintFormat, Got: s, Want: "duration", err: err}
+	}
+	return int64(d), nil
+}
+
+func convertBigInt(value any, path string) (any, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case json.Number:
+		s = v.String()
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return value, nil
+	}
+	if _, ok := new(big.Int).SetString(s, 10); !ok {
+		return nil, &ArgumentError{Field: path, Constraint: ArgumentConstraintFormat, Got: s, Want: "integer"}
+	}
+	return json.Number(s), nil
+}
+
+func convertTime(value any, path string) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(time.RFC3339Nano), nil
+		}
+	}
+	return nil, &ArgumentError{Field: path, Constraint: ArgumentConstraintFormat, Got: s, Want: "time"}
+}
+
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields lists the JSON names and types of the fields of a struct,
+// including promoted fields of embedded structs.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: field.Type})
+	}
+	return fields
+}
+
+// matchField finds the key for a field the way encoding/json does, preferring
+// an exact match over a case-insensitive one.
+func matchField(object map[string]any, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func joinArgumentPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"encoding/json"
+	"math/big"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scheduleArgs struct {
+	Start    time.Time      `json:"start"`
+	Every    time.Duration  `json:"every"`
+	Timeout  *time.Duration `json:"timeout,omitempty"`
+	Amount   *big.Int       `json:"amount"`
+	Windows  []time.Duration
+	Deadline map[string]time.Time `json:"deadline"`
+	embeddedLimits
+}
+
+type embeddedLimits struct {
+	Budget big.Int `json:"budget"`
+}
+
+func TestBindArguments_TimeDurationBigInt(t *testing.T) {
+	const arguments = `{
+		"start": "2024-05-01T09:30:00Z",
+		"every": "5m",
+		"timeout": 1500000000,
+		"amount": "123456789012345678901234567890",
+		"windows": ["1h30m", "10s"],
+		"deadline": {"report": "2024-06-01"},
+		"budget": 98765432109876543210
+	}`
+	want := func(t *testing.T, args scheduleArgs) {
+		t.Helper()
+		assert.Equal(t, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC), args.Start)
+		assert.Equal(t, 5*time.Minute, args.Every)
+		require.NotNil(t, args.Timeout)
+		assert.Equal(t, 1500*time.Millisecond, *args.Timeout)
+		require.NotNil(t, args.Amount)
+		assert.Equal(t, "123456789012345678901234567890", args.Amount.String())
+		assert.Equal(t, []time.Duration{90 * time.Minute, 10 * time.Second}, args.Windows)
+		assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), args.Deadline["report"])
+		assert.Equal(t, "98765432109876543210", args.Budget.String())
+	}
+
+	t.Run("raw JSON", func(t *testing.T) {
+		var request CallToolRequest
+		request.Params.Arguments = json.RawMessage(arguments)
+		var args scheduleArgs
+		require.NoError(t, request.BindArguments(&args))
+		want(t, args)
+	})
+
+	t.Run("decoded JSON", func(t *testing.T) {
+		var request CallToolRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"params":{"name":"schedule","arguments":`+arguments+`}}`), &request))
+		var args scheduleArgs
+		require.NoError(t, request.BindArguments(&args))
+		assert.Equal(t, 5*time.Minute, args.Every)
+		assert.Equal(t, "123456789012345678901234567890", args.Amount.String())
+		// Decoded as float64, the budget loses precision but still binds.
+		assert.Equal(t, "98765432109876540000", args.Budget.String())
+	})
+}
+
+func TestBindArguments_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		message   string
+	}{
+		{name: "duration", arguments: map[string]any{"every": "soon"}, message: `argument every: invalid duration "soon"`},
+		{name: "big int", arguments: map[string]any{"amount": "12.5"}, message: `argument amount: invalid integer "12.5"`},
+		{name: "time", arguments: map[string]any{"start": "yesterday"}, message: `argument start: invalid time "yesterday"`},
+		{name: "nested", arguments: map[string]any{"windows": []any{"1m", "x"}}, message: `argument windows[1]: invalid duration "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request CallToolRequest
+			request.Params.Arguments = tt.arguments
+			var args scheduleArgs
+			err := request.BindArguments(&args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestWithDuration(t *testing.T) {
+	tool := NewTool("wait", WithDuration("delay", Required(), Description("How long to wait")))
+
+	property := tool.InputSchema.Properties["delay"].(map[string]any)
+	assert.Equal(t, "string", property["type"])
+	assert.Equal(t, "duration", property["format"])
+	assert.Equal(t, "How long to wait", property["description"])
+	assert.Equal(t, []string{"delay"}, tool.InputSchema.Required)
+
+	pattern := regexp.MustCompile(property["pattern"].(string))
+	for _, valid := range []string{"5m", "1h30m", "300ms", "1.5s", "-2h"} {
+		assert.True(t, pattern.MatchString(valid), valid)
+	}
+	for _, invalid := range []string{"5", "m", "five minutes", ""} {
+		assert.False(t, pattern.MatchString(invalid), invalid)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tool := NewTool("remind", WithString("at", Format("date-time")))
+	assert.Equal(t, "date-time", tool.InputSchema.Properties["at"].(map[string]any)["format"])
+}
+
+func TestWithInputSchema_TimeDurationBigInt(t *testing.T) {
+	tool := NewTool("schedule", WithInputSchema[scheduleArgs]())
+
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(tool.RawInputSchema, &schema))
+	assert.Equal(t, "date-time", schema.Properties["start"]["format"])
+	assert.Equal(t, "duration", schema.Properties["every"]["format"])
+	assert.Equal(t, "duration", schema.Properties["timeout"]["format"])
+	assert.Equal(t, "string", schema.Properties["amount"]["type"])
+	assert.Equal(t, bigIntPattern, schema.Properties["amount"]["pattern"])
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StructuredContentProtocolVersion is the first protocol version with
+// structured tool results and output schemas. Clients of earlier versions
+// only read a result's content.
+const StructuredContentProtocolVersion = "2025-06-18"
+
+// SupportsStructuredContent reports whether clients and servers of the
+// given protocol version know structured tool results.
+func SupportsStructuredContent(protocolVersion string) bool {
+	// Protocol versions are dates, which sort as strings.
+	return protocolVersion >= StructuredContentProtocolVersion
+}
+
+// StructuredContentToText returns the result with its structured content
+// added as pretty-printed JSON text content, for clients that only read
+// content. Results that already have content, or have no structured
+// content, are returned as is; the input is never modified.
+func StructuredContentToText(result *CallToolResult) (*CallToolResult, error) {
+	if result == nil || result.StructuredContent == nil || len(result.Content) > 0 {
+		return result, nil
+	}
+	text, err := json.MarshalIndent(result.StructuredContent, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal structured content: %w", err)
+	}
+	converted := *result
+	converted.Content = []Content{NewTextContent(string(text))}
+	return &converted, nil
+}
+
+// TextToStructuredContent returns the result with the JSON object of its
+// only text content as its structured content, as servers of protocol
+// versions before StructuredContentProtocolVersion return structured
+// results. Results that have structured content, or whose content is not a
+// single text part holding a JSON object, are returned as is; the input is
+// never modified.
+func TextToStructuredContent(result *CallToolResult) *CallToolResult {
+	if result == nil || result.StructuredContent != nil || len(result.Content) != 1 {
+		return result
+	}
+	text, ok := AsTextContent(result.Content[0])
+	if !ok || !strings.HasPrefix(strings.TrimSpace(text.Text), "{") {
+		return result
+	}
+	var structured map[string]any
+	if err := json.Unmarshal([]byte(text.Text), &structured); err != nil {
+		return result
+	}
+	converted := *result
+	converted.StructuredContent = structured
+	return &converted
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsStructuredContent(t *testing.T) {
+	assert.True(t, SupportsStructuredContent(LATEST_PROTOCOL_VERSION))
+	assert.True(t, SupportsStructuredContent("2025-11-25"))
+	assert.False(t, SupportsStructuredContent("2025-03-26"))
+	assert.False(t, SupportsStructuredContent("2024-11-05"))
+	assert.False(t, SupportsStructuredContent(""))
+}
+
+func TestStructuredContentToText(t *testing.T) {
+	result := &CallToolResult{StructuredContent: map[string]any{"temperature": 21.5, "unit": "C"}}
+
+	converted, err := StructuredContentToText(result)
+	require.NoError(t, err)
+	require.Len(t, converted.Content, 1)
+	assert.Equal(t, NewTextContent("{\n  \"temperature\": 21.5,\n  \"unit\": \"C\"\n}"), converted.Content[0])
+	assert.Equal(t, result.StructuredContent, converted.StructuredContent)
+	assert.Empty(t, result.Content, "input must not be modified")
+
+	withText := NewToolResultStructured(map[string]any{"a": 1}, "a is 1")
+	converted, err = StructuredContentToText(withText)
+	require.NoError(t, err)
+	assert.Same(t, withText, converted)
+
+	_, err = StructuredContentToText(&CallToolResult{StructuredContent: func() {}})
+	assert.Error(t, err)
+}
+
+func TestTextToStructuredContent(t *testing.T) {
+	result := NewToolResultText(`{"temperature": 21.5, "unit": "C"}`)
+	converted := TextToStructuredContent(result)
+	assert.Equal(t, map[string]any{"temperature": 21.5, "unit": "C"}, converted.StructuredContent)
+	assert.Equal(t, result.Content, converted.Content)
+	assert.Nil(t, result.StructuredContent, "input must not be modified")
+
+	tests := []struct {
+		name   string
+		result *CallToolResult
+	}{
+		{"plain text", NewToolResultText("21.5 degrees")},
+		{"JSON array", NewToolResultText(`[1, 2]`)},
+		{"invalid JSON", NewToolResultText(`{"a":`)},
+		{"several parts", &CallToolResult{Content: []Content{NewTextContent(`{}`), NewTextContent(`{}`)}}},
+		{"structured", NewToolResultStructured(map[string]any{"a": 1}, `{"a": 2}`)},
+		{"nil", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Same(t, tt.result, TextToStructuredContent(tt.result))
+		})
+	}
+}
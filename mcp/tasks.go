@@ -66,10 +66,23 @@ func WithTaskCreatedAt(createdAt string) TaskOption {
 	}
 }
 
+// RelatedTaskMetaKey is the _meta key used to associate a message with the
+// task it belongs to, e.g. in tasks/result responses.
+const RelatedTaskMetaKey = "io.modelcontextprotocol/related-task"
+
 //
 // Task Helper Functions
 //
 
+// NewRelatedTaskMeta creates a Meta that associates a message with a task.
+func NewRelatedTaskMeta(taskID string) *Meta {
+	return &Meta{
+		AdditionalFields: map[string]any{
+			RelatedTaskMetaKey: map[string]any{"taskId": taskID},
+		},
+	}
+}
+
 // NewTaskParams creates TaskParams with the given TTL.
 func NewTaskParams(ttlMs *int64) TaskParams {
 	return TaskParams{
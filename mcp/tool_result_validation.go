@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidToolResult is returned by CallToolResult.Validate for results
+// that clients would reject or misread.
+var ErrInvalidToolResult = errors.New("invalid tool result")
+
+// Validate checks that the result can be sent to clients as is: it must
+// have content or structured content, structured content must be a JSON
+// object, each content part must be one of the content types of this
+// package, or registered with RegisterContentType, with its type set and
+// passing the type's validator, image and audio data must be valid base64 of
+// the declared MIME type, and embedded resources must have a URI and valid
+// base64 blobs. The returned error wraps ErrInvalidToolResult and lists
+// every problem found.
+//
+// Call it in tool handlers to catch mistakes where the result is built, or
+// use server.WithStrictToolResults to check every result before it is sent.
+func (r *CallToolResult) Validate() error {
+	if r == nil {
+		return fmt.Errorf("%w: nil result", ErrInvalidToolResult)
+	}
+	var problems []error
+	if len(r.Content) == 0 && r.StructuredContent == nil {
+		problems = append(problems, errors.New("no content and no structured content"))
+	}
+	if r.StructuredContent != nil {
+		if err := validateStructuredContent(r.StructuredContent); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	for i, content := range r.Content {
+		if err := validateContent(content); err != nil {
+			problems = append(problems, fmt.Errorf("content[%d]: %w", i, err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidToolResult, errors.Join(problems...))
+}
+
+func validateStructuredContent(structured any) error {
+	data, err := json.Marshal(structured)
+	if err != nil {
+		return fmt.Errorf("structured content: %w", err)
+	}
+	if data = bytes.TrimSpace(data); len(data) == 0 || data[0] != '{' {
+		return fmt.Errorf("structured content: %T is not a JSON object", structured)
+	}
+	return nil
+}
+
+// validateContent checks a content part of a tool result.
+func validateContent(content Content) error {
+	switch c := content.(type) {
+	case nil:
+		return errors.New("nil content")
+	case TextContent:
+		return checkContentType(c.Type, ContentTypeText)
+	case *TextContent:
+		if c == nil {
+			return errors.New("nil content")
+		}
+		return validateContent(*c)
+	case ImageContent:
+		if err := checkContentType(c.Type, ContentTypeImage); err != nil {
+			return err
+		}
+		return ValidateBinaryContent(c, BinaryContentLimits{})
+	case *ImageContent:
+		if c == nil {
+			return errors.New("nil content")
+		}
+		return validateContent(*c)
+	case AudioContent:
+		if err := checkContentType(c.Type, ContentTypeAudio); err != nil {
+			return err
+		}
+		return ValidateBinaryContent(c, BinaryContentLimits{})
+	case *AudioContent:
+		if c == nil {
+			return errors.New("nil content")
+		}
+		return validateContent(*c)
+	case ResourceLink:
+		if err := checkContentType(c.Type, ContentTypeLink); err != nil {
+			return err
+		}
+		if c.URI == "" || c.Name == "" {
+			return errors.New("resource link: uri and name are required")
+		}
+		return nil
+	case *ResourceLink:
+		if c == nil {
+			return errors.New("nil content")
+		}
+		return validateContent(*c)
+	case EmbeddedResource:
+		if err := checkContentType(c.Type, ContentTypeResource); err != nil {
+			return err
+		}
+		return validateResourceContents(c.Resource)
+	case *EmbeddedResource:
+		if c == nil {
+			return errors.New("nil content")
+		}
+		return validateContent(*c)
+	default:
+		if ok, err := validateRegisteredContent(content); ok {
+			return err
+		}
+		return fmt.Errorf("unknown content type %T", content)
+	}
+}
+
+func checkContentType(got, want string) error {
+	if got != want {
+		return fmt.Errorf("%s content has type %q", want, got)
+	}
+	return nil
+}
+
+func validateResourceContents(contents ResourceContents) error {
+	switch c := contents.(type) {
+	case nil:
+		return errors.New("embedded resource: no contents")
+	case TextResourceContents:
+		if c.URI == "" {
+			return errors.New("embedded resource: uri is required")
+		}
+		return nil
+	case *TextResourceContents:
+		if c == nil {
+			return errors.New("embedded resource: no contents")
+		}
+		return validateResourceContents(*c)
+	case BlobResourceContents:
+		if c.URI == "" {
+			return errors.New("embedded resource: uri is required")
+		}
+		if _, err := base64.StdEncoding.DecodeString(c.Blob); err != nil {
+			return fmt.Errorf("embedded resource %s: invalid base64 blob: %w", c.URI, err)
+		}
+		return nil
+	case *BlobResourceContents:
+		if c == nil {
+			return errors.New("embedded resource: no contents")
+		}
+		return validateResourceContents(*c)
+	default:
+		return fmt.Errorf("embedded resource: unknown contents type %T", contents)
+	}
+}
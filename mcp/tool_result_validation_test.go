@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngData is the base64 of a PNG header, enough to be sniffed as image/png.
+const pngData = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
+
+type customContent struct {
+	TextContent
+	Extra string `json:"extra"`
+}
+
+func TestCallToolResult_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *CallToolResult
+		wantErr string
+	}{
+		{name: "text", result: NewToolResultText("ok")},
+		{name: "structured only", result: &CallToolResult{StructuredContent: map[string]any{"n": 1}}},
+		{name: "image", result: NewToolResultImage("chart", pngData, "image/png")},
+		{name: "pointer content", result: &CallToolResult{Content: []Content{&TextContent{Type: ContentTypeText, Text: "ok"}}}},
+		{
+			name:   "blob resource",
+			result: NewToolResultResource("file", BlobResourceContents{URI: "file:///a.bin", Blob: "AAEC"}),
+		},
+		{name: "nil result", result: nil, wantErr: "nil result"},
+		{name: "empty", result: &CallToolResult{}, wantErr: "no content and no structured content"},
+		{
+			name:    "structured content not an object",
+			result:  &CallToolResult{StructuredContent: []int{1, 2}},
+			wantErr: "structured content: []int is not a JSON object",
+		},
+		{
+			name:    "missing type",
+			result:  &CallToolResult{Content: []Content{TextContent{Text: "ok"}}},
+			wantErr: `content[0]: text content has type ""`,
+		},
+		{
+			name:    "invalid base64 image",
+			result:  &CallToolResult{Content: []Content{NewImageContent("not base64!", "image/png")}},
+			wantErr: "content[0]: image content: invalid base64 data",
+		},
+		{
+			name:    "invalid base64 blob",
+			result:  NewToolResultResource("file", BlobResourceContents{URI: "file:///a.bin", Blob: "%%%"}),
+			wantErr: "content[1]: embedded resource file:///a.bin: invalid base64 blob",
+		},
+		{
+			name:    "embedded resource without URI",
+			result:  NewToolResultResource("file", TextResourceContents{Text: "x"}),
+			wantErr: "content[1]: embedded resource: uri is required",
+		},
+		{
+			name:    "unknown content type",
+			result:  &CallToolResult{Content: []Content{customContent{TextContent: NewTextContent("x")}}},
+			wantErr: "content[0]: unknown content type mcp.customContent",
+		},
+		{
+			name:    "nil content",
+			result:  &CallToolResult{Content: []Content{nil}},
+			wantErr: "content[0]: nil content",
+		},
+		{
+			name:    "resource link without name",
+			result:  &CallToolResult{Content: []Content{ResourceLink{Type: ContentTypeLink, URI: "file:///a"}}},
+			wantErr: "content[0]: resource link: uri and name are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.result.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidToolResult)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCallToolResult_ValidateReportsEveryProblem(t *testing.T) {
+	result := &CallToolResult{Content: []Content{
+		TextContent{Text: "no type"},
+		NewTextContent("fine"),
+		AudioContent{Type: ContentTypeAudio, Data: "AAAA", MIMEType: "text/plain"},
+	}}
+	err := result.Validate()
+	require.ErrorIs(t, err, ErrInvalidToolResult)
+	assert.Contains(t, err.Error(), "content[0]:")
+	assert.NotContains(t, err.Error(), "content[1]:")
+	assert.Contains(t, err.Error(), "content[2]: audio content: MIME type text/plain is not an audio type")
+}
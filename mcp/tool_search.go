@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MethodToolsSearch is the tools/search extension method, which returns
+	// the tools matching a query ranked by relevance. Servers that support it
+	// declare the ExperimentalToolSearch capability.
+	MethodToolsSearch MCPMethod = "tools/search"
+
+	// ExperimentalToolSearch is the experimental server capability declaring
+	// support for tools/search and for the tools/list search filters.
+	ExperimentalToolSearch = "toolSearch"
+
+	// ToolsListQueryMeta is the tools/list _meta key of a search query. When
+	// present, servers with tool search return only the matching tools,
+	// ranked by relevance.
+	ToolsListQueryMeta = "query"
+	// ToolsListLimitMeta is the tools/list _meta key of the maximum number of
+	// tools returned for a query.
+	ToolsListLimitMeta = "limit"
+)
+
+// SearchToolsRequest is sent from the client to find the tools relevant to a
+// query, e.g. the user's request, without listing the whole catalog.
+type SearchToolsRequest struct {
+	Request
+	Header http.Header       `json:"-"`
+	Params SearchToolsParams `json:"params"`
+}
+
+// SearchToolsParams are the parameters of a tools/search request.
+type SearchToolsParams struct {
+	Meta *Meta `json:"_meta,omitempty"`
+	// Query is matched, keyword by keyword, against tool names, titles and
+	// descriptions.
+	Query string `json:"query"`
+	// Limit is the maximum number of tools returned. Zero means no limit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// SearchToolsResult is the server's response to a tools/search request. Its
+// tools are sorted from most to least relevant.
+type SearchToolsResult struct {
+	Result
+	Tools []Tool `json:"tools"`
+}
+
+// SearchTools returns the tools matching query, most relevant first, and at
+// most limit of them if limit is positive. Every keyword of the query must
+// occur in the tool's name, title or description, ignoring case; matches in
+// the name rank above matches in the title and description, and exact name
+// matches above partial ones. Ties keep the order of tools.
+func SearchTools(tools []Tool, query string, limit int) []Tool {
+	keywords := searchKeywords(query)
+	if len(keywords) == 0 {
+		if limit > 0 && len(tools) > limit {
+			return tools[:limit]
+		}
+		return tools
+	}
+
+	type scored struct {
+		tool  Tool
+		score int
+	}
+	var matches []scored
+	for _, tool := range tools {
+		if score := toolSearchScore(tool, keywords); score > 0 {
+			matches = append(matches, scored{tool, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]Tool, len(matches))
+	for i, match := range matches {
+		result[i] = match.tool
+	}
+	return result
+}
+
+// toolSearchScore returns how well the tool matches all keywords, or 0 if
+// one of them does not occur.
+func toolSearchScore(tool Tool, keywords []string) int {
+	name := strings.ToLower(tool.Name)
+	nameWords := searchKeywords(tool.Name)
+	title := strings.ToLower(tool.Annotations.Title)
+	description := strings.ToLower(tool.Description)
+
+	total := 0
+	for _, keyword := range keywords {
+		score := 0
+		switch {
+		case name == keyword:
+			score = 100
+		case containsWord(nameWords, keyword):
+			score = 50
+		case strings.Contains(name, keyword):
+			score = 20
+		}
+		if strings.Contains(title, keyword) {
+			score += 10
+		}
+		if strings.Contains(description, keyword) {
+			score += 5
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+// searchKeywords splits text into lowercase words at any character that is
+// not a letter or digit, so "get_weather" yields "get" and "weather".
+func searchKeywords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTools(t *testing.T) {
+	tools := []Tool{
+		NewTool("get_forecast", WithDescription("Weather forecast for a city")),
+		NewTool("weather", WithDescription("Current conditions")),
+		NewTool("send_email", WithDescription("Send an email, e.g. a weather report")),
+		NewTool("weathervane", WithTitleAnnotation("Wind direction")),
+		NewTool("translate", WithDescription("Translate text")),
+	}
+	names := func(tools []Tool) []string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{name: "ranked", query: "Weather", want: []string{"weather", "weathervane", "get_forecast", "send_email"}},
+		{name: "all keywords must match", query: "weather city", want: []string{"get_forecast"}},
+		{name: "name words", query: "forecast", want: []string{"get_forecast"}},
+		{name: "title", query: "wind", want: []string{"weathervane"}},
+		{name: "limit", query: "weather", limit: 2, want: []string{"weather", "weathervane"}},
+		{name: "no match", query: "calendar"},
+		{name: "empty query keeps order", query: " ", limit: 2, want: []string{"get_forecast", "weather"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(SearchTools(tools, tt.query, tt.limit)))
+		})
+	}
+}
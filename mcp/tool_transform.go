@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ArgumentTransformerFunc rewrites the arguments of a tool call before they are
+// bound and passed to the handler, e.g. to trim strings or convert units.
+// Returning an error fails the call without running the handler.
+type ArgumentTransformerFunc func(ctx context.Context, arguments map[string]any) (map[string]any, error)
+
+// ResultTransformerFunc rewrites the result of a successful tool call before it
+// is returned, e.g. to add links or footnotes.
+type ResultTransformerFunc func(ctx context.Context, request CallToolRequest, result *CallToolResult) (*CallToolResult, error)
+
+// WithArgumentTransformer adds a function that rewrites the tool's arguments
+// before the handler runs. Transformers run in the order they are added.
+func WithArgumentTransformer(fn ArgumentTransformerFunc) ToolOption {
+	return func(t *Tool) {
+		t.ArgumentTransformers = append(t.ArgumentTransformers, fn)
+	}
+}
+
+// WithResultTransformer adds a function that rewrites the tool's result after
+// the handler returns. Transformers run in the order they are added and are
+// skipped when the handler returns an error.
+func WithResultTransformer(fn ResultTransformerFunc) ToolOption {
+	return func(t *Tool) {
+		t.ResultTransformers = append(t.ResultTransformers, fn)
+	}
+}
+
+// TransformArguments runs the tool's argument transformers on the request.
+// Arguments given as raw JSON or as a struct are decoded into a map first.
+func (t Tool) TransformArguments(ctx context.Context, request CallToolRequest) (CallToolRequest, error) {
+	if len(t.ArgumentTransformers) == 0 {
+		return request, nil
+	}
+	arguments, err := argumentsMap(request.Params.Arguments)
+	if err != nil {
+		return request, err
+	}
+	for _, transform := range t.ArgumentTransformers {
+		var err error
+		if arguments, err = transform(ctx, arguments); err != nil {
+			return request, err
+		}
+	}
+	request.Params.Arguments = arguments
+	return request, nil
+}
+
+// argumentsMap returns the arguments of a tool call as a map, decoding them
+// from raw JSON or round-tripping other types through JSON.
+func argumentsMap(arguments any) (map[string]any, error) {
+	var raw []byte
+	switch arguments := arguments.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return arguments, nil
+	case json.RawMessage:
+		raw = arguments
+	default:
+		var err error
+		if raw, err = json.Marshal(arguments); err != nil {
+			return nil, fmt.Errorf("arguments cannot be transformed: %w", err)
+		}
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("arguments cannot be transformed, they must be an object: %w", err)
+	}
+	return decoded, nil
+}
+
+// TransformResult runs the tool's result transformers on a handler's result.
+func (t Tool) TransformResult(ctx context.Context, request CallToolRequest, result *CallToolResult) (*CallToolResult, error) {
+	for _, transform := range t.ResultTransformers {
+		var err error
+		if result, err = transform(ctx, request, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
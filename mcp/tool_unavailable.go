@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrToolUnavailable is matched by ToolUnavailableError.
+var ErrToolUnavailable = errors.New("tool temporarily unavailable")
+
+// ToolUnavailableError is returned for calls to a tool the server disabled
+// temporarily. RetryAfter, when known, is how long until it is available
+// again; it travels in seconds as the retryAfter of the error's data.
+type ToolUnavailableError struct {
+	Tool       string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e ToolUnavailableError) Error() string {
+	msg := fmt.Sprintf("tool '%s' is temporarily unavailable", e.Tool)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %v)", e.RetryAfter)
+	}
+	return msg
+}
+
+// Is matches ErrToolUnavailable and other ToolUnavailableErrors.
+func (e ToolUnavailableError) Is(target error) bool {
+	if target == ErrToolUnavailable {
+		return true
+	}
+	_, ok := target.(ToolUnavailableError)
+	return ok
+}
+
+// ErrorData returns the data of the error's JSON-RPC form.
+func (e ToolUnavailableError) ErrorData() map[string]any {
+	data := map[string]any{"tool": e.Tool}
+	if e.Reason != "" {
+		data["reason"] = e.Reason
+	}
+	if e.RetryAfter > 0 {
+		data["retryAfter"] = int64(math.Ceil(e.RetryAfter.Seconds()))
+	}
+	return data
+}
+
+func (e ToolUnavailableError) JSONRPCError() JSONRPCError {
+	return JSONRPCError{
+		JSONRPC: JSONRPC_VERSION,
+		Error: JSONRPCErrorDetails{
+			Code:    TOOL_UNAVAILABLE,
+			Message: e.Error(),
+			Data:    e.ErrorData(),
+		},
+	}
+}
+
+// toolUnavailableFromData rebuilds a ToolUnavailableError from the data of
+// a JSON-RPC error.
+func toolUnavailableFromData(data any) ToolUnavailableError {
+	var fields struct {
+		Tool       string  `json:"tool"`
+		Reason     string  `json:"reason"`
+		RetryAfter float64 `json:"retryAfter"`
+	}
+	if raw, err := json.Marshal(data); err == nil {
+		_ = json.Unmarshal(raw, &fields)
+	}
+	return ToolUnavailableError{
+		Tool:       fields.Tool,
+		Reason:     fields.Reason,
+		RetryAfter: time.Duration(fields.RetryAfter * float64(time.Second)),
+	}
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolUnavailableError(t *testing.T) {
+	err := ToolUnavailableError{Tool: "deploy", Reason: "maintenance", RetryAfter: 90*time.Second + time.Millisecond}
+	assert.Equal(t, "tool 'deploy' is temporarily unavailable: maintenance (retry after 1m30.001s)", err.Error())
+	assert.ErrorIs(t, err, ErrToolUnavailable)
+	assert.ErrorIs(t, err, ToolUnavailableError{})
+
+	jsonRPCError := err.JSONRPCError()
+	require.Equal(t, TOOL_UNAVAILABLE, jsonRPCError.Error.Code)
+	assert.Equal(t, map[string]any{"tool": "deploy", "reason": "maintenance", "retryAfter": int64(91)}, jsonRPCError.Error.Data)
+}
+
+func TestJSONRPCErrorDetails_AsError_ToolUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ToolUnavailableError
+	}{
+		{
+			name: "with retry after",
+			data: `{"tool":"deploy","reason":"maintenance","retryAfter":30}`,
+			want: ToolUnavailableError{Tool: "deploy", Reason: "maintenance", RetryAfter: 30 * time.Second},
+		},
+		{
+			name: "indefinitely",
+			data: `{"tool":"deploy"}`,
+			want: ToolUnavailableError{Tool: "deploy"},
+		},
+		{
+			name: "without data",
+			data: `null`,
+			want: ToolUnavailableError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var details JSONRPCErrorDetails
+			require.NoError(t, json.Unmarshal([]byte(`{"code":-32003,"message":"unavailable","data":`+tt.data+`}`), &details))
+
+			var unavailable ToolUnavailableError
+			require.True(t, errors.As(details.AsError(), &unavailable))
+			assert.Equal(t, tt.want, unavailable)
+		})
+	}
+}
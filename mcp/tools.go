@@ -7,8 +7,6 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
-
-	"github.com/invopop/jsonschema"
 )
 
 var errToolSchemaConflict = errors.New("provide either InputSchema or RawInputSchema, not both")
@@ -61,6 +59,9 @@ type CallToolParams struct {
 	Name      string `json:"name"`
 	Arguments any    `json:"arguments,omitempty"`
 	Meta      *Meta  `json:"_meta,omitempty"`
+	// Task, when set, asks the server to execute the call as a task and
+	// return a CreateTaskResult instead of the tool result.
+	Task *TaskParams `json:"task,omitempty"`
 }
 
 // GetArguments returns the Arguments as map[string]any for backward compatibility
@@ -79,23 +80,43 @@ func (r CallToolRequest) GetRawArguments() any {
 }
 
 // BindArguments unmarshals the Arguments into the provided struct
-// This is useful for working with strongly-typed arguments
+// This is useful for working with strongly-typed arguments.
+// Arguments that do not fit the target are reported as *ArgumentError.
+// Besides the encodings understood by encoding/json, time.Duration fields
+// accept strings such as "5m", big.Int fields accept decimal strings and
+// time.Time fields accept dates without a time.
 func (r CallToolRequest) BindArguments(target any) error {
 	if target == nil || reflect.ValueOf(target).Kind() != reflect.Ptr {
 		return fmt.Errorf("target must be a non-nil pointer")
 	}
 
+	args := r.Params.Arguments
+	if needsArgumentConversion(reflect.TypeOf(target)) {
+		if raw, ok := args.(json.RawMessage); ok {
+			decoded, err := decodeArguments(raw)
+			if err != nil {
+				return asArgumentError(err)
+			}
+			args = decoded
+		}
+		converted, err := convertArguments(args, reflect.TypeOf(target), "")
+		if err != nil {
+			return err
+		}
+		args = converted
+	}
+
 	// Fast-path: already raw JSON
-	if raw, ok := r.Params.Arguments.(json.RawMessage); ok {
-		return json.Unmarshal(raw, target)
+	if raw, ok := args.(json.RawMessage); ok {
+		return asArgumentError(json.Unmarshal(raw, target))
 	}
 
-	data, err := json.Marshal(r.Params.Arguments)
+	data, err := json.Marshal(args)
 	if err != nil {
 		return fmt.Errorf("failed to marshal arguments: %w", err)
 	}
 
-	return json.Unmarshal(data, target)
+	return asArgumentError(json.Unmarshal(data, target))
 }
 
 // GetString returns a string argument by key, or the default value if not found
@@ -575,6 +596,15 @@ type Tool struct {
 	DeferLoading bool `json:"defer_loading,omitempty"`
 	// Icons provides visual identifiers for the tool
 	Icons []Icon `json:"icons,omitempty"`
+	// ArgumentTransformers run, in order, on the arguments of each call
+	// before they reach the handler.
+	ArgumentTransformers []ArgumentTransformerFunc `json:"-"`
+	// ResultTransformers run, in order, on the result of each successful
+	// call before it is returned.
+	ResultTransformers []ResultTransformerFunc `json:"-"`
+	// RequiredClientCapabilities lists the client capabilities the tool
+	// needs to work.
+	RequiredClientCapabilities []ClientCapability `json:"-"`
 }
 
 // GetName returns the name of the tool.
@@ -783,11 +813,7 @@ func WithInputSchema[T any]() ToolOption {
 
 		// Generate schema using invopop/jsonschema library
 		// Configure reflector to generate clean, MCP-compatible schemas
-		reflector := jsonschema.Reflector{
-			DoNotReference:            true, // Removes $defs map, outputs entire structure inline
-			Anonymous:                 true, // Hides auto-generated Schema IDs
-			AllowAdditionalProperties: true, // Removes additionalProperties: false
-		}
+		reflector := schemaReflector()
 		schema := reflector.Reflect(zero)
 
 		// Clean up schema for MCP compliance
@@ -832,11 +858,7 @@ func WithOutputSchema[T any]() ToolOption {
 
 		// Generate schema using invopop/jsonschema library
 		// Configure reflector to generate clean, MCP-compatible schemas
-		reflector := jsonschema.Reflector{
-			DoNotReference:            true, // Removes $defs map, outputs entire structure inline
-			Anonymous:                 true, // Hides auto-generated Schema IDs
-			AllowAdditionalProperties: true, // Removes additionalProperties: false
-		}
+		reflector := schemaReflector()
 		schema := reflector.Reflect(zero)
 
 		// Clean up schema for MCP compliance
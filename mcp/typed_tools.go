@@ -16,7 +16,7 @@ func NewTypedToolHandler[T any](handler TypedToolHandlerFunc[T]) func(ctx contex
 	return func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
 		var args T
 		if err := request.BindArguments(&args); err != nil {
-			return NewToolResultError(fmt.Sprintf("failed to bind arguments: %v", err)), nil
+			return NewArgumentErrorResult(ctx, err), nil
 		}
 		return handler(ctx, request, args)
 	}
@@ -29,7 +29,7 @@ func NewStructuredToolHandler[TArgs any, TResult any](handler StructuredToolHand
 	return func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
 		var args TArgs
 		if err := request.BindArguments(&args); err != nil {
-			return NewToolResultError(fmt.Sprintf("failed to bind arguments: %v", err)), nil
+			return NewArgumentErrorResult(ctx, err), nil
 		}
 
 		result, err := handler(ctx, request, args)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/yosida95/uritemplate/v3"
@@ -35,6 +36,14 @@ const (
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
 	MethodResourcesRead MCPMethod = "resources/read"
 
+	// MethodResourcesSubscribe requests resources/updated notifications for a resource.
+	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
+	MethodResourcesSubscribe MCPMethod = "resources/subscribe"
+
+	// MethodResourcesUnsubscribe cancels a previous resources/subscribe request.
+	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
+	MethodResourcesUnsubscribe MCPMethod = "resources/unsubscribe"
+
 	// MethodPromptsList lists all available prompt templates.
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/prompts/
 	MethodPromptsList MCPMethod = "prompts/list"
@@ -421,6 +430,10 @@ const (
 	// RESOURCE_NOT_FOUND indicates that the requested resource was not found.
 	RESOURCE_NOT_FOUND = -32002
 
+	// TOOL_UNAVAILABLE is the error code of calls to a tool that is disabled
+	// for now, e.g. during a maintenance window.
+	TOOL_UNAVAILABLE = -32003
+
 	// URL_ELICITATION_REQUIRED is the error code for when URL elicitation is required.
 	URL_ELICITATION_REQUIRED = -32042
 )
@@ -626,6 +639,7 @@ type PaginatedRequest struct {
 }
 
 type PaginatedParams struct {
+	Meta *Meta `json:"_meta,omitempty"`
 	// An opaque token representing the current pagination position.
 	// If provided, the server should return results starting after this cursor.
 	Cursor Cursor `json:"cursor,omitempty"`
@@ -1336,6 +1350,30 @@ func (s TaskStatus) IsTerminal() bool {
 	return s == TaskStatusCompleted || s == TaskStatusFailed || s == TaskStatusCancelled
 }
 
+// IsValid returns true if s is one of the defined task statuses.
+func (s TaskStatus) IsValid() bool {
+	_, ok := taskTransitions[s]
+	return ok
+}
+
+// taskTransitions lists the statuses each status may move to.
+// Terminal statuses have no outgoing transitions.
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusWorking:       {TaskStatusInputRequired, TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled},
+	TaskStatusInputRequired: {TaskStatusWorking, TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled},
+	TaskStatusCompleted:     nil,
+	TaskStatusFailed:        nil,
+	TaskStatusCancelled:     nil,
+}
+
+// CanTransition reports whether a task may move from one status to another.
+// A task starts out working, may alternate between working and
+// input_required, and ends in exactly one of the terminal statuses.
+// Moving a task to the status it already has is not a transition.
+func CanTransition(from, to TaskStatus) bool {
+	return slices.Contains(taskTransitions[from], to)
+}
+
 // Task represents the execution state of a request.
 type Task struct {
 	// Unique identifier for the task.
@@ -1410,8 +1448,43 @@ type TaskResultParams struct {
 // The structure depends on the original request type.
 type TaskResultResult struct {
 	Result
-	// The actual result varies by request type (e.g., CallToolResult for tools/call).
-	// This will be handled by the specific implementation.
+	// Payload is the JSON encoding of the underlying request's result
+	// (e.g., a CallToolResult for tools/call). Its fields are inlined into
+	// the response when marshaled, alongside Meta.
+	Payload json.RawMessage `json:"-"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for TaskResultResult.
+// The payload fields are written at the top level and Meta is merged into
+// the payload's own _meta, if any.
+func (r TaskResultResult) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(r.Payload) > 0 {
+		if err := json.Unmarshal(r.Payload, &fields); err != nil {
+			return nil, fmt.Errorf("task result payload must be a JSON object: %w", err)
+		}
+	}
+
+	if r.Meta != nil {
+		meta := make(map[string]any)
+		if existing, ok := fields["_meta"]; ok {
+			if err := json.Unmarshal(existing, &meta); err != nil {
+				return nil, fmt.Errorf("invalid _meta in task result payload: %w", err)
+			}
+		}
+		if r.Meta.ProgressToken != nil {
+			meta["progressToken"] = r.Meta.ProgressToken
+		}
+		maps.Copy(meta, r.Meta.AdditionalFields)
+
+		encoded, err := json.Marshal(meta)
+		if err != nil {
+			return nil, err
+		}
+		fields["_meta"] = encoded
+	}
+
+	return json.Marshal(fields)
 }
 
 // CancelTaskRequest cancels an in-progress task.
@@ -1487,8 +1560,10 @@ func UnmarshalContent(data []byte) (Content, error) {
 		return content, err
 	case ContentTypeImage:
 		var content ImageContent
-		err := json.Unmarshal(data, &content)
-		return content, err
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		return DecodeContent(content)
 	case ContentTypeAudio:
 		var content AudioContent
 		err := json.Unmarshal(data, &content)
@@ -1502,6 +1577,9 @@ func UnmarshalContent(data []byte) (Content, error) {
 		err := json.Unmarshal(data, &content)
 		return content, err
 	default:
+		if content, ok, err := unmarshalCustomContent(contentType, data); ok {
+			return content, err
+		}
 		return nil, fmt.Errorf("unknown content type: %s", contentType)
 	}
 }
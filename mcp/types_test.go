@@ -373,3 +373,103 @@ func TestParseResourceContentsInvalidMeta(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskResultResult_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   TaskResultResult
+		expected string
+	}{
+		{
+			name:     "empty",
+			result:   TaskResultResult{},
+			expected: `{}`,
+		},
+		{
+			name: "payload with related task meta",
+			result: TaskResultResult{
+				Result:  Result{Meta: NewRelatedTaskMeta("task-1")},
+				Payload: json.RawMessage(`{"content":[{"type":"text","text":"done"}]}`),
+			},
+			expected: `{
+				"content": [{"type": "text", "text": "done"}],
+				"_meta": {"io.modelcontextprotocol/related-task": {"taskId": "task-1"}}
+			}`,
+		},
+		{
+			name: "meta is merged into payload meta",
+			result: TaskResultResult{
+				Result:  Result{Meta: NewRelatedTaskMeta("task-1")},
+				Payload: json.RawMessage(`{"content":[],"_meta":{"source":"tool"}}`),
+			},
+			expected: `{
+				"content": [],
+				"_meta": {"source": "tool", "io.modelcontextprotocol/related-task": {"taskId": "task-1"}}
+			}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.result)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(data))
+		})
+	}
+
+	t.Run("non-object payload", func(t *testing.T) {
+		_, err := json.Marshal(TaskResultResult{Payload: json.RawMessage(`"text"`)})
+		assert.Error(t, err)
+	})
+}
+
+func TestCanTransition(t *testing.T) {
+	statuses := []TaskStatus{
+		TaskStatusWorking,
+		TaskStatusInputRequired,
+		TaskStatusCompleted,
+		TaskStatusFailed,
+		TaskStatusCancelled,
+	}
+	allowed := map[TaskStatus]map[TaskStatus]bool{
+		TaskStatusWorking: {
+			TaskStatusInputRequired: true,
+			TaskStatusCompleted:     true,
+			TaskStatusFailed:        true,
+			TaskStatusCancelled:     true,
+		},
+		TaskStatusInputRequired: {
+			TaskStatusWorking:   true,
+			TaskStatusCompleted: true,
+			TaskStatusFailed:    true,
+			TaskStatusCancelled: true,
+		},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				assert.Equal(t, allowed[from][to], CanTransition(from, to))
+			})
+		}
+	}
+
+	t.Run("unknown statuses", func(t *testing.T) {
+		assert.False(t, CanTransition("paused", TaskStatusWorking))
+		assert.False(t, CanTransition(TaskStatusWorking, "paused"))
+	})
+}
+
+func TestTaskStatus_IsValid(t *testing.T) {
+	for _, status := range []TaskStatus{
+		TaskStatusWorking,
+		TaskStatusInputRequired,
+		TaskStatusCompleted,
+		TaskStatusFailed,
+		TaskStatusCancelled,
+	} {
+		assert.True(t, status.IsValid(), status)
+	}
+	assert.False(t, TaskStatus("").IsValid())
+	assert.False(t, TaskStatus("paused").IsValid())
+}
@@ -604,7 +604,10 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		}
 		c := NewImageContent(data, mimeType)
 		c.Annotations = annotations
-		return c, nil
+		if meta := ExtractMap(contentMap, "_meta"); meta != nil {
+			c.Meta = NewMetaFromMap(meta)
+		}
+		return DecodeContent(c)
 
 	case ContentTypeAudio:
 		data := ExtractString(contentMap, "data")
@@ -644,6 +647,12 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		return c, nil
 	}
 
+	if data, err := json.Marshal(contentMap); err == nil {
+		if content, ok, err := unmarshalCustomContent(contentType, data); ok {
+			return content, err
+		}
+	}
+
 	return nil, fmt.Errorf("unsupported content type: %s", contentType)
 }
 
@@ -798,12 +807,12 @@ func ParseResourceContents(contentMap map[string]any) (ResourceContents, error)
 	}
 
 	if blob := ExtractString(contentMap, "blob"); blob != "" {
-		return BlobResourceContents{
+		return DecodeResourceContents(BlobResourceContents{
 			Meta:     meta,
 			URI:      uri,
 			MIMEType: mimeType,
 			Blob:     blob,
-		}, nil
+		})
 	}
 
 	return nil, fmt.Errorf("unsupported resource type")
@@ -0,0 +1,358 @@
+// Package mcpbench drives configurable load scenarios against an MCP server
+// and reports latency percentiles, error rates and resource growth.
+//
+// A scenario opens a number of concurrent client sessions and has each of
+// them run a weighted mix of operations for a fixed duration or number of
+// iterations:
+//
+//	report, err := mcpbench.Run(ctx, mcpbench.Scenario{
+//		Sessions: 50,
+//		Duration: 30 * time.Second,
+//		Operations: []mcpbench.Operation{
+//			mcpbench.CallTool("search", map[string]any{"q": "coffee"}).WithWeight(8),
+//			mcpbench.CallToolAsTask("reindex", nil).WithWeight(1),
+//			mcpbench.ReadResource("docs://readme").WithWeight(1),
+//		},
+//	}, mcpbench.InProcess(srv))
+//	report.WriteText(os.Stdout)
+package mcpbench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ClientFactory creates an unstarted client for one simulated session.
+// Run starts and initializes the returned client and closes it when the
+// session ends.
+type ClientFactory func(ctx context.Context) (*client.Client, error)
+
+// OperationFunc performs one unit of work against a connected client.
+type OperationFunc func(ctx context.Context, c *client.Client) error
+
+// Operation is a named, weighted unit of work in a scenario.
+type Operation struct {
+	// Name identifies the operation in the report.
+	Name string
+	// Weight controls how often the operation is picked relative to the
+	// others. Operations with a weight below one count as one.
+	Weight int
+	// Run performs the operation.
+	Run OperationFunc
+}
+
+// WithWeight returns a copy of the operation with the given weight.
+func (o Operation) WithWeight(weight int) Operation {
+	o.Weight = weight
+	return o
+}
+
+// Scenario describes a load test.
+type Scenario struct {
+	// Name is included in the report.
+	Name string
+	// Sessions is the number of concurrent client sessions. Defaults to 1.
+	Sessions int
+	// Duration bounds how long each session keeps issuing operations.
+	Duration time.Duration
+	// Iterations bounds how many operations each session issues.
+	// At least one of Duration and Iterations must be set; when both are
+	// set, whichever is reached first ends the session.
+	Iterations int
+	// ThinkTime is an optional pause between operations in a session.
+	ThinkTime time.Duration
+	// Seed makes the operation mix reproducible. Session i uses Seed+i.
+	Seed int64
+	// Operations is the weighted mix of operations to run.
+	Operations []Operation
+}
+
+// ErrInvalidScenario is returned by Run when the scenario cannot be executed.
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+func (s Scenario) validate() error {
+	if len(s.Operations) == 0 {
+		return fmt.Errorf("%w: no operations", ErrInvalidScenario)
+	}
+	if s.Duration <= 0 && s.Iterations <= 0 {
+		return fmt.Errorf("%w: one of duration or iterations must be set", ErrInvalidScenario)
+	}
+	for i, op := range s.Operations {
+		if op.Run == nil {
+			return fmt.Errorf("%w: operation %d (%q) has no Run function", ErrInvalidScenario, i, op.Name)
+		}
+	}
+	return nil
+}
+
+// Run executes the scenario and returns a report. Failed operations are
+// counted in the report rather than aborting the run; an error is only
+// returned when the scenario is invalid or ctx is cancelled before any
+// session could connect.
+func Run(ctx context.Context, scenario Scenario, factory ClientFactory) (*Report, error) {
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+	if scenario.Sessions <= 0 {
+		scenario.Sessions = 1
+	}
+
+	rec := newRecorder()
+	before := takeSnapshot()
+	start := time.Now()
+
+	runCtx := ctx
+	if scenario.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, scenario.Duration)
+		defer cancel()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < scenario.Sessions; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			runSession(runCtx, scenario, factory, index, rec)
+		}(i)
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	after := takeSnapshot()
+
+	report := rec.report(scenario, elapsed, before, after)
+	if report.ConnectErrors == scenario.Sessions && ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+	return report, nil
+}
+
+func runSession(ctx context.Context, scenario Scenario, factory ClientFactory, index int, rec *recorder) {
+	c, err := connect(ctx, factory)
+	if err != nil {
+		rec.connectFailed()
+		return
+	}
+	defer c.Close()
+
+	picker := newPicker(scenario.Operations, scenario.Seed+int64(index))
+	for i := 0; scenario.Iterations <= 0 || i < scenario.Iterations; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+
+		op := picker.next()
+		opStart := time.Now()
+		opErr := op.Run(ctx, c)
+		latency := time.Since(opStart)
+
+		// Operations interrupted by the end of the run are not failures.
+		if opErr != nil && ctx.Err() != nil {
+			return
+		}
+		rec.record(op.Name, latency, opErr)
+
+		if scenario.ThinkTime > 0 {
+			timer := time.NewTimer(scenario.ThinkTime)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+func connect(ctx context.Context, factory ClientFactory) (*client.Client, error) {
+	c, err := factory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	var initRequest mcp.InitializeRequest
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcpbench", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// picker selects operations according to their weights.
+type picker struct {
+	ops    []Operation
+	total  int
+	random *rand.Rand
+}
+
+func newPicker(ops []Operation, seed int64) *picker {
+	p := &picker{ops: ops, random: rand.New(rand.NewSource(seed))}
+	for _, op := range ops {
+		p.total += max(op.Weight, 1)
+	}
+	return p
+}
+
+func (p *picker) next() Operation {
+	n := p.random.Intn(p.total)
+	for _, op := range p.ops {
+		n -= max(op.Weight, 1)
+		if n < 0 {
+			return op
+		}
+	}
+	return p.ops[len(p.ops)-1]
+}
+
+type snapshot struct {
+	goroutines int
+	heapAlloc  uint64
+}
+
+func takeSnapshot() snapshot {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return snapshot{goroutines: runtime.NumGoroutine(), heapAlloc: stats.HeapAlloc}
+}
+
+//
+// Client factories
+//
+
+// InProcess returns a factory that connects sessions directly to srv.
+// Goroutine and memory figures in the report then include the server.
+func InProcess(srv *server.MCPServer) ClientFactory {
+	return func(ctx context.Context) (*client.Client, error) {
+		return client.NewInProcessClient(srv)
+	}
+}
+
+// StreamableHTTP returns a factory that connects each session to the
+// streamable HTTP endpoint at url.
+func StreamableHTTP(url string, options ...transport.StreamableHTTPCOption) ClientFactory {
+	return func(ctx context.Context) (*client.Client, error) {
+		trans, err := transport.NewStreamableHTTP(url, options...)
+		if err != nil {
+			return nil, err
+		}
+		return client.NewClient(trans), nil
+	}
+}
+
+// Stdio returns a factory that launches one server subprocess per session.
+func Stdio(command string, env []string, args ...string) ClientFactory {
+	return func(ctx context.Context) (*client.Client, error) {
+		return client.NewClient(transport.NewStdio(command, env, args...)), nil
+	}
+}
+
+//
+// Operations
+//
+
+// CallTool returns an operation that calls a tool and waits for its result.
+// Tool results flagged with isError count as failures.
+func CallTool(name string, arguments map[string]any) Operation {
+	return Operation{
+		Name:   "tools/call " + name,
+		Weight: 1,
+		Run: func(ctx context.Context, c *client.Client) error {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = name
+			request.Params.Arguments = arguments
+			result, err := c.CallTool(ctx, request)
+			if err != nil {
+				return err
+			}
+			if result.IsError {
+				return fmt.Errorf("tool %s returned an error result", name)
+			}
+			return nil
+		},
+	}
+}
+
+// CallToolAsTask returns an operation that calls a tool as a task, polls the
+// task until it finishes and fetches its result. The recorded latency covers
+// the whole lifecycle.
+func CallToolAsTask(name string, arguments map[string]any) Operation {
+	return Operation{
+		Name:   "task " + name,
+		Weight: 1,
+		Run: func(ctx context.Context, c *client.Client) error {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = name
+			request.Params.Arguments = arguments
+			created, err := c.CallToolAsTask(ctx, request)
+			if err != nil {
+				return err
+			}
+			task, err := c.AwaitTask(ctx, created.Task.TaskId)
+			if err != nil {
+				return err
+			}
+			if task.Status != mcp.TaskStatusCompleted {
+				return fmt.Errorf("task %s ended with status %s", task.TaskId, task.Status)
+			}
+			_, err = c.GetToolTaskResult(ctx, mcp.TaskResultRequest{
+				Params: mcp.TaskResultParams{TaskId: task.TaskId},
+			})
+			return err
+		},
+	}
+}
+
+// ReadResource returns an operation that reads the resource at uri.
+func ReadResource(uri string) Operation {
+	return Operation{
+		Name:   "resources/read " + uri,
+		Weight: 1,
+		Run: func(ctx context.Context, c *client.Client) error {
+			request := mcp.ReadResourceRequest{}
+			request.Params.URI = uri
+			_, err := c.ReadResource(ctx, request)
+			return err
+		},
+	}
+}
+
+// ListTools returns an operation that lists all tools.
+func ListTools() Operation {
+	return Operation{
+		Name:   "tools/list",
+		Weight: 1,
+		Run: func(ctx context.Context, c *client.Client) error {
+			_, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+			return err
+		},
+	}
+}
+
+// Ping returns an operation that pings the server.
+func Ping() Operation {
+	return Operation{
+		Name:   "ping",
+		Weight: 1,
+		Run: func(ctx context.Context, c *client.Client) error {
+			return c.Ping(ctx)
+		},
+	}
+}
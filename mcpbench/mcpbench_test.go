@@ -0,0 +1,185 @@
+package mcpbench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBenchServer(t *testing.T) *server.MCPServer {
+	t.Helper()
+	srv := server.NewMCPServer("bench", "1.0.0")
+	srv.AddTool(mcp.NewTool("echo", mcp.WithString("text")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(request.GetString("text", "")), nil
+		})
+	srv.AddTool(mcp.NewTool("broken"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("always fails"), nil
+		})
+	srv.AddResource(mcp.NewResource("docs://readme", "readme"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: "docs://readme", Text: "hi"}}, nil
+		})
+	return srv
+}
+
+func TestRun_Iterations(t *testing.T) {
+	srv := newBenchServer(t)
+
+	report, err := Run(context.Background(), Scenario{
+		Name:       "mixed",
+		Sessions:   4,
+		Iterations: 25,
+		Seed:       42,
+		Operations: []Operation{
+			CallTool("echo", map[string]any{"text": "hi"}).WithWeight(3),
+			CallTool("broken", nil),
+			ReadResource("docs://readme"),
+			ListTools(),
+		},
+	}, InProcess(srv))
+	require.NoError(t, err)
+
+	assert.Equal(t, "mixed", report.Scenario)
+	assert.Equal(t, 4, report.Sessions)
+	assert.Zero(t, report.ConnectErrors)
+	assert.Equal(t, 100, report.Total.Count)
+
+	var broken *OperationStats
+	for i := range report.Operations {
+		if report.Operations[i].Name == "tools/call broken" {
+			broken = &report.Operations[i]
+		}
+	}
+	require.NotNil(t, broken, "the weighted mix should have picked the broken tool")
+	assert.Equal(t, broken.Count, broken.Errors)
+	assert.Equal(t, broken.Errors, report.Total.Errors)
+	assert.Contains(t, broken.FirstError, "error result")
+	assert.LessOrEqual(t, report.Total.P50, report.Total.P99)
+	assert.LessOrEqual(t, report.Total.P99, report.Total.Max)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "tools/call echo")
+	assert.Contains(t, out.String(), "throughput")
+}
+
+func TestRun_Duration(t *testing.T) {
+	var calls atomic.Int64
+	op := Operation{
+		Name: "custom",
+		Run: func(ctx context.Context, c *client.Client) error {
+			calls.Add(1)
+			return c.Ping(ctx)
+		},
+	}
+
+	report, err := Run(context.Background(), Scenario{
+		Sessions:   2,
+		Duration:   50 * time.Millisecond,
+		ThinkTime:  time.Millisecond,
+		Operations: []Operation{op},
+	}, InProcess(newBenchServer(t)))
+	require.NoError(t, err)
+
+	assert.Positive(t, report.Total.Count)
+	assert.Equal(t, int(calls.Load()), report.Total.Count)
+	assert.Zero(t, report.Total.Errors)
+	assert.GreaterOrEqual(t, report.Elapsed, 50*time.Millisecond)
+}
+
+func TestRun_ConnectErrors(t *testing.T) {
+	factory := func(ctx context.Context) (*client.Client, error) {
+		return nil, errors.New("refused")
+	}
+
+	report, err := Run(context.Background(), Scenario{
+		Sessions:   3,
+		Iterations: 1,
+		Operations: []Operation{Ping()},
+	}, factory)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.ConnectErrors)
+	assert.Zero(t, report.Total.Count)
+}
+
+func TestRun_InvalidScenario(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario Scenario
+	}{
+		{name: "no operations", scenario: Scenario{Iterations: 1}},
+		{name: "no bound", scenario: Scenario{Operations: []Operation{Ping()}}},
+		{name: "nil run", scenario: Scenario{Iterations: 1, Operations: []Operation{{Name: "x"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(context.Background(), tt.scenario, InProcess(newBenchServer(t)))
+			assert.ErrorIs(t, err, ErrInvalidScenario)
+		})
+	}
+}
+
+func TestPicker_Deterministic(t *testing.T) {
+	ops := []Operation{{Name: "a", Weight: 1}, {Name: "b", Weight: 5}, {Name: "c"}}
+	first, second := newPicker(ops, 7), newPicker(ops, 7)
+	counts := map[string]int{}
+	for i := 0; i < 700; i++ {
+		a, b := first.next(), second.next()
+		require.Equal(t, a.Name, b.Name)
+		counts[a.Name]++
+	}
+	assert.Greater(t, counts["b"], counts["a"])
+	assert.Greater(t, counts["b"], counts["c"])
+}
+
+func TestPercentile(t *testing.T) {
+	samples := make([]time.Duration, 100)
+	for i := range samples {
+		samples[i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(samples, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(samples, 99))
+	assert.Equal(t, time.Millisecond, percentile(samples[:1], 99))
+	assert.Zero(t, percentile(nil, 50))
+}
+
+func TestLoadScenario(t *testing.T) {
+	scenario, err := LoadScenario(strings.NewReader(`{
+		"name": "file",
+		"sessions": 3,
+		"duration": "2s",
+		"thinkTime": "10ms",
+		"operations": [
+			{"type": "call", "tool": "echo", "arguments": {"text": "x"}, "weight": 4},
+			{"type": "task", "tool": "slow"},
+			{"type": "read", "uri": "docs://readme"},
+			{"type": "list_tools"},
+			{"type": "ping"}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, scenario.Sessions)
+	assert.Equal(t, 2*time.Second, scenario.Duration)
+	assert.Equal(t, 10*time.Millisecond, scenario.ThinkTime)
+	require.Len(t, scenario.Operations, 5)
+	assert.Equal(t, 4, scenario.Operations[0].Weight)
+	assert.Equal(t, "task slow", scenario.Operations[1].Name)
+
+	_, err = LoadScenario(strings.NewReader(`{"iterations": 1, "operations": [{"type": "call"}]}`))
+	assert.ErrorIs(t, err, ErrInvalidScenario)
+
+	_, err = LoadScenario(strings.NewReader(`{"duration": "soon", "operations": [{"type": "ping"}]}`))
+	assert.ErrorIs(t, err, ErrInvalidScenario)
+}
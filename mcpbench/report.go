@@ -0,0 +1,203 @@
+package mcpbench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// OperationStats summarizes the latencies and failures of one operation.
+type OperationStats struct {
+	Name   string        `json:"name"`
+	Count  int           `json:"count"`
+	Errors int           `json:"errors"`
+	Mean   time.Duration `json:"mean"`
+	P50    time.Duration `json:"p50"`
+	P90    time.Duration `json:"p90"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+	// FirstError is the message of the first failure, to help diagnose runs
+	// with a high error rate.
+	FirstError string `json:"firstError,omitempty"`
+}
+
+// ErrorRate returns the fraction of failed operations.
+func (s OperationStats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Count)
+}
+
+// Report is the outcome of a scenario run.
+type Report struct {
+	Scenario      string        `json:"scenario,omitempty"`
+	Sessions      int           `json:"sessions"`
+	ConnectErrors int           `json:"connectErrors"`
+	Elapsed       time.Duration `json:"elapsed"`
+	// Throughput is the number of completed operations per second.
+	Throughput float64 `json:"throughput"`
+	// Total aggregates every operation.
+	Total OperationStats `json:"total"`
+	// Operations holds per-operation statistics sorted by name.
+	Operations []OperationStats `json:"operations"`
+	// GoroutinesBefore and GoroutinesAfter are sampled in the benchmarking
+	// process after a GC; for in-process servers they include the server.
+	GoroutinesBefore int `json:"goroutinesBefore"`
+	GoroutinesAfter  int `json:"goroutinesAfter"`
+	// HeapBefore and HeapAfter are the live heap sizes in bytes.
+	HeapBefore uint64 `json:"heapBefore"`
+	HeapAfter  uint64 `json:"heapAfter"`
+}
+
+// GoroutineGrowth returns how many more goroutines exist after the run.
+func (r *Report) GoroutineGrowth() int {
+	return r.GoroutinesAfter - r.GoroutinesBefore
+}
+
+// HeapGrowth returns the change in live heap bytes across the run.
+func (r *Report) HeapGrowth() int64 {
+	return int64(r.HeapAfter) - int64(r.HeapBefore)
+}
+
+// WriteText writes a human-readable summary of the report.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if r.Scenario != "" {
+		fmt.Fprintf(tw, "scenario:\t%s\n", r.Scenario)
+	}
+	fmt.Fprintf(tw, "sessions:\t%d (%d failed to connect)\n", r.Sessions, r.ConnectErrors)
+	fmt.Fprintf(tw, "elapsed:\t%s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "throughput:\t%.1f ops/s\n", r.Throughput)
+	fmt.Fprintf(tw, "goroutines:\t%d -> %d (%+d)\n", r.GoroutinesBefore, r.GoroutinesAfter, r.GoroutineGrowth())
+	fmt.Fprintf(tw, "heap:\t%d -> %d bytes (%+d)\n", r.HeapBefore, r.HeapAfter, r.HeapGrowth())
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "operation\tcount\terrors\tmean\tp50\tp90\tp99\tmax")
+	for _, s := range append(r.Operations, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d (%.1f%%)\t%s\t%s\t%s\t%s\t%s\n",
+			s.Name, s.Count, s.Errors, s.ErrorRate()*100,
+			s.Mean.Round(time.Microsecond), s.P50.Round(time.Microsecond),
+			s.P90.Round(time.Microsecond), s.P99.Round(time.Microsecond),
+			s.Max.Round(time.Microsecond))
+	}
+	return tw.Flush()
+}
+
+// recorder collects samples from concurrent sessions.
+type recorder struct {
+	mu            sync.Mutex
+	samples       map[string][]time.Duration
+	errors        map[string]int
+	firstErrors   map[string]string
+	connectErrors int
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		samples:     make(map[string][]time.Duration),
+		errors:      make(map[string]int),
+		firstErrors: make(map[string]string),
+	}
+}
+
+func (r *recorder) record(name string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[name] = append(r.samples[name], latency)
+	if err != nil {
+		r.errors[name]++
+		if _, ok := r.firstErrors[name]; !ok {
+			r.firstErrors[name] = err.Error()
+		}
+	}
+}
+
+func (r *recorder) connectFailed() {
+	r.mu.Lock()
+	r.connectErrors++
+	r.mu.Unlock()
+}
+
+func (r *recorder) report(scenario Scenario, elapsed time.Duration, before, after snapshot) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		Scenario:         scenario.Name,
+		Sessions:         scenario.Sessions,
+		ConnectErrors:    r.connectErrors,
+		Elapsed:          elapsed,
+		GoroutinesBefore: before.goroutines,
+		GoroutinesAfter:  after.goroutines,
+		HeapBefore:       before.heapAlloc,
+		HeapAfter:        after.heapAlloc,
+	}
+
+	names := make([]string, 0, len(r.samples))
+	for name := range r.samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var all []time.Duration
+	totalErrors := 0
+	firstError := ""
+	for _, name := range names {
+		samples := r.samples[name]
+		all = append(all, samples...)
+		totalErrors += r.errors[name]
+		if firstError == "" {
+			firstError = r.firstErrors[name]
+		}
+		stats := summarize(name, samples)
+		stats.Errors = r.errors[name]
+		stats.FirstError = r.firstErrors[name]
+		report.Operations = append(report.Operations, stats)
+	}
+
+	report.Total = summarize("total", all)
+	report.Total.Errors = totalErrors
+	report.Total.FirstError = firstError
+	if elapsed > 0 {
+		report.Throughput = float64(len(all)) / elapsed.Seconds()
+	}
+	return report
+}
+
+func summarize(name string, samples []time.Duration) OperationStats {
+	stats := OperationStats{Name: name, Count: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	stats.Mean = sum / time.Duration(len(sorted))
+	stats.P50 = percentile(sorted, 50)
+	stats.P90 = percentile(sorted, 90)
+	stats.P99 = percentile(sorted, 99)
+	stats.Max = sorted[len(sorted)-1]
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,116 @@
+package mcpbench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ScenarioConfig is the JSON representation of a scenario, used by the
+// mcpbench command to load scenarios from files:
+//
+//	{
+//	  "name": "mixed",
+//	  "sessions": 20,
+//	  "duration": "30s",
+//	  "operations": [
+//	    {"type": "call", "tool": "search", "arguments": {"q": "coffee"}, "weight": 8},
+//	    {"type": "task", "tool": "reindex", "weight": 1},
+//	    {"type": "read", "uri": "docs://readme", "weight": 1}
+//	  ]
+//	}
+type ScenarioConfig struct {
+	Name       string            `json:"name,omitempty"`
+	Sessions   int               `json:"sessions,omitempty"`
+	Duration   string            `json:"duration,omitempty"`
+	Iterations int               `json:"iterations,omitempty"`
+	ThinkTime  string            `json:"thinkTime,omitempty"`
+	Seed       int64             `json:"seed,omitempty"`
+	Operations []OperationConfig `json:"operations"`
+}
+
+// OperationConfig describes one operation of a ScenarioConfig.
+// Type is one of "call", "task", "read", "list_tools" or "ping".
+type OperationConfig struct {
+	Type      string         `json:"type"`
+	Tool      string         `json:"tool,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	URI       string         `json:"uri,omitempty"`
+	Weight    int            `json:"weight,omitempty"`
+}
+
+// LoadScenario parses a JSON ScenarioConfig into a Scenario.
+func LoadScenario(r io.Reader) (Scenario, error) {
+	var config ScenarioConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return Scenario{}, fmt.Errorf("failed to decode scenario: %w", err)
+	}
+	return config.Scenario()
+}
+
+// Scenario converts the configuration into a runnable Scenario.
+func (c ScenarioConfig) Scenario() (Scenario, error) {
+	scenario := Scenario{
+		Name:       c.Name,
+		Sessions:   c.Sessions,
+		Iterations: c.Iterations,
+		Seed:       c.Seed,
+	}
+
+	var err error
+	if c.Duration != "" {
+		if scenario.Duration, err = time.ParseDuration(c.Duration); err != nil {
+			return Scenario{}, fmt.Errorf("%w: duration: %v", ErrInvalidScenario, err)
+		}
+	}
+	if c.ThinkTime != "" {
+		if scenario.ThinkTime, err = time.ParseDuration(c.ThinkTime); err != nil {
+			return Scenario{}, fmt.Errorf("%w: thinkTime: %v", ErrInvalidScenario, err)
+		}
+	}
+
+	for i, opConfig := range c.Operations {
+		op, err := opConfig.operation()
+		if err != nil {
+			return Scenario{}, fmt.Errorf("%w: operation %d: %v", ErrInvalidScenario, i, err)
+		}
+		scenario.Operations = append(scenario.Operations, op)
+	}
+
+	if err := scenario.validate(); err != nil {
+		return Scenario{}, err
+	}
+	return scenario, nil
+}
+
+func (c OperationConfig) operation() (Operation, error) {
+	var op Operation
+	switch c.Type {
+	case "call":
+		if c.Tool == "" {
+			return Operation{}, fmt.Errorf("call requires a tool")
+		}
+		op = CallTool(c.Tool, c.Arguments)
+	case "task":
+		if c.Tool == "" {
+			return Operation{}, fmt.Errorf("task requires a tool")
+		}
+		op = CallToolAsTask(c.Tool, c.Arguments)
+	case "read":
+		if c.URI == "" {
+			return Operation{}, fmt.Errorf("read requires a uri")
+		}
+		op = ReadResource(c.URI)
+	case "list_tools":
+		op = ListTools()
+	case "ping":
+		op = Ping()
+	default:
+		return Operation{}, fmt.Errorf("unknown operation type %q", c.Type)
+	}
+	if c.Weight > 0 {
+		op.Weight = c.Weight
+	}
+	return op, nil
+}
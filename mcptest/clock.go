@@ -0,0 +1,178 @@
+package mcptest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// FakeClock is a clock that only moves when told to, for testing code that
+// waits. It implements server.Clock, so server.WithClock puts task TTLs,
+// keepalives and timeouts on it, and handlers under test can wait on it with
+// After or Sleep. Tests move it with Advance.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // closed and replaced when waiters change
+}
+
+var _ server.Clock = (*FakeClock)(nil)
+
+// fakeWaiter is a pending timer, or a ticker if period is positive.
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a FakeClock reading start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the clock's time once Advance moves
+// it d or more past the current time.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.wait(d, 0).ch
+}
+
+// NewTimer creates a timer that fires once Advance moves the clock d or more
+// past the current time.
+func (c *FakeClock) NewTimer(d time.Duration) server.Timer {
+	return c.wait(d, 0)
+}
+
+// NewTicker creates a ticker that fires each time Advance moves the clock
+// past another multiple of d. Like time.Ticker, it drops the ticks a slow
+// receiver misses.
+func (c *FakeClock) NewTicker(d time.Duration) server.Ticker {
+	if d <= 0 {
+		panic("mcptest: non-positive interval for NewTicker")
+	}
+	return fakeTicker{c.wait(d, d)}
+}
+
+// Sleep waits until the clock moves d past the current time or ctx is done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	w := c.wait(d, 0)
+	select {
+	case <-w.ch:
+		return nil
+	case <-ctx.Done():
+		w.Stop()
+		return ctx.Err()
+	}
+}
+
+func (c *FakeClock) wait(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, deadline: c.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- c.now
+		return w
+	}
+	c.waiters = append(c.waiters, w)
+	c.notifyLocked()
+	return w
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers whose
+// deadline it reaches in deadline order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+	})
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+		if w.period > 0 {
+			for !w.deadline.After(c.now) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+	c.notifyLocked()
+}
+
+// Waiters returns how many timers, tickers, After and Sleep calls wait for
+// the clock.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers, tickers, After or Sleep calls
+// wait for the clock or ctx is done, so that a following Advance is sure to
+// wake them.
+func (c *FakeClock) BlockUntil(ctx context.Context, n int) error {
+	for {
+		c.mu.Lock()
+		count, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *FakeClock) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// C returns the channel the timer fires on.
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop removes the timer from the clock. It returns false if the timer
+// already fired or was stopped.
+func (w *fakeWaiter) Stop() bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.fakeWaiter.Stop() }
@@ -0,0 +1,363 @@
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultTaskWaitTimeout bounds how long FakeTaskEnvironment waits for a
+// task before failing the test.
+const DefaultTaskWaitTimeout = 5 * time.Second
+
+// taskStartTime is the time a FakeTaskEnvironment's clock starts at.
+var taskStartTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FakeTaskEnvironment runs task tool handlers in an MCP server without a
+// transport, with controlled time, scripted elicitation answers and forced
+// failures, so that tests of task handlers need neither real sleeps nor
+// races with the goroutines running the tasks.
+//
+// The server runs on the environment's Clock, so task TTLs only expire when
+// the test advances it. Handlers wait with the Clock too, rather than with
+// the time package, and ask for input with MCPServer.RequestInput, which is
+// answered from the script:
+//
+//	env := mcptest.NewFakeTaskEnvironment(t)
+//	env.AddTool(mcp.NewTool("make_espresso"), makeEspresso(env.Clock()))
+//	taskID := env.StartTask("make_espresso", nil)
+//	env.AwaitStatus(taskID, mcp.TaskStatusInputRequired)
+//	env.AnswerElicitation(&mcp.ElicitationResult{...})
+//	env.AdvanceWhenWaiting(1, 25*time.Second)
+//	result := env.Result(taskID)
+type FakeTaskEnvironment struct {
+	t       testing.TB
+	server  *server.MCPServer
+	session *fakeTaskSession
+	clock   *FakeClock
+	ctx     context.Context
+	nextID  int
+
+	mu       sync.Mutex
+	failures map[string][]error // forced failures by tool name
+}
+
+// NewFakeTaskEnvironment creates an environment around a new MCP server with
+// task support and the given options, connected to a client that supports
+// elicitation.
+func NewFakeTaskEnvironment(t testing.TB, opts ...server.ServerOption) *FakeTaskEnvironment {
+	t.Helper()
+	env := &FakeTaskEnvironment{
+		t:        t,
+		clock:    NewFakeClock(taskStartTime),
+		failures: make(map[string][]error),
+		session: &fakeTaskSession{
+			id:            "fake-task-session",
+			notifications: make(chan mcp.JSONRPCNotification, 100),
+			answers:       make(chan fakeElicitationAnswer, 100),
+		},
+	}
+
+	opts = append([]server.ServerOption{
+		server.WithClock(env.clock),
+		server.WithTaskCapabilities(true, true, true),
+		server.WithToolHandlerMiddleware(env.failureMiddleware),
+	}, opts...)
+	env.server = server.NewMCPServer(t.Name(), "1.0.0", opts...)
+
+	if err := env.server.RegisterSession(context.Background(), env.session); err != nil {
+		t.Fatalf("RegisterSession(): %v", err)
+	}
+	t.Cleanup(func() { env.server.UnregisterSession(context.Background(), env.session.id) })
+	env.ctx = env.server.WithContext(context.Background(), env.session)
+
+	env.call(mcp.MethodInitialize, map[string]any{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"capabilities":    map[string]any{"elicitation": map[string]any{}},
+		"clientInfo":      map[string]any{"name": "fake-task-client", "version": "1.0.0"},
+	}, nil)
+	return env
+}
+
+// Server returns the environment's MCP server.
+func (e *FakeTaskEnvironment) Server() *server.MCPServer {
+	return e.server
+}
+
+// Clock returns the clock task handlers under test should wait with.
+func (e *FakeTaskEnvironment) Clock() *FakeClock {
+	return e.clock
+}
+
+// AddTool adds a tool to the server.
+func (e *FakeTaskEnvironment) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	e.server.AddTool(tool, handler)
+}
+
+// StartTask calls a tool as a task and returns the task's ID without
+// waiting for it.
+func (e *FakeTaskEnvironment) StartTask(name string, arguments map[string]any) string {
+	e.t.Helper()
+	return e.startTask(name, arguments, map[string]any{})
+}
+
+// StartTaskWithTTL is StartTask for a task the server keeps for ttl.
+func (e *FakeTaskEnvironment) StartTaskWithTTL(name string, arguments map[string]any, ttl time.Duration) string {
+	e.t.Helper()
+	return e.startTask(name, arguments, map[string]any{"ttl": ttl.Milliseconds()})
+}
+
+func (e *FakeTaskEnvironment) startTask(name string, arguments map[string]any, task map[string]any) string {
+	e.t.Helper()
+	var result mcp.CreateTaskResult
+	e.call(mcp.MethodToolsCall, map[string]any{
+		"name":      name,
+		"arguments": arguments,
+		"task":      task,
+	}, &result)
+	return result.Task.TaskId
+}
+
+// Task returns the current state of a task.
+func (e *FakeTaskEnvironment) Task(taskID string) mcp.Task {
+	e.t.Helper()
+	var result mcp.GetTaskResult
+	e.call(mcp.MethodTasksGet, map[string]any{"taskId": taskID}, &result)
+	return result.Task
+}
+
+// AwaitStatus waits until a task has the given status, failing the test if
+// it does not within DefaultTaskWaitTimeout.
+func (e *FakeTaskEnvironment) AwaitStatus(taskID string, status mcp.TaskStatus) mcp.Task {
+	e.t.Helper()
+	deadline := time.Now().Add(DefaultTaskWaitTimeout)
+	for {
+		task := e.Task(taskID)
+		if task.Status == status {
+			return task
+		}
+		if time.Now().After(deadline) {
+			e.t.Fatalf("task %s is %s, want %s", taskID, task.Status, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Result waits for a task to finish and returns the tool result. It fails
+// the test if the task failed; use Err to inspect failures.
+func (e *FakeTaskEnvironment) Result(taskID string) *mcp.CallToolResult {
+	e.t.Helper()
+	result, err := e.result(taskID)
+	if err != nil {
+		e.t.Fatalf("task %s failed: %v", taskID, err)
+	}
+	return result
+}
+
+// Err waits for a task to finish and returns why it failed, or nil if it
+// completed.
+func (e *FakeTaskEnvironment) Err(taskID string) error {
+	e.t.Helper()
+	_, err := e.result(taskID)
+	return err
+}
+
+func (e *FakeTaskEnvironment) result(taskID string) (*mcp.CallToolResult, error) {
+	e.t.Helper()
+	ctx, cancel := context.WithTimeout(e.ctx, DefaultTaskWaitTimeout)
+	defer cancel()
+	raw, err := e.send(ctx, mcp.MethodTasksResult, map[string]any{"taskId": taskID})
+	if err != nil {
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+// Cancel cancels a task.
+func (e *FakeTaskEnvironment) Cancel(taskID string) {
+	e.t.Helper()
+	e.call(mcp.MethodTasksCancel, map[string]any{"taskId": taskID}, nil)
+}
+
+// Advance moves the clock forward by d.
+func (e *FakeTaskEnvironment) Advance(d time.Duration) {
+	e.clock.Advance(d)
+}
+
+// AdvanceWhenWaiting waits until n timers wait for the clock, then moves it
+// forward by d, so the handlers are sure to wake. Task TTLs and the
+// timeouts of unanswered elicitations count as timers.
+func (e *FakeTaskEnvironment) AdvanceWhenWaiting(n int, d time.Duration) {
+	e.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTaskWaitTimeout)
+	defer cancel()
+	if err := e.clock.BlockUntil(ctx, n); err != nil {
+		e.t.Fatalf("%d handlers wait for the clock, want %d", e.clock.Waiters(), n)
+	}
+	e.clock.Advance(d)
+}
+
+// AnswerElicitation queues the answer to the next elicitation request.
+// Requests without a queued answer wait for one.
+func (e *FakeTaskEnvironment) AnswerElicitation(result *mcp.ElicitationResult) {
+	e.session.answers <- fakeElicitationAnswer{result: result}
+}
+
+// FailElicitation makes the next elicitation request fail with err, as if
+// the client could not be reached.
+func (e *FakeTaskEnvironment) FailElicitation(err error) {
+	e.session.answers <- fakeElicitationAnswer{err: err}
+}
+
+// ElicitationRequests returns the elicitation requests made so far.
+func (e *FakeTaskEnvironment) ElicitationRequests() []mcp.ElicitationRequest {
+	e.session.mu.Lock()
+	defer e.session.mu.Unlock()
+	return append([]mcp.ElicitationRequest(nil), e.session.requests...)
+}
+
+// FailNextCall makes the next call of the named tool fail with err without
+// running its handler.
+func (e *FakeTaskEnvironment) FailNextCall(name string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures[name] = append(e.failures[name], err)
+}
+
+func (e *FakeTaskEnvironment) failureMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		e.mu.Lock()
+		failures := e.failures[request.Params.Name]
+		var err error
+		if len(failures) > 0 {
+			err, e.failures[request.Params.Name] = failures[0], failures[1:]
+		}
+		e.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
+
+// call sends a request and decodes its result into result, failing the
+// test on errors.
+func (e *FakeTaskEnvironment) call(method mcp.MCPMethod, params any, result any) {
+	e.t.Helper()
+	raw, err := e.send(e.ctx, method, params)
+	if err != nil {
+		e.t.Fatalf("%s: %v", method, err)
+	}
+	if result != nil {
+		if err := json.Unmarshal(raw, result); err != nil {
+			e.t.Fatalf("%s: decoding result: %v", method, err)
+		}
+	}
+}
+
+// send handles a request with the server and returns the encoded result.
+func (e *FakeTaskEnvironment) send(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error) {
+	e.mu.Lock()
+	e.nextID++
+	id := e.nextID
+	e.mu.Unlock()
+
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch response := e.server.HandleMessage(ctx, message).(type) {
+	case mcp.JSONRPCResponse:
+		return json.Marshal(response.Result)
+	case mcp.JSONRPCError:
+		return nil, errors.New(response.Error.Message)
+	default:
+		return nil, fmt.Errorf("unexpected response %T", response)
+	}
+}
+
+// fakeTaskSession is the session of a FakeTaskEnvironment. It answers
+// elicitation requests from the script.
+type fakeTaskSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	answers       chan fakeElicitationAnswer
+	initialized   bool
+
+	mu           sync.Mutex
+	clientInfo   mcp.Implementation
+	capabilities mcp.ClientCapabilities
+	requests     []mcp.ElicitationRequest
+}
+
+type fakeElicitationAnswer struct {
+	result *mcp.ElicitationResult
+	err    error
+}
+
+func (s *fakeTaskSession) SessionID() string { return s.id }
+
+func (s *fakeTaskSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *fakeTaskSession) Initialize() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initialized = true
+}
+
+func (s *fakeTaskSession) Initialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialized
+}
+
+func (s *fakeTaskSession) GetClientInfo() mcp.Implementation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientInfo
+}
+
+func (s *fakeTaskSession) SetClientInfo(clientInfo mcp.Implementation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientInfo = clientInfo
+}
+
+func (s *fakeTaskSession) GetClientCapabilities() mcp.ClientCapabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capabilities
+}
+
+func (s *fakeTaskSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = capabilities
+}
+
+func (s *fakeTaskSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	s.mu.Unlock()
+
+	select {
+	case answer := <-s.answers:
+		return answer.result, answer.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
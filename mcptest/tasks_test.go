@@ -0,0 +1,181 @@
+package mcptest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// makeEspresso asks for the shot size, then brews for 25 seconds.
+func makeEspresso(clock *mcptest.FakeClock) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		answer, err := server.ServerFromContext(ctx).RequestInput(ctx, mcp.ElicitationRequest{
+			Params: mcp.ElicitationParams{
+				Message:         "Single or double?",
+				RequestedSchema: map[string]any{"type": "object"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if answer.Action != mcp.ElicitationResponseActionAccept {
+			return mcp.NewToolResultText("no coffee"), nil
+		}
+		if err := clock.Sleep(ctx, 25*time.Second); err != nil {
+			return nil, err
+		}
+		size := answer.Content.(map[string]any)["size"].(string)
+		return mcp.NewToolResultText(size + " espresso"), nil
+	}
+}
+
+func TestFakeTaskEnvironment(t *testing.T) {
+	env := mcptest.NewFakeTaskEnvironment(t)
+	env.AddTool(mcp.NewTool("make_espresso"), makeEspresso(env.Clock()))
+
+	taskID := env.StartTask("make_espresso", nil)
+	task := env.AwaitStatus(taskID, mcp.TaskStatusInputRequired)
+	if task.StatusMessage != "Single or double?" {
+		t.Errorf("status message = %q", task.StatusMessage)
+	}
+
+	env.AnswerElicitation(&mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: map[string]any{"size": "double"},
+	}})
+	env.AwaitStatus(taskID, mcp.TaskStatusWorking)
+
+	start := env.Clock().Now()
+	env.AdvanceWhenWaiting(1, 10*time.Second)
+	if status := env.Task(taskID).Status; status != mcp.TaskStatusWorking {
+		t.Fatalf("task is %s after 10s, want working", status)
+	}
+	env.Advance(15 * time.Second)
+
+	result := env.Result(taskID)
+	if text := result.Content[0].(mcp.TextContent).Text; text != "double espresso" {
+		t.Errorf("result = %q", text)
+	}
+	if elapsed := env.Clock().Now().Sub(start); elapsed != 25*time.Second {
+		t.Errorf("clock moved %v", elapsed)
+	}
+	if requests := env.ElicitationRequests(); len(requests) != 1 {
+		t.Errorf("got %d elicitation requests", len(requests))
+	}
+}
+
+func TestFakeTaskEnvironmentFailures(t *testing.T) {
+	env := mcptest.NewFakeTaskEnvironment(t)
+	env.AddTool(mcp.NewTool("make_espresso"), makeEspresso(env.Clock()))
+
+	env.FailNextCall("make_espresso", errors.New("grinder jammed"))
+	taskID := env.StartTask("make_espresso", nil)
+	if err := env.Err(taskID); err == nil || !strings.Contains(err.Error(), "grinder jammed") {
+		t.Errorf("Err() = %v", err)
+	}
+	if requests := env.ElicitationRequests(); len(requests) != 0 {
+		t.Errorf("handler ran despite the forced failure")
+	}
+
+	env.FailElicitation(errors.New("client went away"))
+	taskID = env.StartTask("make_espresso", nil)
+	if err := env.Err(taskID); err == nil || !strings.Contains(err.Error(), "client went away") {
+		t.Errorf("Err() = %v", err)
+	}
+
+	taskID = env.StartTask("make_espresso", nil)
+	env.AnswerElicitation(&mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: map[string]any{"size": "single"},
+	}})
+	if err := env.Clock().BlockUntil(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	env.Cancel(taskID)
+	if status := env.Task(taskID).Status; status != mcp.TaskStatusCancelled {
+		t.Errorf("task is %s, want cancelled", status)
+	}
+	// The handler stops sleeping once its context is cancelled.
+	for deadline := time.Now().Add(time.Second); env.Clock().Waiters() != 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("cancelled handler still waits for the clock")
+		}
+	}
+}
+
+func TestFakeTaskEnvironmentTTL(t *testing.T) {
+	env := mcptest.NewFakeTaskEnvironment(t)
+	env.AddTool(mcp.NewTool("noop"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	taskID := env.StartTaskWithTTL("noop", nil, time.Minute)
+	env.AwaitStatus(taskID, mcp.TaskStatusCompleted)
+	env.AdvanceWhenWaiting(1, 59*time.Second)
+	env.Result(taskID)
+
+	env.Advance(time.Second)
+	for deadline := time.Now().Add(time.Second); env.Err(taskID) == nil; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("task outlived its TTL")
+		}
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := mcptest.NewFakeClock(start)
+
+	late := clock.After(2 * time.Second)
+	early := clock.After(time.Second)
+	select {
+	case <-clock.After(0):
+	default:
+		t.Error("After(0) did not fire immediately")
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-early:
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("early fired at %v", now)
+		}
+	default:
+		t.Error("early did not fire")
+	}
+	select {
+	case <-late:
+		t.Error("late fired too soon")
+	default:
+	}
+	if clock.Waiters() != 1 {
+		t.Errorf("Waiters() = %d, want 1", clock.Waiters())
+	}
+
+	clock.Advance(time.Second)
+	<-late
+
+	ticker := clock.NewTicker(time.Second)
+	clock.Advance(2500 * time.Millisecond)
+	select {
+	case <-ticker.C():
+	default:
+		t.Error("ticker did not tick")
+	}
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C():
+	default:
+		t.Error("ticker did not tick again")
+	}
+	ticker.Stop()
+	if clock.Waiters() != 0 {
+		t.Errorf("stopped ticker still waits")
+	}
+}
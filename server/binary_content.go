@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithBinaryContentLimits validates the image and audio content of sampling
+// requests and results and of elicitation answers against the limits.
+// Sampling requests with invalid content are not sent, and invalid results
+// and answers are returned as errors wrapping ErrInvalidBinaryContent.
+func WithBinaryContentLimits(limits mcp.BinaryContentLimits) ServerOption {
+	return func(s *MCPServer) {
+		s.binaryLimits = &limits
+	}
+}
+
+// checkSamplingMessages validates the binary content of sampling messages.
+func (s *MCPServer) checkSamplingMessages(direction string, messages ...mcp.SamplingMessage) error {
+	if s.binaryLimits == nil {
+		return nil
+	}
+	for i, message := range messages {
+		if err := mcp.ValidateBinaryContent(message.Content, *s.binaryLimits); err != nil {
+			if len(messages) > 1 {
+				return fmt.Errorf("%w: sampling %s message %d: %w", ErrInvalidBinaryContent, direction, i, err)
+			}
+			return fmt.Errorf("%w: sampling %s: %w", ErrInvalidBinaryContent, direction, err)
+		}
+	}
+	return nil
+}
+
+// checkElicitationAnswer validates the binary content parts of an accepted
+// elicitation answer.
+func (s *MCPServer) checkElicitationAnswer(result *mcp.ElicitationResult) error {
+	if s.binaryLimits == nil || result == nil {
+		return nil
+	}
+	fields, ok := result.Content.(map[string]any)
+	if !ok {
+		return nil
+	}
+	for name, value := range fields {
+		part, ok := value.(map[string]any)
+		if !ok || !mcp.IsBinaryContentPart(part) {
+			continue
+		}
+		content, err := mcp.ParseContent(part)
+		if err == nil {
+			err = mcp.ValidateBinaryContent(content, *s.binaryLimits)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: elicitation field %s: %w", ErrInvalidBinaryContent, name, err)
+		}
+	}
+	return nil
+}
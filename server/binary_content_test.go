@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWithBinaryContentLimits_Sampling(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	s := NewMCPServer("test", "1.0.0", WithBinaryContentLimits(mcp.BinaryContentLimits{
+		MaxBytes:  64,
+		MIMETypes: []string{"image/png", "audio/*"},
+	}))
+	session := &mockSamplingSession{
+		mockSession: mockSession{sessionID: "s1"},
+		result: &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewImageContent(png, "image/png")},
+			Model:           "test-model",
+		},
+	}
+	ctx := s.WithContext(context.Background(), session)
+	request := func(content any) mcp.CreateMessageRequest {
+		return mcp.CreateMessageRequest{CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{Role: mcp.RoleUser, Content: mcp.NewTextContent("describe this")},
+				{Role: mcp.RoleUser, Content: content},
+			},
+		}}
+	}
+
+	result, err := s.RequestSampling(ctx, request(mcp.NewImageContent(png, "image/png")))
+	require.NoError(t, err)
+	assert.Equal(t, "test-model", result.Model)
+
+	_, err = s.RequestSampling(ctx, request(mcp.NewImageContent(png, "image/gif")))
+	assert.ErrorIs(t, err, ErrInvalidBinaryContent)
+	assert.ErrorContains(t, err, "sampling request message 1: image content: MIME type image/gif is not accepted")
+
+	session.result.Content = mcp.NewImageContent(base64.StdEncoding.EncodeToString(make([]byte, 100)), "image/png")
+	_, err = s.RequestSampling(ctx, request(mcp.NewTextContent("again")))
+	assert.ErrorIs(t, err, ErrInvalidBinaryContent)
+	assert.ErrorContains(t, err, "sampling result: image content: larger than 64 bytes")
+}
+
+func TestWithBinaryContentLimits_Elicitation(t *testing.T) {
+	wav := base64.StdEncoding.EncodeToString([]byte("RIFF\x24\x00\x00\x00WAVEfmt "))
+	s := NewMCPServer("test", "1.0.0", WithBinaryContentLimits(mcp.BinaryContentLimits{MIMETypes: []string{"audio/*"}}))
+	session := &mockElicitationSession{
+		sessionID: "s1",
+		result: &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+			Action: mcp.ElicitationResponseActionAccept,
+			Content: map[string]any{
+				"memo": map[string]any{"type": "audio", "data": wav, "mimeType": "audio/wav"},
+			},
+		}},
+	}
+	ctx := s.WithContext(context.Background(), session)
+	request := mcp.ElicitationRequest{Params: mcp.ElicitationParams{
+		Message: "Record a voice memo",
+		RequestedSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"memo": mcp.BinaryContentSchema(mcp.ContentTypeAudio, "audio/*")},
+		},
+	}}
+
+	result, err := s.RequestElicitation(ctx, request)
+	require.NoError(t, err)
+	memo, err := result.BinaryContentField("memo")
+	require.NoError(t, err)
+	assert.Equal(t, mcp.NewAudioContent(wav, "audio/wav"), memo)
+
+	session.result.Content = map[string]any{
+		"memo": map[string]any{"type": "audio", "data": wav, "mimeType": "video/mp4"},
+	}
+	_, err = s.RequestElicitation(ctx, request)
+	assert.ErrorIs(t, err, ErrInvalidBinaryContent)
+	assert.ErrorContains(t, err, "elicitation field memo: audio content: MIME type video/mp4 is not an audio type")
+
+	// Tools asking through RequestInput, as dialogs do, get the same checks.
+	_, err = s.RequestInput(ctx, request)
+	assert.ErrorIs(t, err, ErrInvalidBinaryContent)
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ChaosFault identifies a kind of fault injected by WithChaos.
+type ChaosFault string
+
+const (
+	ChaosFaultLatency             ChaosFault = "latency"
+	ChaosFaultDroppedNotification ChaosFault = "dropped_notification"
+	ChaosFaultMalformedResult     ChaosFault = "malformed_result"
+	ChaosFaultTaskFailure         ChaosFault = "task_failure"
+	ChaosFaultDisconnect          ChaosFault = "disconnect"
+)
+
+// ChaosEvent records one injected fault.
+type ChaosEvent struct {
+	Fault     ChaosFault
+	Tool      string
+	SessionID string
+	// Detail describes the fault, e.g. the injected delay or the dropped
+	// notification's method.
+	Detail string
+}
+
+// ChaosOption configures WithChaos.
+type ChaosOption func(*chaosConfig)
+
+type chaosConfig struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	latencyRate       float64
+	minLatency        time.Duration
+	maxLatency        time.Duration
+	dropRate          float64
+	malformedRate     float64
+	taskFailureRate   float64
+	disconnectRate    float64
+	tools             []string
+	record            func(ChaosEvent)
+	unregisterSession func(ctx context.Context, sessionID string)
+}
+
+// WithChaosSeed seeds the fault generator, so a failing run can be replayed
+// by using the same seed with the same sequence of requests.
+func WithChaosSeed(seed uint64) ChaosOption {
+	return func(c *chaosConfig) {
+		c.rng = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// WithChaosLatency delays a share of tool calls by a random duration between
+// min and max.
+func WithChaosLatency(rate float64, min, max time.Duration) ChaosOption {
+	return func(c *chaosConfig) {
+		c.latencyRate = rate
+		c.minLatency = min
+		c.maxLatency = max
+	}
+}
+
+// WithChaosDroppedNotifications silently drops a share of the notifications
+// sent to clients.
+func WithChaosDroppedNotifications(rate float64) ChaosOption {
+	return func(c *chaosConfig) {
+		c.dropRate = rate
+	}
+}
+
+// WithChaosMalformedResults replaces a share of tool results with results
+// that violate the protocol, such as null content or content of an unknown
+// type.
+func WithChaosMalformedResults(rate float64) ChaosOption {
+	return func(c *chaosConfig) {
+		c.malformedRate = rate
+	}
+}
+
+// WithChaosTaskFailures fails a share of the tool calls that run as tasks
+// with an error wrapping ErrChaosInjected.
+func WithChaosTaskFailures(rate float64) ChaosOption {
+	return func(c *chaosConfig) {
+		c.taskFailureRate = rate
+	}
+}
+
+// WithChaosDisconnects unregisters the client session while a share of the
+// tool calls that run as tasks are in progress, as if the client went away.
+func WithChaosDisconnects(rate float64) ChaosOption {
+	return func(c *chaosConfig) {
+		c.disconnectRate = rate
+	}
+}
+
+// WithChaosTools limits tool faults to the named tools.
+func WithChaosTools(names ...string) ChaosOption {
+	return func(c *chaosConfig) {
+		c.tools = names
+	}
+}
+
+// WithChaosRecorder sets a function called for every injected fault.
+func WithChaosRecorder(record func(ChaosEvent)) ChaosOption {
+	return func(c *chaosConfig) {
+		c.record = record
+	}
+}
+
+// WithChaos injects faults into the server for resilience testing of clients
+// and of retry logic. Every fault is off unless enabled with its option, and
+// rates are probabilities between 0 and 1. Never enable it in production.
+func WithChaos(opts ...ChaosOption) ServerOption {
+	return func(s *MCPServer) {
+		c := &chaosConfig{rng: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}
+		for _, opt := range opts {
+			opt(c)
+		}
+		c.unregisterSession = s.UnregisterSession
+		s.chaos = c
+		WithToolHandlerMiddleware(c.middleware)(s)
+	}
+}
+
+// roll reports whether an event with the given probability happens.
+func (c *chaosConfig) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < rate
+}
+
+func (c *chaosConfig) latency() time.Duration {
+	if c.maxLatency <= c.minLatency {
+		return c.minLatency
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.minLatency + time.Duration(c.rng.Int64N(int64(c.maxLatency-c.minLatency)))
+}
+
+func (c *chaosConfig) emit(event ChaosEvent) {
+	if c.record != nil {
+		c.record(event)
+	}
+}
+
+func (c *chaosConfig) middleware(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := request.Params.Name
+		if len(c.tools) > 0 && !slices.Contains(c.tools, tool) {
+			return next(ctx, request)
+		}
+		sessionID := getSessionID(ctx)
+		event := func(fault ChaosFault, detail string) {
+			c.emit(ChaosEvent{Fault: fault, Tool: tool, SessionID: sessionID, Detail: detail})
+		}
+
+		if c.roll(c.latencyRate) {
+			delay := c.latency()
+			event(ChaosFaultLatency, delay.String())
+			if err := sleepContext(ctx, ServerFromContext(ctx).Clock(), delay); err != nil {
+				return nil, err
+			}
+		}
+
+		if TaskIDFromContext(ctx) != "" {
+			if c.roll(c.disconnectRate) && sessionID != "" {
+				event(ChaosFaultDisconnect, "session unregistered during task")
+				c.unregisterSession(ctx, sessionID)
+			}
+			if c.roll(c.taskFailureRate) {
+				event(ChaosFaultTaskFailure, "")
+				return nil, fmt.Errorf("%w: task failure", ErrChaosInjected)
+			}
+		}
+
+		result, err := next(ctx, request)
+		if err == nil && c.roll(c.malformedRate) {
+			malformed, detail := c.malformed()
+			event(ChaosFaultMalformedResult, detail)
+			return malformed, nil
+		}
+		return result, err
+	}
+}
+
+// malformed returns one of several protocol-violating results.
+func (c *chaosConfig) malformed() (*mcp.CallToolResult, string) {
+	c.mu.Lock()
+	kind := c.rng.IntN(3)
+	c.mu.Unlock()
+
+	switch kind {
+	case 0:
+		return &mcp.CallToolResult{}, "null content"
+	case 1:
+		return &mcp.CallToolResult{Content: []mcp.Content{nil}}, "null content item"
+	default:
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{mcp.TextContent{Type: "chaos", Text: "\x00"}},
+		}, "unknown content type"
+	}
+}
+
+// dropNotification reports whether a notification should be dropped. It is
+// safe to call on a nil config.
+func (c *chaosConfig) dropNotification(session ClientSession, notification mcp.JSONRPCNotification) bool {
+	if c == nil || !c.roll(c.dropRate) {
+		return false
+	}
+	c.emit(ChaosEvent{
+		Fault:     ChaosFaultDroppedNotification,
+		SessionID: session.SessionID(),
+		Detail:    notification.Method,
+	})
+	return true
+}
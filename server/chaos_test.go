@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func chaosServer(opts ...ChaosOption) *MCPServer {
+	s := NewMCPServer("test", "1.0.0", WithChaos(opts...))
+	s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	s.AddTool(mcp.NewTool("other"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	return s
+}
+
+func callChaosTool(t *testing.T, s *MCPServer, ctx context.Context, name string) (*mcp.CallToolResult, error) {
+	t.Helper()
+	handler, reqErr := s.toolCallHandler(ctx, 1, name)
+	require.Nil(t, reqErr)
+	return handler(ctx, routingRequest(name, nil))
+}
+
+func TestWithChaos_SeedIsReproducible(t *testing.T) {
+	run := func() []ChaosEvent {
+		var events []ChaosEvent
+		s := chaosServer(
+			WithChaosSeed(42),
+			WithChaosMalformedResults(0.5),
+			WithChaosRecorder(func(event ChaosEvent) { events = append(events, event) }),
+		)
+		for range 50 {
+			_, err := callChaosTool(t, s, context.Background(), "echo")
+			require.NoError(t, err)
+		}
+		return events
+	}
+
+	first := run()
+	assert.NotEmpty(t, first)
+	assert.Less(t, len(first), 50)
+	assert.Equal(t, first, run())
+}
+
+func TestWithChaos_MalformedResults(t *testing.T) {
+	s := chaosServer(WithChaosSeed(1), WithChaosMalformedResults(1))
+
+	for range 10 {
+		result, err := callChaosTool(t, s, context.Background(), "echo")
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		malformed := len(result.Content) == 0 || result.Content[0] == nil
+		if !malformed {
+			text, ok := result.Content[0].(mcp.TextContent)
+			malformed = ok && text.Type == "chaos"
+		}
+		assert.True(t, malformed, "result %#v is well formed", result)
+	}
+}
+
+func TestWithChaos_Latency(t *testing.T) {
+	s := chaosServer(WithChaosLatency(1, 20*time.Millisecond, 20*time.Millisecond))
+
+	start := time.Now()
+	_, err := callChaosTool(t, s, context.Background(), "echo")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = callChaosTool(t, s, ctx, "echo")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWithChaos_TaskFailures(t *testing.T) {
+	s := chaosServer(WithChaosTaskFailures(1))
+	session := &sessionTestClient{sessionID: "chaos", notificationChannel: make(chan mcp.JSONRPCNotification, 1)}
+
+	_, err := callChaosTool(t, s, context.Background(), "echo")
+	require.NoError(t, err, "calls outside tasks are not failed")
+
+	ctx, _ := taskContext(t, s, session, "task-1")
+	_, err = callChaosTool(t, s, ctx, "echo")
+	assert.ErrorIs(t, err, ErrChaosInjected)
+}
+
+func TestWithChaos_Disconnects(t *testing.T) {
+	var events []ChaosEvent
+	s := chaosServer(
+		WithChaosDisconnects(1),
+		WithChaosRecorder(func(event ChaosEvent) { events = append(events, event) }),
+	)
+	session := &sessionTestClient{sessionID: "chaos", notificationChannel: make(chan mcp.JSONRPCNotification, 1)}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	ctx, _ := taskContext(t, s, session, "task-1")
+	_, err := callChaosTool(t, s, ctx, "echo")
+	require.NoError(t, err)
+
+	_, registered := s.sessions.Load("chaos")
+	assert.False(t, registered)
+	require.Len(t, events, 1)
+	assert.Equal(t, ChaosEvent{Fault: ChaosFaultDisconnect, Tool: "echo", SessionID: "chaos", Detail: "session unregistered during task"}, events[0])
+}
+
+func TestWithChaos_DroppedNotifications(t *testing.T) {
+	var events []ChaosEvent
+	s := chaosServer(
+		WithChaosDroppedNotifications(1),
+		WithChaosRecorder(func(event ChaosEvent) { events = append(events, event) }),
+	)
+	session := &sessionTestClient{sessionID: "chaos", notificationChannel: make(chan mcp.JSONRPCNotification, 1), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	s.SendNotificationToAllClients("notifications/test", nil)
+	require.NoError(t, s.SendNotificationToSpecificClient("chaos", "notifications/test", nil))
+
+	assert.Empty(t, session.notificationChannel)
+	require.Len(t, events, 2)
+	assert.Equal(t, ChaosFaultDroppedNotification, events[0].Fault)
+	assert.Equal(t, "notifications/test", events[0].Detail)
+}
+
+func TestWithChaos_Tools(t *testing.T) {
+	s := chaosServer(WithChaosMalformedResults(1), WithChaosTools("other"))
+
+	result, err := callChaosTool(t, s, context.Background(), "echo")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Content[0].(mcp.TextContent).Text)
+
+	result, err = callChaosTool(t, s, context.Background(), "other")
+	require.NoError(t, err)
+	if len(result.Content) > 0 {
+		text, ok := result.Content[0].(mcp.TextContent)
+		assert.False(t, ok && text.Text == "ok")
+	}
+}
+
+func TestWithChaos_Disabled(t *testing.T) {
+	s := chaosServer()
+	for range 10 {
+		result, err := callChaosTool(t, s, context.Background(), "echo")
+		require.False(t, errors.Is(err, ErrChaosInjected))
+		assert.Equal(t, "ok", result.Content[0].(mcp.TextContent).Text)
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// missingClientCapabilities returns the capabilities the tool requires that
+// the session's client did not declare. Sessions that do not track client
+// capabilities are assumed to support them all.
+func missingClientCapabilities(session ClientSession, tool mcp.Tool) []mcp.ClientCapability {
+	if len(tool.RequiredClientCapabilities) == 0 {
+		return nil
+	}
+	withInfo, ok := session.(SessionWithClientInfo)
+	if !ok {
+		return nil
+	}
+	return tool.MissingClientCapabilities(withInfo.GetClientCapabilities())
+}
+
+// filterToolsByClientCapabilities removes the tools whose required client
+// capabilities the session's client did not declare.
+func filterToolsByClientCapabilities(session ClientSession, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if len(missingClientCapabilities(session, tool)) == 0 {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRequiredClientCapabilities(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("plain"), noopToolHandler)
+	s.AddTool(mcp.NewTool("interview", mcp.WithRequiredClientCapabilities(mcp.ClientCapabilityElicitation)), noopToolHandler)
+	s.AddTool(mcp.NewTool("draft", mcp.WithRequiredClientCapabilities(mcp.ClientCapabilitySampling, "x-canvas")), noopToolHandler)
+
+	tests := []struct {
+		name         string
+		capabilities mcp.ClientCapabilities
+		listed       []string
+		rejected     []string
+	}{
+		{
+			name:     "no capabilities",
+			listed:   []string{"plain"},
+			rejected: []string{"interview", "draft"},
+		},
+		{
+			name:         "elicitation",
+			capabilities: mcp.ClientCapabilities{Elicitation: &mcp.ElicitationCapability{}},
+			listed:       []string{"interview", "plain"},
+			rejected:     []string{"draft"},
+		},
+		{
+			name: "sampling and experimental",
+			capabilities: mcp.ClientCapabilities{
+				Sampling:     &struct{}{},
+				Experimental: map[string]any{"x-canvas": map[string]any{}},
+			},
+			listed:   []string{"draft", "plain"},
+			rejected: []string{"interview"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &sessionTestClientWithClientInfo{sessionID: tt.name, notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+			session.SetClientCapabilities(tt.capabilities)
+			ctx := s.WithContext(context.Background(), session)
+
+			response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "unexpected response %#v", response)
+			var listed []string
+			for _, tool := range resp.Result.(mcp.ListToolsResult).Tools {
+				listed = append(listed, tool.Name)
+			}
+			assert.Equal(t, tt.listed, listed)
+
+			for _, name := range tt.rejected {
+				response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"`+name+`"}}`))
+				rpcErr, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok, "unexpected response %#v", response)
+				assert.Equal(t, mcp.INVALID_REQUEST, rpcErr.Error.Code)
+				assert.Contains(t, rpcErr.Error.Message, ErrClientCapabilityRequired.Error())
+			}
+			for _, name := range tt.listed {
+				response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"`+name+`"}}`))
+				_, ok := response.(mcp.JSONRPCResponse)
+				assert.True(t, ok, "unexpected response %#v", response)
+			}
+		})
+	}
+
+	// Sessions that do not track client capabilities see every tool.
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	assert.Len(t, response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools, 3)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultSamplingTimeout bounds how long RequestSampling waits for the
+	// client, unless set with WithSamplingTimeout.
+	DefaultSamplingTimeout = 2 * time.Minute
+	// DefaultElicitationTimeout bounds how long RequestElicitation and
+	// RequestInput wait for the user to answer, unless set with
+	// WithElicitationTimeout.
+	DefaultElicitationTimeout = 10 * time.Minute
+	// DefaultRootsTimeout bounds how long RequestRoots waits for the
+	// client, unless set with WithRootsTimeout.
+	DefaultRootsTimeout = 30 * time.Second
+)
+
+// ClientRequestTimeoutError is returned for requests to a client that did
+// not answer in time. It matches ErrClientRequestTimeout and
+// context.DeadlineExceeded with errors.Is.
+type ClientRequestTimeoutError struct {
+	Method    mcp.MCPMethod
+	SessionID string
+	Timeout   time.Duration
+}
+
+func (e *ClientRequestTimeoutError) Error() string {
+	return fmt.Sprintf("%s request to session %s not answered within %v: %v", e.Method, e.SessionID, e.Timeout, ErrClientRequestTimeout)
+}
+
+func (e *ClientRequestTimeoutError) Unwrap() []error {
+	return []error{ErrClientRequestTimeout, context.DeadlineExceeded}
+}
+
+// clientRequestTimeouts are the timeouts of the requests the server sends
+// to clients; zero means none.
+type clientRequestTimeouts struct {
+	sampling    time.Duration
+	elicitation time.Duration
+	roots       time.Duration
+}
+
+// WithSamplingTimeout sets how long RequestSampling waits for the client's
+// answer, including the time spent queued by WithClientRequestLimit. Zero
+// or a negative duration waits for as long as the request's context
+// allows. It defaults to DefaultSamplingTimeout.
+func WithSamplingTimeout(d time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.clientTimeouts.sampling = max(d, 0)
+	}
+}
+
+// WithElicitationTimeout sets how long RequestElicitation, RequestInput
+// and RequestURLElicitation wait for the user's answer, like
+// WithSamplingTimeout. It defaults to DefaultElicitationTimeout.
+func WithElicitationTimeout(d time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.clientTimeouts.elicitation = max(d, 0)
+	}
+}
+
+// WithRootsTimeout sets how long RequestRoots waits for the client's
+// answer, like WithSamplingTimeout. It defaults to DefaultRootsTimeout.
+func WithRootsTimeout(d time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.clientTimeouts.roots = max(d, 0)
+	}
+}
+
+// clientRequestTimeoutKey is the context key of a per-call timeout.
+type clientRequestTimeoutKey struct{}
+
+// WithClientRequestTimeout overrides the server's timeout for the
+// sampling, elicitation and roots requests made with the returned context,
+// e.g. to give a user longer to fill a large form. Zero waits for as long
+// as the context allows.
+func WithClientRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, clientRequestTimeoutKey{}, max(d, 0))
+}
+
+// clientRequestContext bounds ctx by the timeout of a request to the
+// session's client, and returns the function converting the request's
+// error to a *ClientRequestTimeoutError once the timeout expired.
+func (s *MCPServer) clientRequestContext(ctx context.Context, method mcp.MCPMethod, sessionID string) (context.Context, func(error) error) {
+	timeout, ok := ctx.Value(clientRequestTimeoutKey{}).(time.Duration)
+	if !ok {
+		switch method {
+		case mcp.MethodSamplingCreateMessage:
+			timeout = s.clientTimeouts.sampling
+		case mcp.MethodElicitationCreate:
+			timeout = s.clientTimeouts.elicitation
+		case mcp.MethodListRoots:
+			timeout = s.clientTimeouts.roots
+		}
+	}
+	if timeout <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+
+	requestCtx, cancel := contextWithTimeout(ctx, s.Clock(), timeout)
+	return requestCtx, func(err error) error {
+		defer cancel()
+		if err != nil && ctx.Err() == nil && errors.Is(requestCtx.Err(), context.DeadlineExceeded) {
+			return &ClientRequestTimeoutError{Method: method, SessionID: sessionID, Timeout: timeout}
+		}
+		return err
+	}
+}
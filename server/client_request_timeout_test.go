@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// slowRootsSession never answers roots requests.
+type slowRootsSession struct {
+	mockBasicSession
+}
+
+func (s *slowRootsSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestClientRequestTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ServerOption
+		ctx     func(context.Context) context.Context
+		timeout time.Duration
+	}{
+		{name: "default", timeout: DefaultElicitationTimeout},
+		{name: "server option", opts: []ServerOption{WithElicitationTimeout(time.Minute)}, timeout: time.Minute},
+		{
+			name:    "per call",
+			opts:    []ServerOption{WithElicitationTimeout(time.Minute)},
+			ctx:     func(ctx context.Context) context.Context { return WithClientRequestTimeout(ctx, time.Hour) },
+			timeout: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &manualClock{now: time.Unix(0, 0)}
+			s := NewMCPServer("test", "1.0.0", append(tt.opts, WithClock(clock))...)
+			session := &gatedElicitationSession{mockBasicSession: mockBasicSession{sessionID: "s1"}, answer: make(chan struct{})}
+			ctx := s.WithContext(context.Background(), session)
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := s.RequestElicitation(ctx, mcp.ElicitationRequest{Params: mcp.ElicitationParams{
+					Message:         "name?",
+					RequestedSchema: map[string]any{"type": "object"},
+				}})
+				done <- err
+			}()
+			require.Eventually(t, func() bool { return len(session.messages()) == 1 }, time.Second, time.Millisecond)
+
+			clock.advance(tt.timeout - time.Second)
+			select {
+			case err := <-done:
+				t.Fatalf("returned before the timeout: %v", err)
+			case <-time.After(10 * time.Millisecond):
+			}
+
+			clock.advance(time.Second)
+			err := <-done
+			var timeoutErr *ClientRequestTimeoutError
+			require.ErrorAs(t, err, &timeoutErr)
+			assert.Equal(t, mcp.MethodElicitationCreate, timeoutErr.Method)
+			assert.Equal(t, "s1", timeoutErr.SessionID)
+			assert.Equal(t, tt.timeout, timeoutErr.Timeout)
+			assert.ErrorIs(t, err, ErrClientRequestTimeout)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Equal(t, 0, clock.pending(), "the timer is released")
+		})
+	}
+}
+
+func TestClientRequestTimeoutDisabled(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithRootsTimeout(0))
+	ctx, cancel := context.WithTimeout(s.WithContext(context.Background(), &slowRootsSession{mockBasicSession{sessionID: "s1"}}), 20*time.Millisecond)
+	defer cancel()
+	_, err := s.RequestRoots(ctx, mcp.ListRootsRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrClientRequestTimeout, "the caller's deadline is not a client timeout")
+}
+
+func TestRootsTimeout(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithRootsTimeout(20*time.Millisecond))
+	_, err := s.RequestRoots(s.WithContext(context.Background(), &slowRootsSession{mockBasicSession{sessionID: "s1"}}), mcp.ListRootsRequest{})
+	assert.ErrorIs(t, err, ErrClientRequestTimeout)
+}
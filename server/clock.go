@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Clock is the source of time for the server's time-dependent behaviour:
+// task TTLs, keepalive and heartbeat pings, timeouts, retries and latency
+// measurements. Replace it with WithClock to control time in tests or
+// simulations.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a Timer that fires once after d.
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a Ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event created by a Clock, like time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the Clock of the time package, which servers use by
+// default.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// WithClock sets the clock the server reads time from.
+func WithClock(clock Clock) ServerOption {
+	return func(s *MCPServer) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// Clock returns the server's clock, so tool handlers can wait on the same
+// time as the server. It returns SystemClock for a nil server, so
+// ServerFromContext(ctx).Clock() is safe outside of requests.
+func (s *MCPServer) Clock() Clock {
+	if s == nil || s.clock == nil {
+		return SystemClock
+	}
+	return s.clock
+}
+
+// sleepContext waits for d on clock, or until ctx is done.
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// contextWithTimeout is context.WithTimeout on clock.
+func contextWithTimeout(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if clock == SystemClock {
+		return context.WithTimeout(ctx, d)
+	}
+	deadline := clock.Now().Add(d)
+	inner, cancel := context.WithCancelCause(ctx)
+	timer := clock.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(context.DeadlineExceeded)
+		case <-inner.Done():
+			timer.Stop()
+		}
+	}()
+	return &clockTimeoutContext{Context: inner, deadline: deadline}, func() {
+		// Stop the timer right away, for clocks that track pending timers.
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// clockTimeoutContext reports the expiry of a timeout on a Clock as
+// context.DeadlineExceeded, like contexts created by context.WithTimeout.
+type clockTimeoutContext struct {
+	context.Context
+	deadline time.Time
+}
+
+// Deadline returns the deadline on the clock, which may differ from the
+// deadlines of parent contexts if the clock is not SystemClock.
+func (c *clockTimeoutContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockTimeoutContext) Err() error {
+	err := c.Context.Err()
+	if err != nil && errors.Is(context.Cause(c.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
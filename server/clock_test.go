@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock is a Clock whose timers fire only on advance. Tickers are not
+// needed by these tests.
+type manualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+	stopped  bool
+}
+
+func (t *manualTimer) C() <-chan time.Time { return t.ch }
+
+func (t *manualTimer) Stop() bool {
+	stopped := t.stopped
+	t.stopped = true
+	return !stopped
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &manualTimer{deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (c *manualClock) NewTicker(d time.Duration) Ticker {
+	panic("not implemented")
+}
+
+func (c *manualClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	remaining := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			remaining = append(remaining, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = remaining
+}
+
+func TestWithClockTaskTTL(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	s := NewMCPServer("test", "1.0.0", WithClock(clock))
+	assert.Same(t, clock, s.Clock())
+
+	ttl := int64(60000)
+	s.createTask(context.Background(), "task-1", &ttl, nil)
+	require.Eventually(t, func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+
+	clock.advance(59 * time.Second)
+	_, _, err := s.getTask(context.Background(), "task-1")
+	require.NoError(t, err, "task kept until its TTL passes")
+
+	clock.advance(time.Second)
+	require.Eventually(t, func() bool {
+		_, _, err := s.getTask(context.Background(), "task-1")
+		return err != nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestContextWithTimeout(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	ctx, cancel := contextWithTimeout(context.Background(), clock, time.Second)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(1, 0), deadline)
+	assert.NoError(t, ctx.Err())
+
+	clock.advance(time.Second)
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	ctx, cancel = contextWithTimeout(context.Background(), clock, time.Second)
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestNilServerClock(t *testing.T) {
+	assert.Equal(t, SystemClock, ServerFromContext(context.Background()).Clock())
+}
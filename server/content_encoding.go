@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithContentEncoding compresses the blobs of resources/read results and
+// the image and embedded blob content of tool results with gzip, for the
+// sessions whose client declares it can decode gzip with the
+// mcp.ContentEncodings capability. Data smaller than minSize bytes, or
+// that compression would not make smaller, is sent as is. The encoding is
+// named in the contentEncoding key of its _meta, and this package's
+// clients decode it transparently.
+//
+// Compression pays off for text-heavy blobs such as JSON, CSV or SVG; data
+// that is already compressed, like PNG or JPEG images, is left unchanged.
+func WithContentEncoding(minSize int) ServerOption {
+	return func(s *MCPServer) {
+		s.contentEncoding = &contentEncoding{minSize: minSize}
+		s.setExperimentalCapability(mcp.ExperimentalContentEncoding, mcp.ContentEncodingCapability{
+			Encodings: []string{mcp.ContentEncodingGzip},
+		})
+	}
+}
+
+type contentEncoding struct {
+	minSize int
+}
+
+// sessionContentEncoding returns the encoding negotiated with the client of
+// the current session, or false if it declared none the server supports.
+func (s *MCPServer) sessionContentEncoding(ctx context.Context) (string, bool) {
+	if s.contentEncoding == nil {
+		return "", false
+	}
+	remote, ok, err := mcp.ContentEncodings.Get(ClientExperimentalCapabilities(ctx))
+	if !ok || err != nil {
+		return "", false
+	}
+	return mcp.NegotiateContentEncoding(mcp.ContentEncodingCapability{
+		Encodings: []string{mcp.ContentEncodingGzip},
+	}, remote)
+}
+
+// encodeResourceContents encodes the blobs of a resources/read result under
+// WithContentEncoding.
+func (s *MCPServer) encodeResourceContents(ctx context.Context, contents []mcp.ResourceContents) []mcp.ResourceContents {
+	encoding, ok := s.sessionContentEncoding(ctx)
+	if !ok {
+		return contents
+	}
+	encoded := make([]mcp.ResourceContents, len(contents))
+	for i, c := range contents {
+		encoded[i] = mcp.EncodeResourceContents(c, encoding, s.contentEncoding.minSize)
+	}
+	return encoded
+}
+
+// encodeToolResult encodes the binary content of a tool result under
+// WithContentEncoding, leaving the handler's result unchanged.
+func (s *MCPServer) encodeToolResult(ctx context.Context, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil {
+		return nil
+	}
+	encoding, ok := s.sessionContentEncoding(ctx)
+	if !ok {
+		return result
+	}
+	encoded := *result
+	encoded.Content = make([]mcp.Content, len(result.Content))
+	for i, c := range result.Content {
+		encoded.Content[i] = mcp.EncodeContent(c, encoding, s.contentEncoding.minSize)
+	}
+	return &encoded
+}
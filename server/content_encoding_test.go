@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWithContentEncoding(t *testing.T) {
+	csv := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("id,name,email\n", 500)))
+	svg := base64.StdEncoding.EncodeToString([]byte("<svg>" + strings.Repeat(`<circle r="1"/>`, 500) + "</svg>"))
+
+	tests := []struct {
+		name         string
+		capabilities string
+		wantEncoded  bool
+	}{
+		{"client decodes gzip", `{"experimental":{"contentEncoding":{"encodings":["br","gzip"]}}}`, true},
+		{"client without encodings", `{}`, false},
+		{"client with other encodings", `{"experimental":{"contentEncoding":{"encodings":["br"]}}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMCPServer("test", "1.0.0", WithResourceCapabilities(false, false), WithContentEncoding(1024))
+			s.AddResource(mcp.NewResource("file:///users.csv", "users"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.BlobResourceContents{URI: "file:///users.csv", MIMEType: "text/csv", Blob: csv},
+					mcp.BlobResourceContents{URI: "file:///small.csv", MIMEType: "text/csv", Blob: "YSxi"},
+				}, nil
+			})
+			image := mcp.NewImageContent(svg, "image/svg+xml")
+			s.AddTool(mcp.NewTool("draw"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{image}}, nil
+			})
+
+			session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+			ctx := s.WithContext(context.Background(), session)
+			response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":`+tt.capabilities+`,"clientInfo":{"name":"test","version":"1"}}}`))
+			initialize := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+			declared, ok, err := mcp.ContentEncodings.Get(initialize.Capabilities.Experimental)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, []string{mcp.ContentEncodingGzip}, declared.Encodings)
+
+			// Round trip the result through JSON, as a client would see it.
+			roundTrip := func(response mcp.JSONRPCMessage) (json.RawMessage, map[string]any) {
+				resp, ok := response.(mcp.JSONRPCResponse)
+				require.True(t, ok, "unexpected response %#v", response)
+				data, err := json.Marshal(resp.Result)
+				require.NoError(t, err)
+				var decoded map[string]any
+				require.NoError(t, json.Unmarshal(data, &decoded))
+				return data, decoded
+			}
+
+			data, wire := roundTrip(s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"file:///users.csv"}}`)))
+			contents := wire["contents"].([]any)
+			big, small := contents[0].(map[string]any), contents[1].(map[string]any)
+			if tt.wantEncoded {
+				assert.Equal(t, map[string]any{mcp.ContentEncodingMeta: mcp.ContentEncodingGzip}, big["_meta"])
+				assert.Less(t, len(big["blob"].(string))*5, len(csv))
+			} else {
+				assert.NotContains(t, big, "_meta")
+				assert.Equal(t, csv, big["blob"])
+			}
+			assert.NotContains(t, small, "_meta", "blobs below the minimum size are sent as is")
+			read, err := mcp.ParseReadResourceResult(&data)
+			require.NoError(t, err)
+			assert.Equal(t, mcp.BlobResourceContents{URI: "file:///users.csv", MIMEType: "text/csv", Blob: csv}, read.Contents[0])
+
+			data, wire = roundTrip(s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"draw"}}`)))
+			part := wire["content"].([]any)[0].(map[string]any)
+			assert.Equal(t, tt.wantEncoded, part["data"] != svg)
+			result, err := mcp.ParseCallToolResult(&data)
+			require.NoError(t, err)
+			assert.Equal(t, image, result.Content[0])
+		})
+	}
+}
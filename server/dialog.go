@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DialogAnswers holds the content accepted for the steps of a Dialog, by
+// step name.
+type DialogAnswers map[string]map[string]any
+
+// DialogStep is one question of a Dialog.
+type DialogStep struct {
+	// Name identifies the step; its answer is stored under it.
+	Name string
+	// Message and RequestedSchema are the question asked with
+	// RequestInput.
+	Message         string
+	RequestedSchema any
+	// Prepare, if set, builds the question from the answers so far instead
+	// of Message and RequestedSchema, e.g. to offer choices depending on
+	// them.
+	Prepare func(answers DialogAnswers) (mcp.ElicitationParams, error)
+	// Validate, if set, checks an accepted answer. The step is asked again
+	// with the error in front of its message.
+	Validate func(answer map[string]any, answers DialogAnswers) error
+	// Next, if set, returns the name of the step to ask next, or "" to end
+	// the dialog. Without it the dialog goes on with the following step.
+	Next func(answers DialogAnswers) string
+	// Optional steps that are declined are skipped instead of ending the
+	// dialog.
+	Optional bool
+}
+
+// DialogResult is the outcome of running a Dialog.
+type DialogResult struct {
+	// Answers are the accepted answers by step name.
+	Answers DialogAnswers
+	// Action is accept when the dialog ran to its end, and otherwise the
+	// decline or cancel of Step that stopped it.
+	Action mcp.ElicitationResponseAction
+	Step   string
+	// Path lists the steps asked, in order.
+	Path []string
+}
+
+// Dialog is a graph of questions asked one after the other with
+// RequestInput, branching on the answers given so far. A Dialog holds no
+// state of its own, so it may run in any number of tasks at once.
+type Dialog struct {
+	steps       []DialogStep
+	index       map[string]int
+	maxAttempts int
+	maxTurns    int
+}
+
+// DialogOption configures a Dialog.
+type DialogOption func(*Dialog)
+
+// WithDialogMaxAttempts sets how often a step is asked when its answers
+// fail validation, 3 by default.
+func WithDialogMaxAttempts(n int) DialogOption {
+	return func(d *Dialog) {
+		if n > 0 {
+			d.maxAttempts = n
+		}
+	}
+}
+
+// WithDialogMaxTurns bounds the questions asked in one run, so that a
+// dialog branching back to earlier steps cannot loop forever; 100 by
+// default.
+func WithDialogMaxTurns(n int) DialogOption {
+	return func(d *Dialog) {
+		if n > 0 {
+			d.maxTurns = n
+		}
+	}
+}
+
+// NewDialog returns a dialog starting with the first of steps.
+func NewDialog(steps []DialogStep, opts ...DialogOption) (*Dialog, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%w: no steps", ErrInvalidDialog)
+	}
+	d := &Dialog{
+		steps:       steps,
+		index:       make(map[string]int, len(steps)),
+		maxAttempts: 3,
+		maxTurns:    100,
+	}
+	for i, step := range steps {
+		if step.Name == "" {
+			return nil, fmt.Errorf("%w: step %d has no name", ErrInvalidDialog, i)
+		}
+		if _, ok := d.index[step.Name]; ok {
+			return nil, fmt.Errorf("%w: step %q is defined more than once", ErrInvalidDialog, step.Name)
+		}
+		d.index[step.Name] = i
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, nil
+}
+
+// Run asks the dialog's questions on behalf of the current tool call,
+// which should run as a task: the task is input_required while a question
+// waits for its answer. After each answer it sends a progress notification
+// if the tools/call request has a progress token.
+//
+// A declined step that is not optional and a cancelled step end the run
+// with the answers so far; errors of RequestInput are returned as is.
+func (d *Dialog) Run(ctx context.Context, s *MCPServer) (*DialogResult, error) {
+	result := &DialogResult{Answers: make(DialogAnswers)}
+	current := 0
+	for {
+		if len(result.Path) >= d.maxTurns {
+			return result, fmt.Errorf("%w: asked %d questions without ending", ErrInvalidDialog, len(result.Path))
+		}
+		step := d.steps[current]
+		result.Path = append(result.Path, step.Name)
+
+		answer, action, err := d.ask(ctx, s, step, result.Answers)
+		if err != nil {
+			return result, err
+		}
+		switch action {
+		case mcp.ElicitationResponseActionAccept:
+			result.Answers[step.Name] = answer
+		case mcp.ElicitationResponseActionDecline:
+			if !step.Optional {
+				result.Action, result.Step = action, step.Name
+				return result, nil
+			}
+		default:
+			result.Action, result.Step = mcp.ElicitationResponseActionCancel, step.Name
+			return result, nil
+		}
+		s.sendDialogProgress(ctx, len(result.Path), step.Name)
+
+		next := current + 1
+		if step.Next != nil {
+			name := step.Next(result.Answers)
+			if name == "" {
+				next = len(d.steps)
+			} else if i, ok := d.index[name]; ok {
+				next = i
+			} else {
+				return result, fmt.Errorf("%w: step %q branches to unknown step %q", ErrInvalidDialog, step.Name, name)
+			}
+		}
+		if next >= len(d.steps) {
+			result.Action = mcp.ElicitationResponseActionAccept
+			return result, nil
+		}
+		current = next
+	}
+}
+
+// ask asks a step until its answer is valid.
+func (d *Dialog) ask(ctx context.Context, s *MCPServer, step DialogStep, answers DialogAnswers) (map[string]any, mcp.ElicitationResponseAction, error) {
+	params := mcp.ElicitationParams{Message: step.Message, RequestedSchema: step.RequestedSchema}
+	if step.Prepare != nil {
+		var err error
+		if params, err = step.Prepare(answers); err != nil {
+			return nil, "", fmt.Errorf("failed to prepare step %q: %w", step.Name, err)
+		}
+	}
+
+	message := params.Message
+	var invalid error
+	for attempt := 0; attempt < d.maxAttempts; attempt++ {
+		if invalid != nil {
+			params.Message = invalid.Error() + "\n\n" + message
+		}
+		result, err := s.RequestInput(ctx, mcp.ElicitationRequest{Params: params})
+		if err != nil {
+			return nil, "", err
+		}
+		if result.Action != mcp.ElicitationResponseActionAccept {
+			return nil, result.Action, nil
+		}
+
+		answer, ok := result.Content.(map[string]any)
+		if !ok {
+			invalid = fmt.Errorf("expected an object, got %T", result.Content)
+			continue
+		}
+		if step.Validate != nil {
+			if invalid = step.Validate(answer, answers); invalid != nil {
+				continue
+			}
+		}
+		return answer, result.Action, nil
+	}
+	return nil, "", fmt.Errorf("%w for step %q: %v", ErrDialogInvalidAnswer, step.Name, invalid)
+}
+
+// sendDialogProgress reports an answered step to the client of the task's
+// tools/call request, if it asked for progress.
+func (s *MCPServer) sendDialogProgress(ctx context.Context, answered int, step string) {
+	request, ok := TaskToolCallFromContext(ctx)
+	if !ok || request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	_ = s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+		"progress":      answered,
+		"progressToken": request.Params.Meta.ProgressToken,
+		"message":       fmt.Sprintf("Answered %s", step),
+	})
+}
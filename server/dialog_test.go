@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// scriptedInputSession answers elicitations with answer.
+type scriptedInputSession struct {
+	mockCapabilitiesSession
+	mu       sync.Mutex
+	answer   func(request mcp.ElicitationRequest) mcp.ElicitationResponse
+	messages []string
+}
+
+func newScriptedInputSession(id string, answer func(request mcp.ElicitationRequest) mcp.ElicitationResponse) *scriptedInputSession {
+	return &scriptedInputSession{
+		mockCapabilitiesSession: mockCapabilitiesSession{
+			mockElicitationSession: mockElicitationSession{sessionID: id},
+			capabilities:           mcp.ClientCapabilities{Elicitation: &mcp.ElicitationCapability{}},
+		},
+		answer: answer,
+	}
+}
+
+func (s *scriptedInputSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.mu.Lock()
+	s.messages = append(s.messages, request.Params.Message)
+	s.mu.Unlock()
+	return &mcp.ElicitationResult{ElicitationResponse: s.answer(request)}, nil
+}
+
+// fieldSchema requests a single string field.
+func fieldSchema(name string) map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{name: map[string]any{"type": "string"}},
+		"required":   []string{name},
+	}
+}
+
+func accept(content map[string]any) mcp.ElicitationResponse {
+	return mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: content}
+}
+
+// orderDialog asks for a drink, then for milk only for lattes, then for the
+// name to call out.
+func orderDialog(t *testing.T, opts ...DialogOption) *Dialog {
+	t.Helper()
+	dialog, err := NewDialog([]DialogStep{
+		{
+			Name:            "drink",
+			Message:         "What would you like?",
+			RequestedSchema: fieldSchema("drink"),
+			Next: func(answers DialogAnswers) string {
+				if answers["drink"]["drink"] == "latte" {
+					return "milk"
+				}
+				return "name"
+			},
+		},
+		{
+			Name: "milk",
+			Prepare: func(answers DialogAnswers) (mcp.ElicitationParams, error) {
+				return mcp.ElicitationParams{Message: fmt.Sprintf("Which milk for your %s?", answers["drink"]["drink"]), RequestedSchema: fieldSchema("milk")}, nil
+			},
+			Validate: func(answer map[string]any, answers DialogAnswers) error {
+				if answer["milk"] == "cream" {
+					return errors.New("cream is not milk")
+				}
+				return nil
+			},
+			Optional: true,
+		},
+		{Name: "name", Message: "Your name?", RequestedSchema: fieldSchema("name")},
+	}, opts...)
+	require.NoError(t, err)
+	return dialog
+}
+
+func TestDialog_Branches(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers map[string]mcp.ElicitationResponse
+		want    DialogResult
+		asked   []string
+	}{
+		{
+			name: "espresso skips milk",
+			answers: map[string]mcp.ElicitationResponse{
+				"What would you like?": accept(map[string]any{"drink": "espresso"}),
+				"Your name?":           accept(map[string]any{"name": "ada"}),
+			},
+			want: DialogResult{
+				Answers: DialogAnswers{"drink": {"drink": "espresso"}, "name": {"name": "ada"}},
+				Action:  mcp.ElicitationResponseActionAccept,
+				Path:    []string{"drink", "name"},
+			},
+			asked: []string{"What would you like?", "Your name?"},
+		},
+		{
+			name: "latte asks for milk",
+			answers: map[string]mcp.ElicitationResponse{
+				"What would you like?":       accept(map[string]any{"drink": "latte"}),
+				"Which milk for your latte?": accept(map[string]any{"milk": "oat"}),
+				"Your name?":                 accept(map[string]any{"name": "ada"}),
+			},
+			want: DialogResult{
+				Answers: DialogAnswers{"drink": {"drink": "latte"}, "milk": {"milk": "oat"}, "name": {"name": "ada"}},
+				Action:  mcp.ElicitationResponseActionAccept,
+				Path:    []string{"drink", "milk", "name"},
+			},
+			asked: []string{"What would you like?", "Which milk for your latte?", "Your name?"},
+		},
+		{
+			name: "optional step declined",
+			answers: map[string]mcp.ElicitationResponse{
+				"What would you like?":       accept(map[string]any{"drink": "latte"}),
+				"Which milk for your latte?": {Action: mcp.ElicitationResponseActionDecline},
+				"Your name?":                 accept(map[string]any{"name": "ada"}),
+			},
+			want: DialogResult{
+				Answers: DialogAnswers{"drink": {"drink": "latte"}, "name": {"name": "ada"}},
+				Action:  mcp.ElicitationResponseActionAccept,
+				Path:    []string{"drink", "milk", "name"},
+			},
+			asked: []string{"What would you like?", "Which milk for your latte?", "Your name?"},
+		},
+		{
+			name: "required step declined",
+			answers: map[string]mcp.ElicitationResponse{
+				"What would you like?": {Action: mcp.ElicitationResponseActionDecline},
+			},
+			want: DialogResult{
+				Answers: DialogAnswers{},
+				Action:  mcp.ElicitationResponseActionDecline,
+				Step:    "drink",
+				Path:    []string{"drink"},
+			},
+			asked: []string{"What would you like?"},
+		},
+		{
+			name: "cancelled",
+			answers: map[string]mcp.ElicitationResponse{
+				"What would you like?": accept(map[string]any{"drink": "tea"}),
+				"Your name?":           {Action: mcp.ElicitationResponseActionCancel},
+			},
+			want: DialogResult{
+				Answers: DialogAnswers{"drink": {"drink": "tea"}},
+				Action:  mcp.ElicitationResponseActionCancel,
+				Step:    "name",
+				Path:    []string{"drink", "name"},
+			},
+			asked: []string{"What would you like?", "Your name?"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+			session := newScriptedInputSession("s1", func(request mcp.ElicitationRequest) mcp.ElicitationResponse {
+				return tt.answers[request.Params.Message]
+			})
+			ctx, entry := taskContext(t, s, session, "task-1")
+
+			result, err := orderDialog(t).Run(ctx, s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *result)
+			assert.Equal(t, tt.asked, session.messages)
+			assert.Equal(t, mcp.TaskStatusWorking, entry.task.Status)
+		})
+	}
+}
+
+func TestDialog_Validation(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+	milk := []string{"cream", "soy"}
+	session := newScriptedInputSession("s1", func(request mcp.ElicitationRequest) mcp.ElicitationResponse {
+		switch {
+		case strings.HasSuffix(request.Params.Message, "Which milk for your latte?"):
+			answer := milk[0]
+			milk = milk[1:]
+			return accept(map[string]any{"milk": answer})
+		case request.Params.Message == "What would you like?":
+			return accept(map[string]any{"drink": "latte"})
+		}
+		return accept(map[string]any{"name": "ada"})
+	})
+	ctx, _ := taskContext(t, s, session, "task-1")
+
+	result, err := orderDialog(t).Run(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"milk": "soy"}, result.Answers["milk"])
+	assert.Equal(t, "cream is not milk\n\nWhich milk for your latte?", session.messages[2])
+
+	milk = []string{"cream", "cream"}
+	session.messages = nil
+	_, err = orderDialog(t, WithDialogMaxAttempts(2)).Run(ctx, s)
+	assert.ErrorIs(t, err, ErrDialogInvalidAnswer)
+	assert.ErrorContains(t, err, "cream is not milk")
+}
+
+func TestDialog_Concurrent(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+	dialog := orderDialog(t)
+
+	var wg sync.WaitGroup
+	for _, drink := range []string{"latte", "espresso", "tea", "mocha"} {
+		wg.Add(1)
+		session := newScriptedInputSession(drink, func(request mcp.ElicitationRequest) mcp.ElicitationResponse {
+			return accept(map[string]any{"drink": drink, "milk": "oat", "name": drink + "-lover"})
+		})
+		ctx, _ := taskContext(t, s, session, "task-"+drink)
+		go func() {
+			defer wg.Done()
+			result, err := dialog.Run(ctx, s)
+			assert.NoError(t, err)
+			assert.Equal(t, drink, result.Answers["drink"]["drink"])
+			assert.Equal(t, drink+"-lover", result.Answers["name"]["name"])
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDialog_Progress(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+	session := newScriptedInputSession("s1", func(request mcp.ElicitationRequest) mcp.ElicitationResponse {
+		return accept(map[string]any{"drink": "tea", "name": "ada"})
+	})
+	ctx, _ := taskContext(t, s, session, "task-1")
+	var call mcp.CallToolRequest
+	call.Params.Meta = &mcp.Meta{ProgressToken: "order-1"}
+	ctx = context.WithValue(ctx, taskToolCallKey{}, call)
+
+	_, err := orderDialog(t).Run(ctx, s)
+	require.NoError(t, err)
+
+	notifications := session.notifyChan
+	require.Len(t, notifications, 2)
+	for i, step := range []string{"drink", "name"} {
+		notification := <-notifications
+		assert.Equal(t, "notifications/progress", notification.Method)
+		assert.Equal(t, "order-1", notification.Params.AdditionalFields["progressToken"])
+		assert.Equal(t, i+1, notification.Params.AdditionalFields["progress"])
+		assert.Equal(t, "Answered "+step, notification.Params.AdditionalFields["message"])
+	}
+}
+
+func TestDialog_Invalid(t *testing.T) {
+	_, err := NewDialog(nil)
+	assert.ErrorIs(t, err, ErrInvalidDialog)
+	_, err = NewDialog([]DialogStep{{Name: "a"}, {Name: "a"}})
+	assert.ErrorIs(t, err, ErrInvalidDialog)
+	_, err = NewDialog([]DialogStep{{Message: "?"}})
+	assert.ErrorIs(t, err, ErrInvalidDialog)
+
+	s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+	session := newScriptedInputSession("s1", func(request mcp.ElicitationRequest) mcp.ElicitationResponse {
+		return accept(map[string]any{})
+	})
+	ctx, _ := taskContext(t, s, session, "task-1")
+
+	unknown, err := NewDialog([]DialogStep{{Name: "a", Message: "A?", RequestedSchema: fieldSchema("a"), Next: func(DialogAnswers) string { return "b" }}})
+	require.NoError(t, err)
+	_, err = unknown.Run(ctx, s)
+	assert.ErrorIs(t, err, ErrInvalidDialog)
+
+	loop, err := NewDialog([]DialogStep{{Name: "a", Message: "A?", RequestedSchema: fieldSchema("a"), Next: func(DialogAnswers) string { return "a" }}}, WithDialogMaxTurns(5))
+	require.NoError(t, err)
+	result, err := loop.Run(ctx, s)
+	assert.ErrorIs(t, err, ErrInvalidDialog)
+	assert.Len(t, result.Path, 5)
+}
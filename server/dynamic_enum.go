@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithDynamicEnumTTL sets for how long the values of properties declared
+// with mcp.WithDynamicEnum are cached per session. By default they are
+// cached until their provider signals a change or the session ends.
+func WithDynamicEnumTTL(ttl time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.dynamicEnums.ttl = ttl
+	}
+}
+
+// dynamicEnums caches the resolved values of dynamic enums by session, and
+// holds the subscriptions to the providers of the server's tools and of the
+// sessions' own tools.
+type dynamicEnums struct {
+	ttl time.Duration
+
+	mu sync.Mutex
+	// generation counts the changes signalled by providers, so that values
+	// fetched before a change are not cached after it.
+	generation    uint64
+	sessions      map[string]map[string]cachedEnum
+	subscriptions map[enumSubscriptionKey][]func()
+}
+
+// enumSubscriptionKey identifies the tool holding provider subscriptions:
+// a server tool with an empty sessionID, or a session's own tool.
+type enumSubscriptionKey struct {
+	sessionID, tool string
+}
+
+type cachedEnum struct {
+	values  []string
+	expires time.Time
+}
+
+// resolveDynamicEnums resolves the dynamic enums of the tools for the
+// session of ctx, using the cached values where available.
+func (s *MCPServer) resolveDynamicEnums(ctx context.Context, tools []mcp.Tool) ([]mcp.Tool, error) {
+	var sessionID string
+	if session := ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+	resolved := make([]mcp.Tool, len(tools))
+	for i, tool := range tools {
+		var err error
+		resolved[i], err = mcp.ResolveDynamicEnums(tool, func(path string, provider mcp.EnumProvider) ([]string, error) {
+			return s.dynamicEnumValues(ctx, sessionID, tool.Name+path, provider)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// dynamicEnumValues returns the values of a dynamic enum for a session.
+// Values are only cached for sessions with an ID, as requests without one
+// may come from different callers.
+func (s *MCPServer) dynamicEnumValues(ctx context.Context, sessionID, key string, provider mcp.EnumProvider) ([]string, error) {
+	d := &s.dynamicEnums
+	now := s.Clock().Now()
+	d.mu.Lock()
+	generation := d.generation
+	cached, ok := d.sessions[sessionID][key]
+	d.mu.Unlock()
+	if ok && (cached.expires.IsZero() || now.Before(cached.expires)) {
+		return cached.values, nil
+	}
+
+	values, err := provider.EnumValues(ctx)
+	if err != nil || sessionID == "" {
+		return values, err
+	}
+
+	entry := cachedEnum{values: values}
+	if d.ttl > 0 {
+		entry.expires = now.Add(d.ttl)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.generation != generation {
+		return values, nil
+	}
+	if d.sessions == nil {
+		d.sessions = make(map[string]map[string]cachedEnum)
+	}
+	if d.sessions[sessionID] == nil {
+		d.sessions[sessionID] = make(map[string]cachedEnum)
+	}
+	d.sessions[sessionID][key] = entry
+	return values, nil
+}
+
+// watchDynamicEnums subscribes to the providers of the tools' dynamic enums
+// that signal changes, replacing the subscriptions of tools registered
+// before under the same names. sessionID is empty for the server's tools.
+func (s *MCPServer) watchDynamicEnums(sessionID string, tools []ServerTool) {
+	d := &s.dynamicEnums
+	for _, tool := range tools {
+		var unsubscribe []func()
+		for _, provider := range mcp.DynamicEnumProviders(tool.Tool) {
+			if notifier, ok := provider.(mcp.EnumChangeNotifier); ok {
+				unsubscribe = append(unsubscribe, notifier.OnEnumChange(s.dynamicEnumsChanged))
+			}
+		}
+		key := enumSubscriptionKey{sessionID, tool.Tool.Name}
+		d.mu.Lock()
+		previous := d.subscriptions[key]
+		if len(unsubscribe) > 0 {
+			if d.subscriptions == nil {
+				d.subscriptions = make(map[enumSubscriptionKey][]func())
+			}
+			d.subscriptions[key] = unsubscribe
+		} else {
+			delete(d.subscriptions, key)
+		}
+		d.mu.Unlock()
+		for _, f := range previous {
+			f()
+		}
+	}
+}
+
+// unwatchDynamicEnums drops the subscriptions of removed tools.
+func (s *MCPServer) unwatchDynamicEnums(sessionID string, names ...string) {
+	d := &s.dynamicEnums
+	var unsubscribe []func()
+	d.mu.Lock()
+	for _, name := range names {
+		key := enumSubscriptionKey{sessionID, name}
+		unsubscribe = append(unsubscribe, d.subscriptions[key]...)
+		delete(d.subscriptions, key)
+	}
+	d.mu.Unlock()
+	for _, f := range unsubscribe {
+		f()
+	}
+}
+
+// dynamicEnumsChanged drops the cached values and tells the clients to list
+// the tools again.
+func (s *MCPServer) dynamicEnumsChanged() {
+	s.dynamicEnums.mu.Lock()
+	s.dynamicEnums.generation++
+	s.dynamicEnums.sessions = nil
+	s.dynamicEnums.mu.Unlock()
+
+	if s.capabilities.tools != nil && s.capabilities.tools.listChanged {
+		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	}
+}
+
+// dropSession forgets the cached values of a session that ended, and drops
+// the subscriptions of its tools.
+func (d *dynamicEnums) dropSession(sessionID string) {
+	var unsubscribe []func()
+	d.mu.Lock()
+	delete(d.sessions, sessionID)
+	for key, subscriptions := range d.subscriptions {
+		if key.sessionID == sessionID {
+			unsubscribe = append(unsubscribe, subscriptions...)
+			delete(d.subscriptions, key)
+		}
+	}
+	d.mu.Unlock()
+	for _, f := range unsubscribe {
+		f()
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// projectsProvider returns the projects of the calling session.
+type projectsProvider struct {
+	mcp.EnumChanges
+	calls    atomic.Int32
+	projects map[string][]string
+}
+
+func (p *projectsProvider) EnumValues(ctx context.Context) ([]string, error) {
+	p.calls.Add(1)
+	return p.projects[ClientSessionFromContext(ctx).SessionID()], nil
+}
+
+func listToolEnum(t *testing.T, s *MCPServer, ctx context.Context) any {
+	t.Helper()
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	tools := resp.Result.(mcp.ListToolsResult).Tools
+	require.Len(t, tools, 1)
+	return tools[0].InputSchema.Properties["project"].(map[string]any)["enum"]
+}
+
+func TestDynamicEnum(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_700_000_000, 0)}
+	s := NewMCPServer("test", "1.0.0", WithToolCapabilities(true), WithClock(clock), WithDynamicEnumTTL(time.Minute))
+	provider := &projectsProvider{projects: map[string][]string{"alice": {"apollo"}, "bob": {"gemini", "mercury"}}}
+	s.AddTool(mcp.NewTool("deploy", mcp.WithString("project", mcp.WithDynamicEnum(provider))), nil)
+
+	sessions := map[string]*sessionTestClientWithClientInfo{}
+	contexts := map[string]context.Context{}
+	for _, id := range []string{"alice", "bob"} {
+		sessions[id] = &sessionTestClientWithClientInfo{sessionID: id, notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+		require.NoError(t, s.RegisterSession(context.Background(), sessions[id]))
+		contexts[id] = s.WithContext(context.Background(), sessions[id])
+	}
+
+	assert.Equal(t, []string{"apollo"}, listToolEnum(t, s, contexts["alice"]))
+	assert.Equal(t, []string{"gemini", "mercury"}, listToolEnum(t, s, contexts["bob"]))
+	assert.Equal(t, []string{"apollo"}, listToolEnum(t, s, contexts["alice"]))
+	assert.Equal(t, int32(2), provider.calls.Load(), "values are cached per session")
+
+	clock.advance(2 * time.Minute)
+	assert.Equal(t, []string{"apollo"}, listToolEnum(t, s, contexts["alice"]))
+	assert.Equal(t, int32(3), provider.calls.Load(), "cached values expire")
+
+	provider.projects["alice"] = []string{"apollo", "artemis"}
+	provider.Changed()
+	for id, session := range sessions {
+		select {
+		case notification := <-session.notificationChannel:
+			assert.Equal(t, mcp.MethodNotificationToolsListChanged, notification.Method)
+		default:
+			t.Fatalf("session %s was not notified", id)
+		}
+	}
+	assert.Equal(t, []string{"apollo", "artemis"}, listToolEnum(t, s, contexts["alice"]))
+
+	// Deleted tools no longer subscribe to their providers.
+	s.DeleteTools("deploy")
+	for _, session := range sessions {
+		<-session.notificationChannel
+	}
+	provider.Changed()
+	for id, session := range sessions {
+		assert.Empty(t, session.notificationChannel, "session %s was notified", id)
+	}
+}
+
+func TestDynamicEnum_ProviderFails(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	failing := mcp.EnumProviderFunc(func(ctx context.Context) ([]string, error) {
+		return nil, errors.New("database down")
+	})
+	s.AddTool(mcp.NewTool("deploy", mcp.WithString("project", mcp.WithDynamicEnum(failing))), nil)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "database down")
+}
+
+func TestDynamicEnum_SessionToolsAndSearch(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolCapabilities(true), WithToolSearch())
+	provider := &projectsProvider{projects: map[string][]string{"alice": {"apollo"}}}
+	session := &sessionTestClientWithTools{sessionID: "alice", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := s.WithContext(context.Background(), session)
+
+	deploy := ServerTool{Tool: mcp.NewTool("deploy", mcp.WithString("project", mcp.WithDynamicEnum(provider))), Handler: noopToolHandler}
+	require.NoError(t, s.AddSessionTools("alice", deploy))
+	<-session.notificationChannel
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/search","params":{"query":"deploy"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	tools := resp.Result.(*mcp.SearchToolsResult).Tools
+	require.Len(t, tools, 1)
+	assert.Equal(t, []string{"apollo"}, tools[0].InputSchema.Properties["project"].(map[string]any)["enum"], "search results are resolved")
+
+	// Session tools subscribe to their providers until they are deleted.
+	provider.Changed()
+	require.Len(t, session.notificationChannel, 1)
+	<-session.notificationChannel
+	require.NoError(t, s.DeleteSessionTools("alice", "deploy"))
+	<-session.notificationChannel
+	provider.Changed()
+	assert.Empty(t, session.notificationChannel)
+
+	// Or until the session ends.
+	require.NoError(t, s.AddSessionTools("alice", deploy))
+	assert.Len(t, s.dynamicEnums.subscriptions, 1)
+	s.UnregisterSession(context.Background(), "alice")
+	assert.Empty(t, s.dynamicEnums.subscriptions)
+}
+
+func TestDynamicEnum_SchemaHashes(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolCapabilities(true), WithToolSchemaHashes())
+	provider := &projectsProvider{projects: map[string][]string{"alice": {"apollo"}}}
+	s.AddTool(mcp.NewTool("deploy", mcp.WithString("project", mcp.WithDynamicEnum(provider))), noopToolHandler)
+	session := &sessionTestClientWithClientInfo{sessionID: "alice", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := s.WithContext(context.Background(), session)
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	tools := resp.Result.(mcp.ListToolsResult).Tools
+	require.Len(t, tools, 1)
+	require.NotEmpty(t, mcp.ToolSchemaHashFromMeta(tools[0].Meta))
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"deploy","arguments":{"project":"apollo"}}}`))
+	resp, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+	assert.Equal(t, mcp.ToolSchemaHashFromMeta(tools[0].Meta), mcp.ToolSchemaHashFromMeta(result.Meta))
+	assert.False(t, mcp.IsToolSchemaStale(tools[0], &result))
+}
@@ -28,7 +28,20 @@ func (s *MCPServer) RequestElicitation(ctx context.Context, request mcp.Elicitat
 		if err := request.Params.Validate(); err != nil {
 			return nil, err
 		}
-		return elicitationSession.RequestElicitation(ctx, request)
+		ctx, done := s.clientRequestContext(ctx, mcp.MethodElicitationCreate, session.SessionID())
+		release, err := s.flowControl.acquire(ctx, session.SessionID())
+		if err != nil {
+			return nil, done(err)
+		}
+		result, err := elicitationSession.RequestElicitation(ctx, request)
+		release()
+		if err = done(err); err != nil {
+			return nil, err
+		}
+		if err := s.checkElicitationAnswer(result); err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
 
 	return nil, ErrElicitationNotSupported
@@ -66,7 +79,14 @@ func (s *MCPServer) RequestURLElicitation(
 	}
 
 	if elicitationSession, ok := session.(SessionWithElicitation); ok {
-		return elicitationSession.RequestElicitation(ctx, request)
+		ctx, done := s.clientRequestContext(ctx, mcp.MethodElicitationCreate, session.SessionID())
+		release, err := s.flowControl.acquire(ctx, session.SessionID())
+		if err != nil {
+			return nil, done(err)
+		}
+		result, err := elicitationSession.RequestElicitation(ctx, request)
+		release()
+		return result, done(err)
 	}
 	return nil, ErrElicitationNotSupported
 }
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TranslatedError is the user-facing form of an error returned by a tool
+// handler.
+type TranslatedError struct {
+	// Code is a stable, machine-readable error code.
+	Code string `json:"code"`
+	// Message is safe to show to users and models.
+	Message string `json:"message"`
+}
+
+type errorRule struct {
+	translate func(err error) (TranslatedError, bool)
+}
+
+// ErrorTranslator maps errors returned by tool handlers to user-facing
+// messages and codes. Rules are tried in registration order and match
+// anywhere in the error's wrap chain, so internal details such as the
+// wrapped cause never reach the client.
+type ErrorTranslator struct {
+	rules    []errorRule
+	fallback *TranslatedError
+}
+
+// NewErrorTranslator creates an ErrorTranslator without rules.
+func NewErrorTranslator() *ErrorTranslator {
+	return &ErrorTranslator{}
+}
+
+// Register translates errors matching target, as reported by errors.Is.
+func (t *ErrorTranslator) Register(target error, code, message string) *ErrorTranslator {
+	t.rules = append(t.rules, errorRule{translate: func(err error) (TranslatedError, bool) {
+		if !errors.Is(err, target) {
+			return TranslatedError{}, false
+		}
+		return TranslatedError{Code: code, Message: message}, true
+	}})
+	return t
+}
+
+// Fallback translates errors no rule matches, e.g. to a generic "internal
+// error". Without a fallback, such errors are reported as before, as
+// JSON-RPC errors carrying the error text.
+func (t *ErrorTranslator) Fallback(code, message string) *ErrorTranslator {
+	t.fallback = &TranslatedError{Code: code, Message: message}
+	return t
+}
+
+// RegisterErrorType translates errors of type E found with errors.As, using
+// message to build the user-facing message from the typed error.
+func RegisterErrorType[E error](t *ErrorTranslator, code string, message func(E) string) *ErrorTranslator {
+	t.rules = append(t.rules, errorRule{translate: func(err error) (TranslatedError, bool) {
+		var target E
+		if !errors.As(err, &target) {
+			return TranslatedError{}, false
+		}
+		return TranslatedError{Code: code, Message: message(target)}, true
+	}})
+	return t
+}
+
+// Translate returns the user-facing form of err, or false if neither a rule
+// nor a fallback applies.
+func (t *ErrorTranslator) Translate(err error) (TranslatedError, bool) {
+	if t == nil || err == nil {
+		return TranslatedError{}, false
+	}
+	for _, rule := range t.rules {
+		if translated, ok := rule.translate(err); ok {
+			return translated, true
+		}
+	}
+	if t.fallback != nil {
+		return *t.fallback, true
+	}
+	return TranslatedError{}, false
+}
+
+// WithErrorTranslator translates errors returned by tool handlers into tool
+// results with IsError set, the translated message as text content and the
+// code and message as structured content. The original error is still passed
+// to the OnError hooks, so it remains available to logging and auditing.
+func WithErrorTranslator(translator *ErrorTranslator) ServerOption {
+	return func(s *MCPServer) {
+		s.errorTranslator = translator
+	}
+}
+
+// WithMessageCatalog translates the argument errors that typed tool handlers
+// report to clients, such as a string passed for a number, with catalog. The
+// catalog receives the call's context, so it can pick the locale from values
+// the transport's context function stored there.
+func WithMessageCatalog(catalog mcp.MessageCatalog) ServerOption {
+	return func(s *MCPServer) {
+		s.messageCatalog = catalog
+	}
+}
+
+// translateToolError turns err into an error result if the server's error
+// translator handles it.
+func (s *MCPServer) translateToolError(ctx context.Context, id any, request mcp.CallToolRequest, err error) (*mcp.CallToolResult, bool) {
+	translated, ok := s.errorTranslator.Translate(err)
+	if !ok {
+		return nil, false
+	}
+	s.hooks.onError(ctx, id, mcp.MethodToolsCall, &request, err)
+
+	result := mcp.NewToolResultError(translated.Message)
+	result.StructuredContent = translated
+	return result, true
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+type validationError struct {
+	Field string
+}
+
+func (e *validationError) Error() string { return "invalid field " + e.Field }
+
+func TestErrorTranslator_Translate(t *testing.T) {
+	translator := NewErrorTranslator().
+		Register(errQuotaExceeded, "quota_exceeded", "You have used up your quota.")
+	RegisterErrorType(translator, "invalid_argument", func(err *validationError) string {
+		return "Check the " + err.Field + " argument."
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want TranslatedError
+		ok   bool
+	}{
+		{
+			name: "wrapped sentinel",
+			err:  fmt.Errorf("db shard 7: %w", errQuotaExceeded),
+			want: TranslatedError{Code: "quota_exceeded", Message: "You have used up your quota."},
+			ok:   true,
+		},
+		{
+			name: "wrapped type",
+			err:  fmt.Errorf("decode: %w", &validationError{Field: "city"}),
+			want: TranslatedError{Code: "invalid_argument", Message: "Check the city argument."},
+			ok:   true,
+		},
+		{
+			name: "unmatched",
+			err:  errors.New("connection refused"),
+		},
+		{
+			name: "nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := translator.Translate(tt.err)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	translator.Fallback("internal", "Something went wrong.")
+	got, ok := translator.Translate(errors.New("connection refused"))
+	assert.True(t, ok)
+	assert.Equal(t, TranslatedError{Code: "internal", Message: "Something went wrong."}, got)
+
+	_, ok = (*ErrorTranslator)(nil).Translate(errQuotaExceeded)
+	assert.False(t, ok)
+}
+
+func TestWithErrorTranslator(t *testing.T) {
+	var hookErrs []error
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		hookErrs = append(hookErrs, err)
+	})
+	s := NewMCPServer("test", "1.0.0",
+		WithHooks(hooks),
+		WithErrorTranslator(NewErrorTranslator().Register(errQuotaExceeded, "quota_exceeded", "Quota exceeded.")),
+	)
+	internal := fmt.Errorf("tenant 42 on shard 7: %w", errQuotaExceeded)
+	s.AddTool(mcp.NewTool("quota"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, internal
+	})
+	s.AddTool(mcp.NewTool("broken"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"quota"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Quota exceeded.", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, TranslatedError{Code: "quota_exceeded", Message: "Quota exceeded."}, result.StructuredContent)
+	require.Len(t, hookErrs, 1)
+	assert.Same(t, internal, hookErrs[0])
+
+	// Unmatched errors are reported as before.
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"broken"}}`))
+	_, ok = response.(mcp.JSONRPCError)
+	assert.True(t, ok, "unexpected response %#v", response)
+}
+
+func TestWithMessageCatalog(t *testing.T) {
+	type localeKey struct{}
+	messages := map[string]string{
+		"fr": "Le champ %s doit être de type %s",
+	}
+
+	s := NewMCPServer("test", "1.0.0", WithMessageCatalog(func(ctx context.Context, err *mcp.ArgumentError) string {
+		locale, _ := ctx.Value(localeKey{}).(string)
+		if format, ok := messages[locale]; ok && err.Constraint == mcp.ArgumentConstraintType {
+			return fmt.Sprintf(format, err.Field, err.Want)
+		}
+		return ""
+	}))
+	s.AddTools(NewTypedTool(mcp.NewTool("weather", mcp.WithString("city"), mcp.WithNumber("days")), validateHandler))
+
+	call := func(ctx context.Context) mcp.CallToolResult {
+		response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"weather","arguments":{"city":"Paris","days":"deux"}}}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		return resp.Result.(mcp.CallToolResult)
+	}
+
+	result := call(context.WithValue(context.Background(), localeKey{}, "fr"))
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Le champ days doit être de type integer", result.Content[0].(mcp.TextContent).Text)
+
+	result = call(context.Background())
+	assert.Equal(t, "failed to bind arguments: argument days: got string, want integer", result.Content[0].(mcp.TextContent).Text)
+}
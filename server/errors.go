@@ -12,20 +12,111 @@ var (
 	ErrPromptNotFound   = errors.New("prompt not found")
 	ErrToolNotFound     = errors.New("tool not found")
 
+	// ErrBreakingSchemaChange is returned when a tool's input schema breaks
+	// compatibility with the schema baseline.
+	ErrBreakingSchemaChange = errors.New("breaking schema change")
+
+	// ErrChaosInjected is returned by tool calls failed by WithChaos.
+	ErrChaosInjected = errors.New("chaos fault injected")
+
+	// ErrInvalidRegistration is returned by Validate for registered tools,
+	// prompts and resources that cannot work as registered.
+	ErrInvalidRegistration = errors.New("invalid registration")
+
+	// ErrSamplingBudgetExceeded is wrapped by SamplingBudgetError.
+	ErrSamplingBudgetExceeded = errors.New("sampling budget exceeded")
+
+	// ErrNoInteractiveClient is returned for requests the server sends while
+	// processing a stream with NDJSONServer that have no configured answer.
+	ErrNoInteractiveClient = errors.New("no interactive client")
+
+	// ErrClientCapabilityRequired is returned for calls to a tool that needs
+	// client capabilities the session's client did not declare.
+	ErrClientCapabilityRequired = errors.New("client capability required")
+
+	// ErrInvalidBinaryContent is returned for image or audio content that
+	// fails the limits set with WithBinaryContentLimits.
+	ErrInvalidBinaryContent = errors.New("invalid binary content")
+
+	// ErrInvalidContinuation is returned by result continuations for tokens
+	// they do not know, e.g. because the paginated result expired.
+	ErrInvalidContinuation = errors.New("invalid continuation token")
+
+	// ErrClientRequestTimeout is wrapped by ClientRequestTimeoutError.
+	ErrClientRequestTimeout = errors.New("client request timed out")
+
+	// ErrClientRequestQueueFull is returned for requests to a client whose
+	// queue, limited with WithClientRequestQueueLimit, is full.
+	ErrClientRequestQueueFull = errors.New("client request queue full")
+
+	// ErrTaskQueueEmpty is returned by TaskQueue.Claim when no job is ready
+	// to run.
+	ErrTaskQueueEmpty = errors.New("task queue empty")
+
+	// ErrTaskJobNotFound is returned by a TaskQueue for jobs it does not know.
+	ErrTaskJobNotFound = errors.New("task job not found")
+
+	// ErrTaskJobCancelled is returned by TaskQueue.Extend and Finish for jobs
+	// cancelled since they were claimed.
+	ErrTaskJobCancelled = errors.New("task job cancelled")
+
+	// ErrTaskJobLeaseLost is returned by TaskQueue.Extend and Finish to a
+	// worker whose lease on a job expired, so another may have claimed it.
+	ErrTaskJobLeaseLost = errors.New("task job lease lost")
+
+	// ErrInvalidDialog is returned for dialogs whose steps are not uniquely
+	// named, that branch to unknown steps or that never end.
+	ErrInvalidDialog = errors.New("invalid dialog")
+
+	// ErrDialogInvalidAnswer is returned by Dialog.Run when a step's answer
+	// failed validation more often than allowed.
+	ErrDialogInvalidAnswer = errors.New("invalid dialog answer")
+
+	// ErrInvalidToolDowntime is returned by ScheduleToolDowntime for
+	// downtimes ending before they start.
+	ErrInvalidToolDowntime = errors.New("invalid tool downtime")
+
 	// Session-related errors
 	ErrSessionNotFound                        = errors.New("session not found")
 	ErrSessionExists                          = errors.New("session already exists")
 	ErrSessionNotInitialized                  = errors.New("session not properly initialized")
+	ErrSessionClosed                          = errors.New("session closed")
 	ErrSessionDoesNotSupportTools             = errors.New("session does not support per-session tools")
 	ErrSessionDoesNotSupportResources         = errors.New("session does not support per-session resources")
 	ErrSessionDoesNotSupportResourceTemplates = errors.New("session does not support resource templates")
 	ErrSessionDoesNotSupportLogging           = errors.New("session does not support setting logging level")
 
+	// Task-related errors
+	ErrTaskNotFound          = errors.New("task not found")
+	ErrInvalidTaskTransition = errors.New("invalid task status transition")
+
 	// Notification-related errors
 	ErrNotificationNotInitialized = errors.New("notification channel not initialized")
 	ErrNotificationChannelBlocked = errors.New("notification channel queue is full - client may not be processing notifications fast enough")
 )
 
+// SamplingBudgetError is returned by RequestSampling when a request would
+// exceed the budget of its session or task. It wraps
+// ErrSamplingBudgetExceeded.
+type SamplingBudgetError struct {
+	// Scope is "session" or "task".
+	Scope string
+	// ID is the session or task ID.
+	ID string
+	// Limit is the exceeded limit: "requests", "tokens" or "cost".
+	Limit string
+	Used  float64
+	Max   float64
+}
+
+func (e *SamplingBudgetError) Error() string {
+	return fmt.Sprintf("%s %s: %s limit reached (%v of %v): %v", e.Scope, e.ID, e.Limit, e.Used, e.Max, ErrSamplingBudgetExceeded)
+}
+
+func (e *SamplingBudgetError) Unwrap() error {
+	return ErrSamplingBudgetExceeded
+}
+
 // ErrDynamicPathConfig is returned when attempting to use static path methods with dynamic path configuration
 type ErrDynamicPathConfig struct {
 	Method string
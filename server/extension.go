@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// extensionHandlerFunc handles a request for a method that is not part of
+// the protocol, such as tools/search.
+type extensionHandlerFunc func(ctx context.Context, id any, message json.RawMessage, header http.Header) (any, *requestError)
+
+// handleExtension registers the handler of a non-standard request method.
+func (s *MCPServer) handleExtension(method mcp.MCPMethod, handler extensionHandlerFunc) {
+	if s.extensions == nil {
+		s.extensions = make(map[mcp.MCPMethod]extensionHandlerFunc)
+	}
+	s.extensions[method] = handler
+}
+
+// extensionHandler returns the handler registered for a non-standard method.
+func (s *MCPServer) extensionHandler(method mcp.MCPMethod) (extensionHandlerFunc, bool) {
+	handler, ok := s.extensions[method]
+	return handler, ok
+}
+
+// WithExperimentalCapability declares an experimental server capability,
+// such as a vendor extension, in the initialize result. Use
+// mcp.ExperimentalCapability to read the payload on the client.
+func WithExperimentalCapability(name string, value any) ServerOption {
+	return func(s *MCPServer) {
+		s.setExperimentalCapability(name, value)
+	}
+}
+
+// ClientExperimentalCapabilities returns the experimental capabilities the
+// client of the current session declared, or nil if the session does not
+// track client capabilities. Read entries with mcp.ExperimentalCapability.
+func ClientExperimentalCapabilities(ctx context.Context) map[string]any {
+	session, ok := ClientSessionFromContext(ctx).(SessionWithClientInfo)
+	if !ok {
+		return nil
+	}
+	return session.GetClientCapabilities().Experimental
+}
+
+// setExperimentalCapability declares an experimental server capability in
+// the initialize result.
+func (s *MCPServer) setExperimentalCapability(name string, value any) {
+	s.capabilitiesMu.Lock()
+	defer s.capabilitiesMu.Unlock()
+	if s.capabilities.experimental == nil {
+		s.capabilities.experimental = make(map[string]any)
+	}
+	s.capabilities.experimental[name] = value
+}
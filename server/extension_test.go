@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWithExperimentalCapability(t *testing.T) {
+	canvas := mcp.NewExperimentalCapability[mcp.VersionedCapability]("x-canvas")
+	s := NewMCPServer("test", "1.0.0", WithExperimentalCapability(canvas.Name, mcp.VersionedCapability{Versions: []string{"2", "1"}}))
+
+	var clientCanvas mcp.VersionedCapability
+	s.AddTool(mcp.NewTool("draw"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var err error
+		clientCanvas, _, err = canvas.Get(ClientExperimentalCapabilities(ctx))
+		return mcp.NewToolResultText("ok"), err
+	})
+
+	session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+	ctx := s.WithContext(context.Background(), session)
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"experimental":{"x-canvas":{"versions":["1"]}}},"clientInfo":{"name":"test","version":"1"}}}`))
+	initialize := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+	serverCanvas, ok, err := canvas.Get(initialize.Capabilities.Experimental)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"2", "1"}, serverCanvas.Versions)
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"draw"}}`))
+	_, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, []string{"1"}, clientCanvas.Versions)
+
+	assert.Nil(t, ClientExperimentalCapabilities(context.Background()))
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClientRequestQueueStats describes the flow control of the requests the
+// server sends to one session's client: sampling, elicitation and
+// roots/list.
+type ClientRequestQueueStats struct {
+	// InFlight is the number of requests awaiting the client's answer.
+	InFlight int
+	// Queued is the number of requests waiting to be sent.
+	Queued int
+	// QueuedByTask counts the queued requests by the task making them; ""
+	// counts those made outside of tasks.
+	QueuedByTask map[string]int
+	// Sent is the number of requests sent so far.
+	Sent int
+	// Rejected is the number of requests refused because the queue was
+	// full.
+	Rejected int
+	// MaxWait is the longest time a sent request waited in the queue.
+	MaxWait time.Duration
+}
+
+// FlowControlOption configures WithClientRequestLimit.
+type FlowControlOption func(*flowControl)
+
+// WithClientRequestQueueLimit caps the number of requests queued per
+// session. Requests beyond it fail with ErrClientRequestQueueFull. The
+// queue is unbounded by default.
+func WithClientRequestQueueLimit(n int) FlowControlOption {
+	return func(f *flowControl) {
+		f.maxQueued = n
+	}
+}
+
+// WithClientRequestLimit limits the number of sampling, elicitation and
+// roots/list requests awaiting an answer from each session's client to
+// maxInFlight, so that an aggressive task cannot flood a slow client.
+// Further requests wait in a queue until one is answered or their context
+// is done. The queue is served round-robin across the tasks making the
+// requests, so each task gets its turn, and in order within a task. Queued
+// requests fail with ErrSessionClosed when their session is unregistered.
+func WithClientRequestLimit(maxInFlight int, opts ...FlowControlOption) ServerOption {
+	return func(s *MCPServer) {
+		if maxInFlight <= 0 {
+			return
+		}
+		f := &flowControl{
+			clock:       s.Clock,
+			maxInFlight: maxInFlight,
+			sessions:    make(map[string]*sessionFlow),
+		}
+		for _, opt := range opts {
+			opt(f)
+		}
+		s.flowControl = f
+	}
+}
+
+// ClientRequestQueueStats returns the flow control statistics of a session,
+// or zero statistics without WithClientRequestLimit.
+func (s *MCPServer) ClientRequestQueueStats(sessionID string) ClientRequestQueueStats {
+	if s.flowControl == nil {
+		return ClientRequestQueueStats{}
+	}
+	s.flowControl.mu.Lock()
+	defer s.flowControl.mu.Unlock()
+	session, ok := s.flowControl.sessions[sessionID]
+	if !ok {
+		return ClientRequestQueueStats{}
+	}
+	stats := session.stats
+	stats.InFlight = session.inFlight
+	stats.QueuedByTask = make(map[string]int, len(session.waiters))
+	for taskID, waiters := range session.waiters {
+		stats.Queued += len(waiters)
+		stats.QueuedByTask[taskID] = len(waiters)
+	}
+	return stats
+}
+
+type flowControl struct {
+	clock       func() Clock
+	maxInFlight int
+	maxQueued   int
+
+	mu       sync.Mutex
+	sessions map[string]*sessionFlow
+}
+
+type sessionFlow struct {
+	inFlight int
+	// lanes are the tasks with queued requests, in the order they are
+	// served; waiters are their requests, oldest first.
+	lanes   []string
+	waiters map[string][]*flowWaiter
+	stats   ClientRequestQueueStats
+}
+
+type flowWaiter struct {
+	ready    chan struct{}
+	queuedAt time.Time
+	// err is set before ready is closed when the request will not be sent.
+	err error
+}
+
+// acquire waits for the turn of a request to a session's client made by
+// the task of ctx, and returns the function to call once the client has
+// answered. Without flow control, it returns immediately.
+func (f *flowControl) acquire(ctx context.Context, sessionID string) (func(), error) {
+	if f == nil {
+		return func() {}, nil
+	}
+	taskID := TaskIDFromContext(ctx)
+
+	f.mu.Lock()
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		session = &sessionFlow{waiters: make(map[string][]*flowWaiter)}
+		f.sessions[sessionID] = session
+	}
+	if session.inFlight < f.maxInFlight && len(session.lanes) == 0 {
+		session.inFlight++
+		session.stats.Sent++
+		f.mu.Unlock()
+		return f.releaser(sessionID), nil
+	}
+	if f.maxQueued > 0 && session.queued() >= f.maxQueued {
+		session.stats.Rejected++
+		f.mu.Unlock()
+		return nil, fmt.Errorf("session %s has %d queued requests: %w", sessionID, f.maxQueued, ErrClientRequestQueueFull)
+	}
+	waiter := &flowWaiter{ready: make(chan struct{}), queuedAt: f.clock().Now()}
+	if len(session.waiters[taskID]) == 0 {
+		session.lanes = append(session.lanes, taskID)
+	}
+	session.waiters[taskID] = append(session.waiters[taskID], waiter)
+	f.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		if waiter.err != nil {
+			return nil, waiter.err
+		}
+		return f.releaser(sessionID), nil
+	case <-ctx.Done():
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		select {
+		case <-waiter.ready:
+			// Admitted meanwhile: hand the turn to the next request.
+			if waiter.err == nil {
+				f.releaseLocked(sessionID)
+			}
+		default:
+			session.remove(taskID, waiter)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (f *flowControl) releaser(sessionID string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.releaseLocked(sessionID)
+		})
+	}
+}
+
+// releaseLocked ends a request in flight and admits the next queued one,
+// taking the lanes in turn.
+func (f *flowControl) releaseLocked(sessionID string) {
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		return
+	}
+	session.inFlight--
+	if len(session.lanes) == 0 {
+		return
+	}
+	taskID := session.lanes[0]
+	session.lanes = session.lanes[1:]
+	waiter := session.waiters[taskID][0]
+	session.waiters[taskID] = session.waiters[taskID][1:]
+	if len(session.waiters[taskID]) > 0 {
+		session.lanes = append(session.lanes, taskID)
+	} else {
+		delete(session.waiters, taskID)
+	}
+
+	session.inFlight++
+	session.stats.Sent++
+	if wait := f.clock().Now().Sub(waiter.queuedAt); wait > session.stats.MaxWait {
+		session.stats.MaxWait = wait
+	}
+	close(waiter.ready)
+}
+
+// dropSession forgets the flow control state of an unregistered session.
+// Its queued requests fail with ErrSessionClosed.
+func (f *flowControl) dropSession(sessionID string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		return
+	}
+	for _, waiters := range session.waiters {
+		for _, waiter := range waiters {
+			waiter.err = fmt.Errorf("session %s: %w", sessionID, ErrSessionClosed)
+			close(waiter.ready)
+		}
+	}
+	delete(f.sessions, sessionID)
+}
+
+func (s *sessionFlow) queued() int {
+	n := 0
+	for _, waiters := range s.waiters {
+		n += len(waiters)
+	}
+	return n
+}
+
+// remove takes a waiter that gave up out of the queue.
+func (s *sessionFlow) remove(taskID string, waiter *flowWaiter) {
+	waiters := s.waiters[taskID]
+	for i, w := range waiters {
+		if w == waiter {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) > 0 {
+		s.waiters[taskID] = waiters
+		return
+	}
+	delete(s.waiters, taskID)
+	for i, lane := range s.lanes {
+		if lane == taskID {
+			s.lanes = append(s.lanes[:i], s.lanes[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// gatedElicitationSession records the elicitations it receives and answers
+// each one when told to.
+type gatedElicitationSession struct {
+	mockBasicSession
+	mu       sync.Mutex
+	received []string
+	answer   chan struct{}
+}
+
+func (g *gatedElicitationSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	g.mu.Lock()
+	g.received = append(g.received, request.Params.Message)
+	g.mu.Unlock()
+	select {
+	case <-g.answer:
+		return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *gatedElicitationSession) messages() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.received...)
+}
+
+func elicitFor(s *MCPServer, session ClientSession, taskID, message string) (context.Context, func() error) {
+	ctx := s.WithContext(context.Background(), session)
+	if taskID != "" {
+		ctx = context.WithValue(ctx, taskIDKey{}, taskID)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.RequestElicitation(ctx, mcp.ElicitationRequest{Params: mcp.ElicitationParams{
+			Message:         message,
+			RequestedSchema: map[string]any{"type": "object"},
+		}})
+		done <- err
+	}()
+	return ctx, func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func TestClientRequestLimitFairness(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithClientRequestLimit(1, WithClientRequestQueueLimit(4)))
+	session := &gatedElicitationSession{mockBasicSession: mockBasicSession{sessionID: "s1"}, answer: make(chan struct{})}
+
+	queued := func(n int) func() bool {
+		return func() bool { return s.ClientRequestQueueStats("s1").Queued == n }
+	}
+	var waits []func() error
+	_, wait := elicitFor(s, session, "A", "a0")
+	waits = append(waits, wait)
+	require.Eventually(t, func() bool { return len(session.messages()) == 1 }, time.Second, time.Millisecond)
+	for i, message := range []string{"a1", "a2", "a3"} {
+		_, wait := elicitFor(s, session, "A", message)
+		waits = append(waits, wait)
+		require.Eventually(t, queued(i+1), time.Second, time.Millisecond)
+	}
+	_, wait = elicitFor(s, session, "B", "b1")
+	waits = append(waits, wait)
+	require.Eventually(t, queued(4), time.Second, time.Millisecond)
+
+	stats := s.ClientRequestQueueStats("s1")
+	assert.Equal(t, 1, stats.InFlight)
+	assert.Equal(t, map[string]int{"A": 3, "B": 1}, stats.QueuedByTask)
+
+	_, wait = elicitFor(s, session, "C", "c1")
+	assert.ErrorIs(t, wait(), ErrClientRequestQueueFull)
+
+	for i := 1; i <= 4; i++ {
+		session.answer <- struct{}{}
+		require.Eventually(t, func() bool { return len(session.messages()) == i+1 }, time.Second, time.Millisecond)
+		assert.Equal(t, 1, s.ClientRequestQueueStats("s1").InFlight)
+	}
+	session.answer <- struct{}{}
+	assert.Equal(t, []string{"a0", "a1", "b1", "a2", "a3"}, session.messages(), "tasks take turns")
+
+	for _, wait := range waits {
+		assert.NoError(t, wait())
+	}
+	stats = s.ClientRequestQueueStats("s1")
+	assert.Equal(t, 0, stats.InFlight)
+	assert.Equal(t, 0, stats.Queued)
+	assert.Equal(t, 5, stats.Sent)
+	assert.Equal(t, 1, stats.Rejected)
+}
+
+func TestClientRequestLimitCancelledWhileQueued(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithClientRequestLimit(1))
+	session := &gatedElicitationSession{mockBasicSession: mockBasicSession{sessionID: "s1"}, answer: make(chan struct{})}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	_, first := elicitFor(s, session, "", "first")
+	require.Eventually(t, func() bool { return len(session.messages()) == 1 }, time.Second, time.Millisecond)
+	_, queued := elicitFor(s, session, "", "queued")
+	require.Eventually(t, func() bool { return s.ClientRequestQueueStats("s1").Queued == 1 }, time.Second, time.Millisecond)
+
+	assert.ErrorIs(t, queued(), context.Canceled)
+	assert.Equal(t, 0, s.ClientRequestQueueStats("s1").Queued)
+
+	session.answer <- struct{}{}
+	assert.NoError(t, first())
+	_, next := elicitFor(s, session, "", "next")
+	session.answer <- struct{}{}
+	assert.NoError(t, next())
+	assert.Equal(t, []string{"first", "next"}, session.messages())
+
+	s.UnregisterSession(context.Background(), "s1")
+	assert.Equal(t, ClientRequestQueueStats{}, s.ClientRequestQueueStats("s1"))
+}
+
+func TestClientRequestLimitSessionClosedWhileQueued(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithClientRequestLimit(1))
+	session := &gatedElicitationSession{mockBasicSession: mockBasicSession{sessionID: "s1"}, answer: make(chan struct{})}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	_, first := elicitFor(s, session, "", "first")
+	require.Eventually(t, func() bool { return len(session.messages()) == 1 }, time.Second, time.Millisecond)
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.RequestElicitation(s.WithContext(context.Background(), session), mcp.ElicitationRequest{Params: mcp.ElicitationParams{
+			Message:         "queued",
+			RequestedSchema: map[string]any{"type": "object"},
+		}})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return s.ClientRequestQueueStats("s1").Queued == 1 }, time.Second, time.Millisecond)
+
+	s.UnregisterSession(context.Background(), "s1")
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrSessionClosed)
+	case <-time.After(time.Second):
+		t.Fatal("queued request not woken when its session closed")
+	}
+	assert.ErrorIs(t, first(), context.Canceled)
+	assert.Equal(t, []string{"first"}, session.messages())
+}
@@ -14,6 +14,10 @@ type OnRegisterSessionHookFunc func(ctx context.Context, session ClientSession)
 // OnUnregisterSessionHookFunc is a hook that will be called when a session is being unregistered.
 type OnUnregisterSessionHookFunc func(ctx context.Context, session ClientSession)
 
+// OnTaskStatusChangeHookFunc is a hook that will be called after a task
+// changes status. The context is that of the request that created the task.
+type OnTaskStatusChangeHookFunc func(ctx context.Context, task mcp.Task)
+
 // BeforeAnyHookFunc is a function that is called after the request is
 // parsed but before the method is called.
 type BeforeAnyHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, message any)
@@ -106,6 +110,7 @@ type OnAfterCancelTaskFunc func(ctx context.Context, id any, message *mcp.Cancel
 type Hooks struct {
 	OnRegisterSession             []OnRegisterSessionHookFunc
 	OnUnregisterSession           []OnUnregisterSessionHookFunc
+	OnTaskStatusChange            []OnTaskStatusChangeHookFunc
 	OnBeforeAny                   []BeforeAnyHookFunc
 	OnSuccess                     []OnSuccessHookFunc
 	OnError                       []OnErrorHookFunc
@@ -264,6 +269,19 @@ func (c *Hooks) UnregisterSession(ctx context.Context, session ClientSession) {
 	}
 }
 
+func (c *Hooks) AddOnTaskStatusChange(hook OnTaskStatusChangeHookFunc) {
+	c.OnTaskStatusChange = append(c.OnTaskStatusChange, hook)
+}
+
+func (c *Hooks) taskStatusChanged(ctx context.Context, task mcp.Task) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnTaskStatusChange {
+		hook(ctx, task)
+	}
+}
+
 func (c *Hooks) AddOnRequestInitialization(hook OnRequestInitializationFunc) {
 	c.OnRequestInitialization = append(c.OnRequestInitialization, hook)
 }
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// IDKind is the kind of identifier an IDGenerator makes.
+type IDKind int
+
+const (
+	// IDKindSession identifies the sessions of the SSE and streamable HTTP
+	// transports.
+	IDKindSession IDKind = iota + 1
+	// IDKindTask identifies the tasks created by task-augmented requests.
+	IDKindTask
+	// IDKindRequest identifies the requests the server sends to clients,
+	// such as sampling, elicitation, roots and ping requests.
+	IDKindRequest
+)
+
+func (k IDKind) String() string {
+	switch k {
+	case IDKindSession:
+		return "session"
+	case IDKindTask:
+		return "task"
+	case IDKindRequest:
+		return "request"
+	default:
+		return "unknown"
+	}
+}
+
+// IDGenerator makes the identifiers of the server's sessions, tasks and
+// requests to clients, e.g. to make them sortable or namespaced for
+// correlation in downstream systems.
+//
+// Implementations must be safe for concurrent use and return unique IDs.
+type IDGenerator interface {
+	NewID(kind IDKind) string
+}
+
+// IDGeneratorFunc adapts a function to an IDGenerator.
+type IDGeneratorFunc func(kind IDKind) string
+
+// NewID implements IDGenerator.
+func (f IDGeneratorFunc) NewID(kind IDKind) string {
+	return f(kind)
+}
+
+// WithIDGenerator sets the generator of session IDs, task IDs and the IDs
+// of the requests the server sends to clients. By default, sessions and
+// tasks get random UUIDs and requests get numbers counting up from 1 per
+// session; with a generator, requests get string IDs.
+//
+// Session IDs are generated by the SSE transport and, for streamable HTTP,
+// by StatelessGeneratingSessionIdManager and
+// InsecureStatefulSessionIdManager unless their Generator is set.
+func WithIDGenerator(gen IDGenerator) ServerOption {
+	return func(s *MCPServer) {
+		s.idGenerator = gen
+	}
+}
+
+// newID returns a new ID of the given kind, a random UUID without
+// WithIDGenerator.
+func (s *MCPServer) newID(kind IDKind) string {
+	if s != nil && s.idGenerator != nil {
+		return s.idGenerator.NewID(kind)
+	}
+	return uuid.New().String()
+}
+
+// ids returns the server's IDGenerator, or nil if it has none.
+func (s *MCPServer) ids() IDGenerator {
+	if s == nil {
+		return nil
+	}
+	return s.idGenerator
+}
+
+// nextRequestID returns the ID of a request to a client: from gen if set,
+// otherwise the next value of counter.
+func nextRequestID(gen IDGenerator, counter *atomic.Int64) mcp.RequestId {
+	if gen != nil {
+		return mcp.NewRequestId(gen.NewID(IDKindRequest))
+	}
+	return mcp.NewRequestId(counter.Add(1))
+}
+
+// validRequestID reports whether id can be the ID of a request to a
+// client: a string with an IDGenerator, a number otherwise.
+func validRequestID(gen IDGenerator, id mcp.RequestId) bool {
+	switch id.Value().(type) {
+	case string:
+		return gen != nil
+	case nil:
+		return false
+	default:
+		return gen == nil
+	}
+}
+
+// requestIDKey returns the key correlating a client's response with the
+// pending request of the given ID. Numeric IDs match whether they were
+// decoded as integers or floats.
+func requestIDKey(id any) string {
+	if rid, ok := id.(mcp.RequestId); ok {
+		return rid.String()
+	}
+	return mcp.NewRequestId(id).String()
+}
+
+// UUIDv7Generator returns an IDGenerator of time-ordered UUIDs (RFC 9562
+// version 7), which sort by creation time.
+func UUIDv7Generator() IDGenerator {
+	return IDGeneratorFunc(func(IDKind) string {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return uuid.New().String()
+		}
+		return id.String()
+	})
+}
+
+// ULIDGenerator returns an IDGenerator of ULIDs: 26 characters of
+// Crockford base32 that sort by creation time, reading the time from clock,
+// or the system clock if clock is nil. IDs made within the same millisecond
+// keep increasing, except session IDs: they get fresh randomness every time
+// so that they cannot be guessed from one another, and only sort by the
+// millisecond.
+func ULIDGenerator(clock Clock) IDGenerator {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &ulidGenerator{clock: clock}
+}
+
+// PrefixedIDGenerator returns an IDGenerator that prepends the prefix of
+// each kind, e.g. "sess_" or "task_", to the IDs of base, or of random
+// UUIDs if base is nil. Kinds without a prefix get the IDs of base as is.
+func PrefixedIDGenerator(prefixes map[IDKind]string, base IDGenerator) IDGenerator {
+	return IDGeneratorFunc(func(kind IDKind) string {
+		var id string
+		if base != nil {
+			id = base.NewID(kind)
+		} else {
+			id = uuid.New().String()
+		}
+		return prefixes[kind] + id
+	})
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+type ulidGenerator struct {
+	clock Clock
+
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+// NewID implements IDGenerator.
+func (g *ulidGenerator) NewID(kind IDKind) string {
+	ms := uint64(g.clock.Now().UnixMilli())
+
+	var id [16]byte
+	if kind == IDKindSession {
+		binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+		binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+		_, _ = rand.Read(id[6:])
+		return encodeULID(id)
+	}
+
+	g.mu.Lock()
+	if ms > g.lastMs {
+		g.lastMs = ms
+		_, _ = rand.Read(g.entropy[:])
+	} else {
+		// Same millisecond, or the clock went back: stay monotonic.
+		ms = g.lastMs
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+		}
+	}
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], g.entropy[:])
+	g.mu.Unlock()
+
+	return encodeULID(id)
+}
+
+// encodeULID encodes the 128 bits of a ULID into 26 base32 characters, the
+// first of which holds the top 3 bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
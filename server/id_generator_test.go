@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// countingIDGenerator makes IDs like "session-1" and "request-2".
+func countingIDGenerator() IDGenerator {
+	var n atomic.Int64
+	return IDGeneratorFunc(func(kind IDKind) string {
+		return fmt.Sprintf("%s-%d", kind, n.Add(1))
+	})
+}
+
+func TestULIDGenerator(t *testing.T) {
+	clock := &manualClock{now: time.UnixMilli(1_700_000_000_000)}
+	gen := ULIDGenerator(clock)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, gen.NewID(IDKindTask))
+	}
+	clock.advance(time.Millisecond)
+	ids = append(ids, gen.NewID(IDKindTask))
+
+	for _, id := range ids {
+		require.Len(t, id, 26)
+		assert.Empty(t, strings.Trim(id, crockfordBase32), "unexpected characters in %s", id)
+	}
+	// The first 10 characters encode the time.
+	assert.Equal(t, ids[0][:10], ids[2][:10])
+	assert.NotEqual(t, ids[2][:10], ids[3][:10])
+	assert.True(t, sort.StringsAreSorted(ids), "IDs not increasing: %v", ids)
+	assert.Equal(t, "01HF7YAT00", ids[0][:10])
+
+	// Session IDs of the same millisecond are not one increment apart.
+	first, second := gen.NewID(IDKindSession), gen.NewID(IDKindSession)
+	assert.Equal(t, first[:10], second[:10])
+	assert.NotEqual(t, first[10:25], second[10:25])
+}
+
+func TestUUIDv7Generator(t *testing.T) {
+	gen := UUIDv7Generator()
+	id, err := uuid.Parse(gen.NewID(IDKindSession))
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+}
+
+func TestPrefixedIDGenerator(t *testing.T) {
+	gen := PrefixedIDGenerator(map[IDKind]string{
+		IDKindSession: "sess_",
+		IDKindTask:    "task_",
+	}, IDGeneratorFunc(func(IDKind) string { return "x" }))
+
+	assert.Equal(t, "sess_x", gen.NewID(IDKindSession))
+	assert.Equal(t, "task_x", gen.NewID(IDKindTask))
+	assert.Equal(t, "x", gen.NewID(IDKindRequest))
+
+	_, err := uuid.Parse(strings.TrimPrefix(PrefixedIDGenerator(map[IDKind]string{IDKindTask: "t-"}, nil).NewID(IDKindTask), "t-"))
+	assert.NoError(t, err)
+}
+
+func TestWithIDGenerator_TaskIDs(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+		WithIDGenerator(countingIDGenerator()),
+	)
+	server.AddTool(mcp.NewTool("quick"), noopToolHandler)
+
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "quick", "task": {}}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	created, ok := resp.Result.(mcp.CreateTaskResult)
+	require.True(t, ok, "Expected CreateTaskResult, got %T", resp.Result)
+	assert.Equal(t, "task-1", created.Task.TaskId)
+}
+
+func TestWithIDGenerator_StdioRequestIDs(t *testing.T) {
+	in, out := io.Pipe()
+	session := &stdioSession{
+		pendingRequests:     make(map[string]chan *samplingResponse),
+		pendingElicitations: make(map[string]chan *elicitationResponse),
+		pendingRoots:        make(map[string]chan *rootsResponse),
+	}
+	session.SetWriter(out)
+	session.setIDGenerator(countingIDGenerator())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := session.ListRoots(context.Background(), mcp.ListRootsRequest{})
+		done <- err
+	}()
+
+	line, err := bufio.NewReader(in).ReadBytes('\n')
+	require.NoError(t, err)
+	var request struct {
+		ID any `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(line, &request))
+	assert.Equal(t, "request-1", request.ID)
+
+	assert.False(t, session.handleListRootsResponse(json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{"roots":[]}}`)),
+		"a numeric ID must not match a string request ID")
+	assert.True(t, session.handleListRootsResponse(json.RawMessage(`{"jsonrpc":"2.0","id":"request-1","result":{"roots":[]}}`)))
+	require.NoError(t, <-done)
+}
+
+func TestWithIDGenerator_StreamableHTTPSessionIDs(t *testing.T) {
+	for _, stateful := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stateful=%v", stateful), func(t *testing.T) {
+			mcpServer := NewMCPServer("test", "1.0.0", WithIDGenerator(countingIDGenerator()))
+			server := NewTestStreamableHTTPServer(mcpServer, WithStateful(stateful))
+			defer server.Close()
+
+			resp, err := postJSON(server.URL, initRequest)
+			require.NoError(t, err)
+			resp.Body.Close()
+			sessionID := resp.Header.Get(HeaderKeySessionID)
+			assert.Equal(t, "session-1", sessionID)
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(HeaderKeySessionID, sessionID)
+			resp, err = http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestWithIDGenerator_OwnSessionIdManagerGenerator(t *testing.T) {
+	own := IDGeneratorFunc(func(IDKind) string { return "own" })
+	manager := &StatelessGeneratingSessionIdManager{Generator: own}
+	NewStreamableHTTPServer(NewMCPServer("test", "1.0.0", WithIDGenerator(countingIDGenerator())), WithSessionIdManager(manager))
+	assert.Equal(t, "own", manager.Generate())
+
+	// Without a generator, IDs keep their format.
+	plain := &StatelessGeneratingSessionIdManager{}
+	NewStreamableHTTPServer(NewMCPServer("test", "1.0.0"), WithSessionIdManager(plain))
+	id := plain.Generate()
+	assert.True(t, strings.HasPrefix(id, idPrefix))
+	_, err := plain.Validate("session-1")
+	assert.Error(t, err)
+}
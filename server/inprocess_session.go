@@ -32,6 +32,7 @@ type InProcessSession struct {
 	loggingLevel       atomic.Value
 	clientInfo         atomic.Value
 	clientCapabilities atomic.Value
+	protocolVersion    atomic.Value
 	samplingHandler    SamplingHandler
 	elicitationHandler ElicitationHandler
 	rootsHandler       RootsHandler
@@ -64,6 +65,12 @@ func (s *InProcessSession) NotificationChannel() chan<- mcp.JSONRPCNotification
 	return s.notifications
 }
 
+// Notifications returns the channel the server's notifications to the
+// session are delivered on, for the in-process client to read.
+func (s *InProcessSession) Notifications() <-chan mcp.JSONRPCNotification {
+	return s.notifications
+}
+
 func (s *InProcessSession) Initialize() {
 	s.loggingLevel.Store(mcp.LoggingLevelError)
 	s.initialized.Store(true)
@@ -99,6 +106,15 @@ func (s *InProcessSession) SetClientCapabilities(clientCapabilities mcp.ClientCa
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *InProcessSession) GetProtocolVersion() string {
+	version, _ := s.protocolVersion.Load().(string)
+	return version
+}
+
+func (s *InProcessSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *InProcessSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -156,10 +172,11 @@ func GenerateInProcessSessionID() string {
 
 // Ensure interface compliance
 var (
-	_ ClientSession          = (*InProcessSession)(nil)
-	_ SessionWithLogging     = (*InProcessSession)(nil)
-	_ SessionWithClientInfo  = (*InProcessSession)(nil)
-	_ SessionWithSampling    = (*InProcessSession)(nil)
-	_ SessionWithElicitation = (*InProcessSession)(nil)
-	_ SessionWithRoots       = (*InProcessSession)(nil)
+	_ ClientSession              = (*InProcessSession)(nil)
+	_ SessionWithLogging         = (*InProcessSession)(nil)
+	_ SessionWithClientInfo      = (*InProcessSession)(nil)
+	_ SessionWithProtocolVersion = (*InProcessSession)(nil)
+	_ SessionWithSampling        = (*InProcessSession)(nil)
+	_ SessionWithElicitation     = (*InProcessSession)(nil)
+	_ SessionWithRoots           = (*InProcessSession)(nil)
 )
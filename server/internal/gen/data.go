@@ -10,6 +10,10 @@ type MCPRequestType struct {
 	GroupHookName  string
 	UnmarshalError string
 	HandlerFunc    string
+	// TaskHandlerFunc, if set, handles requests carrying task parameters
+	// when the server supports running TaskCapability requests as tasks.
+	TaskHandlerFunc string
+	TaskCapability  string
 }
 
 var MCPRequestTypes = []MCPRequestType{
@@ -98,15 +102,17 @@ var MCPRequestTypes = []MCPRequestType{
 		UnmarshalError: "invalid list tools request",
 		HandlerFunc:    "handleListTools",
 	}, {
-		MethodName:     "MethodToolsCall",
-		ParamType:      "CallToolRequest",
-		ResultType:     "CallToolResult",
-		Group:          "tools",
-		GroupName:      "Tools",
-		GroupHookName:  "Tool",
-		HookName:       "CallTool",
-		UnmarshalError: "invalid call tool request",
-		HandlerFunc:    "handleToolCall",
+		MethodName:      "MethodToolsCall",
+		ParamType:       "CallToolRequest",
+		ResultType:      "CallToolResult",
+		Group:           "tools",
+		GroupName:       "Tools",
+		GroupHookName:   "Tool",
+		HookName:        "CallTool",
+		UnmarshalError:  "invalid call tool request",
+		HandlerFunc:     "handleToolCall",
+		TaskHandlerFunc: "handleTaskAugmentedToolCall",
+		TaskCapability:  "toolCallTasks",
 	}, {
 		MethodName:     "MethodTasksGet",
 		ParamType:      "GetTaskRequest",
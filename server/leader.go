@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLeaseName     = "mcp-go-leader"
+	defaultLeaseDuration = 15 * time.Second
+)
+
+// LeaseStore holds the leases used for leader election between replicas of
+// a server, such as a row in a shared database or a Kubernetes Lease object.
+// The contrib/sqllease package implements it on a SQL database.
+//
+// Implementations must be safe for concurrent use.
+type LeaseStore interface {
+	// AcquireLease takes the named lease for holder for ttl, and reports
+	// whether holder has it. It succeeds if the lease is free, expired or
+	// already held by holder, in which case it is renewed.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLease frees the named lease if holder has it.
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// MemoryLeaseStore is a LeaseStore for servers sharing one process, e.g. in
+// tests. Replicas running as separate processes need a shared store, such
+// as the one of contrib/sqllease.
+type MemoryLeaseStore struct {
+	clock  Clock
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+type memoryLease struct {
+	holder  string
+	expires time.Time
+}
+
+// NewMemoryLeaseStore returns an empty MemoryLeaseStore that tells the time
+// with clock, or the system clock if clock is nil.
+func NewMemoryLeaseStore(clock Clock) *MemoryLeaseStore {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &MemoryLeaseStore{clock: clock, leases: make(map[string]memoryLease)}
+}
+
+// AcquireLease implements LeaseStore.
+func (m *MemoryLeaseStore) AcquireLease(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	if lease, ok := m.leases[name]; ok && lease.holder != holder && now.Before(lease.expires) {
+		return false, nil
+	}
+	m.leases[name] = memoryLease{holder: holder, expires: now.Add(ttl)}
+	return true, nil
+}
+
+// ReleaseLease implements LeaseStore.
+func (m *MemoryLeaseStore) ReleaseLease(_ context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lease, ok := m.leases[name]; ok && lease.holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+// LeaderElectionOption configures WithLeaderElection.
+type LeaderElectionOption func(*leaderElection)
+
+// WithLeaseName sets the name of the lease replicas compete for. Servers
+// with different singleton jobs should use different names. It defaults
+// to "mcp-go-leader".
+func WithLeaseName(name string) LeaderElectionOption {
+	return func(e *leaderElection) {
+		e.name = name
+	}
+}
+
+// WithLeaseDuration sets how long a lease lasts without renewal, and so how
+// long the jobs of a crashed leader stay without a runner. The leader
+// renews it every third of the duration. It defaults to 15 seconds.
+func WithLeaseDuration(d time.Duration) LeaderElectionOption {
+	return func(e *leaderElection) {
+		if d > 0 {
+			e.duration = d
+		}
+	}
+}
+
+// WithLeaseHolder sets the identity of this replica, such as the pod name.
+// It defaults to the host name followed by a random suffix.
+func WithLeaseHolder(holder string) LeaderElectionOption {
+	return func(e *leaderElection) {
+		e.holder = holder
+	}
+}
+
+// WithLeaderElection makes the replicas of a server elect a leader through
+// the leases of store, so that jobs added with WithSingletonJob, such as
+// sweeps of shared task or session stores, run on exactly one of them.
+// Election runs while RunLeaderElection does.
+//
+// Only singleton jobs are gated on leadership. The server's own background
+// work, such as the cleanup of expired tasks and the retries of task
+// webhooks, concerns the tasks held in each replica's memory and keeps
+// running on every replica.
+func WithLeaderElection(store LeaseStore, opts ...LeaderElectionOption) ServerOption {
+	return func(s *MCPServer) {
+		e := &leaderElection{
+			server:   s,
+			store:    store,
+			name:     defaultLeaseName,
+			duration: defaultLeaseDuration,
+		}
+		for _, opt := range opts {
+			opt(e)
+		}
+		if e.holder == "" {
+			host, _ := os.Hostname()
+			e.holder = fmt.Sprintf("%s-%s", host, uuid.NewString()[:8])
+		}
+		s.leader = e
+	}
+}
+
+// WithSingletonJob adds a job that runs only on the elected leader. The
+// job starts when this server becomes leader and its context is cancelled
+// when it stops being leader; it runs again if leadership returns. Without
+// WithLeaderElection, jobs never run.
+func WithSingletonJob(name string, job func(ctx context.Context)) ServerOption {
+	return func(s *MCPServer) {
+		s.singletonJobs = append(s.singletonJobs, singletonJob{name: name, run: job})
+	}
+}
+
+type singletonJob struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// IsLeader reports whether this server currently holds the leader lease.
+func (s *MCPServer) IsLeader() bool {
+	return s.leader != nil && s.leader.leading.Load()
+}
+
+// RunLeaderElection competes for the leader lease until ctx is done,
+// running the singleton jobs while this server leads, then stops them and
+// releases the lease. It returns ErrUnsupported without WithLeaderElection.
+//
+// While it runs, failures to reach the lease store make the server not
+// ready, see CheckReadiness.
+func (s *MCPServer) RunLeaderElection(ctx context.Context) error {
+	if s.leader == nil {
+		return fmt.Errorf("leader election is not configured: %w", ErrUnsupported)
+	}
+	return s.leader.run(ctx)
+}
+
+type leaderElection struct {
+	server   *MCPServer
+	store    LeaseStore
+	name     string
+	holder   string
+	duration time.Duration
+
+	leading atomic.Bool
+	// running is set while run competes for the lease.
+	running atomic.Bool
+	errMu   sync.Mutex
+	err     error
+}
+
+func (e *leaderElection) run(ctx context.Context) error {
+	if !e.running.CompareAndSwap(false, true) {
+		return fmt.Errorf("leader election is already running")
+	}
+	defer e.running.Store(false)
+
+	var stopJobs func()
+	stepDown := func() {
+		if stopJobs != nil {
+			stopJobs()
+			stopJobs = nil
+		}
+		e.leading.Store(false)
+	}
+	defer func() {
+		stepDown()
+		// Release with a fresh context: ctx is done by now.
+		releaseCtx, cancel := contextWithTimeout(context.Background(), e.server.Clock(), e.duration)
+		defer cancel()
+		_ = e.store.ReleaseLease(releaseCtx, e.name, e.holder)
+	}()
+
+	clock := e.server.Clock()
+	for {
+		acquireCtx, cancel := contextWithTimeout(ctx, clock, e.duration/3)
+		acquired, err := e.store.AcquireLease(acquireCtx, e.name, e.holder, e.duration)
+		cancel()
+		e.setErr(err)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil || !acquired:
+			// A renewal that cannot be confirmed may be lost to another
+			// replica, so stop leading.
+			stepDown()
+		case stopJobs == nil:
+			e.leading.Store(true)
+			stopJobs = e.startJobs(ctx)
+		}
+		if err := sleepContext(ctx, clock, e.duration/3); err != nil {
+			return nil
+		}
+	}
+}
+
+// startJobs runs the singleton jobs, and returns the function that cancels
+// them and waits for them to return.
+func (e *leaderElection) startJobs(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, job := range e.server.singletonJobs {
+		wg.Add(1)
+		go func(job singletonJob) {
+			defer wg.Done()
+			job.run(ctx)
+		}(job)
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (e *leaderElection) setErr(err error) {
+	e.errMu.Lock()
+	defer e.errMu.Unlock()
+	e.err = err
+}
+
+// check is the readiness check of the lease store.
+func (e *leaderElection) check(context.Context) error {
+	if !e.running.Load() {
+		return nil
+	}
+	e.errMu.Lock()
+	defer e.errMu.Unlock()
+	if e.err != nil {
+		return fmt.Errorf("lease store: %w", e.err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLeaseStore(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	store := NewMemoryLeaseStore(clock)
+	ctx := context.Background()
+
+	ok, err := store.AcquireLease(ctx, "jobs", "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = store.AcquireLease(ctx, "jobs", "b", time.Minute)
+	assert.False(t, ok, "held by a")
+	ok, _ = store.AcquireLease(ctx, "other", "b", time.Minute)
+	assert.True(t, ok, "leases are independent")
+
+	clock.advance(time.Minute)
+	ok, _ = store.AcquireLease(ctx, "jobs", "b", time.Minute)
+	assert.True(t, ok, "expired")
+
+	require.NoError(t, store.ReleaseLease(ctx, "jobs", "a"))
+	ok, _ = store.AcquireLease(ctx, "jobs", "a", time.Minute)
+	assert.False(t, ok, "only the holder releases")
+	require.NoError(t, store.ReleaseLease(ctx, "jobs", "b"))
+	ok, _ = store.AcquireLease(ctx, "jobs", "a", time.Minute)
+	assert.True(t, ok)
+}
+
+func TestLeaderElection(t *testing.T) {
+	store := NewMemoryLeaseStore(nil)
+	var running atomic.Int32
+	var runs atomic.Int32
+	job := func(ctx context.Context) {
+		running.Add(1)
+		runs.Add(1)
+		<-ctx.Done()
+		running.Add(-1)
+	}
+	newReplica := func(holder string) *MCPServer {
+		return NewMCPServer("test", "1.0.0",
+			WithLeaderElection(store, WithLeaseName("sweeper"), WithLeaseHolder(holder), WithLeaseDuration(30*time.Millisecond)),
+			WithSingletonJob("sweep", job),
+		)
+	}
+	a, b := newReplica("a"), newReplica("b")
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	var wg sync.WaitGroup
+	for _, run := range []struct {
+		s   *MCPServer
+		ctx context.Context
+	}{{a, ctxA}, {b, ctxB}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, run.s.RunLeaderElection(run.ctx))
+		}()
+	}
+
+	require.Eventually(t, func() bool { return a.IsLeader() != b.IsLeader() }, time.Second, time.Millisecond)
+	leader, follower, stopLeader := a, b, stopA
+	if b.IsLeader() {
+		leader, follower, stopLeader = b, a, stopB
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, leader.IsLeader(), "the leader keeps its lease")
+	assert.False(t, follower.IsLeader())
+	assert.Equal(t, int32(1), running.Load())
+
+	stopLeader()
+	require.Eventually(t, follower.IsLeader, time.Second, time.Millisecond)
+	assert.False(t, leader.IsLeader())
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), running.Load(), "the job moved to the new leader")
+
+	stopA()
+	stopB()
+	wg.Wait()
+	assert.Equal(t, int32(0), running.Load())
+}
+
+// failingLeaseStore fails to acquire leases while err is set.
+type failingLeaseStore struct {
+	*MemoryLeaseStore
+	mu  sync.Mutex
+	err error
+}
+
+func (f *failingLeaseStore) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *failingLeaseStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	err := f.err
+	f.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return f.MemoryLeaseStore.AcquireLease(ctx, name, holder, ttl)
+}
+
+func TestLeaderElectionStoreFailure(t *testing.T) {
+	store := &failingLeaseStore{MemoryLeaseStore: NewMemoryLeaseStore(nil)}
+	s := NewMCPServer("test", "1.0.0", WithLeaderElection(store, WithLeaseDuration(30*time.Millisecond)))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.RunLeaderElection(ctx) }()
+
+	require.Eventually(t, s.IsLeader, time.Second, time.Millisecond)
+	assert.NoError(t, s.CheckReadiness(context.Background()))
+
+	store.setErr(errors.New("connection refused"))
+	require.Eventually(t, func() bool { return !s.IsLeader() }, time.Second, time.Millisecond)
+	assert.ErrorContains(t, s.CheckReadiness(context.Background()), "connection refused")
+
+	store.setErr(nil)
+	require.Eventually(t, s.IsLeader, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.NoError(t, s.CheckReadiness(context.Background()), "not checked once election stops")
+
+	assert.ErrorIs(t, NewMCPServer("test", "1.0.0").RunLeaderElection(context.Background()), ErrUnsupported)
+}
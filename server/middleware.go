@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HandlerKind identifies the kind of handler wrapped by a UniversalMiddleware.
+type HandlerKind string
+
+const (
+	HandlerKindTool     HandlerKind = "tool"
+	HandlerKindResource HandlerKind = "resource"
+	HandlerKindPrompt   HandlerKind = "prompt"
+)
+
+// HandlerCall describes a call to a tool, resource or prompt handler.
+type HandlerCall struct {
+	Kind HandlerKind
+	// Name is the tool or prompt name, or the resource URI.
+	Name string
+	// Request is the mcp.CallToolRequest, mcp.ReadResourceRequest or
+	// mcp.GetPromptRequest being handled. Middleware may replace it with a
+	// modified request of the same type.
+	Request any
+}
+
+// UniversalHandlerFunc handles a call to any kind of handler. Its result is a
+// *mcp.CallToolResult, []mcp.ResourceContents or *mcp.GetPromptResult,
+// according to the kind of call.
+type UniversalHandlerFunc func(ctx context.Context, call HandlerCall) (any, error)
+
+// UniversalMiddleware is a middleware function that wraps tool, resource and
+// prompt handlers alike.
+type UniversalMiddleware func(UniversalHandlerFunc) UniversalHandlerFunc
+
+// WithUniversalMiddleware adds a middleware to the tool, resource and prompt
+// handler call chains, so cross-cutting concerns such as logging, auth or
+// metrics need a single implementation. It runs in the same position in each
+// chain as a typed middleware added at this point.
+func WithUniversalMiddleware(middleware UniversalMiddleware) ServerOption {
+	return func(s *MCPServer) {
+		WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+			return adaptUniversal(middleware, HandlerKindTool, next, func(r mcp.CallToolRequest) string {
+				return r.Params.Name
+			})
+		})(s)
+		WithResourceHandlerMiddleware(func(next ResourceHandlerFunc) ResourceHandlerFunc {
+			return adaptUniversal(middleware, HandlerKindResource, next, func(r mcp.ReadResourceRequest) string {
+				return r.Params.URI
+			})
+		})(s)
+		WithPromptHandlerMiddleware(func(next PromptHandlerFunc) PromptHandlerFunc {
+			return adaptUniversal(middleware, HandlerKindPrompt, next, func(r mcp.GetPromptRequest) string {
+				return r.Params.Name
+			})
+		})(s)
+	}
+}
+
+// adaptUniversal wraps a typed handler in a universal middleware.
+func adaptUniversal[Req, Res any](
+	middleware UniversalMiddleware,
+	kind HandlerKind,
+	next func(context.Context, Req) (Res, error),
+	name func(Req) string,
+) func(context.Context, Req) (Res, error) {
+	handler := middleware(func(ctx context.Context, call HandlerCall) (any, error) {
+		request, ok := call.Request.(Req)
+		if !ok {
+			return nil, fmt.Errorf("middleware replaced %s request with %T", kind, call.Request)
+		}
+		return next(ctx, request)
+	})
+	return func(ctx context.Context, request Req) (Res, error) {
+		var zero Res
+		result, err := handler(ctx, HandlerCall{Kind: kind, Name: name(request), Request: request})
+		if result == nil {
+			return zero, err
+		}
+		typed, ok := result.(Res)
+		if !ok {
+			return zero, fmt.Errorf("middleware returned %T for %s %s", result, kind, name(request))
+		}
+		return typed, err
+	}
+}
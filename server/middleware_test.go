@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func middlewareTestServer(opts ...ServerOption) *MCPServer {
+	s := NewMCPServer("test", "1.0.0", opts...)
+	s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("tool"), nil
+	})
+	s.AddResource(mcp.NewResource("test://static", "static"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "resource"}}, nil
+	})
+	s.AddResourceTemplate(mcp.NewResourceTemplate("test://items/{id}", "items"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "template"}}, nil
+	})
+	s.AddPrompt(mcp.NewPrompt("greet"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("greeting", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("hello "+request.Params.Arguments["name"])),
+		}), nil
+	})
+	return s
+}
+
+func TestWithPromptHandlerMiddleware(t *testing.T) {
+	var order []string
+	middleware := func(name string) PromptHandlerMiddleware {
+		return func(next PromptHandlerFunc) PromptHandlerFunc {
+			return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				order = append(order, name)
+				return next(ctx, request)
+			}
+		}
+	}
+	s := middlewareTestServer(
+		WithPromptHandlerMiddleware(middleware("first")),
+		WithPromptHandlerMiddleware(middleware("second")),
+	)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"greet"}}`))
+	_, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, []string{"first", "second"}, order)
+}
+
+func TestWithUniversalMiddleware(t *testing.T) {
+	var calls []HandlerCall
+	s := middlewareTestServer(WithUniversalMiddleware(func(next UniversalHandlerFunc) UniversalHandlerFunc {
+		return func(ctx context.Context, call HandlerCall) (any, error) {
+			calls = append(calls, HandlerCall{Kind: call.Kind, Name: call.Name})
+			return next(ctx, call)
+		}
+	}))
+
+	messages := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"test://static"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"test://items/1"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"greet"}}`,
+	}
+	for _, message := range messages {
+		response := s.HandleMessage(context.Background(), []byte(message))
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+	}
+
+	assert.Equal(t, []HandlerCall{
+		{Kind: HandlerKindTool, Name: "echo"},
+		{Kind: HandlerKindResource, Name: "test://static"},
+		{Kind: HandlerKindResource, Name: "test://items/1"},
+		{Kind: HandlerKindPrompt, Name: "greet"},
+	}, calls)
+}
+
+func TestWithUniversalMiddleware_ReplacesRequest(t *testing.T) {
+	s := middlewareTestServer(WithUniversalMiddleware(func(next UniversalHandlerFunc) UniversalHandlerFunc {
+		return func(ctx context.Context, call HandlerCall) (any, error) {
+			if request, ok := call.Request.(mcp.GetPromptRequest); ok {
+				request.Params.Arguments = map[string]string{"name": "middleware"}
+				call.Request = request
+			}
+			return next(ctx, call)
+		}
+	}))
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"greet"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result := resp.Result.(mcp.GetPromptResult)
+	assert.Equal(t, "hello middleware", result.Messages[0].Content.(mcp.TextContent).Text)
+}
+
+func TestWithUniversalMiddleware_WrongTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware UniversalMiddleware
+	}{
+		{
+			name: "result",
+			middleware: func(next UniversalHandlerFunc) UniversalHandlerFunc {
+				return func(ctx context.Context, call HandlerCall) (any, error) {
+					return "not a result", nil
+				}
+			},
+		},
+		{
+			name: "request",
+			middleware: func(next UniversalHandlerFunc) UniversalHandlerFunc {
+				return func(ctx context.Context, call HandlerCall) (any, error) {
+					call.Request = "not a request"
+					return next(ctx, call)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := middlewareTestServer(WithUniversalMiddleware(tt.middleware))
+			handler, reqErr := s.toolCallHandler(context.Background(), 1, "echo")
+			require.Nil(t, reqErr)
+			_, err := handler(context.Background(), routingRequest("echo", nil))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// NDJSONAnswerFunc answers a request the server sends to the client while
+// processing a recorded stream, such as sampling/createMessage,
+// elicitation/create or roots/list. The returned value is converted to the
+// method's result type via JSON, so it may be the result type itself or a
+// map decoded from a recording.
+type NDJSONAnswerFunc func(ctx context.Context, method string, params any) (any, error)
+
+// NDJSONServer processes a stream of newline-delimited JSON-RPC messages,
+// such as a recorded session or a batch of tool calls, without an
+// interactive client. Messages are handled one at a time in input order and
+// every message the server emits is written to the output in the order it
+// was produced, so the same input yields the same output.
+type NDJSONServer struct {
+	server       *MCPServer
+	sessionID    string
+	answers      NDJSONAnswerFunc
+	canned       map[string]any
+	contextFunc  StdioContextFunc
+	omitMessages bool
+
+	writeMu sync.Mutex
+}
+
+// NDJSONOption configures an NDJSONServer.
+type NDJSONOption func(*NDJSONServer)
+
+// WithNDJSONSessionID sets the ID of the session the stream is processed in.
+// It defaults to "ndjson".
+func WithNDJSONSessionID(sessionID string) NDJSONOption {
+	return func(s *NDJSONServer) {
+		s.sessionID = sessionID
+	}
+}
+
+// WithNDJSONAnswer sets the canned result returned for every request the
+// server sends with the given method.
+func WithNDJSONAnswer(method mcp.MCPMethod, result any) NDJSONOption {
+	return func(s *NDJSONServer) {
+		s.canned[string(method)] = result
+	}
+}
+
+// WithNDJSONAnswerFunc sets a function that answers the requests the server
+// sends that have no canned answer.
+func WithNDJSONAnswerFunc(fn NDJSONAnswerFunc) NDJSONOption {
+	return func(s *NDJSONServer) {
+		s.answers = fn
+	}
+}
+
+// WithNDJSONContextFunc sets a function that customizes the context the
+// stream is processed with.
+func WithNDJSONContextFunc(fn StdioContextFunc) NDJSONOption {
+	return func(s *NDJSONServer) {
+		s.contextFunc = fn
+	}
+}
+
+// WithNDJSONResponsesOnly writes only the responses to the input requests,
+// leaving out the notifications and requests the server sends.
+func WithNDJSONResponsesOnly() NDJSONOption {
+	return func(s *NDJSONServer) {
+		s.omitMessages = true
+	}
+}
+
+// NewNDJSONServer creates an NDJSONServer for the server. Requests the
+// server sends to the client fail with ErrNoInteractiveClient unless they
+// are answered with WithNDJSONAnswer or WithNDJSONAnswerFunc.
+func NewNDJSONServer(server *MCPServer, opts ...NDJSONOption) *NDJSONServer {
+	s := &NDJSONServer{
+		server:    server,
+		sessionID: "ndjson",
+		canned:    make(map[string]any),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Process reads JSON-RPC messages from in, one per line, until EOF and writes
+// the server's messages to out, one per line. Lines that are not JSON are
+// answered with a parse error and responses in the input are ignored, since
+// the server's own requests are answered as configured. It returns an error
+// only if reading or writing fails or the context is cancelled.
+func (s *NDJSONServer) Process(ctx context.Context, in io.Reader, out io.Writer) error {
+	if err := s.server.checkStartup(); err != nil {
+		return err
+	}
+
+	session := &ndjsonSession{
+		id:            s.sessionID,
+		notifications: make(chan mcp.JSONRPCNotification, 1024),
+		server:        s,
+		out:           out,
+	}
+	if err := s.server.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer s.server.UnregisterSession(ctx, session.id)
+	ctx = s.server.WithContext(ctx, session)
+	if s.contextFunc != nil {
+		ctx = s.contextFunc(ctx)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if err := s.processLine(ctx, session, line); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return session.flush()
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// processLine handles one input line and writes what it produced.
+func (s *NDJSONServer) processLine(ctx context.Context, session *ndjsonSession, line []byte) error {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	var message json.RawMessage
+	if err := json.Unmarshal(line, &message); err != nil {
+		return s.write(session.out, createErrorResponse(nil, mcp.PARSE_ERROR, "Parse error"))
+	}
+
+	var base struct {
+		Method string `json:"method"`
+	}
+	if json.Unmarshal(message, &base) == nil && base.Method == "" {
+		// A response recorded from a client; the server's requests are
+		// answered as configured instead.
+		return nil
+	}
+
+	response := s.server.HandleMessage(ctx, message)
+	if err := session.flush(); err != nil {
+		return err
+	}
+	if response == nil {
+		return nil
+	}
+	return s.write(session.out, response)
+}
+
+func (s *NDJSONServer) write(w io.Writer, message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// answer returns the configured answer to a request the server sends.
+func (s *NDJSONServer) answer(ctx context.Context, method mcp.MCPMethod, params any, result any) error {
+	answer, ok := s.canned[string(method)]
+	if !ok {
+		if s.answers == nil {
+			return fmt.Errorf("%s: %w", method, ErrNoInteractiveClient)
+		}
+		var err error
+		if answer, err = s.answers(ctx, string(method), params); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return fmt.Errorf("%s: marshal answer: %w", method, err)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("%s: decode answer: %w", method, err)
+	}
+	return nil
+}
+
+// ndjsonSession is the client session of an NDJSONServer stream.
+type ndjsonSession struct {
+	id                 string
+	notifications      chan mcp.JSONRPCNotification
+	server             *NDJSONServer
+	out                io.Writer
+	initialized        atomic.Bool
+	loggingLevel       atomic.Value
+	clientInfo         atomic.Value
+	clientCapabilities atomic.Value
+	protocolVersion    atomic.Value
+	requestID          atomic.Int64
+}
+
+var (
+	_ ClientSession              = (*ndjsonSession)(nil)
+	_ SessionWithLogging         = (*ndjsonSession)(nil)
+	_ SessionWithClientInfo      = (*ndjsonSession)(nil)
+	_ SessionWithProtocolVersion = (*ndjsonSession)(nil)
+	_ SessionWithSampling        = (*ndjsonSession)(nil)
+	_ SessionWithElicitation     = (*ndjsonSession)(nil)
+	_ SessionWithRoots           = (*ndjsonSession)(nil)
+)
+
+func (s *ndjsonSession) SessionID() string { return s.id }
+
+func (s *ndjsonSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *ndjsonSession) Initialize() { s.initialized.Store(true) }
+
+func (s *ndjsonSession) Initialized() bool { return s.initialized.Load() }
+
+func (s *ndjsonSession) SetLogLevel(level mcp.LoggingLevel) { s.loggingLevel.Store(level) }
+
+func (s *ndjsonSession) GetLogLevel() mcp.LoggingLevel {
+	if level, ok := s.loggingLevel.Load().(mcp.LoggingLevel); ok {
+		return level
+	}
+	return mcp.LoggingLevelError
+}
+
+func (s *ndjsonSession) GetClientInfo() mcp.Implementation {
+	if info, ok := s.clientInfo.Load().(mcp.Implementation); ok {
+		return info
+	}
+	return mcp.Implementation{}
+}
+
+func (s *ndjsonSession) SetClientInfo(clientInfo mcp.Implementation) { s.clientInfo.Store(clientInfo) }
+
+func (s *ndjsonSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if capabilities, ok := s.clientCapabilities.Load().(mcp.ClientCapabilities); ok {
+		return capabilities
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *ndjsonSession) SetClientCapabilities(clientCapabilities mcp.ClientCapabilities) {
+	s.clientCapabilities.Store(clientCapabilities)
+}
+
+func (s *ndjsonSession) GetProtocolVersion() string {
+	version, _ := s.protocolVersion.Load().(string)
+	return version
+}
+
+func (s *ndjsonSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
+func (s *ndjsonSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	var result mcp.CreateMessageResult
+	if err := s.request(ctx, mcp.MethodSamplingCreateMessage, request.CreateMessageParams, &result); err != nil {
+		return nil, err
+	}
+	// Parse content from map[string]any to proper Content type
+	if contentMap, ok := result.Content.(map[string]any); ok {
+		content, err := mcp.ParseContent(contentMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sampling answer content: %w", err)
+		}
+		result.Content = content
+	}
+	return &result, nil
+}
+
+func (s *ndjsonSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	var result mcp.ElicitationResult
+	if err := s.request(ctx, mcp.MethodElicitationCreate, request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *ndjsonSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	var result mcp.ListRootsResult
+	if err := s.request(ctx, mcp.MethodListRoots, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// request records a request the server sends and answers it as configured.
+func (s *ndjsonSession) request(ctx context.Context, method mcp.MCPMethod, params any, result any) error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if !s.server.omitMessages {
+		request := mcp.JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      nextRequestID(s.server.server.ids(), &s.requestID),
+			Params:  params,
+			Request: mcp.Request{Method: string(method)},
+		}
+		if err := s.server.write(s.out, request); err != nil {
+			return err
+		}
+	}
+	return s.server.answer(ctx, method, params, result)
+}
+
+// flush writes the notifications sent so far.
+func (s *ndjsonSession) flush() error {
+	for {
+		select {
+		case notification := <-s.notifications:
+			if s.server.omitMessages {
+				continue
+			}
+			if err := s.server.write(s.out, notification); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
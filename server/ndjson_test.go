@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newNDJSONTestServer() *MCPServer {
+	s := NewMCPServer("test", "1.0.0", WithLogging())
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.GetString("text", "")), nil
+	})
+	s.AddTool(mcp.NewTool("summarize"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_ = ServerFromContext(ctx).SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": 1})
+		result, err := ServerFromContext(ctx).RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("summarize")}},
+				MaxTokens: 10,
+			},
+		})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(result.Content.(mcp.TextContent).Text), nil
+	})
+	return s
+}
+
+func ndjsonLines(t *testing.T, output string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var message map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &message), line)
+		lines = append(lines, message)
+	}
+	return lines
+}
+
+const ndjsonTestInput = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"sampling":{}},"clientInfo":{"name":"replay","version":"1"}}}
+{"jsonrpc":"2.0","method":"notifications/initialized"}
+
+{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}
+not json
+{"jsonrpc":"2.0","id":90,"result":{}}
+{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"summarize"}}`
+
+func TestNDJSONServer_Process(t *testing.T) {
+	var answered []string
+	r := NewNDJSONServer(newNDJSONTestServer(), WithNDJSONAnswerFunc(func(ctx context.Context, method string, params any) (any, error) {
+		answered = append(answered, method)
+		return map[string]any{
+			"role":    "assistant",
+			"content": map[string]any{"type": "text", "text": "short"},
+			"model":   "canned",
+		}, nil
+	}))
+
+	var out bytes.Buffer
+	require.NoError(t, r.Process(context.Background(), strings.NewReader(ndjsonTestInput), &out))
+	assert.Equal(t, []string{"sampling/createMessage"}, answered)
+
+	lines := ndjsonLines(t, out.String())
+	require.Len(t, lines, 6)
+	assert.Equal(t, float64(1), lines[0]["id"])
+	assert.Equal(t, float64(2), lines[1]["id"])
+	assert.Equal(t, "hi", lines[1]["result"].(map[string]any)["content"].([]any)[0].(map[string]any)["text"])
+	assert.Equal(t, float64(mcp.PARSE_ERROR), lines[2]["error"].(map[string]any)["code"])
+	assert.Equal(t, "notifications/progress", lines[3]["method"])
+	assert.Equal(t, "sampling/createMessage", lines[4]["method"])
+	assert.Equal(t, float64(3), lines[5]["id"])
+	assert.Equal(t, "short", lines[5]["result"].(map[string]any)["content"].([]any)[0].(map[string]any)["text"])
+
+	// The same input produces the same output.
+	var again bytes.Buffer
+	require.NoError(t, r.Process(context.Background(), strings.NewReader(ndjsonTestInput), &again))
+	assert.Equal(t, out.String(), again.String())
+}
+
+func TestNDJSONServer_UnansweredRequests(t *testing.T) {
+	var out bytes.Buffer
+	r := NewNDJSONServer(newNDJSONTestServer(), WithNDJSONResponsesOnly())
+	require.NoError(t, r.Process(context.Background(), strings.NewReader(ndjsonTestInput), &out))
+
+	lines := ndjsonLines(t, out.String())
+	require.Len(t, lines, 4)
+	result := lines[3]["result"].(map[string]any)
+	assert.Equal(t, true, result["isError"])
+	assert.Contains(t, result["content"].([]any)[0].(map[string]any)["text"], ErrNoInteractiveClient.Error())
+}
+
+func TestNDJSONServer_CannedAnswer(t *testing.T) {
+	var out bytes.Buffer
+	r := NewNDJSONServer(newNDJSONTestServer(), WithNDJSONResponsesOnly(), WithNDJSONAnswer(mcp.MethodSamplingCreateMessage, mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("canned")},
+		Model:           "canned",
+	}))
+	require.NoError(t, r.Process(context.Background(), strings.NewReader(ndjsonTestInput), &out))
+
+	lines := ndjsonLines(t, out.String())
+	require.Len(t, lines, 4)
+	assert.Equal(t, "canned", lines[3]["result"].(map[string]any)["content"].([]any)[0].(map[string]any)["text"])
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// NotificationOrder controls how notifications emitted while handling a
+// request are ordered relative to that request's response.
+type NotificationOrder int
+
+const (
+	// NotificationOrderTransport leaves ordering to the transport. This is
+	// the default.
+	NotificationOrderTransport NotificationOrder = iota
+	// NotificationOrderBeforeResponse writes notifications emitted by a
+	// handler as they are sent, and its response only once they have been
+	// written, so clients have processed e.g. a list_changed notification
+	// by the time they see the result.
+	NotificationOrderBeforeResponse
+	// NotificationOrderAfterResponse holds notifications emitted by a
+	// handler until its response has been written.
+	NotificationOrderAfterResponse
+)
+
+// WithNotificationOrder makes notifications emitted while a request is being
+// handled, such as progress, resource updated or list_changed, reach the
+// transport strictly before or after the request's response. Only
+// notifications sent with the request's context, e.g. with
+// SendNotificationToClient, belong to the request; broadcasts and
+// notifications sent to the session without one are delivered as usual.
+//
+// Ordering is honored by the stdio, SSE and streamable HTTP transports. With
+// streamable HTTP, held notifications are written on the request's SSE
+// stream, which is used even if the response alone would have been plain
+// JSON.
+func WithNotificationOrder(order NotificationOrder) ServerOption {
+	return func(s *MCPServer) {
+		s.notificationOrder = order
+	}
+}
+
+type notificationFenceKey struct{}
+
+// notificationFence orders the notifications emitted for one request with
+// its response. Before the response, they are written through the request's
+// transport as they are sent; after it, they are held until it was written.
+type notificationFence struct {
+	sessionID string
+	order     NotificationOrder
+	write     func(mcp.JSONRPCNotification) error
+
+	mu       sync.Mutex
+	pending  []mcp.JSONRPCNotification
+	released bool
+}
+
+// hold writes or queues a notification, unless the fence was already
+// released. Writes happen under f.mu, so releasing the fence waits for the
+// ones in progress.
+func (f *notificationFence) hold(notification mcp.JSONRPCNotification) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.released {
+		return false
+	}
+	if f.order == NotificationOrderBeforeResponse {
+		_ = f.write(notification)
+		return true
+	}
+	f.pending = append(f.pending, notification)
+	return true
+}
+
+// fenceNotifications opens a fence for the request about to be handled with
+// ctx, whose notifications are written with writeNotification. It returns
+// nil when notifications are not being ordered. Transports must pass the
+// fence to writeFenced, flushFence or releaseFence once done.
+func (s *MCPServer) fenceNotifications(
+	ctx context.Context,
+	writeNotification func(mcp.JSONRPCNotification) error,
+) (context.Context, *notificationFence) {
+	if s.notificationOrder == NotificationOrderTransport {
+		return ctx, nil
+	}
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return ctx, nil
+	}
+
+	fence := &notificationFence{
+		sessionID: session.SessionID(),
+		order:     s.notificationOrder,
+		write:     writeNotification,
+	}
+	return context.WithValue(ctx, notificationFenceKey{}, fence), fence
+}
+
+// releaseFence closes a fence and returns the notifications it held.
+// Notifications sent afterwards are delivered as usual.
+func (s *MCPServer) releaseFence(fence *notificationFence) []mcp.JSONRPCNotification {
+	if fence == nil {
+		return nil
+	}
+	fence.mu.Lock()
+	defer fence.mu.Unlock()
+	fence.released = true
+	pending := fence.pending
+	fence.pending = nil
+	return pending
+}
+
+// holdNotification hands a notification for session to the fence of the
+// request ctx belongs to, if it is still open. It reports whether the fence
+// took the notification.
+func (s *MCPServer) holdNotification(ctx context.Context, session ClientSession, notification mcp.JSONRPCNotification) bool {
+	if s.notificationOrder == NotificationOrderTransport {
+		return false
+	}
+	fence, ok := ctx.Value(notificationFenceKey{}).(*notificationFence)
+	if !ok || fence.sessionID != session.SessionID() {
+		return false
+	}
+	// Work that outlives its request, like a task, sends directly.
+	return fence.hold(notification)
+}
+
+// writeFenced writes a response ordered with the notifications sent while
+// producing it: after the ones already written through, or before the held
+// ones. Without a fence it only writes the response.
+func (s *MCPServer) writeFenced(fence *notificationFence, writeResponse func() error) error {
+	if fence == nil {
+		return writeResponse()
+	}
+	if fence.order == NotificationOrderBeforeResponse {
+		// Releasing waits for notifications being written.
+		s.releaseFence(fence)
+		return writeResponse()
+	}
+
+	errs := []error{writeResponse()}
+	for _, notification := range s.releaseFence(fence) {
+		errs = append(errs, fence.write(notification))
+	}
+	return errors.Join(errs...)
+}
+
+// flushFence releases a fence whose request had no response and delivers
+// its notifications to the session as usual.
+func (s *MCPServer) flushFence(ctx context.Context, fence *notificationFence) {
+	session := ClientSessionFromContext(ctx)
+	for _, notification := range s.releaseFence(fence) {
+		if session != nil {
+			_ = s.sendNotificationToSpecificClient(session, notification)
+		}
+	}
+}
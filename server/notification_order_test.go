@@ -0,0 +1,196 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStdioServer_NotificationOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order NotificationOrder
+		want  []string
+	}{
+		{
+			name:  "before response",
+			order: NotificationOrderBeforeResponse,
+			want:  []string{"notifications/progress", "notifications/tools/list_changed", "response"},
+		},
+		{
+			name:  "after response",
+			order: NotificationOrderAfterResponse,
+			want:  []string{"response", "notifications/progress", "notifications/tools/list_changed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpServer := NewMCPServer("test", "1.0.0",
+				WithToolCapabilities(true),
+				WithNotificationOrder(tt.order),
+			)
+			mcpServer.AddTool(mcp.NewTool("refresh"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if err := mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": 1}); err != nil {
+					return nil, err
+				}
+				if err := mcpServer.SendNotificationToClient(ctx, mcp.MethodNotificationToolsListChanged, nil); err != nil {
+					return nil, err
+				}
+				return mcp.NewToolResultText("done"), nil
+			})
+
+			stdinReader, stdinWriter := io.Pipe()
+			stdoutReader, stdoutWriter := io.Pipe()
+			stdioServer := NewStdioServer(mcpServer)
+			stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+				stdoutWriter.Close()
+			}()
+			defer stdinWriter.Close()
+
+			lines := bufio.NewScanner(stdoutReader)
+			send := func(message map[string]any) {
+				data, err := json.Marshal(message)
+				require.NoError(t, err)
+				_, err = stdinWriter.Write(append(data, '\n'))
+				require.NoError(t, err)
+			}
+			next := func() string {
+				require.True(t, lines.Scan())
+				var message struct {
+					Method string `json:"method"`
+				}
+				require.NoError(t, json.Unmarshal(lines.Bytes(), &message))
+				if message.Method == "" {
+					return "response"
+				}
+				return message.Method
+			}
+
+			send(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{
+				"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+				"clientInfo":      map[string]any{"name": "test", "version": "1.0.0"},
+			}})
+			require.Equal(t, "response", next())
+			send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+
+			send(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": map[string]any{"name": "refresh"}})
+			got := make([]string, 0, len(tt.want))
+			for range tt.want {
+				got = append(got, next())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMCPServer_NotificationFence(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithNotificationOrder(NotificationOrderAfterResponse))
+	session := &sessionTestClient{
+		sessionID:           "fenced",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := s.WithContext(context.Background(), session)
+
+	var written []string
+	ctx, fence := s.fenceNotifications(ctx, func(notification mcp.JSONRPCNotification) error {
+		written = append(written, notification.Method)
+		return nil
+	})
+	require.NotNil(t, fence)
+
+	require.NoError(t, s.SendNotificationToClient(ctx, "notifications/progress", nil))
+	require.NoError(t, s.SendNotificationToClient(ctx, "notifications/message", nil))
+	assert.Empty(t, written, "notifications are held while the request is open")
+
+	// Notifications sent without the request's context are not held.
+	require.NoError(t, s.SendNotificationToSpecificClient("fenced", "notifications/resources/updated", nil))
+	s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	require.Len(t, session.notificationChannel, 2)
+	<-session.notificationChannel
+	<-session.notificationChannel
+
+	err := s.writeFenced(fence, func() error {
+		written = append(written, "response")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"response", "notifications/progress", "notifications/message"}, written)
+
+	// Work that outlives the request, like a task, is no longer held back.
+	require.NoError(t, s.SendNotificationToClient(context.WithoutCancel(ctx), "notifications/progress", nil))
+	assert.Len(t, session.notificationChannel, 1)
+}
+
+func TestMCPServer_NotificationFenceWriteThrough(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithNotificationOrder(NotificationOrderBeforeResponse))
+	session := &sessionTestClient{
+		sessionID:           "streamed",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	var written []string
+	ctx, fence := s.fenceNotifications(s.WithContext(context.Background(), session), func(notification mcp.JSONRPCNotification) error {
+		written = append(written, notification.Method)
+		return nil
+	})
+	require.NoError(t, s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": 1}))
+	assert.Equal(t, []string{"notifications/progress"}, written, "progress is written as it is sent")
+	require.NoError(t, s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": 2}))
+
+	require.NoError(t, s.writeFenced(fence, func() error {
+		written = append(written, "response")
+		return nil
+	}))
+	assert.Equal(t, []string{"notifications/progress", "notifications/progress", "response"}, written)
+	assert.Empty(t, session.notificationChannel)
+}
+
+func TestMCPServer_NotificationFenceFlush(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithNotificationOrder(NotificationOrderAfterResponse))
+	session := &sessionTestClient{
+		sessionID:           "flushed",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	ctx, fence := s.fenceNotifications(s.WithContext(context.Background(), session), func(mcp.JSONRPCNotification) error {
+		t.Error("flushed notifications go to the session")
+		return nil
+	})
+	require.NoError(t, s.SendNotificationToClient(ctx, "notifications/progress", nil))
+	assert.Empty(t, session.notificationChannel)
+
+	// A client notification has no response to order against.
+	s.flushFence(ctx, fence)
+	require.Len(t, session.notificationChannel, 1)
+	assert.Equal(t, "notifications/progress", (<-session.notificationChannel).Method)
+}
+
+func TestMCPServer_NotificationOrderDefault(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	session := &sessionTestClient{sessionID: "default", initialized: true}
+
+	ctx := s.WithContext(context.Background(), session)
+	fencedCtx, fence := s.fenceNotifications(ctx, nil)
+	assert.Nil(t, fence)
+	assert.Equal(t, ctx, fencedCtx)
+}
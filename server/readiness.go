@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// defaultReadinessTimeout bounds the readiness checks run by the health
+// handlers and the readiness gate.
+const defaultReadinessTimeout = 5 * time.Second
+
+// ReadinessCheck reports whether a dependency of the server, such as a
+// shared task or session store, is reachable.
+type ReadinessCheck func(ctx context.Context) error
+
+// WithReadinessCheck adds a check that must pass for the server to be
+// ready, see CheckReadiness.
+func WithReadinessCheck(name string, check ReadinessCheck) ServerOption {
+	return func(s *MCPServer) {
+		if s.readinessChecks == nil {
+			s.readinessChecks = make(map[string]ReadinessCheck)
+		}
+		s.readinessChecks[name] = check
+	}
+}
+
+// CheckReadiness runs the readiness checks and returns an error joining
+// the failed ones, each prefixed with its name. With WithLeaderElection, it
+// also fails while RunLeaderElection cannot reach the lease store.
+func (s *MCPServer) CheckReadiness(ctx context.Context) error {
+	names := make([]string, 0, len(s.readinessChecks))
+	for name := range s.readinessChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := s.readinessChecks[name](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if s.leader != nil {
+		if err := s.leader.check(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("leader-election: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// healthStatus is the body of the health handlers' responses.
+type healthStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Leader *bool  `json:"leader,omitempty"`
+}
+
+// LivenessHandler returns an HTTP handler for liveness probes, such as a
+// Kubernetes livenessProbe. It answers 200 as long as the process serves
+// requests.
+func (s *MCPServer) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, healthStatus{Status: "ok"})
+	})
+}
+
+// ReadinessHandler returns an HTTP handler for readiness probes, such as a
+// Kubernetes readinessProbe. It answers 200 if CheckReadiness passes and
+// 503 with the failures otherwise. With WithLeaderElection, the body tells
+// whether this server is the leader.
+func (s *MCPServer) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), defaultReadinessTimeout)
+		defer cancel()
+		status := healthStatus{Status: "ok"}
+		if s.leader != nil {
+			leader := s.IsLeader()
+			status.Leader = &leader
+		}
+		if err := s.CheckReadiness(ctx); err != nil {
+			status.Status = "unavailable"
+			status.Error = err.Error()
+			writeHealth(w, http.StatusServiceUnavailable, status)
+			return
+		}
+		writeHealth(w, http.StatusOK, status)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, code int, status healthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessHandlers(t *testing.T) {
+	var storeDown atomic.Bool
+	storeDown.Store(true)
+	s := NewMCPServer("test", "1.0.0",
+		WithReadinessCheck("tasks", func(ctx context.Context) error {
+			if storeDown.Load() {
+				return errors.New("unreachable")
+			}
+			return nil
+		}),
+		WithReadinessCheck("sessions", func(ctx context.Context) error { return nil }),
+	)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		down    bool
+		code    int
+		status  string
+	}{
+		{name: "live while store is down", handler: s.LivenessHandler(), down: true, code: http.StatusOK, status: "ok"},
+		{name: "not ready while store is down", handler: s.ReadinessHandler(), down: true, code: http.StatusServiceUnavailable, status: "unavailable"},
+		{name: "ready", handler: s.ReadinessHandler(), code: http.StatusOK, status: "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeDown.Store(tt.down)
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.code, w.Code)
+			var status healthStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			assert.Equal(t, tt.status, status.Status)
+			if tt.code != http.StatusOK {
+				assert.Equal(t, "tasks: unreachable", status.Error)
+			}
+		})
+	}
+}
+
+func TestStreamableHTTPReadinessGate(t *testing.T) {
+	var storeDown atomic.Bool
+	storeDown.Store(true)
+	s := NewMCPServer("test", "1.0.0", WithReadinessCheck("store", func(ctx context.Context) error {
+		if storeDown.Load() {
+			return errors.New("unreachable")
+		}
+		return nil
+	}))
+	httpServer := NewStreamableHTTPServer(s, WithReadinessGate())
+
+	post := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		httpServer.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	storeDown.Store(false)
+	assert.Equal(t, http.StatusOK, post().Code)
+	storeDown.Store(true)
+	assert.Equal(t, http.StatusOK, post().Code, "the gate stays open once ready")
+}
@@ -72,6 +72,8 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 
+	defer s.stats.observe(s.clock, s.statsMethod(baseMessage.Method), s.clock.Now())
+
 	// Get request header from ctx
 	h := ctx.Value(requestHeader)
 	headers, ok := h.(http.Header)
@@ -321,6 +323,14 @@ func (s *MCPServer) HandleMessage(
 		} else {
 			request.Header = headers
 			s.hooks.beforeCallTool(ctx, baseMessage.ID, &request)
+			if request.Params.Task != nil && s.capabilities.tasks != nil && s.capabilities.tasks.toolCallTasks {
+				taskResult, taskErr := s.handleTaskAugmentedToolCall(ctx, baseMessage.ID, request)
+				if taskErr != nil {
+					s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, taskErr)
+					return taskErr.ToJSONRPCError()
+				}
+				return createResponse(baseMessage.ID, *taskResult)
+			}
 			result, err = s.handleToolCall(ctx, baseMessage.ID, request)
 		}
 		if err != nil {
@@ -434,6 +444,14 @@ func (s *MCPServer) HandleMessage(
 		s.hooks.afterCancelTask(ctx, baseMessage.ID, &request, result)
 		return createResponse(baseMessage.ID, *result)
 	default:
+		if handler, ok := s.extensionHandler(baseMessage.Method); ok {
+			result, err := handler(ctx, baseMessage.ID, message, headers)
+			if err != nil {
+				s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, message, err)
+				return err.ToJSONRPCError()
+			}
+			return createResponse(baseMessage.ID, result)
+		}
 		return createErrorResponse(
 			baseMessage.ID,
 			mcp.METHOD_NOT_FOUND,
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithResourceSubscriptions handles resources/subscribe and
+// resources/unsubscribe and declares subscribe support in the resources
+// capability. Once enabled, notifications/resources/updated for a URI, e.g.
+// from TouchResource, go only to the sessions subscribed to it; without it
+// they are sent to every client.
+func WithResourceSubscriptions() ServerOption {
+	return func(s *MCPServer) {
+		if s.capabilities.resources == nil {
+			s.capabilities.resources = &resourceCapabilities{}
+		}
+		s.resourceSubscriptions = &resourceSubscriptions{
+			sessions: make(map[string]map[string]struct{}),
+		}
+		s.handleExtension(mcp.MethodResourcesSubscribe, s.handleSubscribe)
+		s.handleExtension(mcp.MethodResourcesUnsubscribe, s.handleUnsubscribe)
+	}
+}
+
+// resourceSubscriptions tracks the resource URIs each session subscribed to.
+type resourceSubscriptions struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]struct{}
+}
+
+func (r *resourceSubscriptions) subscribe(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uris, ok := r.sessions[sessionID]
+	if !ok {
+		uris = make(map[string]struct{})
+		r.sessions[sessionID] = uris
+	}
+	uris[uri] = struct{}{}
+}
+
+func (r *resourceSubscriptions) unsubscribe(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions[sessionID], uri)
+	if len(r.sessions[sessionID]) == 0 {
+		delete(r.sessions, sessionID)
+	}
+}
+
+// subscribers returns the IDs of the sessions subscribed to the URI.
+func (r *resourceSubscriptions) subscribers(uri string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessionIDs []string
+	for sessionID, uris := range r.sessions {
+		if _, ok := uris[uri]; ok {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// dropSession forgets the subscriptions of an unregistered session.
+func (r *resourceSubscriptions) dropSession(sessionID string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, sessionID)
+}
+
+func (s *MCPServer) handleSubscribe(ctx context.Context, id any, message json.RawMessage, header http.Header) (any, *requestError) {
+	var request mcp.SubscribeRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  &UnparsableMessageError{message: message, err: err, method: mcp.MethodResourcesSubscribe},
+		}
+	}
+	session, reqErr := subscriptionSession(ctx, id, request.Params.URI)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	s.resourceSubscriptions.subscribe(session.SessionID(), request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+func (s *MCPServer) handleUnsubscribe(ctx context.Context, id any, message json.RawMessage, header http.Header) (any, *requestError) {
+	var request mcp.UnsubscribeRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  &UnparsableMessageError{message: message, err: err, method: mcp.MethodResourcesUnsubscribe},
+		}
+	}
+	session, reqErr := subscriptionSession(ctx, id, request.Params.URI)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	s.resourceSubscriptions.unsubscribe(session.SessionID(), request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+// subscriptionSession validates a subscription request and returns the
+// session it belongs to.
+func subscriptionSession(ctx context.Context, id any, uri string) (ClientSession, *requestError) {
+	if uri == "" {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("uri is required"),
+		}
+	}
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  ErrSessionNotFound,
+		}
+	}
+	return session, nil
+}
+
+// notifyResourceUpdated sends notifications/resources/updated for the URI
+// to its subscribers, or to every client when subscriptions are disabled.
+func (s *MCPServer) notifyResourceUpdated(uri string) {
+	params := map[string]any{"uri": uri}
+	if s.resourceSubscriptions == nil {
+		s.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, params)
+		return
+	}
+	for _, sessionID := range s.resourceSubscriptions.subscribers(uri) {
+		_ = s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, params)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResourceSubscriptions(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithResourceSubscriptions())
+	subscriber := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	other := &sessionTestClientWithClientInfo{sessionID: "s2", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), subscriber))
+	require.NoError(t, s.RegisterSession(context.Background(), other))
+	ctx := s.WithContext(context.Background(), subscriber)
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test","version":"1.0.0"}}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	capabilities := resp.Result.(mcp.InitializeResult).Capabilities
+	require.NotNil(t, capabilities.Resources)
+	assert.True(t, capabilities.Resources.Subscribe)
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"counter://value"}}`))
+	_, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+
+	require.NoError(t, s.TouchResource(context.Background(), "counter://value"))
+	require.NoError(t, s.TouchResource(context.Background(), "counter://other"))
+	require.Len(t, subscriber.notificationChannel, 1, "only subscribed URIs are notified")
+	notification := <-subscriber.notificationChannel
+	assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+	assert.Equal(t, "counter://value", notification.Params.AdditionalFields["uri"])
+	assert.Empty(t, other.notificationChannel, "sessions that did not subscribe are not notified")
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"resources/unsubscribe","params":{"uri":"counter://value"}}`))
+	_, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	require.NoError(t, s.TouchResource(context.Background(), "counter://value"))
+	assert.Empty(t, subscriber.notificationChannel)
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":4,"method":"resources/subscribe","params":{"uri":""}}`))
+	errResp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.INVALID_PARAMS, errResp.Error.Code)
+
+	s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":5,"method":"resources/subscribe","params":{"uri":"counter://value"}}`))
+	s.UnregisterSession(context.Background(), subscriber.sessionID)
+	assert.Empty(t, s.resourceSubscriptions.subscribers("counter://value"), "subscriptions end with the session")
+}
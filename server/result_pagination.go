@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResultContinuationFunc returns the page of a paginated tool result that
+// the request's continuation token identifies. Pages other than the last
+// carry the token of the next one, see mcp.NewToolResultPage. Unknown or
+// expired tokens should be reported with ErrInvalidContinuation.
+type ResultContinuationFunc func(ctx context.Context, request mcp.ContinueResultRequest) (*mcp.CallToolResult, error)
+
+type resultContinuations struct {
+	mu        sync.RWMutex
+	providers map[string]ResultContinuationFunc
+}
+
+// WithResultPagination enables the tools/continueResult extension method,
+// for tools whose structured results are too large for one response, such
+// as queries returning many rows. Such a tool returns the first page with a
+// continuation token, and the continuation registered for it with
+// AddResultContinuation serves the following ones.
+func WithResultPagination() ServerOption {
+	return func(s *MCPServer) {
+		if s.continuations == nil {
+			s.continuations = &resultContinuations{providers: make(map[string]ResultContinuationFunc)}
+		}
+		s.setExperimentalCapability(mcp.ExperimentalResultPagination, map[string]any{})
+		s.handleExtension(mcp.MethodToolsContinueResult, s.handleContinueResult)
+	}
+}
+
+// AddResultContinuation registers the continuation serving the pages that
+// follow the first one returned by tool. It has no effect unless the server
+// was created with WithResultPagination.
+func (s *MCPServer) AddResultContinuation(tool string, continuation ResultContinuationFunc) {
+	if s.continuations == nil {
+		return
+	}
+	s.continuations.mu.Lock()
+	defer s.continuations.mu.Unlock()
+	s.continuations.providers[tool] = continuation
+}
+
+// RemoveResultContinuation unregisters the continuation of tool.
+func (s *MCPServer) RemoveResultContinuation(tool string) {
+	if s.continuations == nil {
+		return
+	}
+	s.continuations.mu.Lock()
+	defer s.continuations.mu.Unlock()
+	delete(s.continuations.providers, tool)
+}
+
+func (s *MCPServer) handleContinueResult(ctx context.Context, id any, message json.RawMessage, header http.Header) (any, *requestError) {
+	var request mcp.ContinueResultRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  &UnparsableMessageError{message: message, err: err, method: mcp.MethodToolsContinueResult},
+		}
+	}
+	request.Header = header
+	name := request.Params.Name
+	if request.Params.ContinuationToken == "" {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("continuationToken is required: %w", ErrInvalidContinuation),
+		}
+	}
+	if _, reqErr := s.callableTool(ctx, id, name); reqErr != nil {
+		return nil, reqErr
+	}
+
+	s.continuations.mu.RLock()
+	continuation, ok := s.continuations.providers[name]
+	s.continuations.mu.RUnlock()
+	if !ok {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("tool '%s' does not paginate its results: %w", name, ErrInvalidContinuation),
+		}
+	}
+
+	// Pages are served through the tool middlewares, as the first one was.
+	handler := s.withToolMiddlewares(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return continuation(ctx, request)
+	})
+	call := mcp.CallToolRequest{Header: request.Header}
+	call.Method = string(mcp.MethodToolsCall)
+	call.Params.Name = name
+	call.Params.Meta = request.Params.Meta
+	result, err := handler(ctx, call)
+	if err != nil {
+		code := mcp.INTERNAL_ERROR
+		if errors.Is(err, ErrInvalidContinuation) {
+			code = mcp.INVALID_PARAMS
+		}
+		return nil, &requestError{id: id, code: code, err: err}
+	}
+	if result == nil {
+		result = &mcp.CallToolResult{Content: []mcp.Content{}}
+	}
+	if result, err = s.structuredToolResultCompat(ctx, request.Header, result); err != nil {
+		return nil, &requestError{id: id, code: mcp.INTERNAL_ERROR, err: err}
+	}
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rowPages serves rows in pages of size, keyed by the offset of the page.
+func rowPages(rows []string, size int) (ToolHandlerFunc, ResultContinuationFunc) {
+	page := func(offset int) *mcp.CallToolResult {
+		end := min(offset+size, len(rows))
+		next := ""
+		if end < len(rows) {
+			next = strconv.Itoa(end)
+		}
+		return mcp.NewToolResultPage(map[string]any{"rows": rows[offset:end]}, next)
+	}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return page(0), nil
+	}
+	continuation := func(ctx context.Context, request mcp.ContinueResultRequest) (*mcp.CallToolResult, error) {
+		offset, err := strconv.Atoi(request.Params.ContinuationToken)
+		if err != nil || offset < 0 || offset >= len(rows) {
+			return nil, fmt.Errorf("token %q: %w", request.Params.ContinuationToken, ErrInvalidContinuation)
+		}
+		return page(offset), nil
+	}
+	return handler, continuation
+}
+
+func continueResult(s *MCPServer, tool, token string) mcp.JSONRPCMessage {
+	return s.HandleMessage(context.Background(), []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/continueResult","params":{"name":%q,"continuationToken":%q}}`, tool, token)))
+}
+
+func TestWithResultPagination(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithResultPagination())
+	handler, continuation := rowPages([]string{"a", "b", "c", "d", "e"}, 2)
+	s.AddTool(mcp.NewTool("query"), handler)
+	s.AddResultContinuation("query", continuation)
+	s.AddTool(mcp.NewTool("echo"), noopToolHandler)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+	initialize := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+	assert.Contains(t, initialize.Capabilities.Experimental, mcp.ExperimentalResultPagination)
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"query"}}`))
+	result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	token, ok := mcp.ContinuationToken(&result)
+	require.True(t, ok)
+
+	var rows []string
+	rows = append(rows, result.StructuredContent.(map[string]any)["rows"].([]string)...)
+	for ok {
+		response = continueResult(s, "query", token)
+		resp, isResponse := response.(mcp.JSONRPCResponse)
+		require.True(t, isResponse, "unexpected response %#v", response)
+		page := resp.Result.(*mcp.CallToolResult)
+		rows = append(rows, page.StructuredContent.(map[string]any)["rows"].([]string)...)
+		token, ok = mcp.ContinuationToken(page)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, rows)
+
+	tests := []struct {
+		name  string
+		tool  string
+		token string
+	}{
+		{name: "unknown token", tool: "query", token: "99"},
+		{name: "empty token", tool: "query", token: ""},
+		{name: "no continuation", tool: "echo", token: "2"},
+		{name: "unknown tool", tool: "missing", token: "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := continueResult(s, tt.tool, tt.token)
+			rpcErr, ok := response.(mcp.JSONRPCError)
+			require.True(t, ok, "unexpected response %#v", response)
+			assert.Equal(t, mcp.INVALID_PARAMS, rpcErr.Error.Code)
+		})
+	}
+
+	s.RemoveResultContinuation("query")
+	rpcErr, ok := continueResult(s, "query", "2").(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INVALID_PARAMS, rpcErr.Error.Code)
+}
+
+func TestResultPaginationChecks(t *testing.T) {
+	var seen []string
+	s := NewMCPServer("test", "1.0.0", WithResultPagination(), WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			seen = append(seen, request.Params.Name)
+			return next(ctx, request)
+		}
+	}))
+	handler, continuation := rowPages([]string{"a", "b", "c"}, 2)
+	s.AddTool(mcp.NewTool("query"), handler)
+	s.AddResultContinuation("query", continuation)
+
+	_, ok := continueResult(s, "query", "2").(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Equal(t, []string{"query"}, seen, "pages go through the middlewares")
+
+	s.DisableTools("query")
+	rpcErr, ok := continueResult(s, "query", "2").(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.TOOL_UNAVAILABLE, rpcErr.Error.Code)
+	s.EnableTools("query")
+
+	s.AddTool(mcp.NewTool("query", mcp.WithRequiredClientCapabilities(mcp.ClientCapabilityElicitation)), handler)
+	session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	response := s.HandleMessage(s.WithContext(context.Background(), session), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/continueResult","params":{"name":"query","continuationToken":"2"}}`))
+	rpcErr, ok = response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.INVALID_REQUEST, rpcErr.Error.Code)
+}
+
+func TestResultPaginationDisabled(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	handler, continuation := rowPages([]string{"a", "b", "c"}, 2)
+	s.AddTool(mcp.NewTool("query"), handler)
+	s.AddResultContinuation("query", continuation)
+
+	rpcErr, ok := continueResult(s, "query", "2").(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, rpcErr.Error.Code)
+}
@@ -25,7 +25,14 @@ func (s *MCPServer) RequestRoots(ctx context.Context, request mcp.ListRootsReque
 
 	// Check if the session supports roots requests
 	if rootsSession, ok := session.(SessionWithRoots); ok {
-		return rootsSession.ListRoots(ctx, request)
+		ctx, done := s.clientRequestContext(ctx, mcp.MethodListRoots, session.SessionID())
+		release, err := s.flowControl.acquire(ctx, session.SessionID())
+		if err != nil {
+			return nil, done(err)
+		}
+		result, err := rootsSession.ListRoots(ctx, request)
+		release()
+		return result, done(err)
 	}
 
 	return nil, ErrRootsNotSupported
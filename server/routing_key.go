@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RoutingKeyFunc derives the key used to route a tool call to one of several
+// backends. Calls with the same key should land on the same backend.
+type RoutingKeyFunc func(ctx context.Context, request mcp.CallToolRequest) string
+
+type routingKeyKey struct{}
+
+// WithRoutingKey computes a routing key for every tool call with fn. The key
+// is available to tool middleware and handlers, including handlers that
+// forward calls to a fleet of workers, through RoutingKeyFromContext.
+func WithRoutingKey(fn RoutingKeyFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.routingKey = fn
+	}
+}
+
+// RoutingKeyFromContext returns the routing key of the current tool call, or
+// an empty string if no RoutingKeyFunc is configured.
+func RoutingKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(routingKeyKey{}).(string)
+	return key
+}
+
+// RoutingKeyFromArguments returns a RoutingKeyFunc keyed on the tool name and
+// the given argument fields. Nested fields are addressed with dots, e.g.
+// "customer.id"; missing fields count as null. The key has the form
+// "<tool>:<hash>", where the hash only depends on the field values, so it is
+// stable across processes and does not reveal the arguments.
+func RoutingKeyFromArguments(fields ...string) RoutingKeyFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) string {
+		if len(fields) == 0 {
+			return request.Params.Name
+		}
+		args := request.GetArguments()
+		values := make([]any, len(fields))
+		for i, field := range fields {
+			values[i] = argumentAtPath(args, field)
+		}
+		// Maps are encoded with sorted keys, so equal values give equal keys.
+		data, err := json.Marshal(values)
+		if err != nil {
+			return request.Params.Name
+		}
+		sum := sha256.Sum256(data)
+		return request.Params.Name + ":" + hex.EncodeToString(sum[:8])
+	}
+}
+
+func argumentAtPath(args map[string]any, path string) any {
+	var value any = args
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// ShardForRoutingKey maps a routing key to one of shards backends using jump
+// consistent hashing: when the number of shards grows from n to n+1, only
+// about 1/(n+1) of the keys move. It returns 0 when shards is less than 1.
+func ShardForRoutingKey(key string, shards int) int {
+	if shards < 1 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(key))
+	h := binary.BigEndian.Uint64(sum[:8])
+
+	// Lamping and Veach, "A Fast, Minimal Memory, Consistent Hash Algorithm".
+	b, j := int64(-1), int64(0)
+	for j < int64(shards) {
+		b = j
+		h = h*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((h>>33)+1)))
+	}
+	return int(b)
+}
+
+// withRoutingKey stores the routing key of a tool call in ctx.
+func (s *MCPServer) withRoutingKey(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	if s.routingKey == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, routingKeyKey{}, s.routingKey(ctx, request))
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func routingRequest(name string, args map[string]any) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return request
+}
+
+func TestRoutingKeyFromArguments(t *testing.T) {
+	key := RoutingKeyFromArguments("tenant", "customer.id")
+	ctx := context.Background()
+
+	base := key(ctx, routingRequest("lookup", map[string]any{
+		"tenant":   "acme",
+		"customer": map[string]any{"id": 42, "name": "Ada"},
+		"verbose":  true,
+	}))
+	assert.Regexp(t, `^lookup:[0-9a-f]{16}$`, base)
+
+	tests := []struct {
+		name    string
+		request mcp.CallToolRequest
+		same    bool
+	}{
+		{
+			name: "other fields are ignored",
+			request: routingRequest("lookup", map[string]any{
+				"tenant":   "acme",
+				"customer": map[string]any{"id": 42, "name": "Grace"},
+			}),
+			same: true,
+		},
+		{
+			name: "different key field",
+			request: routingRequest("lookup", map[string]any{
+				"tenant":   "acme",
+				"customer": map[string]any{"id": 43},
+			}),
+		},
+		{
+			name: "different tool",
+			request: routingRequest("update", map[string]any{
+				"tenant":   "acme",
+				"customer": map[string]any{"id": 42},
+			}),
+		},
+		{
+			name:    "missing fields",
+			request: routingRequest("lookup", nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.same {
+				assert.Equal(t, base, key(ctx, tt.request))
+			} else {
+				assert.NotEqual(t, base, key(ctx, tt.request))
+			}
+		})
+	}
+
+	assert.Equal(t, "lookup", RoutingKeyFromArguments()(ctx, routingRequest("lookup", nil)))
+}
+
+func TestShardForRoutingKey(t *testing.T) {
+	assert.Equal(t, 0, ShardForRoutingKey("key", 0))
+	assert.Equal(t, 0, ShardForRoutingKey("key", 1))
+
+	const keys = 1000
+	counts := make([]int, 10)
+	moved := 0
+	for i := range keys {
+		key := fmt.Sprintf("tool:%d", i)
+		shard := ShardForRoutingKey(key, 10)
+		require.GreaterOrEqual(t, shard, 0)
+		require.Less(t, shard, 10)
+		assert.Equal(t, shard, ShardForRoutingKey(key, 10), "deterministic")
+		counts[shard]++
+
+		if ShardForRoutingKey(key, 11) != shard {
+			moved++
+		}
+	}
+	for shard, count := range counts {
+		assert.Greater(t, count, 50, "shard %d is underused", shard)
+	}
+	// Adding an eleventh shard should move about 1/11 of the keys.
+	assert.Less(t, moved, keys/5)
+}
+
+func TestMCPServer_RoutingKey(t *testing.T) {
+	var seen []string
+	s := NewMCPServer("test", "1.0.0",
+		WithRoutingKey(RoutingKeyFromArguments("tenant")),
+		WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				seen = append(seen, RoutingKeyFromContext(ctx))
+				return next(ctx, request)
+			}
+		}),
+	)
+	s.AddTool(mcp.NewTool("lookup"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(RoutingKeyFromContext(ctx)), nil
+	})
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"lookup","arguments":{"tenant":"acme"}}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+
+	want := RoutingKeyFromArguments("tenant")(context.Background(), routingRequest("lookup", map[string]any{"tenant": "acme"}))
+	assert.Equal(t, want, result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, []string{want}, seen)
+}
+
+func TestRoutingKeyFromContext_Unset(t *testing.T) {
+	assert.Empty(t, RoutingKeyFromContext(context.Background()))
+}
@@ -25,6 +25,44 @@ func (s *MCPServer) RequestSampling(ctx context.Context, request mcp.CreateMessa
 		return nil, fmt.Errorf("no active session")
 	}
 
+	if err := s.checkSamplingMessages("request", request.Messages...); err != nil {
+		return nil, err
+	}
+
+	if s.samplingBudget != nil {
+		release, err := s.samplingBudget.reserve(ctx, session.SessionID(), TaskIDFromContext(ctx), request)
+		if err != nil {
+			return nil, err
+		}
+		result, err := s.sendCheckedSamplingRequest(ctx, session, request)
+		release(result, err)
+		return result, err
+	}
+	return s.sendCheckedSamplingRequest(ctx, session, request)
+}
+
+// sendCheckedSamplingRequest sends a sampling request and validates the
+// binary content of its result.
+func (s *MCPServer) sendCheckedSamplingRequest(ctx context.Context, session ClientSession, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	ctx, done := s.clientRequestContext(ctx, mcp.MethodSamplingCreateMessage, session.SessionID())
+	release, err := s.flowControl.acquire(ctx, session.SessionID())
+	if err != nil {
+		return nil, done(err)
+	}
+	result, err := s.sendSamplingRequest(ctx, session, request)
+	release()
+	if err = done(err); err != nil || result == nil {
+		return result, err
+	}
+	if err := s.checkSamplingMessages("result", result.SamplingMessage); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sendSamplingRequest sends a sampling request through the session, or to
+// the in-process sampling handler.
+func (s *MCPServer) sendSamplingRequest(ctx context.Context, session ClientSession, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
 	// Check if the session supports sampling requests
 	if samplingSession, ok := session.(SessionWithSampling); ok {
 		return samplingSession.RequestSampling(ctx, request)
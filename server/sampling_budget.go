@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SamplingBudget limits the sampling requests made on behalf of one session
+// or one task. Zero fields are unlimited.
+type SamplingBudget struct {
+	// MaxRequests limits the number of sampling requests.
+	MaxRequests int
+	// MaxTokens limits the sum of the MaxTokens asked for by the requests.
+	MaxTokens int
+	// MaxCost limits the total cost of the requests, as computed by the
+	// cost model set with WithSamplingCostModel.
+	MaxCost float64
+}
+
+// SamplingTotals is the sampling usage accumulated by a session or a task.
+type SamplingTotals struct {
+	Requests int
+	Tokens   int
+	Cost     float64
+}
+
+// SamplingUsage describes one completed sampling request.
+type SamplingUsage struct {
+	SessionID string
+	// TaskID is set when the request was made by a tool running as a task.
+	TaskID  string
+	Request mcp.CreateMessageRequest
+	Result  *mcp.CreateMessageResult
+	// Cost is the cost of the request according to the cost model.
+	Cost float64
+	// Session and Task are the totals after the request.
+	Session SamplingTotals
+	Task    SamplingTotals
+}
+
+// SamplingCostFunc computes the cost of a completed sampling request, e.g.
+// from the model in the result and the tokens requested.
+type SamplingCostFunc func(request mcp.CreateMessageRequest, result *mcp.CreateMessageResult) float64
+
+// SamplingBudgetOption configures WithSamplingBudget.
+type SamplingBudgetOption func(*samplingBudget)
+
+type samplingBudget struct {
+	session SamplingBudget
+	task    SamplingBudget
+	cost    SamplingCostFunc
+	account []func(ctx context.Context, usage SamplingUsage)
+
+	mu       sync.Mutex
+	sessions map[string]*SamplingTotals
+	tasks    map[string]*SamplingTotals
+}
+
+// WithSessionSamplingBudget limits the sampling requests of each session.
+func WithSessionSamplingBudget(budget SamplingBudget) SamplingBudgetOption {
+	return func(b *samplingBudget) {
+		b.session = budget
+	}
+}
+
+// WithTaskSamplingBudget limits the sampling requests of each task.
+func WithTaskSamplingBudget(budget SamplingBudget) SamplingBudgetOption {
+	return func(b *samplingBudget) {
+		b.task = budget
+	}
+}
+
+// WithSamplingCostModel sets the function that computes the cost of each
+// sampling request. Without one, every request costs nothing.
+func WithSamplingCostModel(cost SamplingCostFunc) SamplingBudgetOption {
+	return func(b *samplingBudget) {
+		b.cost = cost
+	}
+}
+
+// WithSamplingAccounting adds a function called after every successful
+// sampling request with its cost and the updated totals, e.g. to record
+// usage for billing.
+func WithSamplingAccounting(account func(ctx context.Context, usage SamplingUsage)) SamplingBudgetOption {
+	return func(b *samplingBudget) {
+		b.account = append(b.account, account)
+	}
+}
+
+// WithSamplingBudget accounts for the sampling requests made with
+// RequestSampling and rejects those that would exceed the session or task
+// budget with a *SamplingBudgetError. Requests and tokens are reserved
+// before a request is sent and released if it fails; costs are known, and
+// added, once it completes.
+func WithSamplingBudget(opts ...SamplingBudgetOption) ServerOption {
+	return func(s *MCPServer) {
+		b := &samplingBudget{
+			sessions: make(map[string]*SamplingTotals),
+			tasks:    make(map[string]*SamplingTotals),
+		}
+		for _, opt := range opts {
+			opt(b)
+		}
+		s.samplingBudget = b
+	}
+}
+
+// SessionSamplingUsage returns the sampling usage of a session.
+func (s *MCPServer) SessionSamplingUsage(sessionID string) SamplingTotals {
+	if s.samplingBudget == nil {
+		return SamplingTotals{}
+	}
+	return s.samplingBudget.usage(s.samplingBudget.sessions, sessionID)
+}
+
+// TaskSamplingUsage returns the sampling usage of a task.
+func (s *MCPServer) TaskSamplingUsage(taskID string) SamplingTotals {
+	if s.samplingBudget == nil {
+		return SamplingTotals{}
+	}
+	return s.samplingBudget.usage(s.samplingBudget.tasks, taskID)
+}
+
+func (b *samplingBudget) usage(totals map[string]*SamplingTotals, id string) SamplingTotals {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t := totals[id]; t != nil {
+		return *t
+	}
+	return SamplingTotals{}
+}
+
+// check returns an error if adding a request for tokens to used exceeds the
+// budget.
+func (budget SamplingBudget) check(scope, id string, used SamplingTotals, tokens int) error {
+	exceeded := func(limit string, used, max float64) error {
+		return &SamplingBudgetError{Scope: scope, ID: id, Limit: limit, Used: used, Max: max}
+	}
+	switch {
+	case budget.MaxRequests > 0 && used.Requests+1 > budget.MaxRequests:
+		return exceeded("requests", float64(used.Requests), float64(budget.MaxRequests))
+	case budget.MaxTokens > 0 && used.Tokens+tokens > budget.MaxTokens:
+		return exceeded("tokens", float64(used.Tokens), float64(budget.MaxTokens))
+	case budget.MaxCost > 0 && used.Cost >= budget.MaxCost:
+		return exceeded("cost", used.Cost, budget.MaxCost)
+	}
+	return nil
+}
+
+// reserve checks the budgets of the session and task and reserves the
+// request in both. It returns a function to call with the outcome.
+func (b *samplingBudget) reserve(ctx context.Context, sessionID, taskID string, request mcp.CreateMessageRequest) (func(*mcp.CreateMessageResult, error), error) {
+	tokens := request.MaxTokens
+
+	b.mu.Lock()
+	session := b.sessions[sessionID]
+	if session == nil {
+		session = &SamplingTotals{}
+	}
+	var task *SamplingTotals
+	if taskID != "" {
+		if task = b.tasks[taskID]; task == nil {
+			task = &SamplingTotals{}
+		}
+		if err := b.task.check("task", taskID, *task, tokens); err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+	}
+	if err := b.session.check("session", sessionID, *session, tokens); err != nil {
+		b.mu.Unlock()
+		return nil, err
+	}
+	b.sessions[sessionID] = session
+	if task != nil {
+		b.tasks[taskID] = task
+	}
+	for _, totals := range []*SamplingTotals{session, task} {
+		if totals != nil {
+			totals.Requests++
+			totals.Tokens += tokens
+		}
+	}
+	b.mu.Unlock()
+
+	return func(result *mcp.CreateMessageResult, err error) {
+		var cost float64
+		if err == nil && b.cost != nil {
+			cost = b.cost(request, result)
+		}
+
+		b.mu.Lock()
+		for _, totals := range []*SamplingTotals{session, task} {
+			if totals == nil {
+				continue
+			}
+			if err != nil {
+				totals.Requests--
+				totals.Tokens -= tokens
+			} else {
+				totals.Cost += cost
+			}
+		}
+		usage := SamplingUsage{
+			SessionID: sessionID,
+			TaskID:    taskID,
+			Request:   request,
+			Result:    result,
+			Cost:      cost,
+			Session:   *session,
+		}
+		if task != nil {
+			usage.Task = *task
+		}
+		b.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+		for _, account := range b.account {
+			account(ctx, usage)
+		}
+	}, nil
+}
+
+// dropSession forgets the usage of a session once it is unregistered.
+func (b *samplingBudget) dropSession(sessionID string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	delete(b.sessions, sessionID)
+	b.mu.Unlock()
+}
+
+// dropTask forgets the usage of a task once it is removed.
+func (b *samplingBudget) dropTask(taskID string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	delete(b.tasks, taskID)
+	b.mu.Unlock()
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func budgetSamplingSession(id string) *mockSamplingSession {
+	return &mockSamplingSession{
+		mockSession: mockSession{sessionID: id},
+		result: &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("ok")},
+			Model:           "test-model",
+		},
+	}
+}
+
+func samplingRequest(maxTokens int) mcp.CreateMessageRequest {
+	return mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("hi")}},
+			MaxTokens: maxTokens,
+		},
+	}
+}
+
+func TestWithSamplingBudget_SessionLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget SamplingBudget
+		calls  int
+		limit  string
+	}{
+		{name: "requests", budget: SamplingBudget{MaxRequests: 2}, calls: 2, limit: "requests"},
+		{name: "tokens", budget: SamplingBudget{MaxTokens: 250}, calls: 2, limit: "tokens"},
+		{name: "cost", budget: SamplingBudget{MaxCost: 0.015}, calls: 2, limit: "cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMCPServer("test", "1.0.0", WithSamplingBudget(
+				WithSessionSamplingBudget(tt.budget),
+				WithSamplingCostModel(func(request mcp.CreateMessageRequest, result *mcp.CreateMessageResult) float64 {
+					return float64(request.MaxTokens) * 0.0001
+				}),
+			))
+			ctx := s.WithContext(context.Background(), budgetSamplingSession("s1"))
+
+			for range tt.calls {
+				_, err := s.RequestSampling(ctx, samplingRequest(100))
+				require.NoError(t, err)
+			}
+			_, err := s.RequestSampling(ctx, samplingRequest(100))
+			assert.ErrorIs(t, err, ErrSamplingBudgetExceeded)
+			var budgetErr *SamplingBudgetError
+			require.ErrorAs(t, err, &budgetErr)
+			assert.Equal(t, "session", budgetErr.Scope)
+			assert.Equal(t, "s1", budgetErr.ID)
+			assert.Equal(t, tt.limit, budgetErr.Limit)
+
+			// Other sessions have their own budget.
+			other := s.WithContext(context.Background(), budgetSamplingSession("s2"))
+			_, err = s.RequestSampling(other, samplingRequest(100))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWithSamplingBudget_TaskLimits(t *testing.T) {
+	var usages []SamplingUsage
+	s := NewMCPServer("test", "1.0.0", WithSamplingBudget(
+		WithTaskSamplingBudget(SamplingBudget{MaxRequests: 1}),
+		WithSamplingCostModel(func(request mcp.CreateMessageRequest, result *mcp.CreateMessageResult) float64 {
+			return 0.5
+		}),
+		WithSamplingAccounting(func(ctx context.Context, usage SamplingUsage) {
+			usages = append(usages, usage)
+		}),
+	))
+	session := budgetSamplingSession("s1")
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	ctx, _ := taskContext(t, s, session, "task-1")
+	_, err := s.RequestSampling(ctx, samplingRequest(100))
+	require.NoError(t, err)
+	_, err = s.RequestSampling(ctx, samplingRequest(100))
+	var budgetErr *SamplingBudgetError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, "task", budgetErr.Scope)
+	assert.Equal(t, "task-1", budgetErr.ID)
+
+	// The session itself is not limited.
+	_, err = s.RequestSampling(s.WithContext(context.Background(), session), samplingRequest(100))
+	require.NoError(t, err)
+
+	require.Len(t, usages, 2)
+	assert.Equal(t, "task-1", usages[0].TaskID)
+	assert.Equal(t, 0.5, usages[0].Cost)
+	assert.Equal(t, SamplingTotals{Requests: 1, Tokens: 100, Cost: 0.5}, usages[0].Task)
+	assert.Equal(t, SamplingTotals{Requests: 2, Tokens: 200, Cost: 1}, usages[1].Session)
+	assert.Equal(t, SamplingTotals{Requests: 1, Tokens: 100, Cost: 0.5}, s.TaskSamplingUsage("task-1"))
+	assert.Equal(t, SamplingTotals{Requests: 2, Tokens: 200, Cost: 1}, s.SessionSamplingUsage("s1"))
+
+	s.UnregisterSession(context.Background(), "s1")
+	assert.Equal(t, SamplingTotals{}, s.SessionSamplingUsage("s1"))
+}
+
+func TestWithSamplingBudget_FailedRequestsAreReleased(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithSamplingBudget(WithSessionSamplingBudget(SamplingBudget{MaxRequests: 1})))
+	session := budgetSamplingSession("s1")
+	session.err = errors.New("client refused")
+	ctx := s.WithContext(context.Background(), session)
+
+	_, err := s.RequestSampling(ctx, samplingRequest(10))
+	require.EqualError(t, err, "client refused")
+	assert.Equal(t, SamplingTotals{}, s.SessionSamplingUsage("s1"))
+
+	session.err = nil
+	_, err = s.RequestSampling(ctx, samplingRequest(10))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// SchemaBaseline maps tool names to the input schemas they were released
+// with. It is usually generated with MCPServer.SchemaBaseline, committed next
+// to the server and loaded with ReadSchemaBaseline.
+type SchemaBaseline map[string]json.RawMessage
+
+// ReadSchemaBaseline decodes a baseline written as a JSON object of tool
+// names to input schemas.
+func ReadSchemaBaseline(r io.Reader) (SchemaBaseline, error) {
+	var baseline SchemaBaseline
+	if err := json.NewDecoder(r).Decode(&baseline); err != nil {
+		return nil, fmt.Errorf("failed to decode schema baseline: %w", err)
+	}
+	return baseline, nil
+}
+
+// SchemaBaselineOption configures WithSchemaBaseline.
+type SchemaBaselineOption func(*schemaBaselineConfig)
+
+type schemaBaselineConfig struct {
+	baseline SchemaBaseline
+	enforce  bool
+	logger   util.Logger
+}
+
+// WithSchemaBaselineEnforced makes the server refuse to start, returning
+// ErrBreakingSchemaChange from ServeStdio or Start, while a registered tool
+// breaks compatibility with the baseline.
+func WithSchemaBaselineEnforced() SchemaBaselineOption {
+	return func(c *schemaBaselineConfig) {
+		c.enforce = true
+	}
+}
+
+// WithSchemaBaselineLogger sets the logger that receives a warning for every
+// breaking change found when a tool is registered. It defaults to the
+// standard library logger.
+func WithSchemaBaselineLogger(logger util.Logger) SchemaBaselineOption {
+	return func(c *schemaBaselineConfig) {
+		c.logger = logger
+	}
+}
+
+// WithSchemaBaseline checks the input schema of every registered tool against
+// baseline with mcp.CompareSchemas. Breaking changes are logged as tools are
+// added; with WithSchemaBaselineEnforced they also prevent startup. Tools
+// missing from the baseline are new and always accepted.
+func WithSchemaBaseline(baseline SchemaBaseline, opts ...SchemaBaselineOption) ServerOption {
+	return func(s *MCPServer) {
+		c := &schemaBaselineConfig{
+			baseline: baseline,
+			logger:   util.DefaultLogger(),
+		}
+		for _, opt := range opts {
+			opt(c)
+		}
+		s.schemaBaseline = c
+	}
+}
+
+// SchemaBaseline returns the input schemas of the registered tools, ready to
+// be stored as the baseline for the next release.
+func (s *MCPServer) SchemaBaseline() (SchemaBaseline, error) {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+
+	baseline := make(SchemaBaseline, len(s.tools))
+	for name, tool := range s.tools {
+		schema, err := json.Marshal(toolInputSchema(tool.Tool))
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", name, err)
+		}
+		baseline[name] = schema
+	}
+	return baseline, nil
+}
+
+// CheckSchemaBaseline compares the registered tools with the baseline set by
+// WithSchemaBaseline. The returned error wraps ErrBreakingSchemaChange and
+// lists every breaking change; it is nil without a baseline.
+func (s *MCPServer) CheckSchemaBaseline() error {
+	if s.schemaBaseline == nil {
+		return nil
+	}
+
+	s.toolsMu.RLock()
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	tools := make([]mcp.Tool, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		tools = append(tools, s.tools[name].Tool)
+	}
+	s.toolsMu.RUnlock()
+
+	var errs []error
+	for _, tool := range tools {
+		changes, err := s.schemaBaseline.breakingChanges(tool)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, change := range changes {
+			errs = append(errs, fmt.Errorf("%w: tool %s: %s", ErrBreakingSchemaChange, tool.Name, change))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// breakingChanges compares tool with its baseline schema, if it has one.
+func (c *schemaBaselineConfig) breakingChanges(tool mcp.Tool) ([]mcp.SchemaChange, error) {
+	baseline, ok := c.baseline[tool.Name]
+	if !ok {
+		return nil, nil
+	}
+	comparison, err := mcp.CompareSchemas(baseline, toolInputSchema(tool))
+	if err != nil {
+		return nil, fmt.Errorf("tool %s: %w", tool.Name, err)
+	}
+	return comparison.BreakingChanges(), nil
+}
+
+// warnSchemaBaseline logs the breaking changes of newly registered tools.
+func (s *MCPServer) warnSchemaBaseline(tools []ServerTool) {
+	if s.schemaBaseline == nil {
+		return
+	}
+	for _, entry := range tools {
+		changes, err := s.schemaBaseline.breakingChanges(entry.Tool)
+		if err != nil {
+			s.schemaBaseline.logger.Errorf("schema baseline check failed: %v", err)
+			continue
+		}
+		for _, change := range changes {
+			s.schemaBaseline.logger.Errorf("tool %s breaks schema baseline: %s", entry.Tool.Name, change)
+		}
+	}
+}
+
+func toolInputSchema(tool mcp.Tool) any {
+	if tool.RawInputSchema != nil {
+		return tool.RawInputSchema
+	}
+	return tool.InputSchema
+}
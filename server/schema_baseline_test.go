@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type recordingLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *recordingLogger) Infof(format string, v ...any) {}
+
+func (l *recordingLogger) Errorf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, v...))
+}
+
+func noopToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText("ok"), nil
+}
+
+func weatherBaseline(t *testing.T) SchemaBaseline {
+	t.Helper()
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("weather",
+		mcp.WithString("city", mcp.Required()),
+		mcp.WithString("units", mcp.Enum("metric", "imperial")),
+	), noopToolHandler)
+
+	baseline, err := s.SchemaBaseline()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(baseline))
+	loaded, err := ReadSchemaBaseline(&buf)
+	require.NoError(t, err)
+	return loaded
+}
+
+func TestMCPServer_SchemaBaseline(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     mcp.Tool
+		breaking []string
+	}{
+		{
+			name: "unchanged",
+			tool: mcp.NewTool("weather",
+				mcp.WithString("city", mcp.Required()),
+				mcp.WithString("units", mcp.Enum("metric", "imperial")),
+			),
+		},
+		{
+			name: "compatible change",
+			tool: mcp.NewTool("weather",
+				mcp.WithString("city", mcp.Required()),
+				mcp.WithString("units", mcp.Enum("metric", "imperial", "kelvin")),
+				mcp.WithNumber("days"),
+			),
+		},
+		{
+			name: "new tool",
+			tool: mcp.NewTool("forecast", mcp.WithString("city", mcp.Required())),
+		},
+		{
+			name: "breaking changes",
+			tool: mcp.NewTool("weather",
+				mcp.WithNumber("city", mcp.Required()),
+				mcp.WithString("units", mcp.Enum("metric")),
+			),
+			breaking: []string{
+				"properties.city: type changed from string to number",
+				`properties.units: enum values ["imperial"] removed`,
+			},
+		},
+		{
+			name: "raw input schema",
+			tool: mcp.NewToolWithRawSchema("weather", "", []byte(`{
+				"type": "object",
+				"properties": {"units": {"type": "string"}}
+			}`)),
+			breaking: []string{`properties.city: property "city" removed`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			s := NewMCPServer("test", "1.0.0",
+				WithSchemaBaseline(weatherBaseline(t), WithSchemaBaselineLogger(logger)),
+			)
+			s.AddTool(tt.tool, noopToolHandler)
+
+			require.Len(t, logger.errors, len(tt.breaking))
+			for i, want := range tt.breaking {
+				assert.Contains(t, logger.errors[i], want)
+			}
+
+			err := s.CheckSchemaBaseline()
+			if len(tt.breaking) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrBreakingSchemaChange)
+			for _, want := range tt.breaking {
+				assert.Contains(t, err.Error(), want)
+			}
+			// Without enforcement the server still starts.
+			assert.NoError(t, s.checkStartup())
+		})
+	}
+}
+
+func TestMCPServer_SchemaBaselineEnforced(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0",
+		WithSchemaBaseline(weatherBaseline(t),
+			WithSchemaBaselineEnforced(),
+			WithSchemaBaselineLogger(&recordingLogger{}),
+		),
+	)
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+	assert.ErrorIs(t, s.checkStartup(), ErrBreakingSchemaChange)
+
+	stdio := NewStdioServer(s)
+	err := stdio.Listen(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrBreakingSchemaChange)
+
+	assert.ErrorIs(t, NewStreamableHTTPServer(s).Start("127.0.0.1:0"), ErrBreakingSchemaChange)
+	assert.ErrorIs(t, NewSSEServer(s).Start("127.0.0.1:0"), ErrBreakingSchemaChange)
+
+	// Restoring the tool lets the server start again.
+	s.AddTool(mcp.NewTool("weather",
+		mcp.WithString("city", mcp.Required()),
+		mcp.WithString("units", mcp.Enum("metric", "imperial")),
+	), noopToolHandler)
+	assert.NoError(t, s.checkStartup())
+}
+
+func TestReadSchemaBaseline_Invalid(t *testing.T) {
+	_, err := ReadSchemaBaseline(strings.NewReader(`["weather"]`))
+	assert.ErrorContains(t, err, "failed to decode schema baseline")
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithToolSchemaHashes publishes a fingerprint of every tool's schemas under
+// mcp.ToolSchemaHashMetaKey, in the _meta of tools/list entries and of the
+// results of calls to the tool. Clients compare the two, e.g. with
+// mcp.IsToolSchemaStale, to notice mid-session that their cached definition
+// of a tool is out of date. The fingerprints cover the schemas as registered,
+// before dynamic enums are resolved; changes of enum values are announced
+// with notifications/tools/list_changed instead.
+func WithToolSchemaHashes() ServerOption {
+	return func(s *MCPServer) {
+		s.toolSchemaHashes = true
+	}
+}
+
+// addSchemaHashes sets the schema fingerprints of listed tools, with
+// WithToolSchemaHashes.
+func (s *MCPServer) addSchemaHashes(tools []mcp.Tool) {
+	if !s.toolSchemaHashes {
+		return
+	}
+	for i, tool := range tools {
+		if hash, err := mcp.ToolSchemaHash(tool); err == nil {
+			tools[i].Meta = metaWithSchemaHash(tool.Meta, hash)
+		}
+	}
+}
+
+// metaWithSchemaHash returns a copy of meta, which may be nil, with the
+// schema fingerprint set. Tool definitions share their Meta, so it must not
+// be modified in place.
+func metaWithSchemaHash(meta *mcp.Meta, hash string) *mcp.Meta {
+	updated := &mcp.Meta{AdditionalFields: make(map[string]any)}
+	if meta != nil {
+		updated.ProgressToken = meta.ProgressToken
+		maps.Copy(updated.AdditionalFields, meta.AdditionalFields)
+	}
+	updated.AdditionalFields[mcp.ToolSchemaHashMetaKey] = hash
+	return updated
+}
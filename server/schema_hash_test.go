@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_ToolSchemaHashes(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolSchemaHashes())
+	tool := mcp.NewTool("weather", mcp.WithString("city", mcp.Required()))
+	tool.Meta = &mcp.Meta{AdditionalFields: map[string]any{"owner": "team-a"}}
+	s.AddTool(tool, noopToolHandler)
+	want, err := mcp.ToolSchemaHash(tool)
+	require.NoError(t, err)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	listed := resp.Result.(mcp.ListToolsResult).Tools
+	require.Len(t, listed, 1)
+	assert.Equal(t, want, mcp.ToolSchemaHashFromMeta(listed[0].Meta))
+	assert.Equal(t, "team-a", listed[0].Meta.AdditionalFields["owner"], "existing _meta is kept")
+	assert.NotContains(t, s.GetTool("weather").Tool.Meta.AdditionalFields, mcp.ToolSchemaHashMetaKey, "registered tool is not modified")
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"weather","arguments":{"city":"Paris"}}}`))
+	resp, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+	assert.Equal(t, want, mcp.ToolSchemaHashFromMeta(result.Meta))
+	assert.False(t, mcp.IsToolSchemaStale(listed[0], &result))
+
+	// Changing the tool mid-session makes the client's copy stale.
+	s.AddTool(mcp.NewTool("weather", mcp.WithString("city", mcp.Required()), mcp.WithString("units")), noopToolHandler)
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"weather","arguments":{"city":"Paris"}}}`))
+	result = response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	assert.True(t, mcp.IsToolSchemaStale(listed[0], &result))
+}
+
+func TestMCPServer_ToolSchemaHashesDisabled(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"weather"}}`))
+	result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	assert.Empty(t, mcp.ToolSchemaHashFromMeta(result.Meta))
+}
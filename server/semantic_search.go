@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// EmbeddingsProvider computes vector embeddings of texts, e.g. by calling an
+// embeddings API. It returns one vector per text, in order.
+type EmbeddingsProvider interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbeddingsProviderFunc adapts a function to EmbeddingsProvider.
+type EmbeddingsProviderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// Embed calls f.
+func (f EmbeddingsProviderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+// VectorMatch is a result of VectorIndex.Search.
+type VectorMatch struct {
+	ID    string
+	Score float64
+}
+
+// VectorIndex stores vectors by ID and finds the ones most similar to a
+// query vector. Implementations must be safe for concurrent use.
+type VectorIndex interface {
+	// Put stores or replaces the vector of id.
+	Put(id string, vector []float32)
+	// Search returns up to limit matches among the IDs accepted by filter,
+	// most similar first. A limit of zero means no limit.
+	Search(vector []float32, limit int, filter func(id string) bool) []VectorMatch
+}
+
+// MemoryVectorIndex is an in-memory VectorIndex ranking by cosine
+// similarity. It compares the query with every vector, which suits
+// catalogs of up to a few thousand tools.
+type MemoryVectorIndex struct {
+	mu      sync.RWMutex
+	vectors map[string][]float32
+}
+
+// NewMemoryVectorIndex creates an empty MemoryVectorIndex.
+func NewMemoryVectorIndex() *MemoryVectorIndex {
+	return &MemoryVectorIndex{vectors: make(map[string][]float32)}
+}
+
+// Put stores the normalized vector of id.
+func (m *MemoryVectorIndex) Put(id string, vector []float32) {
+	normalized := normalizeVector(vector)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vectors[id] = normalized
+}
+
+// Search returns the vectors most similar to vector by cosine similarity.
+func (m *MemoryVectorIndex) Search(vector []float32, limit int, filter func(id string) bool) []VectorMatch {
+	query := normalizeVector(vector)
+	m.mu.RLock()
+	matches := make([]VectorMatch, 0, len(m.vectors))
+	for id, v := range m.vectors {
+		if filter != nil && !filter(id) {
+			continue
+		}
+		if len(v) != len(query) {
+			continue
+		}
+		var dot float64
+		for i := range v {
+			dot += float64(v[i]) * float64(query[i])
+		}
+		matches = append(matches, VectorMatch{ID: id, Score: dot})
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func normalizeVector(vector []float32) []float32 {
+	var sum float64
+	for _, x := range vector {
+		sum += float64(x) * float64(x)
+	}
+	normalized := make([]float32, len(vector))
+	if sum == 0 {
+		return normalized
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range vector {
+		normalized[i] = float32(float64(x) / norm)
+	}
+	return normalized
+}
+
+// SemanticSearchOption configures WithSemanticToolSearch.
+type SemanticSearchOption func(*semanticSearch)
+
+// WithVectorIndex sets the index the tool embeddings are stored in. It
+// defaults to a MemoryVectorIndex.
+func WithVectorIndex(index VectorIndex) SemanticSearchOption {
+	return func(s *semanticSearch) {
+		s.index = index
+	}
+}
+
+// WithEmbeddingBatchSize sets the maximum number of tool descriptions sent
+// to the provider in one Embed call. It defaults to 64.
+func WithEmbeddingBatchSize(size int) SemanticSearchOption {
+	return func(s *semanticSearch) {
+		if size > 0 {
+			s.batchSize = size
+		}
+	}
+}
+
+// WithMinSimilarity leaves out tools whose similarity to the query is below
+// min. Cosine similarities range from -1 to 1.
+func WithMinSimilarity(min float64) SemanticSearchOption {
+	return func(s *semanticSearch) {
+		s.minScore = min
+	}
+}
+
+type semanticSearch struct {
+	provider  EmbeddingsProvider
+	index     VectorIndex
+	batchSize int
+	minScore  float64
+
+	mu      sync.Mutex
+	indexed map[string]struct{} // IDs of the embedded tools
+}
+
+// WithSemanticToolSearch enables tool search, as WithToolSearch does, and
+// ranks the tools by the similarity of their embeddings to the query's
+// instead of by keywords, so a query such as "coffee brewing" finds a tool
+// described as preparing espresso. Each tool's name, title and description
+// are embedded with provider the first time a search sees the tool, or sees
+// it changed, in batches.
+func WithSemanticToolSearch(provider EmbeddingsProvider, opts ...SemanticSearchOption) ServerOption {
+	return func(s *MCPServer) {
+		search := &semanticSearch{
+			provider:  provider,
+			batchSize: 64,
+			minScore:  math.Inf(-1),
+			indexed:   make(map[string]struct{}),
+		}
+		for _, opt := range opts {
+			opt(search)
+		}
+		if search.index == nil {
+			search.index = NewMemoryVectorIndex()
+		}
+		s.semanticSearch = search
+		WithToolSearch()(s)
+	}
+}
+
+// searchTools ranks tools against a query, semantically if configured.
+func (s *MCPServer) searchTools(ctx context.Context, tools []mcp.Tool, query string, limit int) ([]mcp.Tool, error) {
+	if s.semanticSearch == nil || strings.TrimSpace(query) == "" {
+		return mcp.SearchTools(tools, query, limit), nil
+	}
+	return s.semanticSearch.search(ctx, tools, query, limit)
+}
+
+func (e *semanticSearch) search(ctx context.Context, tools []mcp.Tool, query string, limit int) ([]mcp.Tool, error) {
+	if err := e.indexTools(ctx, tools); err != nil {
+		return nil, err
+	}
+	vectors, err := e.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query: got %d embeddings for 1 text", len(vectors))
+	}
+
+	byID := make(map[string]mcp.Tool, len(tools))
+	for _, tool := range tools {
+		byID[toolEmbeddingID(tool)] = tool
+	}
+	var result []mcp.Tool
+	for _, match := range e.index.Search(vectors[0], limit, func(id string) bool {
+		_, ok := byID[id]
+		return ok
+	}) {
+		if match.Score < e.minScore {
+			break
+		}
+		result = append(result, byID[match.ID])
+	}
+	return result, nil
+}
+
+// indexTools embeds the tools that are not indexed yet or whose description
+// changed since. The provider is called without holding e.mu, so searches
+// do not queue behind each other's network calls; concurrent searches may
+// embed the same new tool twice, which is harmless.
+func (e *semanticSearch) indexTools(ctx context.Context, tools []mcp.Tool) error {
+	var ids, texts []string
+	e.mu.Lock()
+	for _, tool := range tools {
+		id := toolEmbeddingID(tool)
+		if _, ok := e.indexed[id]; !ok {
+			ids = append(ids, id)
+			texts = append(texts, toolEmbeddingText(tool))
+		}
+	}
+	e.mu.Unlock()
+
+	for start := 0; start < len(texts); start += e.batchSize {
+		end := min(start+e.batchSize, len(texts))
+		vectors, err := e.provider.Embed(ctx, texts[start:end])
+		if err != nil {
+			return fmt.Errorf("embed tools: %w", err)
+		}
+		if len(vectors) != end-start {
+			return fmt.Errorf("embed tools: got %d embeddings for %d texts", len(vectors), end-start)
+		}
+		for i, vector := range vectors {
+			e.index.Put(ids[start+i], vector)
+		}
+		e.mu.Lock()
+		for _, id := range ids[start:end] {
+			e.indexed[id] = struct{}{}
+		}
+		e.mu.Unlock()
+	}
+	return nil
+}
+
+// toolEmbeddingID is the ID a tool's embedding is stored under. It includes
+// a hash of the embedded text, so that a session tool shadowing a global
+// tool of the same name, or a tool that changed, has an embedding of its
+// own instead of replacing the other one's.
+func toolEmbeddingID(tool mcp.Tool) string {
+	sum := sha256.Sum256([]byte(toolEmbeddingText(tool)))
+	return tool.Name + "#" + hex.EncodeToString(sum[:8])
+}
+
+// toolEmbeddingText is the text embedded for a tool.
+func toolEmbeddingText(tool mcp.Tool) string {
+	parts := []string{tool.Name}
+	if tool.Annotations.Title != "" {
+		parts = append(parts, tool.Annotations.Title)
+	}
+	if tool.Description != "" {
+		parts = append(parts, tool.Description)
+	}
+	return strings.Join(parts, "\n")
+}
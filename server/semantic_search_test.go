@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// topicEmbedder embeds texts as counts of topic words, so that synonyms such
+// as "coffee" and "espresso" land on the same dimension.
+type topicEmbedder struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+var embeddingTopics = [][]string{
+	{"coffee", "espresso", "brew", "brewing", "latte"},
+	{"weather", "forecast", "rain"},
+	{"translate", "language"},
+}
+
+func (e *topicEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.batches = append(e.batches, texts)
+	e.mu.Unlock()
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(embeddingTopics))
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			for topic, words := range embeddingTopics {
+				for _, w := range words {
+					if strings.Trim(word, ".,_") == w {
+						vector[topic]++
+					}
+				}
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func TestMemoryVectorIndex(t *testing.T) {
+	index := NewMemoryVectorIndex()
+	index.Put("a", []float32{1, 0})
+	index.Put("b", []float32{1, 1})
+	index.Put("c", []float32{0, 3})
+	index.Put("other", []float32{1, 0, 0})
+
+	matches := index.Search([]float32{2, 0}, 0, nil)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "a", matches[0].ID)
+	assert.InDelta(t, 1, matches[0].Score, 1e-6)
+	assert.Equal(t, "b", matches[1].ID)
+	assert.InDelta(t, 0.7071, matches[1].Score, 1e-4)
+	assert.Equal(t, "c", matches[2].ID)
+	assert.InDelta(t, 0, matches[2].Score, 1e-6)
+
+	matches = index.Search([]float32{2, 0}, 1, func(id string) bool { return id != "a" })
+	require.Len(t, matches, 1)
+	assert.Equal(t, "b", matches[0].ID)
+}
+
+func TestWithSemanticToolSearch(t *testing.T) {
+	embedder := &topicEmbedder{}
+	s := NewMCPServer("test", "1.0.0", WithSemanticToolSearch(embedder, WithEmbeddingBatchSize(2), WithMinSimilarity(0.5)))
+	s.AddTool(mcp.NewTool("make_espresso", mcp.WithDescription("Pull an espresso shot")), noopToolHandler)
+	s.AddTool(mcp.NewTool("get_forecast", mcp.WithDescription("Weather forecast")), noopToolHandler)
+	s.AddTool(mcp.NewTool("translate", mcp.WithDescription("Translate text to another language")), noopToolHandler)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/search","params":{"query":"find tools related to coffee brewing"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, []string{"make_espresso"}, toolNames(resp.Result.(*mcp.SearchToolsResult).Tools))
+
+	// Three tools in batches of two, then the query.
+	require.Len(t, embedder.batches, 3)
+	assert.Len(t, embedder.batches[0], 2)
+	assert.Len(t, embedder.batches[1], 1)
+	assert.Equal(t, []string{"find tools related to coffee brewing"}, embedder.batches[2])
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{"_meta":{"query":"will it rain"}}}`))
+	assert.Equal(t, []string{"get_forecast"}, toolNames(response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools))
+	assert.Len(t, embedder.batches, 4, "indexed tools are not embedded again")
+
+	s.AddTool(mcp.NewTool("translate", mcp.WithDescription("Brew a latte")), noopToolHandler)
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/search","params":{"query":"coffee"}}`))
+	assert.Equal(t, []string{"make_espresso", "translate"}, toolNames(response.(mcp.JSONRPCResponse).Result.(*mcp.SearchToolsResult).Tools))
+	require.Len(t, embedder.batches, 6)
+	assert.Equal(t, []string{"translate\nBrew a latte"}, embedder.batches[4], "changed tools are embedded again")
+}
+
+func TestSemanticToolSearchProviderError(t *testing.T) {
+	failing := EmbeddingsProviderFunc(func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("quota exceeded")
+	})
+	s := NewMCPServer("test", "1.0.0", WithSemanticToolSearch(failing))
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/search","params":{"query":"weather"}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, rpcErr.Error.Code)
+	assert.Contains(t, rpcErr.Error.Message, "quota exceeded")
+}
+
+func TestSemanticToolSearchShadowedTools(t *testing.T) {
+	embedder := &topicEmbedder{}
+	s := NewMCPServer("test", "1.0.0", WithToolCapabilities(true), WithSemanticToolSearch(embedder))
+	s.AddTool(mcp.NewTool("brew", mcp.WithDescription("Brew coffee")), noopToolHandler)
+	session := &sessionTestClientWithTools{sessionID: "alice", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	require.NoError(t, s.AddSessionTools("alice", ServerTool{Tool: mcp.NewTool("brew", mcp.WithDescription("Brew a latte")), Handler: noopToolHandler}))
+	sessionCtx := s.WithContext(context.Background(), session)
+
+	search := func(ctx context.Context) string {
+		t.Helper()
+		response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/search","params":{"query":"coffee"}}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		tools := resp.Result.(*mcp.SearchToolsResult).Tools
+		require.Len(t, tools, 1)
+		return tools[0].Description
+	}
+	for range 2 {
+		assert.Equal(t, "Brew coffee", search(context.Background()))
+		assert.Equal(t, "Brew a latte", search(sessionCtx))
+	}
+
+	var embedded []string
+	for _, batch := range embedder.batches {
+		if batch[0] != "coffee" {
+			embedded = append(embedded, batch...)
+		}
+	}
+	assert.ElementsMatch(t, []string{"brew\nBrew coffee", "brew\nBrew a latte"}, embedded, "each version is embedded once")
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"reflect"
 	"slices"
 	"sort"
 	"sync"
@@ -30,13 +31,18 @@ type resourceTemplateEntry struct {
 
 // taskEntry holds task state and associated data
 type taskEntry struct {
-	task       mcp.Task
-	sessionID  string
-	result     any                // The actual result once completed
-	resultErr  error              // Error if task failed
-	cancelFunc context.CancelFunc // Function to cancel the task
-	done       chan struct{}      // Channel to signal task completion
-	completed  bool               // Whether the task has been completed (guards done channel closure)
+	task        mcp.Task
+	sessionID   string
+	principal   string             // Principal that created the task, if task ownership is enabled
+	resultErr   error              // Error if task failed
+	cancelFunc  context.CancelFunc // Function to cancel the task
+	done        chan struct{}      // Channel to signal task completion
+	completed   bool               // Whether the task reached a terminal status (guards done channel closure)
+	ctx         context.Context    // Context of the creating request, without its cancellation, for task hooks
+	callbackURL string             // URL the task outcome is posted to, if requested
+
+	inputMu       sync.Mutex // Serializes the input_required bookkeeping of RequestInput
+	pendingInputs int        // RequestInput calls waiting for an answer
 }
 
 // ServerOption is a function that configures an MCPServer.
@@ -60,6 +66,9 @@ type ToolHandlerMiddleware func(ToolHandlerFunc) ToolHandlerFunc
 // ResourceHandlerMiddleware is a middleware function that wraps a ResourceHandlerFunc.
 type ResourceHandlerMiddleware func(ResourceHandlerFunc) ResourceHandlerFunc
 
+// PromptHandlerMiddleware is a middleware function that wraps a PromptHandlerFunc.
+type PromptHandlerMiddleware func(PromptHandlerFunc) PromptHandlerFunc
+
 // ToolFilterFunc is a function that filters tools based on context, typically using session information.
 type ToolFilterFunc func(ctx context.Context, tools []mcp.Tool) []mcp.Tool
 
@@ -67,6 +76,12 @@ type ToolFilterFunc func(ctx context.Context, tools []mcp.Tool) []mcp.Tool
 type ServerTool struct {
 	Tool    mcp.Tool
 	Handler ToolHandlerFunc
+	// ArgumentsType is the type the handler binds the arguments to, if it is
+	// a typed handler. Validate checks it against the tool's input schema.
+	ArgumentsType reflect.Type
+	// RequiresTask rejects calls to the tool that are not task-augmented,
+	// e.g. because the handler requests input with RequestInput.
+	RequiresTask bool
 }
 
 // ServerPrompt combines a Prompt with its handler function.
@@ -128,6 +143,8 @@ type requestError struct {
 	id   any
 	code int
 	err  error
+	// data, if set, is the data of the JSON-RPC error.
+	data any
 }
 
 func (e *requestError) Error() string {
@@ -138,7 +155,7 @@ func (e *requestError) ToJSONRPCError() mcp.JSONRPCError {
 	return mcp.JSONRPCError{
 		JSONRPC: mcp.JSONRPC_VERSION,
 		ID:      mcp.NewRequestId(e.id),
-		Error:   mcp.NewJSONRPCErrorDetails(e.code, e.err.Error(), nil),
+		Error:   mcp.NewJSONRPCErrorDetails(e.code, e.err.Error(), e.data),
 	}
 }
 
@@ -156,6 +173,7 @@ type MCPServer struct {
 	resourcesMu            sync.RWMutex
 	resourceMiddlewareMu   sync.RWMutex
 	promptsMu              sync.RWMutex
+	promptMiddlewareMu     sync.RWMutex
 	toolsMu                sync.RWMutex
 	toolMiddlewareMu       sync.RWMutex
 	notificationHandlersMu sync.RWMutex
@@ -173,6 +191,7 @@ type MCPServer struct {
 	tools                      map[string]ServerTool
 	toolHandlerMiddlewares     []ToolHandlerMiddleware
 	resourceHandlerMiddlewares []ResourceHandlerMiddleware
+	promptHandlerMiddlewares   []PromptHandlerMiddleware
 	toolFilters                []ToolFilterFunc
 	notificationHandlers       map[string]NotificationHandlerFunc
 	capabilities               serverCapabilities
@@ -180,6 +199,44 @@ type MCPServer struct {
 	sessions                   sync.Map
 	hooks                      *Hooks
 	tasks                      map[string]*taskEntry
+	taskPayloads               TaskPayloadStore
+	inputFallback              InputFallback
+	taskWebhook                *TaskWebhook
+	schemaBaseline             *schemaBaselineConfig
+	notificationOrder          NotificationOrder
+	sessionStore               SessionStore
+	sessionKVs                 sync.Map
+	routingKey                 RoutingKeyFunc
+	toolSchemaHashes           bool
+	chaos                      *chaosConfig
+	taskOwnership              *taskOwnership
+	errorTranslator            *ErrorTranslator
+	strictStartup              bool
+	duplicates                 duplicateRegistrations
+	samplingBudget             *samplingBudget
+	binaryLimits               *mcp.BinaryContentLimits
+	messageCatalog             mcp.MessageCatalog
+	stats                      requestStats
+	toolSearch                 bool
+	extensions                 map[mcp.MCPMethod]extensionHandlerFunc
+	semanticSearch             *semanticSearch
+	clock                      Clock
+	toolTracer                 *toolTracer
+	continuations              *resultContinuations
+	leader                     *leaderElection
+	singletonJobs              []singletonJob
+	readinessChecks            map[string]ReadinessCheck
+	flowControl                *flowControl
+	dynamicEnums               dynamicEnums
+	toolDowntimes              toolDowntimes
+	resourceInvalidators       []ResourceInvalidatorFunc
+	clientTimeouts             clientRequestTimeouts
+	idGenerator                IDGenerator
+	strictToolResults          bool
+	contentEncoding            *contentEncoding
+	structuredContentCompat    bool
+	taskQueue                  *taskQueue
+	resourceSubscriptions      *resourceSubscriptions
 }
 
 // WithPaginationLimit sets the pagination limit for the server.
@@ -191,14 +248,15 @@ func WithPaginationLimit(limit int) ServerOption {
 
 // serverCapabilities defines the supported features of the MCP server
 type serverCapabilities struct {
-	tools       *toolCapabilities
-	resources   *resourceCapabilities
-	prompts     *promptCapabilities
-	logging     *bool
-	sampling    *bool
-	elicitation *bool
-	roots       *bool
-	tasks       *taskCapabilities
+	tools        *toolCapabilities
+	resources    *resourceCapabilities
+	prompts      *promptCapabilities
+	logging      *bool
+	sampling     *bool
+	elicitation  *bool
+	roots        *bool
+	tasks        *taskCapabilities
+	experimental map[string]any
 }
 
 // resourceCapabilities defines the supported resource-related features
@@ -219,9 +277,9 @@ type toolCapabilities struct {
 
 // taskCapabilities defines the supported task-related features
 type taskCapabilities struct {
-	list           bool
-	cancel         bool
-	toolCallTasks  bool
+	list          bool
+	cancel        bool
+	toolCallTasks bool
 }
 
 // WithResourceCapabilities configures resource-related server capabilities
@@ -259,6 +317,18 @@ func WithResourceHandlerMiddleware(
 	}
 }
 
+// WithPromptHandlerMiddleware allows adding a middleware for the
+// prompt handler call chain.
+func WithPromptHandlerMiddleware(
+	promptHandlerMiddleware PromptHandlerMiddleware,
+) ServerOption {
+	return func(s *MCPServer) {
+		s.promptMiddlewareMu.Lock()
+		s.promptHandlerMiddlewares = append(s.promptHandlerMiddlewares, promptHandlerMiddleware)
+		s.promptMiddlewareMu.Unlock()
+	}
+}
+
 // WithResourceRecovery adds a middleware that recovers from panics in resource handlers.
 func WithResourceRecovery() ServerOption {
 	return WithResourceHandlerMiddleware(func(next ResourceHandlerFunc) ResourceHandlerFunc {
@@ -368,6 +438,16 @@ func WithTaskCapabilities(list, cancel, toolCallTasks bool) ServerOption {
 	}
 }
 
+// WithTaskPayloadStore sets where the results of completed tasks are kept
+// until they are retrieved or expire. By default results are held in memory
+// without limit; use a TieredTaskPayloadStore to bound memory usage and
+// spill to disk.
+func WithTaskPayloadStore(store TaskPayloadStore) ServerOption {
+	return func(s *MCPServer) {
+		s.taskPayloads = store
+	}
+}
+
 // WithInstructions sets the server instructions for the client returned in the initialize response
 func WithInstructions(instructions string) ServerOption {
 	return func(s *MCPServer) {
@@ -388,10 +468,17 @@ func NewMCPServer(
 		tools:                      make(map[string]ServerTool),
 		toolHandlerMiddlewares:     make([]ToolHandlerMiddleware, 0),
 		resourceHandlerMiddlewares: make([]ResourceHandlerMiddleware, 0),
+		promptHandlerMiddlewares:   make([]PromptHandlerMiddleware, 0),
 		name:                       name,
 		version:                    version,
 		notificationHandlers:       make(map[string]NotificationHandlerFunc),
 		tasks:                      make(map[string]*taskEntry),
+		clock:                      SystemClock,
+		clientTimeouts: clientRequestTimeouts{
+			sampling:    DefaultSamplingTimeout,
+			elicitation: DefaultElicitationTimeout,
+			roots:       DefaultRootsTimeout,
+		},
 		capabilities: serverCapabilities{
 			tools:     nil,
 			resources: nil,
@@ -404,6 +491,15 @@ func NewMCPServer(
 		opt(s)
 	}
 
+	if s.taskWebhook != nil {
+		s.taskWebhook.clock = s.clock
+	}
+
+	if s.taskPayloads == nil {
+		// An in-memory store cannot fail to initialize.
+		s.taskPayloads, _ = NewTieredTaskPayloadStore()
+	}
+
 	return s
 }
 
@@ -418,6 +514,9 @@ func (s *MCPServer) AddResources(resources ...ServerResource) {
 
 	s.resourcesMu.Lock()
 	for _, entry := range resources {
+		if _, ok := s.resources[entry.Resource.URI]; ok {
+			s.duplicates.add("resource", entry.Resource.URI)
+		}
 		s.resources[entry.Resource.URI] = resourceEntry{
 			resource: entry.Resource,
 			handler:  entry.Handler,
@@ -437,6 +536,7 @@ func (s *MCPServer) SetResources(resources ...ServerResource) {
 	s.resourcesMu.Lock()
 	s.resources = make(map[string]resourceEntry, len(resources))
 	s.resourcesMu.Unlock()
+	s.duplicates.reset("resource")
 	s.AddResources(resources...)
 }
 
@@ -459,6 +559,7 @@ func (s *MCPServer) DeleteResources(uris ...string) {
 		}
 	}
 	s.resourcesMu.Unlock()
+	s.duplicates.forget("resource", uris...)
 
 	// Send notification to all initialized sessions if listChanged capability is enabled and we actually remove a resource
 	if exists && s.capabilities.resources != nil && s.capabilities.resources.listChanged {
@@ -474,6 +575,7 @@ func (s *MCPServer) RemoveResource(uri string) {
 		delete(s.resources, uri)
 	}
 	s.resourcesMu.Unlock()
+	s.duplicates.forget("resource", uri)
 
 	// Send notification to all initialized sessions if listChanged capability is enabled and we actually remove a resource
 	if exists && s.capabilities.resources != nil && s.capabilities.resources.listChanged {
@@ -487,6 +589,9 @@ func (s *MCPServer) AddResourceTemplates(resourceTemplates ...ServerResourceTemp
 
 	s.resourcesMu.Lock()
 	for _, entry := range resourceTemplates {
+		if _, ok := s.resourceTemplates[entry.Template.URITemplate.Raw()]; ok {
+			s.duplicates.add("resource template", entry.Template.URITemplate.Raw())
+		}
 		s.resourceTemplates[entry.Template.URITemplate.Raw()] = resourceTemplateEntry{
 			template: entry.Template,
 			handler:  entry.Handler,
@@ -506,6 +611,7 @@ func (s *MCPServer) SetResourceTemplates(templates ...ServerResourceTemplate) {
 	s.resourcesMu.Lock()
 	s.resourceTemplates = make(map[string]resourceTemplateEntry, len(templates))
 	s.resourcesMu.Unlock()
+	s.duplicates.reset("resource template")
 	s.AddResourceTemplates(templates...)
 }
 
@@ -523,6 +629,9 @@ func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
 
 	s.promptsMu.Lock()
 	for _, entry := range prompts {
+		if _, ok := s.prompts[entry.Prompt.Name]; ok {
+			s.duplicates.add("prompt", entry.Prompt.Name)
+		}
 		s.prompts[entry.Prompt.Name] = entry.Prompt
 		s.promptHandlers[entry.Prompt.Name] = entry.Handler
 	}
@@ -546,6 +655,7 @@ func (s *MCPServer) SetPrompts(prompts ...ServerPrompt) {
 	s.prompts = make(map[string]mcp.Prompt, len(prompts))
 	s.promptHandlers = make(map[string]PromptHandlerFunc, len(prompts))
 	s.promptsMu.Unlock()
+	s.duplicates.reset("prompt")
 	s.AddPrompts(prompts...)
 }
 
@@ -561,6 +671,7 @@ func (s *MCPServer) DeletePrompts(names ...string) {
 		}
 	}
 	s.promptsMu.Unlock()
+	s.duplicates.forget("prompt", names...)
 
 	// Send notification to all initialized sessions if listChanged capability is enabled, and we actually remove a prompt
 	if exists && s.capabilities.prompts != nil && s.capabilities.prompts.listChanged {
@@ -619,10 +730,16 @@ func (s *MCPServer) AddTools(tools ...ServerTool) {
 
 	s.toolsMu.Lock()
 	for _, entry := range tools {
+		if _, ok := s.tools[entry.Tool.Name]; ok {
+			s.duplicates.add("tool", entry.Tool.Name)
+		}
 		s.tools[entry.Tool.Name] = entry
 	}
 	s.toolsMu.Unlock()
 
+	s.watchDynamicEnums("", tools)
+	s.warnSchemaBaseline(tools)
+
 	// When the list of available tools changes, servers that declared the listChanged capability SHOULD send a notification.
 	if s.capabilities.tools.listChanged {
 		// Send notification to all initialized sessions
@@ -633,8 +750,14 @@ func (s *MCPServer) AddTools(tools ...ServerTool) {
 // SetTools replaces all existing tools with the provided list
 func (s *MCPServer) SetTools(tools ...ServerTool) {
 	s.toolsMu.Lock()
+	previous := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		previous = append(previous, name)
+	}
 	s.tools = make(map[string]ServerTool, len(tools))
 	s.toolsMu.Unlock()
+	s.duplicates.reset("tool")
+	s.unwatchDynamicEnums("", previous...)
 	s.AddTools(tools...)
 }
 
@@ -673,6 +796,8 @@ func (s *MCPServer) DeleteTools(names ...string) {
 		}
 	}
 	s.toolsMu.Unlock()
+	s.duplicates.forget("tool", names...)
+	s.unwatchDynamicEnums("", names...)
 
 	// When the list of available tools changes, servers that declared the listChanged capability SHOULD send a notification.
 	if exists && s.capabilities.tools != nil && s.capabilities.tools.listChanged {
@@ -704,7 +829,7 @@ func (s *MCPServer) handleInitialize(
 			Subscribe   bool `json:"subscribe,omitempty"`
 			ListChanged bool `json:"listChanged,omitempty"`
 		}{
-			Subscribe:   s.capabilities.resources.subscribe,
+			Subscribe:   s.capabilities.resources.subscribe || s.resourceSubscriptions != nil,
 			ListChanged: s.capabilities.resources.listChanged,
 		}
 	}
@@ -768,6 +893,10 @@ func (s *MCPServer) handleInitialize(
 		capabilities.Tasks = tasksCapability
 	}
 
+	if len(s.capabilities.experimental) > 0 {
+		capabilities.Experimental = maps.Clone(s.capabilities.experimental)
+	}
+
 	result := mcp.InitializeResult{
 		ProtocolVersion: s.protocolVersion(request.Params.ProtocolVersion),
 		ServerInfo: mcp.Implementation{
@@ -786,6 +915,9 @@ func (s *MCPServer) handleInitialize(
 			sessionWithClientInfo.SetClientInfo(request.Params.ClientInfo)
 			sessionWithClientInfo.SetClientCapabilities(request.Params.Capabilities)
 		}
+		if sessionWithVersion, ok := session.(SessionWithProtocolVersion); ok {
+			sessionWithVersion.SetProtocolVersion(result.ProtocolVersion)
+		}
 	}
 
 	return &result, nil
@@ -1064,7 +1196,7 @@ func (s *MCPServer) handleReadResource(
 				err:  err,
 			}
 		}
-		return &mcp.ReadResourceResult{Contents: contents}, nil
+		return &mcp.ReadResourceResult{Contents: s.encodeResourceContents(ctx, contents)}, nil
 	}
 
 	// If no direct handler found, try matching against templates
@@ -1135,7 +1267,7 @@ func (s *MCPServer) handleReadResource(
 				err:  err,
 			}
 		}
-		return &mcp.ReadResourceResult{Contents: contents}, nil
+		return &mcp.ReadResourceResult{Contents: s.encodeResourceContents(ctx, contents)}, nil
 	}
 
 	return nil, &requestError{
@@ -1209,7 +1341,16 @@ func (s *MCPServer) handleGetPrompt(
 		}
 	}
 
-	result, err := handler(ctx, request)
+	finalHandler := handler
+	s.promptMiddlewareMu.RLock()
+	mw := s.promptHandlerMiddlewares
+	// Apply middlewares in reverse order
+	for i := len(mw) - 1; i >= 0; i-- {
+		finalHandler = mw[i](finalHandler)
+	}
+	s.promptMiddlewareMu.RUnlock()
+
+	result, err := finalHandler(ctx, request)
 	if err != nil {
 		return nil, &requestError{
 			id:   id,
@@ -1226,6 +1367,63 @@ func (s *MCPServer) handleListTools(
 	id any,
 	request mcp.ListToolsRequest,
 ) (*mcp.ListToolsResult, *requestError) {
+	tools := s.visibleTools(ctx)
+
+	paginate := listByPagination[mcp.Tool]
+	if s.toolSearch {
+		if query, limit, ok := toolsListQuery(request); ok {
+			var err error
+			if tools, err = s.searchTools(ctx, tools, query, limit); err != nil {
+				return nil, &requestError{
+					id:   id,
+					code: mcp.INTERNAL_ERROR,
+					err:  err,
+				}
+			}
+			// Search results are ranked rather than sorted by name.
+			paginate = listByOffset[mcp.Tool]
+		}
+	}
+
+	// Apply pagination
+	toolsToReturn, nextCursor, err := paginate(
+		ctx,
+		s,
+		request.Params.Cursor,
+		tools,
+	)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  err,
+		}
+	}
+
+	s.addSchemaHashes(toolsToReturn)
+
+	if toolsToReturn, err = s.resolveDynamicEnums(ctx, toolsToReturn); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  err,
+		}
+	}
+
+	result := mcp.ListToolsResult{
+		Tools: toolsToReturn,
+		PaginatedResult: mcp.PaginatedResult{
+			NextCursor: nextCursor,
+		},
+	}
+	return &result, nil
+}
+
+// visibleTools returns the tools the session can see, sorted by name: the
+// server's tools merged with the session's own, without those requiring
+// client capabilities the client lacks or disabled for now, and passed
+// through the tool filters.
+func (s *MCPServer) visibleTools(ctx context.Context) []mcp.Tool {
 	// Get the base tools from the server
 	s.toolsMu.RLock()
 	tools := make([]mcp.Tool, 0, len(s.tools))
@@ -1278,6 +1476,9 @@ func (s *MCPServer) handleListTools(
 		}
 	}
 
+	tools = filterToolsByClientCapabilities(session, tools)
+	tools = s.withoutUnavailableTools(tools)
+
 	// Apply tool filters if any are defined
 	s.toolFiltersMu.RLock()
 	if len(s.toolFilters) > 0 {
@@ -1287,69 +1488,165 @@ func (s *MCPServer) handleListTools(
 	}
 	s.toolFiltersMu.RUnlock()
 
-	// Apply pagination
-	toolsToReturn, nextCursor, err := listByPagination(
-		ctx,
-		s,
-		request.Params.Cursor,
-		tools,
-	)
+	return tools
+}
+
+func (s *MCPServer) handleToolCall(
+	ctx context.Context,
+	id any,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, *requestError) {
+	if reqErr := s.requireTaskCall(ctx, id, request.Params.Name); reqErr != nil {
+		return nil, reqErr
+	}
+	finalHandler, reqErr := s.toolCallHandler(ctx, id, request.Params.Name)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	ctx = s.withRoutingKey(ctx, request)
+	result, err := finalHandler(ctx, request)
 	if err != nil {
+		translated, ok := s.translateToolError(ctx, id, request, err)
+		if !ok {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  err,
+			}
+		}
+		result = translated
+	}
+	if err := s.checkToolResult(request.Params.Name, result); err != nil {
 		return nil, &requestError{
 			id:   id,
-			code: mcp.INVALID_PARAMS,
+			code: mcp.INTERNAL_ERROR,
 			err:  err,
 		}
 	}
 
-	result := mcp.ListToolsResult{
-		Tools: toolsToReturn,
-		PaginatedResult: mcp.PaginatedResult{
-			NextCursor: nextCursor,
-		},
+	if s.toolSchemaHashes && result != nil {
+		if tool, ok := s.lookupTool(ctx, request.Params.Name); ok {
+			if hash, err := mcp.ToolSchemaHash(tool.Tool); err == nil {
+				result.Meta = metaWithSchemaHash(result.Meta, hash)
+			}
+		}
 	}
-	return &result, nil
+
+	result, err = s.structuredToolResultCompat(ctx, request.Header, result)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  err,
+		}
+	}
+
+	return s.encodeToolResult(ctx, result), nil
 }
 
-func (s *MCPServer) handleToolCall(
+// handleTaskAugmentedToolCall handles tools/call requests that ask to be run
+// as a task. The tool runs in the background and a CreateTaskResult is
+// returned immediately; the tool's result is later available via tasks/result.
+func (s *MCPServer) handleTaskAugmentedToolCall(
 	ctx context.Context,
 	id any,
 	request mcp.CallToolRequest,
-) (*mcp.CallToolResult, *requestError) {
-	// First check session-specific tools
-	var tool ServerTool
-	var ok bool
+) (*mcp.CreateTaskResult, *requestError) {
+	if s.taskQueue != nil {
+		if _, reqErr := s.callableTool(ctx, id, request.Params.Name); reqErr != nil {
+			return nil, reqErr
+		}
+		return s.enqueueToolCall(ctx, id, request)
+	}
+	finalHandler, reqErr := s.toolCallHandler(ctx, id, request.Params.Name)
+	if reqErr != nil {
+		return nil, reqErr
+	}
 
-	session := ClientSessionFromContext(ctx)
-	if session != nil {
-		if sessionWithTools, typeAssertOk := session.(SessionWithTools); typeAssertOk {
-			if sessionTools := sessionWithTools.GetSessionTools(); sessionTools != nil {
-				var sessionOk bool
-				tool, sessionOk = sessionTools[request.Params.Name]
-				if sessionOk {
-					ok = true
-				}
-			}
+	ctx = s.withRoutingKey(ctx, request)
+	ctx = context.WithValue(ctx, taskToolCallKey{}, request)
+	entry := s.createTask(ctx, s.newID(IDKindTask), request.Params.Task.TTL, nil)
+
+	// The task outlives the request, so it must not be cancelled with it.
+	taskCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	taskCtx = context.WithValue(taskCtx, taskIDKey{}, entry.task.TaskId)
+
+	s.tasksMu.Lock()
+	entry.cancelFunc = cancel
+	entry.callbackURL = s.taskWebhook.callbackURL(request)
+	task := entry.task
+	s.tasksMu.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := finalHandler(taskCtx, request)
+		if translated, ok := s.translateToolError(taskCtx, id, request, err); ok {
+			result, err = translated, nil
+		}
+		if err == nil {
+			err = s.checkToolResult(request.Params.Name, result)
 		}
+		// Completing fails only if the task was cancelled meanwhile.
+		_ = s.completeTask(entry, result, err)
+	}()
+
+	result := mcp.NewCreateTaskResult(task)
+	return &result, nil
+}
+
+// toolCallHandler looks up a tool, first among the session-specific tools
+// and then the global ones, and wraps its handler in the tool middlewares.
+func (s *MCPServer) toolCallHandler(ctx context.Context, id any, name string) (ToolHandlerFunc, *requestError) {
+	tool, reqErr := s.callableTool(ctx, id, name)
+	if reqErr != nil {
+		return nil, reqErr
 	}
+	return s.wrapToolHandler(tool.Tool, tool.Handler), nil
+}
 
-	// If not found in session tools, check global tools
-	if !ok {
-		s.toolsMu.RLock()
-		tool, ok = s.tools[request.Params.Name]
-		s.toolsMu.RUnlock()
+// wrapToolHandler wraps a handler of tool as calls to it are: with the
+// embedding of touched resources, the tool's transformers and the tool
+// middlewares.
+func (s *MCPServer) wrapToolHandler(tool mcp.Tool, handler ToolHandlerFunc) ToolHandlerFunc {
+	finalHandler := embedTouchedResources(handler)
+	if len(tool.ArgumentTransformers) > 0 || len(tool.ResultTransformers) > 0 {
+		finalHandler = transformingToolHandler(tool, finalHandler)
 	}
+	return s.withToolMiddlewares(finalHandler)
+}
 
+// callableTool looks up the tool a request is for, and checks that the
+// client may call it.
+func (s *MCPServer) callableTool(ctx context.Context, id any, name string) (ServerTool, *requestError) {
+	tool, ok := s.lookupTool(ctx, name)
 	if !ok {
-		return nil, &requestError{
+		return ServerTool{}, &requestError{
 			id:   id,
 			code: mcp.INVALID_PARAMS,
-			err:  fmt.Errorf("tool '%s' not found: %w", request.Params.Name, ErrToolNotFound),
+			err:  fmt.Errorf("tool '%s' not found: %w", name, ErrToolNotFound),
 		}
 	}
+	if missing := missingClientCapabilities(ClientSessionFromContext(ctx), tool.Tool); len(missing) > 0 {
+		return ServerTool{}, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  fmt.Errorf("tool '%s' requires client capabilities %v: %w", name, missing, ErrClientCapabilityRequired),
+		}
+	}
+	if unavailable, ok := s.toolUnavailable(name); ok {
+		return ServerTool{}, &requestError{
+			id:   id,
+			code: mcp.TOOL_UNAVAILABLE,
+			err:  unavailable,
+			data: unavailable.ErrorData(),
+		}
+	}
+	return tool, nil
+}
 
-	finalHandler := tool.Handler
-
+// withToolMiddlewares wraps a tool handler with the server's middlewares.
+func (s *MCPServer) withToolMiddlewares(finalHandler ToolHandlerFunc) ToolHandlerFunc {
 	s.toolMiddlewareMu.RLock()
 	mw := s.toolHandlerMiddlewares
 
@@ -1359,16 +1656,58 @@ func (s *MCPServer) handleToolCall(
 	}
 	s.toolMiddlewareMu.RUnlock()
 
-	result, err := finalHandler(ctx, request)
-	if err != nil {
-		return nil, &requestError{
-			id:   id,
-			code: mcp.INTERNAL_ERROR,
-			err:  err,
+	if catalog := s.messageCatalog; catalog != nil {
+		next := finalHandler
+		finalHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next(mcp.WithMessageCatalog(ctx, catalog), request)
 		}
 	}
+	return finalHandler
+}
 
-	return result, nil
+// transformingToolHandler runs the tool's argument transformers before the
+// handler and its result transformers after it.
+func transformingToolHandler(tool mcp.Tool, handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		request, err := tool.TransformArguments(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		result, err := handler(ctx, request)
+		if err != nil {
+			return result, err
+		}
+		return tool.TransformResult(ctx, request, result)
+	}
+}
+
+// lookupTool finds a tool by name, preferring the session's own tools.
+func (s *MCPServer) lookupTool(ctx context.Context, name string) (ServerTool, bool) {
+	// First check session-specific tools
+	var tool ServerTool
+	var ok bool
+
+	session := ClientSessionFromContext(ctx)
+	if session != nil {
+		if sessionWithTools, typeAssertOk := session.(SessionWithTools); typeAssertOk {
+			if sessionTools := sessionWithTools.GetSessionTools(); sessionTools != nil {
+				var sessionOk bool
+				tool, sessionOk = sessionTools[name]
+				if sessionOk {
+					ok = true
+				}
+			}
+		}
+	}
+
+	// If not found in session tools, check global tools
+	if !ok {
+		s.toolsMu.RLock()
+		tool, ok = s.tools[name]
+		s.toolsMu.RUnlock()
+	}
+
+	return tool, ok
 }
 
 func (s *MCPServer) handleNotification(
@@ -1481,6 +1820,7 @@ func (s *MCPServer) handleTaskResult(
 	// Read result error under lock
 	s.tasksMu.RLock()
 	resultErr := entry.resultErr
+	status := entry.task.Status
 	s.tasksMu.RUnlock()
 
 	// Return error if task failed
@@ -1492,10 +1832,29 @@ func (s *MCPServer) handleTaskResult(
 		}
 	}
 
-	// The result structure varies by original request type
-	// For now, return the raw result wrapped in TaskResultResult
 	result := &mcp.TaskResultResult{
-		Result: mcp.Result{},
+		Result: mcp.Result{Meta: mcp.NewRelatedTaskMeta(request.Params.TaskId)},
+	}
+
+	// Cancelled tasks have no result to return
+	if status != mcp.TaskStatusCompleted {
+		return result, nil
+	}
+
+	payload, err := s.taskPayloads.Get(request.Params.TaskId)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  fmt.Errorf("result of task %s is unavailable: %w", request.Params.TaskId, err),
+		}
+	}
+	if result.Payload, err = s.structuredTaskPayloadCompat(ctx, request.Header, payload); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  err,
+		}
 	}
 
 	return result, nil
@@ -1548,7 +1907,9 @@ func (s *MCPServer) createTask(ctx context.Context, taskID string, ttl *int64, p
 	entry := &taskEntry{
 		task:      task,
 		sessionID: getSessionID(ctx),
+		principal: s.taskPrincipal(ctx),
 		done:      make(chan struct{}),
+		ctx:       context.WithValue(context.WithoutCancel(ctx), taskIDKey{}, taskID),
 	}
 
 	s.tasksMu.Lock()
@@ -1570,21 +1931,18 @@ func (s *MCPServer) getTask(ctx context.Context, taskID string) (mcp.Task, chan
 	entry, exists := s.tasks[taskID]
 	if !exists {
 		s.tasksMu.RUnlock()
-		return mcp.Task{}, nil, fmt.Errorf("task not found")
+		return mcp.Task{}, nil, ErrTaskNotFound
 	}
 
-	// Verify session isolation
-	sessionID := getSessionID(ctx)
-	if entry.sessionID != "" && sessionID != "" && entry.sessionID != sessionID {
-		s.tasksMu.RUnlock()
-		return mcp.Task{}, nil, fmt.Errorf("task not found")
-	}
-
-	// Return a copy of the task and the done channel
+	// Take a copy of the task and the done channel
 	taskCopy := entry.task
 	done := entry.done
 	s.tasksMu.RUnlock()
 
+	if !s.taskVisible(ctx, taskCopy, entry.owner()) {
+		return mcp.Task{}, nil, ErrTaskNotFound
+	}
+
 	return taskCopy, done, nil
 }
 
@@ -1592,100 +1950,171 @@ func (s *MCPServer) getTask(ctx context.Context, taskID string) (mcp.Task, chan
 func (s *MCPServer) getTaskEntry(ctx context.Context, taskID string) (*taskEntry, error) {
 	s.tasksMu.RLock()
 	entry, exists := s.tasks[taskID]
-	s.tasksMu.RUnlock()
-
-	if !exists {
-		return nil, fmt.Errorf("task not found")
+	var taskCopy mcp.Task
+	if exists {
+		taskCopy = entry.task
 	}
+	s.tasksMu.RUnlock()
 
-	// Verify session isolation
-	sessionID := getSessionID(ctx)
-	if entry.sessionID != "" && sessionID != "" && entry.sessionID != sessionID {
-		return nil, fmt.Errorf("task not found")
+	if !exists || !s.taskVisible(ctx, taskCopy, entry.owner()) {
+		return nil, ErrTaskNotFound
 	}
 
 	return entry, nil
 }
 
-// listTasks returns copies of all tasks for the current session.
+// listTasks returns copies of all tasks visible to the caller.
 func (s *MCPServer) listTasks(ctx context.Context) []mcp.Task {
-	sessionID := getSessionID(ctx)
-
 	s.tasksMu.RLock()
-	defer s.tasksMu.RUnlock()
+	all := make([]mcp.Task, 0, len(s.tasks))
+	owners := make([]TaskOwner, 0, len(s.tasks))
+	for _, entry := range s.tasks {
+		all = append(all, entry.task)
+		owners = append(owners, entry.owner())
+	}
+	s.tasksMu.RUnlock()
 
 	var tasks []mcp.Task
-	for _, entry := range s.tasks {
-		// Filter by session if applicable
-		if sessionID == "" || entry.sessionID == "" || entry.sessionID == sessionID {
-			tasks = append(tasks, entry.task)
+	for i, task := range all {
+		if s.taskVisible(ctx, task, owners[i]) {
+			tasks = append(tasks, task)
 		}
 	}
 
 	return tasks
 }
 
-// completeTask marks a task as completed with the given result.
-func (s *MCPServer) completeTask(entry *taskEntry, result any, err error) {
-	s.tasksMu.Lock()
-	defer s.tasksMu.Unlock()
-
-	// Guard against double completion
-	if entry.completed {
-		return
+// completeTask marks a task as completed with the given result, or as
+// failed if err is not nil. The result is encoded and kept in the task
+// payload store; if that fails, the task is marked as failed instead.
+// It returns ErrInvalidTaskTransition if the task already reached a
+// terminal status, e.g. because it was cancelled.
+func (s *MCPServer) completeTask(entry *taskEntry, result any, err error) error {
+	// The task ID never changes, so it can be read without the lock.
+	taskID := entry.task.TaskId
+	if err == nil {
+		err = s.storeTaskPayload(taskID, result)
 	}
 
 	if err != nil {
-		entry.task.Status = mcp.TaskStatusFailed
-		entry.task.StatusMessage = err.Error()
-		entry.resultErr = err
-	} else {
-		entry.task.Status = mcp.TaskStatusCompleted
-		entry.result = result
+		return s.setTaskStatus(entry, mcp.TaskStatusFailed, err.Error(), func() {
+			entry.resultErr = err
+		})
 	}
 
-	// Mark as completed and signal
-	entry.completed = true
-	close(entry.done)
+	if transitionErr := s.setTaskStatus(entry, mcp.TaskStatusCompleted, "", nil); transitionErr != nil {
+		_ = s.taskPayloads.Delete(taskID)
+		return transitionErr
+	}
+	return nil
 }
 
-// cancelTask cancels a running task.
-func (s *MCPServer) cancelTask(ctx context.Context, taskID string) error {
-	entry, err := s.getTaskEntry(ctx, taskID)
+// setTaskStatus transitions a task, applies update (if any) while still
+// holding the lock, and then runs the task status hooks.
+func (s *MCPServer) setTaskStatus(entry *taskEntry, to mcp.TaskStatus, statusMessage string, update func()) error {
+	s.tasksMu.Lock()
+	err := s.transitionTask(entry, to, statusMessage)
+	if err == nil && update != nil {
+		update()
+	}
+	task := entry.task
+	if err == nil {
+		// Queued under the lock so that TaskWebhook.Wait covers every task
+		// already seen in a terminal status.
+		s.notifyTaskWebhook(entry, task)
+	}
+	s.tasksMu.Unlock()
+
 	if err != nil {
 		return err
 	}
+	s.hooks.taskStatusChanged(entry.ctx, task)
+	return nil
+}
 
-	s.tasksMu.Lock()
-	defer s.tasksMu.Unlock()
-
-	// Don't allow cancelling already completed tasks
-	if entry.completed {
-		return fmt.Errorf("cannot cancel task in terminal status: %s", entry.task.Status)
+// transitionTask moves a task to a new status, rejecting changes that the
+// task state machine does not allow (see mcp.CanTransition). Reaching a
+// terminal status signals anyone waiting on the task.
+// Must be called with s.tasksMu held.
+func (s *MCPServer) transitionTask(entry *taskEntry, to mcp.TaskStatus, statusMessage string) error {
+	from := entry.task.Status
+	if !mcp.CanTransition(from, to) {
+		return fmt.Errorf("%w: task %s cannot move from %s to %s", ErrInvalidTaskTransition, entry.task.TaskId, from, to)
 	}
 
-	// Cancel the context if available
-	if entry.cancelFunc != nil {
-		entry.cancelFunc()
+	entry.task.Status = to
+	entry.task.StatusMessage = statusMessage
+
+	if to.IsTerminal() && !entry.completed {
+		entry.completed = true
+		close(entry.done)
 	}
+	return nil
+}
 
-	entry.task.Status = mcp.TaskStatusCancelled
-	entry.task.StatusMessage = "Task cancelled by request"
+// storeTaskPayload encodes a task result and puts it in the payload store.
+func (s *MCPServer) storeTaskPayload(taskID string, result any) error {
+	payload := []byte("{}")
+	if result != nil {
+		var err error
+		if payload, err = json.Marshal(result); err != nil {
+			return fmt.Errorf("failed to encode task result: %w", err)
+		}
+	}
+	if err := s.taskPayloads.Put(taskID, payload); err != nil {
+		return fmt.Errorf("failed to store task result: %w", err)
+	}
+	return nil
+}
 
-	// Mark as completed and signal
-	entry.completed = true
-	close(entry.done)
+// cancelTask cancels a running task.
+func (s *MCPServer) cancelTask(ctx context.Context, taskID string) error {
+	entry, err := s.getTaskEntry(ctx, taskID)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	// Tasks in a terminal status cannot be cancelled
+	return s.setTaskStatus(entry, mcp.TaskStatusCancelled, "Task cancelled by request", func() {
+		// Cancel the context if available
+		if entry.cancelFunc != nil {
+			entry.cancelFunc()
+		}
+	})
 }
 
 // scheduleTaskCleanup schedules a task for cleanup after its TTL expires.
 func (s *MCPServer) scheduleTaskCleanup(taskID string, ttlMs int64) {
-	time.Sleep(time.Duration(ttlMs) * time.Millisecond)
+	timer := s.clock.NewTimer(time.Duration(ttlMs) * time.Millisecond)
+	<-timer.C()
 
 	s.tasksMu.Lock()
 	delete(s.tasks, taskID)
 	s.tasksMu.Unlock()
+
+	_ = s.taskPayloads.Delete(taskID)
+	s.samplingBudget.dropTask(taskID)
+}
+
+// taskIDKey is the context key for the ID of the task a handler runs in.
+type taskIDKey struct{}
+
+// TaskIDFromContext returns the ID of the task the current tool call is
+// running as, or an empty string if it was not invoked as a task.
+func TaskIDFromContext(ctx context.Context) string {
+	taskID, _ := ctx.Value(taskIDKey{}).(string)
+	return taskID
+}
+
+// taskToolCallKey is the context key for the tools/call request a task runs.
+type taskToolCallKey struct{}
+
+// TaskToolCallFromContext returns the tools/call request that started the
+// current task. It is available to tool handlers running as a task and to
+// task status hooks.
+func TaskToolCallFromContext(ctx context.Context) (mcp.CallToolRequest, bool) {
+	request, ok := ctx.Value(taskToolCallKey{}).(mcp.CallToolRequest)
+	return request, ok
 }
 
 // getSessionID extracts the session ID from the context.
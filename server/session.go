@@ -75,6 +75,17 @@ type SessionWithClientInfo interface {
 	SetClientCapabilities(clientCapabilities mcp.ClientCapabilities)
 }
 
+// SessionWithProtocolVersion is an extension of ClientSession that can store
+// the protocol version negotiated during initialization
+type SessionWithProtocolVersion interface {
+	ClientSession
+	// GetProtocolVersion returns the negotiated protocol version, or an empty
+	// string before initialization
+	GetProtocolVersion() string
+	// SetProtocolVersion sets the negotiated protocol version
+	SetProtocolVersion(version string)
+}
+
 // SessionWithElicitation is an extension of ClientSession that can send elicitation requests
 type SessionWithElicitation interface {
 	ClientSession
@@ -174,6 +185,9 @@ func (s *MCPServer) sendNotificationToAllClients(notification mcp.JSONRPCNotific
 			if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 				sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 			}
+			if s.chaos.dropNotification(session, notification) {
+				return true
+			}
 			select {
 			case session.NotificationChannel() <- notification:
 				// Successfully sent notification
@@ -203,6 +217,9 @@ func (s *MCPServer) sendNotificationToSpecificClient(session ClientSession, noti
 	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
+	if s.chaos.dropNotification(session, notification) {
+		return nil
+	}
 	select {
 	case session.NotificationChannel() <- notification:
 		return nil
@@ -253,6 +270,11 @@ func (s *MCPServer) UnregisterSession(
 	if !ok {
 		return
 	}
+	s.dropSessionKV(sessionID)
+	s.samplingBudget.dropSession(sessionID)
+	s.flowControl.dropSession(sessionID)
+	s.dynamicEnums.dropSession(sessionID)
+	s.resourceSubscriptions.dropSession(sessionID)
 	if session, ok := sessionValue.(ClientSession); ok {
 		s.hooks.UnregisterSession(ctx, session)
 	}
@@ -285,6 +307,10 @@ func (s *MCPServer) sendNotificationCore(
 	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
+	if s.chaos.dropNotification(session, notification) ||
+		s.holdNotification(ctx, session, notification) {
+		return nil
+	}
 	select {
 	case session.NotificationChannel() <- notification:
 		return nil
@@ -392,6 +418,7 @@ func (s *MCPServer) AddSessionTools(sessionID string, tools ...ServerTool) error
 
 	// Set the tools (this should be thread-safe)
 	session.SetSessionTools(newSessionTools)
+	s.watchDynamicEnums(sessionID, tools)
 
 	// It only makes sense to send tool notifications to initialized sessions --
 	// if we're not initialized yet the client can't possibly have sent their
@@ -454,6 +481,7 @@ func (s *MCPServer) DeleteSessionTools(sessionID string, names ...string) error
 
 	// Set the tools (this should be thread-safe)
 	session.SetSessionTools(newSessionTools)
+	s.unwatchDynamicEnums(sessionID, names...)
 
 	// It only makes sense to send tool notifications to initialized sessions --
 	// if we're not initialized yet the client can't possibly have sent their
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SessionStore persists the values of SessionKV across server restarts or
+// between the instances of a fleet sharing session IDs. Values are stored
+// JSON-encoded.
+type SessionStore interface {
+	// LoadSession returns the values saved for a session, or nil if none.
+	LoadSession(ctx context.Context, sessionID string) (map[string]json.RawMessage, error)
+	// SaveSession replaces the values saved for a session.
+	SaveSession(ctx context.Context, sessionID string, values map[string]json.RawMessage) error
+}
+
+// WithSessionStore persists SessionKV values through store. Values are
+// loaded the first time a session's store is used, and saved on every change.
+func WithSessionStore(store SessionStore) ServerOption {
+	return func(s *MCPServer) {
+		s.sessionStore = store
+	}
+}
+
+// SessionKV is a key/value store scoped to one client session, for handlers
+// and middleware that keep lightweight conversation state. Its in-memory
+// values are dropped when the session is unregistered; values saved through
+// a SessionStore are kept and loaded again if the session comes back.
+//
+// A nil *SessionKV, returned when the context has no session, holds nothing
+// and rejects writes with ErrNoActiveSession.
+type SessionKV struct {
+	sessionID string
+	store     SessionStore
+
+	mu      sync.RWMutex
+	values  map[string]any
+	encoded map[string]json.RawMessage
+	loadErr error
+}
+
+// SessionKVFromContext returns the key/value store of the session handling
+// the request carried by ctx.
+func SessionKVFromContext(ctx context.Context) *SessionKV {
+	s := ServerFromContext(ctx)
+	session := ClientSessionFromContext(ctx)
+	if s == nil || session == nil {
+		return nil
+	}
+	return s.SessionKV(ctx, session.SessionID())
+}
+
+// SessionKV returns the key/value store of a session. Stores of sessions
+// that are not registered with the server are not kept in memory between
+// calls, but are still persisted through the SessionStore.
+func (s *MCPServer) SessionKV(ctx context.Context, sessionID string) *SessionKV {
+	if kv, ok := s.sessionKVs.Load(sessionID); ok {
+		return kv.(*SessionKV)
+	}
+
+	kv := &SessionKV{
+		sessionID: sessionID,
+		store:     s.sessionStore,
+		values:    make(map[string]any),
+		encoded:   make(map[string]json.RawMessage),
+	}
+	if kv.store != nil {
+		values, err := kv.store.LoadSession(ctx, sessionID)
+		if err != nil {
+			kv.loadErr = fmt.Errorf("failed to load session %s: %w", sessionID, err)
+		}
+		for key, value := range values {
+			kv.values[key] = value
+			kv.encoded[key] = value
+		}
+	}
+
+	if _, registered := s.sessions.Load(sessionID); !registered {
+		return kv
+	}
+	actual, _ := s.sessionKVs.LoadOrStore(sessionID, kv)
+	return actual.(*SessionKV)
+}
+
+// dropSessionKV forgets the in-memory values of a session that ended.
+func (s *MCPServer) dropSessionKV(sessionID string) {
+	s.sessionKVs.Delete(sessionID)
+}
+
+// Get returns the value stored under key. Values loaded from a SessionStore
+// are json.RawMessage until set again; use SessionValue to decode them.
+func (kv *SessionKV) Get(key string) (any, bool) {
+	if kv == nil {
+		return nil, false
+	}
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+	value, ok := kv.values[key]
+	return value, ok
+}
+
+// Set stores value under key. With a SessionStore, value must be JSON
+// encodable and the session is saved before Set returns.
+func (kv *SessionKV) Set(ctx context.Context, key string, value any) error {
+	if kv == nil {
+		return ErrNoActiveSession
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if kv.store != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode session value %q: %w", key, err)
+		}
+		previous, existed := kv.encoded[key]
+		kv.encoded[key] = data
+		if err := kv.save(ctx); err != nil {
+			if existed {
+				kv.encoded[key] = previous
+			} else {
+				delete(kv.encoded, key)
+			}
+			return err
+		}
+	}
+	kv.values[key] = value
+	return nil
+}
+
+// Delete removes the value stored under key.
+func (kv *SessionKV) Delete(ctx context.Context, key string) error {
+	if kv == nil {
+		return ErrNoActiveSession
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if _, ok := kv.values[key]; !ok {
+		return nil
+	}
+	if kv.store != nil {
+		previous := kv.encoded[key]
+		delete(kv.encoded, key)
+		if err := kv.save(ctx); err != nil {
+			kv.encoded[key] = previous
+			return err
+		}
+	}
+	delete(kv.values, key)
+	return nil
+}
+
+// Keys returns the stored keys in sorted order.
+func (kv *SessionKV) Keys() []string {
+	if kv == nil {
+		return nil
+	}
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+	keys := make([]string, 0, len(kv.values))
+	for key := range kv.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// save writes all encoded values to the store. The caller holds kv.mu.
+func (kv *SessionKV) save(ctx context.Context) error {
+	// Saving after a failed load would overwrite the stored session.
+	if kv.loadErr != nil {
+		return kv.loadErr
+	}
+	values := make(map[string]json.RawMessage, len(kv.encoded))
+	for key, value := range kv.encoded {
+		values[key] = value
+	}
+	if err := kv.store.SaveSession(ctx, kv.sessionID, values); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", kv.sessionID, err)
+	}
+	return nil
+}
+
+// SessionValue returns the value stored under key in the session of ctx as
+// a T, decoding values loaded from a SessionStore.
+func SessionValue[T any](ctx context.Context, key string) (T, bool, error) {
+	var zero T
+	value, ok := SessionKVFromContext(ctx).Get(key)
+	if !ok {
+		return zero, false, nil
+	}
+	if typed, ok := value.(T); ok {
+		return typed, true, nil
+	}
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		return zero, true, fmt.Errorf("session value %q is a %T, not a %T", key, value, zero)
+	}
+	var decoded T
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return zero, true, fmt.Errorf("failed to decode session value %q: %w", key, err)
+	}
+	return decoded, true, nil
+}
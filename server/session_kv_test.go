@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]json.RawMessage
+	loadErr  error
+	saveErr  error
+}
+
+func (m *memorySessionStore) LoadSession(ctx context.Context, sessionID string) (map[string]json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	return m.sessions[sessionID], nil
+}
+
+func (m *memorySessionStore) SaveSession(ctx context.Context, sessionID string, values map[string]json.RawMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.sessions == nil {
+		m.sessions = make(map[string]map[string]json.RawMessage)
+	}
+	m.sessions[sessionID] = values
+	return nil
+}
+
+func registerKVSession(t *testing.T, s *MCPServer, sessionID string) context.Context {
+	t.Helper()
+	session := &sessionTestClient{
+		sessionID:           sessionID,
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := context.WithValue(context.Background(), serverKey{}, s)
+	return s.WithContext(ctx, session)
+}
+
+func TestSessionKV(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	ctx := registerKVSession(t, s, "kv-1")
+	other := registerKVSession(t, s, "kv-2")
+
+	kv := SessionKVFromContext(ctx)
+	require.NotNil(t, kv)
+	require.NoError(t, kv.Set(ctx, "cart", []string{"espresso"}))
+	require.NoError(t, kv.Set(ctx, "step", 2))
+
+	// The same store is returned for every request of the session.
+	value, ok := SessionKVFromContext(ctx).Get("cart")
+	require.True(t, ok)
+	assert.Equal(t, []string{"espresso"}, value)
+	assert.Equal(t, []string{"cart", "step"}, kv.Keys())
+
+	_, ok = SessionKVFromContext(other).Get("cart")
+	assert.False(t, ok, "sessions do not share values")
+
+	step, ok, err := SessionValue[int](ctx, "step")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 2, step)
+
+	_, _, err = SessionValue[string](ctx, "step")
+	assert.ErrorContains(t, err, "not a string")
+
+	require.NoError(t, kv.Delete(ctx, "step"))
+	assert.Equal(t, []string{"cart"}, kv.Keys())
+
+	s.UnregisterSession(context.Background(), "kv-1")
+	ctx = registerKVSession(t, s, "kv-1")
+	assert.Empty(t, SessionKVFromContext(ctx).Keys(), "values are dropped at session end")
+}
+
+func TestSessionKV_NoSession(t *testing.T) {
+	kv := SessionKVFromContext(context.Background())
+	assert.Nil(t, kv)
+
+	_, ok := kv.Get("missing")
+	assert.False(t, ok)
+	assert.Nil(t, kv.Keys())
+	assert.ErrorIs(t, kv.Set(context.Background(), "key", 1), ErrNoActiveSession)
+	assert.ErrorIs(t, kv.Delete(context.Background(), "key"), ErrNoActiveSession)
+}
+
+func TestSessionKV_Handler(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("count"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		count, _, err := SessionValue[int](ctx, "count")
+		if err != nil {
+			return nil, err
+		}
+		count++
+		if err := SessionKVFromContext(ctx).Set(ctx, "count", count); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(strconv.Itoa(count)), nil
+	})
+
+	ctx := registerKVSession(t, s, "counter")
+	for want := 1; want <= 3; want++ {
+		response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"count"}}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		result := resp.Result.(mcp.CallToolResult)
+		assert.Equal(t, strconv.Itoa(want), result.Content[0].(mcp.TextContent).Text)
+	}
+}
+
+func TestSessionKV_Persistence(t *testing.T) {
+	store := &memorySessionStore{}
+	s := NewMCPServer("test", "1.0.0", WithSessionStore(store))
+	ctx := registerKVSession(t, s, "persisted")
+
+	type cart struct {
+		Items []string `json:"items"`
+	}
+	kv := SessionKVFromContext(ctx)
+	require.NoError(t, kv.Set(ctx, "cart", cart{Items: []string{"espresso"}}))
+	require.NoError(t, kv.Set(ctx, "step", 1))
+	require.NoError(t, kv.Delete(ctx, "step"))
+	assert.Equal(t, map[string]json.RawMessage{"cart": json.RawMessage(`{"items":["espresso"]}`)}, store.sessions["persisted"])
+
+	assert.ErrorContains(t, kv.Set(ctx, "bad", func() {}), "failed to encode")
+
+	// A restarted server picks the session up again.
+	restarted := NewMCPServer("test", "1.0.0", WithSessionStore(store))
+	ctx = registerKVSession(t, restarted, "persisted")
+	loaded, ok, err := SessionValue[cart](ctx, "cart")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, cart{Items: []string{"espresso"}}, loaded)
+
+	store.saveErr = errors.New("disk full")
+	kv = SessionKVFromContext(ctx)
+	require.ErrorContains(t, kv.Set(ctx, "step", 2), "disk full")
+	_, ok = kv.Get("step")
+	assert.False(t, ok, "failed writes are not applied")
+}
+
+func TestSessionKV_LoadFailure(t *testing.T) {
+	store := &memorySessionStore{loadErr: errors.New("unavailable")}
+	s := NewMCPServer("test", "1.0.0", WithSessionStore(store))
+	ctx := registerKVSession(t, s, "broken")
+
+	err := SessionKVFromContext(ctx).Set(ctx, "key", "value")
+	assert.ErrorContains(t, err, "unavailable")
+	assert.Empty(t, store.sessions, "nothing is overwritten after a failed load")
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const defaultShadowTimeout = 30 * time.Second
+
+// ShadowComparison is the outcome of mirroring one tool call to a shadow
+// handler.
+type ShadowComparison struct {
+	Request     mcp.CallToolRequest
+	Primary     *mcp.CallToolResult
+	PrimaryErr  error
+	PrimaryTime time.Duration
+	Shadow      *mcp.CallToolResult
+	ShadowErr   error
+	ShadowTime  time.Duration
+	Match       bool
+	// Diff describes the first difference found, e.g.
+	// `content[0].text: "4" != "5"`. It is empty when Match is true.
+	Diff string
+}
+
+// ShadowOption configures tool call shadowing.
+type ShadowOption func(*shadowConfig)
+
+type shadowConfig struct {
+	shadow  ToolHandlerFunc
+	percent float64
+	tools   []string
+	timeout time.Duration
+	record  func(ShadowComparison)
+	random  func() float64
+}
+
+// WithShadowPercent sets the percentage (0-100) of tool calls mirrored to
+// the shadow handler. The default is 100.
+func WithShadowPercent(percent float64) ShadowOption {
+	return func(c *shadowConfig) {
+		c.percent = percent
+	}
+}
+
+// WithShadowTools limits shadowing to the named tools.
+func WithShadowTools(names ...string) ShadowOption {
+	return func(c *shadowConfig) {
+		c.tools = names
+	}
+}
+
+// WithShadowTimeout bounds how long a shadow call may run. The default is
+// 30 seconds.
+func WithShadowTimeout(timeout time.Duration) ShadowOption {
+	return func(c *shadowConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithShadowRecorder sets the function that receives every comparison,
+// e.g. to log mismatches or count them in metrics. It is called from the
+// shadow goroutine.
+func WithShadowRecorder(record func(ShadowComparison)) ShadowOption {
+	return func(c *shadowConfig) {
+		c.record = record
+	}
+}
+
+// NewShadowMiddleware returns a tool middleware that mirrors a share of tool
+// calls to shadow, for example a rewritten implementation or a handler that
+// forwards to an upstream server, and records how its results differ.
+//
+// The shadow runs in the background after the primary handler returns and
+// never affects the response sent to the client; its panics are recovered.
+func NewShadowMiddleware(shadow ToolHandlerFunc, opts ...ShadowOption) ToolHandlerMiddleware {
+	c := &shadowConfig{
+		shadow:  shadow,
+		percent: 100,
+		timeout: defaultShadowTimeout,
+		random:  rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c.middleware
+}
+
+// WithToolShadowing mirrors tool calls to shadow; see NewShadowMiddleware.
+func WithToolShadowing(shadow ToolHandlerFunc, opts ...ShadowOption) ServerOption {
+	return WithToolHandlerMiddleware(NewShadowMiddleware(shadow, opts...))
+}
+
+func (c *shadowConfig) middleware(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		clock := ServerFromContext(ctx).Clock()
+		start := clock.Now()
+		result, err := next(ctx, request)
+		if !c.selected(request.Params.Name) {
+			return result, err
+		}
+
+		comparison := ShadowComparison{
+			Request:     request,
+			Primary:     result,
+			PrimaryErr:  err,
+			PrimaryTime: clock.Now().Sub(start),
+		}
+		go c.run(context.WithoutCancel(ctx), comparison)
+		return result, err
+	}
+}
+
+func (c *shadowConfig) selected(tool string) bool {
+	if len(c.tools) > 0 && !slices.Contains(c.tools, tool) {
+		return false
+	}
+	return c.percent >= 100 || c.random()*100 < c.percent
+}
+
+func (c *shadowConfig) run(ctx context.Context, comparison ShadowComparison) {
+	clock := ServerFromContext(ctx).Clock()
+	ctx, cancel := contextWithTimeout(ctx, clock, c.timeout)
+	defer cancel()
+
+	start := clock.Now()
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				comparison.ShadowErr = fmt.Errorf("panic recovered in shadow handler: %v", r)
+			}
+		}()
+		comparison.Shadow, comparison.ShadowErr = c.shadow(ctx, comparison.Request)
+	}()
+	comparison.ShadowTime = clock.Now().Sub(start)
+
+	comparison.Diff = diffToolResults(comparison.Primary, comparison.PrimaryErr, comparison.Shadow, comparison.ShadowErr)
+	comparison.Match = comparison.Diff == ""
+	if c.record != nil {
+		c.record(comparison)
+	}
+}
+
+// diffToolResults compares two tool call outcomes by their JSON encoding,
+// ignoring _meta, and describes the first difference.
+func diffToolResults(primary *mcp.CallToolResult, primaryErr error, shadow *mcp.CallToolResult, shadowErr error) string {
+	switch {
+	case primaryErr != nil && shadowErr != nil:
+		return ""
+	case primaryErr != nil:
+		return fmt.Sprintf("error: %q != <nil>", primaryErr.Error())
+	case shadowErr != nil:
+		return fmt.Sprintf("error: <nil> != %q", shadowErr.Error())
+	}
+
+	a, err := normalizeToolResult(primary)
+	if err != nil {
+		return fmt.Sprintf("primary result cannot be compared: %v", err)
+	}
+	b, err := normalizeToolResult(shadow)
+	if err != nil {
+		return fmt.Sprintf("shadow result cannot be compared: %v", err)
+	}
+	return diffJSONValues("", a, b)
+}
+
+func normalizeToolResult(result *mcp.CallToolResult) (any, error) {
+	if result == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var value map[string]any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	delete(value, "_meta")
+	return value, nil
+}
+
+func diffJSONValues(path string, a, b any) string {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			if diff := diffJSONValues(child, av[k], bv[k]); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < min(len(av), len(bv)); i++ {
+			if diff := diffJSONValues(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i]); diff != "" {
+				return diff
+			}
+		}
+		if len(av) != len(bv) {
+			return fmt.Sprintf("%s: length %d != %d", path, len(av), len(bv))
+		}
+		return ""
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return ""
+	}
+	if path == "" {
+		path = "result"
+	}
+	return fmt.Sprintf("%s: %s != %s", path, jsonString(a), jsonString(b))
+}
+
+func jsonString(v any) string {
+	if v == nil {
+		return "<missing>"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
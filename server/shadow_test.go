@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowMiddleware(t *testing.T) {
+	primary := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("4"), nil
+	}
+
+	tests := []struct {
+		name   string
+		shadow ToolHandlerFunc
+		match  bool
+		diff   string
+	}{
+		{
+			name: "same result",
+			shadow: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				result := mcp.NewToolResultText("4")
+				result.Meta = mcp.NewMetaFromMap(map[string]any{"version": "v2"})
+				return result, nil
+			},
+			match: true,
+		},
+		{
+			name: "different text",
+			shadow: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("5"), nil
+			},
+			diff: `content[0].text: "4" != "5"`,
+		},
+		{
+			name: "extra content",
+			shadow: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent("4"), mcp.NewTextContent("!")}}, nil
+			},
+			diff: "content: length 1 != 2",
+		},
+		{
+			name: "shadow error",
+			shadow: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, errors.New("not implemented")
+			},
+			diff: `error: <nil> != "not implemented"`,
+		},
+		{
+			name: "shadow panic",
+			shadow: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				panic("boom")
+			},
+			diff: `error: <nil> != "panic recovered in shadow handler: boom"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			comparisons := make(chan ShadowComparison, 1)
+			handler := NewShadowMiddleware(tc.shadow, WithShadowRecorder(func(c ShadowComparison) {
+				comparisons <- c
+			}))(primary)
+
+			result, err := handler(context.Background(), mcp.CallToolRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, "4", result.Content[0].(mcp.TextContent).Text, "the primary result is returned")
+
+			select {
+			case c := <-comparisons:
+				assert.Equal(t, tc.match, c.Match)
+				assert.Equal(t, tc.diff, c.Diff)
+			case <-time.After(time.Second):
+				t.Fatal("no comparison recorded")
+			}
+		})
+	}
+}
+
+func TestShadowMiddleware_Selection(t *testing.T) {
+	calls := make(chan string, 10)
+	shadow := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls <- request.Params.Name
+		return nil, nil
+	}
+	primary := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, nil
+	}
+	call := func(handler ToolHandlerFunc, name string) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		_, _ = handler(context.Background(), request)
+	}
+
+	t.Run("by tool", func(t *testing.T) {
+		handler := NewShadowMiddleware(shadow, WithShadowTools("search"))(primary)
+		call(handler, "delete")
+		call(handler, "search")
+		assert.Equal(t, "search", <-calls)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("by percentage", func(t *testing.T) {
+		samples := []float64{0.05, 0.5, 0.2, 0.9}
+		config := &shadowConfig{shadow: shadow, timeout: time.Second}
+		WithShadowPercent(25)(config)
+		// Make the sampling deterministic.
+		config.random = func() float64 {
+			sample := samples[0]
+			samples = samples[1:]
+			return sample
+		}
+
+		handler := config.middleware(primary)
+		for range 4 {
+			call(handler, "search")
+		}
+		assert.Equal(t, "search", <-calls)
+		assert.Equal(t, "search", <-calls)
+		assert.Empty(t, calls)
+	})
+}
+
+func TestWithToolShadowing(t *testing.T) {
+	comparisons := make(chan ShadowComparison, 1)
+	s := NewMCPServer("test", "1.0.0",
+		WithToolCapabilities(false),
+		WithToolShadowing(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("v2"), nil
+		}, WithShadowRecorder(func(c ShadowComparison) { comparisons <- c })),
+	)
+	s.AddTool(mcp.NewTool("version"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("v1"), nil
+	})
+
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "version"}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Equal(t, "v1", resp.Result.(mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	c := <-comparisons
+	assert.False(t, c.Match)
+	assert.Equal(t, "version", c.Request.Params.Name)
+	assert.Equal(t, `content[0].text: "v1" != "v2"`, c.Diff)
+}
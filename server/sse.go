@@ -14,8 +14,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
-
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -33,6 +31,7 @@ type sseSession struct {
 	resourceTemplates   sync.Map     // stores session-specific resource templates
 	clientInfo          atomic.Value // stores session-specific client info
 	clientCapabilities  atomic.Value // stores session-specific client capabilities
+	protocolVersion     atomic.Value // stores the protocol version negotiated at initialization
 }
 
 // SSEContextFunc is a function that takes an existing context and the current
@@ -55,6 +54,20 @@ func (s *sseSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
 	return s.notificationChannel
 }
 
+// queueEvent queues an SSE event for the session's stream, dropping it if
+// the session is closed or its queue is full.
+func (s *sseSession) queueEvent(event string) {
+	select {
+	case s.eventQueue <- event:
+		// Event queued successfully
+	case <-s.done:
+		// Session is closed, don't try to queue
+	default:
+		// Queue is full, log this situation
+		log.Printf("Event queue full for session %s", s.sessionID)
+	}
+}
+
 func (s *sseSession) Initialize() {
 	// set default logging level
 	s.loggingLevel.Store(mcp.LoggingLevelError)
@@ -157,6 +170,15 @@ func (s *sseSession) SetClientCapabilities(clientCapabilities mcp.ClientCapabili
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *sseSession) GetProtocolVersion() string {
+	version, _ := s.protocolVersion.Load().(string)
+	return version
+}
+
+func (s *sseSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *sseSession) GetClientCapabilities() mcp.ClientCapabilities {
 	if value := s.clientCapabilities.Load(); value != nil {
 		if clientCapabilities, ok := value.(mcp.ClientCapabilities); ok {
@@ -173,6 +195,7 @@ var (
 	_ SessionWithResourceTemplates = (*sseSession)(nil)
 	_ SessionWithLogging           = (*sseSession)(nil)
 	_ SessionWithClientInfo        = (*sseSession)(nil)
+	_ SessionWithProtocolVersion   = (*sseSession)(nil)
 )
 
 // SSEServer implements a Server-Sent Events (SSE) based MCP server.
@@ -348,6 +371,10 @@ func NewTestServer(server *MCPServer, opts ...SSEOption) *httptest.Server {
 // Start begins serving SSE connections on the specified address.
 // It sets up HTTP handlers for SSE and message endpoints.
 func (s *SSEServer) Start(addr string) error {
+	if err := s.server.checkStartup(); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	if s.srv == nil {
 		s.srv = &http.Server{
@@ -407,7 +434,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionID := uuid.New().String()
+	sessionID := s.server.newID(IDKindSession)
 	session := &sseSession{
 		done:                make(chan struct{}),
 		eventQueue:          make(chan string, 100), // Buffer for events
@@ -453,14 +480,14 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	// Start keep alive : ping
 	if s.keepAlive {
 		go func() {
-			ticker := time.NewTicker(s.keepAliveInterval)
+			ticker := s.server.Clock().NewTicker(s.keepAliveInterval)
 			defer ticker.Stop()
 			for {
 				select {
-				case <-ticker.C:
+				case <-ticker.C():
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(session.requestID.Add(1)),
+						ID:      nextRequestID(s.server.ids(), &session.requestID),
 						Request: mcp.Request{
 							Method: "ping",
 						},
@@ -569,31 +596,36 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 
 	go func(ctx context.Context) {
 		defer cancel()
+		ctx, fence := s.server.fenceNotifications(ctx, func(notification mcp.JSONRPCNotification) error {
+			eventData, err := json.Marshal(notification)
+			if err != nil {
+				return err
+			}
+			session.queueEvent(fmt.Sprintf("event: message\ndata: %s\n\n", eventData))
+			return nil
+		})
 		// Use the context that will be canceled when session is done
 		// Process message through MCPServer
 		response := s.server.HandleMessage(ctx, rawMessage)
 		// Only send response if there is one (not for notifications)
-		if response != nil {
-			var message string
-			if eventData, err := json.Marshal(response); err != nil {
-				// If there is an error marshalling the response, send a generic error response
-				log.Printf("failed to marshal response: %v", err)
-				message = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
-			} else {
-				message = fmt.Sprintf("event: message\ndata: %s\n\n", eventData)
-			}
-
-			// Queue the event for sending via SSE
-			select {
-			case session.eventQueue <- message:
-				// Event queued successfully
-			case <-session.done:
-				// Session is closed, don't try to queue
-			default:
-				// Queue is full, log this situation
-				log.Printf("Event queue full for session %s", sessionID)
-			}
+		if response == nil {
+			s.server.flushFence(ctx, fence)
+			return
 		}
+		_ = s.server.writeFenced(fence,
+			func() error {
+				var message string
+				if eventData, err := json.Marshal(response); err != nil {
+					// If there is an error marshalling the response, send a generic error response
+					log.Printf("failed to marshal response: %v", err)
+					message = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
+				} else {
+					message = fmt.Sprintf("event: message\ndata: %s\n\n", eventData)
+				}
+				session.queueEvent(message)
+				return nil
+			},
+		)
 	}(messageCtx)
 }
 
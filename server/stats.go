@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Stats is a snapshot of the server's runtime statistics.
+type Stats struct {
+	// Requests counts the requests handled since the server was created,
+	// by method. Requests for methods the server does not handle are
+	// counted under "unknown".
+	Requests map[string]uint64 `json:"requests"`
+	// AverageLatency is the mean time spent handling a request, by method.
+	AverageLatency map[string]time.Duration `json:"averageLatency"`
+	// ActiveSessions is the number of registered sessions.
+	ActiveSessions int `json:"activeSessions"`
+	// Tasks counts the tasks the server holds, by status.
+	Tasks map[mcp.TaskStatus]int `json:"tasks"`
+	// NotificationQueues is the number of notifications waiting to be
+	// delivered, by session ID.
+	NotificationQueues map[string]int `json:"notificationQueues"`
+}
+
+// unknownMethod is the method requests for unhandled methods are counted
+// under, so that clients cannot add entries to the statistics at will.
+const unknownMethod mcp.MCPMethod = "unknown"
+
+// handledMethods are the request methods the server handles, besides its
+// extensions.
+var handledMethods = map[mcp.MCPMethod]bool{
+	mcp.MethodInitialize:             true,
+	mcp.MethodPing:                   true,
+	mcp.MethodSetLogLevel:            true,
+	mcp.MethodResourcesList:          true,
+	mcp.MethodResourcesTemplatesList: true,
+	mcp.MethodResourcesRead:          true,
+	mcp.MethodPromptsList:            true,
+	mcp.MethodPromptsGet:             true,
+	mcp.MethodToolsList:              true,
+	mcp.MethodToolsCall:              true,
+	mcp.MethodTasksGet:               true,
+	mcp.MethodTasksList:              true,
+	mcp.MethodTasksResult:            true,
+	mcp.MethodTasksCancel:            true,
+}
+
+// statsMethod returns the method a request is counted under.
+func (s *MCPServer) statsMethod(method mcp.MCPMethod) mcp.MCPMethod {
+	if handledMethods[method] {
+		return method
+	}
+	if _, ok := s.extensionHandler(method); ok {
+		return method
+	}
+	return unknownMethod
+}
+
+// requestStats accumulates request counts and latencies.
+type requestStats struct {
+	mu       sync.Mutex
+	byMethod map[string]*methodStats
+}
+
+type methodStats struct {
+	count uint64
+	total time.Duration
+}
+
+func (r *requestStats) observe(clock Clock, method mcp.MCPMethod, start time.Time) {
+	elapsed := clock.Now().Sub(start)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byMethod == nil {
+		r.byMethod = make(map[string]*methodStats)
+	}
+	m := r.byMethod[string(method)]
+	if m == nil {
+		m = &methodStats{}
+		r.byMethod[string(method)] = m
+	}
+	m.count++
+	m.total += elapsed
+}
+
+// Stats returns the server's runtime statistics.
+func (s *MCPServer) Stats() Stats {
+	stats := Stats{
+		Requests:           make(map[string]uint64),
+		AverageLatency:     make(map[string]time.Duration),
+		Tasks:              make(map[mcp.TaskStatus]int),
+		NotificationQueues: make(map[string]int),
+	}
+
+	s.stats.mu.Lock()
+	for method, m := range s.stats.byMethod {
+		stats.Requests[method] = m.count
+		stats.AverageLatency[method] = m.total / time.Duration(m.count)
+	}
+	s.stats.mu.Unlock()
+
+	s.sessions.Range(func(key, value any) bool {
+		stats.ActiveSessions++
+		if session, ok := value.(ClientSession); ok {
+			if ch := session.NotificationChannel(); ch != nil {
+				stats.NotificationQueues[session.SessionID()] = len(ch)
+			}
+		}
+		return true
+	})
+
+	s.tasksMu.RLock()
+	for _, entry := range s.tasks {
+		stats.Tasks[entry.task.Status]++
+	}
+	s.tasksMu.RUnlock()
+
+	return stats
+}
+
+// PublishExpvar publishes the server's statistics as the expvar variable
+// name, so they are served by the /debug/vars handler of package expvar.
+// Like expvar.Publish, it panics if the name is already in use.
+func (s *MCPServer) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return s.Stats() }))
+}
+
+// StatsHandler returns an HTTP handler that serves the server's statistics
+// as JSON, for mounting on a debug endpoint.
+func (s *MCPServer) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.Stats())
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_Stats(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithTaskCapabilities(true, true, true))
+	s.AddTool(mcp.NewTool("echo"), noopToolHandler)
+
+	session := &sessionTestClient{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	session.notificationChannel <- mcp.JSONRPCNotification{}
+	ctx := s.WithContext(context.Background(), session)
+
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"made/up"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"made/up/too"}`,
+	} {
+		s.HandleMessage(ctx, []byte(message))
+	}
+	s.createTask(ctx, "task-1", nil, nil)
+
+	stats := s.Stats()
+	assert.Equal(t, map[string]uint64{"ping": 1, "tools/list": 1, "tools/call": 2, "unknown": 2}, stats.Requests)
+	assert.Contains(t, stats.AverageLatency, "tools/call")
+	assert.Equal(t, 1, stats.ActiveSessions)
+	assert.Equal(t, map[mcp.TaskStatus]int{mcp.TaskStatusWorking: 1}, stats.Tasks)
+	assert.Equal(t, map[string]int{"s1": 1}, stats.NotificationQueues)
+
+	recorder := httptest.NewRecorder()
+	s.StatsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/mcp", nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var served Stats
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served))
+	assert.Equal(t, stats.Requests, served.Requests)
+
+	s.PublishExpvar("mcp_stats_test")
+	published := expvar.Get("mcp_stats_test")
+	require.NotNil(t, published)
+	assert.Contains(t, published.String(), `"activeSessions":1`)
+}
@@ -95,15 +95,17 @@ type stdioSession struct {
 	notifications       chan mcp.JSONRPCNotification
 	initialized         atomic.Bool
 	loggingLevel        atomic.Value
-	clientInfo          atomic.Value                        // stores session-specific client info
-	clientCapabilities  atomic.Value                        // stores session-specific client capabilities
-	writer              io.Writer                           // for sending requests to client
-	requestID           atomic.Int64                        // for generating unique request IDs
-	mu                  sync.RWMutex                        // protects writer
-	pendingRequests     map[int64]chan *samplingResponse    // for tracking pending sampling requests
-	pendingElicitations map[int64]chan *elicitationResponse // for tracking pending elicitation requests
-	pendingRoots        map[int64]chan *rootsResponse       // for tracking pending list roots requests
-	pendingMu           sync.RWMutex                        // protects pendingRequests and pendingElicitations
+	clientInfo          atomic.Value                         // stores session-specific client info
+	clientCapabilities  atomic.Value                         // stores session-specific client capabilities
+	protocolVersion     atomic.Value                         // stores the protocol version negotiated at initialization
+	writer              io.Writer                            // for sending requests to client
+	requestID           atomic.Int64                         // for generating unique request IDs
+	idGenerator         IDGenerator                          // generates request IDs if set
+	mu                  sync.RWMutex                         // protects writer and idGenerator
+	pendingRequests     map[string]chan *samplingResponse    // for tracking pending sampling requests
+	pendingElicitations map[string]chan *elicitationResponse // for tracking pending elicitation requests
+	pendingRoots        map[string]chan *rootsResponse       // for tracking pending list roots requests
+	pendingMu           sync.RWMutex                         // protects pendingRequests and pendingElicitations
 }
 
 // samplingResponse represents a response to a sampling request
@@ -168,6 +170,15 @@ func (s *stdioSession) SetClientCapabilities(clientCapabilities mcp.ClientCapabi
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *stdioSession) GetProtocolVersion() string {
+	version, _ := s.protocolVersion.Load().(string)
+	return version
+}
+
+func (s *stdioSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *stdioSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -184,6 +195,7 @@ func (s *stdioSession) GetLogLevel() mcp.LoggingLevel {
 func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
 	s.mu.RLock()
 	writer := s.writer
+	gen := s.idGenerator
 	s.mu.RUnlock()
 
 	if writer == nil {
@@ -191,18 +203,19 @@ func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMe
 	}
 
 	// Generate a unique request ID
-	id := s.requestID.Add(1)
+	id := nextRequestID(gen, &s.requestID)
+	key := requestIDKey(id)
 
 	// Create a response channel for this request
 	responseChan := make(chan *samplingResponse, 1)
 	s.pendingMu.Lock()
-	s.pendingRequests[id] = responseChan
+	s.pendingRequests[key] = responseChan
 	s.pendingMu.Unlock()
 
 	// Cleanup function to remove the pending request
 	cleanup := func() {
 		s.pendingMu.Lock()
-		delete(s.pendingRequests, id)
+		delete(s.pendingRequests, key)
 		s.pendingMu.Unlock()
 	}
 	defer cleanup()
@@ -210,7 +223,7 @@ func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMe
 	// Create the JSON-RPC request
 	jsonRPCRequest := struct {
 		JSONRPC string                  `json:"jsonrpc"`
-		ID      int64                   `json:"id"`
+		ID      mcp.RequestId           `json:"id"`
 		Method  string                  `json:"method"`
 		Params  mcp.CreateMessageParams `json:"params"`
 	}{
@@ -247,6 +260,7 @@ func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMe
 func (s *stdioSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
 	s.mu.RLock()
 	writer := s.writer
+	gen := s.idGenerator
 	s.mu.RUnlock()
 
 	if writer == nil {
@@ -254,27 +268,28 @@ func (s *stdioSession) ListRoots(ctx context.Context, request mcp.ListRootsReque
 	}
 
 	// Generate a unique request ID
-	id := s.requestID.Add(1)
+	id := nextRequestID(gen, &s.requestID)
+	key := requestIDKey(id)
 
 	// Create a response channel for this request
 	responseChan := make(chan *rootsResponse, 1)
 	s.pendingMu.Lock()
-	s.pendingRoots[id] = responseChan
+	s.pendingRoots[key] = responseChan
 	s.pendingMu.Unlock()
 
 	// Cleanup function to remove the pending request
 	cleanup := func() {
 		s.pendingMu.Lock()
-		delete(s.pendingRoots, id)
+		delete(s.pendingRoots, key)
 		s.pendingMu.Unlock()
 	}
 	defer cleanup()
 
 	// Create the JSON-RPC request
 	jsonRPCRequest := struct {
-		JSONRPC string `json:"jsonrpc"`
-		ID      int64  `json:"id"`
-		Method  string `json:"method"`
+		JSONRPC string        `json:"jsonrpc"`
+		ID      mcp.RequestId `json:"id"`
+		Method  string        `json:"method"`
 	}{
 		JSONRPC: mcp.JSONRPC_VERSION,
 		ID:      id,
@@ -308,6 +323,7 @@ func (s *stdioSession) ListRoots(ctx context.Context, request mcp.ListRootsReque
 func (s *stdioSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
 	s.mu.RLock()
 	writer := s.writer
+	gen := s.idGenerator
 	s.mu.RUnlock()
 
 	if writer == nil {
@@ -315,18 +331,19 @@ func (s *stdioSession) RequestElicitation(ctx context.Context, request mcp.Elici
 	}
 
 	// Generate a unique request ID
-	id := s.requestID.Add(1)
+	id := nextRequestID(gen, &s.requestID)
+	key := requestIDKey(id)
 
 	// Create a response channel for this request
 	responseChan := make(chan *elicitationResponse, 1)
 	s.pendingMu.Lock()
-	s.pendingElicitations[id] = responseChan
+	s.pendingElicitations[key] = responseChan
 	s.pendingMu.Unlock()
 
 	// Cleanup function to remove the pending request
 	cleanup := func() {
 		s.pendingMu.Lock()
-		delete(s.pendingElicitations, id)
+		delete(s.pendingElicitations, key)
 		s.pendingMu.Unlock()
 	}
 	defer cleanup()
@@ -334,7 +351,7 @@ func (s *stdioSession) RequestElicitation(ctx context.Context, request mcp.Elici
 	// Create the JSON-RPC request
 	jsonRPCRequest := struct {
 		JSONRPC string                `json:"jsonrpc"`
-		ID      int64                 `json:"id"`
+		ID      mcp.RequestId         `json:"id"`
 		Method  string                `json:"method"`
 		Params  mcp.ElicitationParams `json:"params"`
 	}{
@@ -374,20 +391,28 @@ func (s *stdioSession) SetWriter(writer io.Writer) {
 	s.writer = writer
 }
 
+// setIDGenerator sets the generator of the session's request IDs.
+func (s *stdioSession) setIDGenerator(gen IDGenerator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idGenerator = gen
+}
+
 var (
-	_ ClientSession          = (*stdioSession)(nil)
-	_ SessionWithLogging     = (*stdioSession)(nil)
-	_ SessionWithClientInfo  = (*stdioSession)(nil)
-	_ SessionWithSampling    = (*stdioSession)(nil)
-	_ SessionWithElicitation = (*stdioSession)(nil)
-	_ SessionWithRoots       = (*stdioSession)(nil)
+	_ ClientSession              = (*stdioSession)(nil)
+	_ SessionWithLogging         = (*stdioSession)(nil)
+	_ SessionWithClientInfo      = (*stdioSession)(nil)
+	_ SessionWithProtocolVersion = (*stdioSession)(nil)
+	_ SessionWithSampling        = (*stdioSession)(nil)
+	_ SessionWithElicitation     = (*stdioSession)(nil)
+	_ SessionWithRoots           = (*stdioSession)(nil)
 )
 
 var stdioSessionInstance = stdioSession{
 	notifications:       make(chan mcp.JSONRPCNotification, 100),
-	pendingRequests:     make(map[int64]chan *samplingResponse),
-	pendingElicitations: make(map[int64]chan *elicitationResponse),
-	pendingRoots:        make(map[int64]chan *rootsResponse),
+	pendingRequests:     make(map[string]chan *samplingResponse),
+	pendingElicitations: make(map[string]chan *elicitationResponse),
+	pendingRoots:        make(map[string]chan *rootsResponse),
 }
 
 // NewStdioServer creates a new stdio server wrapper around an MCPServer.
@@ -477,11 +502,8 @@ func (s *StdioServer) toolCallWorker(ctx context.Context) {
 				return
 			}
 			// Process the tool call
-			response := s.server.HandleMessage(work.ctx, work.message)
-			if response != nil {
-				if err := s.writeResponse(response, work.writer); err != nil {
-					s.errLogger.Printf("Error writing tool response: %v", err)
-				}
+			if err := s.handleMessage(work.ctx, work.message, work.writer); err != nil {
+				s.errLogger.Printf("Error writing tool response: %v", err)
 			}
 		case <-ctx.Done():
 			return
@@ -523,6 +545,10 @@ func (s *StdioServer) Listen(
 	stdin io.Reader,
 	stdout io.Writer,
 ) error {
+	if err := s.server.checkStartup(); err != nil {
+		return err
+	}
+
 	// Initialize the tool call queue
 	s.toolCallQueue = make(chan *toolCallWork, s.queueSize)
 
@@ -535,6 +561,7 @@ func (s *StdioServer) Listen(
 
 	// Set the writer for sending requests to the client
 	stdioSessionInstance.SetWriter(stdout)
+	stdioSessionInstance.setIDGenerator(s.server.ids())
 
 	// Add in any custom context.
 	if s.contextFunc != nil {
@@ -615,27 +642,34 @@ func (s *StdioServer) processMessage(
 		default:
 			// Queue is full, process synchronously as fallback
 			s.errLogger.Printf("Tool call queue full, processing synchronously")
-			response := s.server.HandleMessage(ctx, rawMessage)
-			if response != nil {
-				return s.writeResponse(response, writer)
-			}
-			return nil
+			return s.handleMessage(ctx, rawMessage, writer)
 		}
 	}
 
 	// Handle other messages synchronously
-	response := s.server.HandleMessage(ctx, rawMessage)
-
-	// Only write response if there is one (not for notifications)
-	if response != nil {
-		if err := s.writeResponse(response, writer); err != nil {
-			return fmt.Errorf("failed to write response: %w", err)
-		}
+	if err := s.handleMessage(ctx, rawMessage, writer); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
 	}
 
 	return nil
 }
 
+// handleMessage processes a message through the wrapped MCPServer and writes
+// its response, if any, ordered with the notifications it emitted as
+// configured with WithNotificationOrder.
+func (s *StdioServer) handleMessage(ctx context.Context, message json.RawMessage, writer io.Writer) error {
+	ctx, fence := s.server.fenceNotifications(ctx, func(notification mcp.JSONRPCNotification) error {
+		return s.writeResponse(notification, writer)
+	})
+	response := s.server.HandleMessage(ctx, message)
+	// Only write response if there is one (not for notifications)
+	if response == nil {
+		s.server.flushFence(ctx, fence)
+		return nil
+	}
+	return s.server.writeFenced(fence, func() error { return s.writeResponse(response, writer) })
+}
+
 // handleSamplingResponse checks if the message is a response to a sampling request
 // and routes it to the appropriate pending request channel.
 func (s *StdioServer) handleSamplingResponse(rawMessage json.RawMessage) bool {
@@ -647,7 +681,7 @@ func (s *stdioSession) handleSamplingResponse(rawMessage json.RawMessage) bool {
 	// Try to parse as a JSON-RPC response
 	var response struct {
 		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
+		ID      mcp.RequestId   `json:"id"`
 		Result  json.RawMessage `json:"result,omitempty"`
 		Error   *struct {
 			Code    int    `json:"code"`
@@ -658,15 +692,13 @@ func (s *stdioSession) handleSamplingResponse(rawMessage json.RawMessage) bool {
 	if err := json.Unmarshal(rawMessage, &response); err != nil {
 		return false
 	}
-	// Parse the ID as int64
-	idInt64, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
+	if response.ID.IsNil() || (response.Result == nil && response.Error == nil) {
 		return false
 	}
 
 	// Look for a pending request with this ID
 	s.pendingMu.RLock()
-	responseChan, exists := s.pendingRequests[idInt64]
+	responseChan, exists := s.pendingRequests[requestIDKey(response.ID)]
 	s.pendingMu.RUnlock()
 
 	if !exists {
@@ -717,7 +749,7 @@ func (s *stdioSession) handleElicitationResponse(rawMessage json.RawMessage) boo
 	// Try to parse as a JSON-RPC response
 	var response struct {
 		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
+		ID      mcp.RequestId   `json:"id"`
 		Result  json.RawMessage `json:"result,omitempty"`
 		Error   *struct {
 			Code    int    `json:"code"`
@@ -728,15 +760,13 @@ func (s *stdioSession) handleElicitationResponse(rawMessage json.RawMessage) boo
 	if err := json.Unmarshal(rawMessage, &response); err != nil {
 		return false
 	}
-	// Parse the ID as int64
-	id, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
+	if response.ID.IsNil() || (response.Result == nil && response.Error == nil) {
 		return false
 	}
 
 	// Check if we have a pending elicitation request with this ID
 	s.pendingMu.RLock()
-	responseChan, exists := s.pendingElicitations[id]
+	responseChan, exists := s.pendingElicitations[requestIDKey(response.ID)]
 	s.pendingMu.RUnlock()
 
 	if !exists {
@@ -778,7 +808,7 @@ func (s *stdioSession) handleListRootsResponse(rawMessage json.RawMessage) bool
 	// Try to parse as a JSON-RPC response
 	var response struct {
 		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
+		ID      mcp.RequestId   `json:"id"`
 		Result  json.RawMessage `json:"result,omitempty"`
 		Error   *struct {
 			Code    int    `json:"code"`
@@ -789,15 +819,13 @@ func (s *stdioSession) handleListRootsResponse(rawMessage json.RawMessage) bool
 	if err := json.Unmarshal(rawMessage, &response); err != nil {
 		return false
 	}
-	// Parse the ID as int64
-	id, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
+	if response.ID.IsNil() || (response.Result == nil && response.Error == nil) {
 		return false
 	}
 
 	// Check if we have a pending list root request with this ID
 	s.pendingMu.RLock()
-	responseChan, exists := s.pendingRoots[id]
+	responseChan, exists := s.pendingRoots[requestIDKey(response.ID)]
 	s.pendingMu.RUnlock()
 
 	if !exists {
@@ -144,6 +144,27 @@ func WithTLSCert(certFile, keyFile string) StreamableHTTPOption {
 	}
 }
 
+// WithHealthEndpoints serves the MCP server's LivenessHandler and
+// ReadinessHandler at the given paths when the server is run with Start,
+// e.g. "/livez" and "/readyz" for Kubernetes probes. An empty path is not
+// served.
+func WithHealthEndpoints(livenessPath, readinessPath string) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.livenessPath = livenessPath
+		s.readinessPath = readinessPath
+	}
+}
+
+// WithReadinessGate answers MCP requests with 503 Service Unavailable and a
+// Retry-After header until the MCP server's CheckReadiness passes for the
+// first time, e.g. while shared stores are not reachable yet after a
+// deploy.
+func WithReadinessGate() StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.readinessGate = true
+	}
+}
+
 // StreamableHTTPServer implements a Streamable-http based MCP server.
 // It communicates with clients over HTTP protocol, supporting both direct HTTP responses, and SSE streams.
 // https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#streamable-http
@@ -188,6 +209,11 @@ type StreamableHTTPServer struct {
 
 	tlsCertFile string
 	tlsKeyFile  string
+
+	livenessPath  string
+	readinessPath string
+	readinessGate bool
+	ready         atomic.Bool
 }
 
 // NewStreamableHTTPServer creates a new streamable-http server instance
@@ -207,11 +233,36 @@ func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *S
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.useIDGenerator(server.ids())
 	return s
 }
 
+// useIDGenerator makes the built-in session ID managers generate IDs with
+// the MCP server's IDGenerator, unless they have their own.
+func (s *StreamableHTTPServer) useIDGenerator(gen IDGenerator) {
+	resolver, ok := s.sessionIdManagerResolver.(*DefaultSessionIdManagerResolver)
+	if gen == nil || !ok {
+		return
+	}
+	switch manager := resolver.manager.(type) {
+	case *StatelessGeneratingSessionIdManager:
+		if manager.Generator == nil {
+			manager.Generator = gen
+		}
+	case *InsecureStatefulSessionIdManager:
+		if manager.Generator == nil {
+			manager.Generator = gen
+		}
+	}
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.readinessGate && !s.checkReady(r.Context()) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Server not ready", http.StatusServiceUnavailable)
+		return
+	}
 	switch r.Method {
 	case http.MethodPost:
 		s.handlePost(w, r)
@@ -229,10 +280,20 @@ func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request)
 //
 //	s.Start(":8080")
 func (s *StreamableHTTPServer) Start(addr string) error {
+	if err := s.server.checkStartup(); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	if s.httpServer == nil {
 		mux := http.NewServeMux()
 		mux.Handle(s.endpointPath, s)
+		if s.livenessPath != "" {
+			mux.Handle(s.livenessPath, s.server.LivenessHandler())
+		}
+		if s.readinessPath != "" {
+			mux.Handle(s.readinessPath, s.server.ReadinessHandler())
+		}
 		s.httpServer = &http.Server{
 			Addr:    addr,
 			Handler: mux,
@@ -279,6 +340,22 @@ func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
 
 // --- internal methods ---
 
+// checkReady reports whether the readiness gate is open, opening it once
+// the MCP server's readiness checks pass.
+func (s *StreamableHTTPServer) checkReady(ctx context.Context) bool {
+	if s.ready.Load() {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultReadinessTimeout)
+	defer cancel()
+	if err := s.server.CheckReadiness(ctx); err != nil {
+		s.logger.Infof("Rejecting request, server not ready: %v", err)
+		return false
+	}
+	s.ready.Store(true)
+	return true
+}
+
 func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request) {
 	// post request carry request/notification message
 
@@ -377,6 +454,7 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	// Create ephemeral session if no persistent session exists
 	if session == nil {
 		session = newStreamableHttpSession(sessionID, s.sessionTools, s.sessionResources, s.sessionResourceTemplates, s.sessionLogLevels)
+		session.idGenerator = s.server.ids()
 	}
 
 	// Set the client context before handling the message
@@ -433,9 +511,32 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		}
 	}()
 
+	writeSSEHeader := func() {
+		if !upgradedHeader {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			upgradedHeader = true
+		}
+	}
+
 	// Process message through MCPServer
-	response := s.server.HandleMessage(ctx, rawData)
+	handlerCtx, fence := s.server.fenceNotifications(ctx, func(notification mcp.JSONRPCNotification) error {
+		mu.Lock()
+		defer mu.Unlock()
+		writeSSEHeader()
+		if err := writeSSEEvent(w, notification); err != nil {
+			s.logger.Errorf("Failed to write SSE event: %v", err)
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	response := s.server.HandleMessage(handlerCtx, rawData)
 	if response == nil {
+		s.server.flushFence(handlerCtx, fence)
 		// For notifications, just send 202 Accepted with no body
 		w.WriteHeader(http.StatusAccepted)
 		return
@@ -470,32 +571,32 @@ drainLoop:
 	close(done)
 	mu.Unlock()
 	if ctx.Err() != nil {
+		s.server.releaseFence(fence)
 		return
 	}
-	// If client-server communication already upgraded to SSE stream
-	if session.upgradeToSSE.Load() {
-		if !upgradedHeader {
-			w.Header().Set("Content-Type", "text/event-stream")
-			w.Header().Set("Connection", "keep-alive")
-			w.Header().Set("Cache-Control", "no-cache")
-			w.WriteHeader(http.StatusOK)
-			upgradedHeader = true
-		}
-		if err := writeSSEEvent(w, response); err != nil {
-			s.logger.Errorf("Failed to write final SSE response event: %v", err)
-		}
-	} else {
-		w.Header().Set("Content-Type", "application/json")
-		if isInitializeRequest && sessionID != "" {
-			// send the session ID back to the client
-			w.Header().Set(HeaderKeySessionID, sessionID)
-		}
-		w.WriteHeader(http.StatusOK)
-		err := json.NewEncoder(w).Encode(response)
-		if err != nil {
-			s.logger.Errorf("Failed to write response: %v", err)
-		}
-	}
+	_ = s.server.writeFenced(fence,
+		func() error {
+			// If client-server communication already upgraded to SSE stream
+			if session.upgradeToSSE.Load() {
+				writeSSEHeader()
+				if err := writeSSEEvent(w, response); err != nil {
+					s.logger.Errorf("Failed to write final SSE response event: %v", err)
+				}
+			} else {
+				w.Header().Set("Content-Type", "application/json")
+				if isInitializeRequest && sessionID != "" {
+					// send the session ID back to the client
+					w.Header().Set(HeaderKeySessionID, sessionID)
+				}
+				w.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(w).Encode(response)
+				if err != nil {
+					s.logger.Errorf("Failed to write response: %v", err)
+				}
+			}
+			return nil
+		},
+	)
 
 	// Register session after successful initialization
 	// Only register if not already registered (e.g., by a GET connection)
@@ -536,13 +637,14 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	if sessionID == "" {
 		// It's a stateless server,
 		// but the MCP server requires a unique ID for registering, so we use a random one
-		sessionID = uuid.New().String()
+		sessionID = s.server.newID(IDKindSession)
 	}
 
 	// Get or create session atomically to prevent TOCTOU races
 	// where concurrent GETs could both create and register duplicate sessions
 	var session *streamableHttpSession
 	newSession := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionResources, s.sessionResourceTemplates, s.sessionLogLevels)
+	newSession.idGenerator = s.server.ids()
 	actual, loaded := s.activeSessions.LoadOrStore(sessionID, newSession)
 	session = actual.(*streamableHttpSession)
 
@@ -632,14 +734,14 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	if s.listenHeartbeatInterval > 0 {
 		// heartbeat to keep the connection alive
 		go func() {
-			ticker := time.NewTicker(s.listenHeartbeatInterval)
+			ticker := s.server.Clock().NewTicker(s.listenHeartbeatInterval)
 			defer ticker.Stop()
 			for {
 				select {
-				case <-ticker.C:
+				case <-ticker.C():
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(s.nextRequestID(sessionID)),
+						ID:      s.nextRequestID(sessionID),
 						Request: mcp.Request{
 							Method: "ping",
 						},
@@ -743,8 +845,8 @@ func (s *StreamableHTTPServer) handleSamplingResponse(w http.ResponseWriter, r *
 	}
 
 	// Parse the request ID
-	var requestID int64
-	if err := json.Unmarshal(responseMessage.ID, &requestID); err != nil {
+	var requestID mcp.RequestId
+	if err := json.Unmarshal(responseMessage.ID, &requestID); err != nil || !validRequestID(s.server.ids(), requestID) {
 		http.Error(w, "Invalid request ID in sampling response", http.StatusBadRequest)
 		return err
 	}
@@ -800,23 +902,23 @@ func (s *StreamableHTTPServer) deliverSamplingResponse(sessionID string, respons
 	}
 
 	// Look up the dedicated response channel for this specific request
-	responseChannelInterface, exists := session.samplingRequests.Load(response.requestID)
+	responseChannelInterface, exists := session.samplingRequests.Load(requestIDKey(response.requestID))
 	if !exists {
-		return fmt.Errorf("no pending request found for session %s, request %d", sessionID, response.requestID)
+		return fmt.Errorf("no pending request found for session %s, request %v", sessionID, response.requestID.Value())
 	}
 
 	responseChan, ok := responseChannelInterface.(chan samplingResponseItem)
 	if !ok {
-		return fmt.Errorf("invalid response channel type for session %s, request %d", sessionID, response.requestID)
+		return fmt.Errorf("invalid response channel type for session %s, request %v", sessionID, response.requestID.Value())
 	}
 
 	// Attempt to deliver the response with timeout to prevent indefinite blocking
 	select {
 	case responseChan <- response:
-		s.logger.Infof("Delivered sampling response for session %s, request %d", sessionID, response.requestID)
+		s.logger.Infof("Delivered sampling response for session %s, request %v", sessionID, response.requestID.Value())
 		return nil
 	default:
-		return fmt.Errorf("failed to deliver sampling response for session %s, request %d: channel full or blocked", sessionID, response.requestID)
+		return fmt.Errorf("failed to deliver sampling response for session %s, request %v: channel full or blocked", sessionID, response.requestID.Value())
 	}
 }
 
@@ -836,11 +938,11 @@ func (s *StreamableHTTPServer) writeJSONRPCError(
 	}
 }
 
-// nextRequestID gets the next incrementing requestID for the current session
-func (s *StreamableHTTPServer) nextRequestID(sessionID string) int64 {
+// nextRequestID gets the next requestID for the current session
+func (s *StreamableHTTPServer) nextRequestID(sessionID string) mcp.RequestId {
 	actual, _ := s.sessionRequestIDs.LoadOrStore(sessionID, new(atomic.Int64))
 	counter := actual.(*atomic.Int64)
-	return counter.Add(1)
+	return nextRequestID(s.server.ids(), counter)
 }
 
 // --- session ---
@@ -978,27 +1080,27 @@ func (s *sessionToolsStore) delete(sessionID string) {
 
 // Sampling support types for HTTP transport
 type samplingRequestItem struct {
-	requestID int64
+	requestID any // int64, or string with an IDGenerator
 	request   mcp.CreateMessageRequest
 	response  chan samplingResponseItem
 }
 
 type samplingResponseItem struct {
-	requestID int64
+	requestID mcp.RequestId
 	result    json.RawMessage
 	err       error
 }
 
 // Elicitation support types for HTTP transport
 type elicitationRequestItem struct {
-	requestID int64
+	requestID any
 	request   mcp.ElicitationRequest
 	response  chan samplingResponseItem
 }
 
 // Roots support types for HTTP transport
 type rootsRequestItem struct {
-	requestID int64
+	requestID any
 	request   mcp.ListRootsRequest
 	response  chan samplingResponseItem
 }
@@ -1016,14 +1118,16 @@ type streamableHttpSession struct {
 	logLevels           *sessionLogLevelsStore
 	clientInfo          atomic.Value // stores session-specific client info
 	clientCapabilities  atomic.Value // stores session-specific client capabilities
+	protocolVersion     atomic.Value // stores the protocol version negotiated at initialization
 
 	// Sampling support for bidirectional communication
 	samplingRequestChan    chan samplingRequestItem    // server -> client sampling requests
 	elicitationRequestChan chan elicitationRequestItem // server -> client elicitation requests
 	rootsRequestChan       chan rootsRequestItem       // server -> client list roots requests
 
-	samplingRequests sync.Map     // requestID -> pending sampling request context
+	samplingRequests sync.Map     // requestIDKey -> pending sampling request context
 	requestIDCounter atomic.Int64 // for generating unique request IDs
+	idGenerator      IDGenerator  // generates request IDs if set
 }
 
 func newStreamableHttpSession(sessionID string, toolStore *sessionToolsStore, resourcesStore *sessionResourcesStore, templatesStore *sessionResourceTemplatesStore, levels *sessionLogLevelsStore) *streamableHttpSession {
@@ -1119,12 +1223,22 @@ func (s *streamableHttpSession) SetClientCapabilities(clientCapabilities mcp.Cli
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *streamableHttpSession) GetProtocolVersion() string {
+	version, _ := s.protocolVersion.Load().(string)
+	return version
+}
+
+func (s *streamableHttpSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 var (
 	_ SessionWithTools             = (*streamableHttpSession)(nil)
 	_ SessionWithResources         = (*streamableHttpSession)(nil)
 	_ SessionWithResourceTemplates = (*streamableHttpSession)(nil)
 	_ SessionWithLogging           = (*streamableHttpSession)(nil)
 	_ SessionWithClientInfo        = (*streamableHttpSession)(nil)
+	_ SessionWithProtocolVersion   = (*streamableHttpSession)(nil)
 )
 
 func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
@@ -1136,14 +1250,15 @@ var _ SessionWithStreamableHTTPConfig = (*streamableHttpSession)(nil)
 // RequestSampling implements SessionWithSampling interface for HTTP transport
 func (s *streamableHttpSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
 	// Generate unique request ID
-	requestID := s.requestIDCounter.Add(1)
+	id := nextRequestID(s.idGenerator, &s.requestIDCounter)
+	requestID := requestIDKey(id)
 
 	// Create response channel for this specific request
 	responseChan := make(chan samplingResponseItem, 1)
 
 	// Create the sampling request item
 	samplingRequest := samplingRequestItem{
-		requestID: requestID,
+		requestID: id.Value(),
 		request:   request,
 		response:  responseChan,
 	}
@@ -1193,14 +1308,15 @@ func (s *streamableHttpSession) RequestSampling(ctx context.Context, request mcp
 // It sends a list roots request to the client via SSE and waits for the response.
 func (s *streamableHttpSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
 	// Generate unique request ID
-	requestID := s.requestIDCounter.Add(1)
+	id := nextRequestID(s.idGenerator, &s.requestIDCounter)
+	requestID := requestIDKey(id)
 
 	// Create response channel for this specific request
 	responseChan := make(chan samplingResponseItem, 1)
 
 	// Create the roots request item
 	rootsRequest := rootsRequestItem{
-		requestID: requestID,
+		requestID: id.Value(),
 		request:   request,
 		response:  responseChan,
 	}
@@ -1238,14 +1354,15 @@ func (s *streamableHttpSession) ListRoots(ctx context.Context, request mcp.ListR
 // RequestElicitation implements SessionWithElicitation interface for HTTP transport
 func (s *streamableHttpSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
 	// Generate unique request ID
-	requestID := s.requestIDCounter.Add(1)
+	id := nextRequestID(s.idGenerator, &s.requestIDCounter)
+	requestID := requestIDKey(id)
 
 	// Create response channel for this specific request
 	responseChan := make(chan samplingResponseItem, 1)
 
 	// Create the sampling request item
 	elicitationRequest := elicitationRequestItem{
-		requestID: requestID,
+		requestID: id.Value(),
 		request:   request,
 		response:  responseChan,
 	}
@@ -1339,13 +1456,27 @@ func (s *StatelessSessionIdManager) Terminate(sessionID string) (isNotAllowed bo
 
 // StatelessGeneratingSessionIdManager generates session IDs but doesn't validate them locally.
 // This allows session IDs to be generated for clients while working across multiple instances.
-type StatelessGeneratingSessionIdManager struct{}
+type StatelessGeneratingSessionIdManager struct {
+	// Generator generates the session IDs, which are then only checked to
+	// be non-empty. If nil, IDs are a prefixed UUID, or come from the
+	// server's WithIDGenerator.
+	Generator IDGenerator
+}
 
 func (s *StatelessGeneratingSessionIdManager) Generate() string {
+	if s.Generator != nil {
+		return s.Generator.NewID(IDKindSession)
+	}
 	return idPrefix + uuid.New().String()
 }
 
 func (s *StatelessGeneratingSessionIdManager) Validate(sessionID string) (isTerminated bool, err error) {
+	if s.Generator != nil {
+		if sessionID == "" {
+			return false, fmt.Errorf("invalid session id: %s", sessionID)
+		}
+		return false, nil
+	}
 	// Only validate format, not existence - allows cross-instance operation
 	if !strings.HasPrefix(sessionID, idPrefix) {
 		return false, fmt.Errorf("invalid session id: %s", sessionID)
@@ -1365,6 +1496,11 @@ func (s *StatelessGeneratingSessionIdManager) Terminate(sessionID string) (isNot
 // It validates both format and existence of session IDs.
 // For more secure session id, use a more complex generator, like a JWT.
 type InsecureStatefulSessionIdManager struct {
+	// Generator generates the session IDs, which are then only checked to
+	// exist. If nil, IDs are a prefixed UUID, or come from the server's
+	// WithIDGenerator.
+	Generator IDGenerator
+
 	sessions   sync.Map
 	terminated sync.Map
 }
@@ -1373,16 +1509,21 @@ const idPrefix = "mcp-session-"
 
 func (s *InsecureStatefulSessionIdManager) Generate() string {
 	sessionID := idPrefix + uuid.New().String()
+	if s.Generator != nil {
+		sessionID = s.Generator.NewID(IDKindSession)
+	}
 	s.sessions.Store(sessionID, true)
 	return sessionID
 }
 
 func (s *InsecureStatefulSessionIdManager) Validate(sessionID string) (isTerminated bool, err error) {
-	if !strings.HasPrefix(sessionID, idPrefix) {
-		return false, fmt.Errorf("invalid session id: %s", sessionID)
-	}
-	if _, err := uuid.Parse(sessionID[len(idPrefix):]); err != nil {
-		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	if s.Generator == nil {
+		if !strings.HasPrefix(sessionID, idPrefix) {
+			return false, fmt.Errorf("invalid session id: %s", sessionID)
+		}
+		if _, err := uuid.Parse(sessionID[len(idPrefix):]); err != nil {
+			return false, fmt.Errorf("invalid session id: %s", sessionID)
+		}
 	}
 	if _, exists := s.terminated.Load(sessionID); exists {
 		return true, nil
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithStructuredContentCompat adds the structured content of tool results
+// that have no content as pretty-printed JSON text, for clients that
+// negotiated a protocol version before structured results
+// (mcp.StructuredContentProtocolVersion) and only read content. Tools can
+// then set only StructuredContent without special-casing older hosts.
+// Clients of later versions get the results unchanged. This covers the
+// results of tools/call, of tasks/result and of tools/continueResult pages.
+//
+// The protocol version is the one negotiated by the session, or for
+// stateless HTTP requests the one of the Mcp-Protocol-Version header,
+// defaulting to 2025-03-26 as the specification requires.
+func WithStructuredContentCompat() ServerOption {
+	return func(s *MCPServer) {
+		s.structuredContentCompat = true
+	}
+}
+
+// ClientProtocolVersion returns the protocol version negotiated by the
+// client of the current session, or an empty string if the session does
+// not track it or is not initialized.
+func ClientProtocolVersion(ctx context.Context) string {
+	session, ok := ClientSessionFromContext(ctx).(SessionWithProtocolVersion)
+	if !ok {
+		return ""
+	}
+	return session.GetProtocolVersion()
+}
+
+// requestProtocolVersion returns the protocol version of a request: the
+// session's, the one of its HTTP header, or the version the specification
+// tells servers to assume without either.
+func (s *MCPServer) requestProtocolVersion(ctx context.Context, header http.Header) string {
+	if version := ClientProtocolVersion(ctx); version != "" {
+		return version
+	}
+	return s.protocolVersion(header.Get(HeaderKeyProtocolVersion))
+}
+
+// structuredToolResultCompat converts structured-only tool results to text
+// for legacy clients under WithStructuredContentCompat.
+func (s *MCPServer) structuredToolResultCompat(ctx context.Context, header http.Header, result *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+	if !s.legacyStructuredClient(ctx, header) {
+		return result, nil
+	}
+	return mcp.StructuredContentToText(result)
+}
+
+// structuredTaskPayloadCompat is structuredToolResultCompat for the encoded
+// tool result of a task. Fields other than the content are kept as stored.
+func (s *MCPServer) structuredTaskPayloadCompat(ctx context.Context, header http.Header, payload json.RawMessage) (json.RawMessage, error) {
+	if !s.legacyStructuredClient(ctx, header) {
+		return payload, nil
+	}
+	result, err := mcp.ParseCallToolResult(&payload)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := mcp.StructuredContentToText(result)
+	if err != nil || converted == result {
+		return payload, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	if fields["content"], err = json.Marshal(converted.Content); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// legacyStructuredClient reports whether results of the request must be
+// converted for a client that does not know structured results.
+func (s *MCPServer) legacyStructuredClient(ctx context.Context, header http.Header) bool {
+	return s.structuredContentCompat && !mcp.SupportsStructuredContent(s.requestProtocolVersion(ctx, header))
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newStructuredCompatServer() *MCPServer {
+	s := NewMCPServer("test", "1.0.0", WithToolCapabilities(false), WithStructuredContentCompat())
+	s.AddTool(mcp.NewTool("weather"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{StructuredContent: map[string]any{"temperature": 21.5}}, nil
+	})
+	return s
+}
+
+func TestWithStructuredContentCompat(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		wantContent []mcp.Content
+	}{
+		{"legacy client", "2025-03-26", []mcp.Content{mcp.NewTextContent("{\n  \"temperature\": 21.5\n}")}},
+		{"oldest client", "2024-11-05", []mcp.Content{mcp.NewTextContent("{\n  \"temperature\": 21.5\n}")}},
+		{"current client", mcp.LATEST_PROTOCOL_VERSION, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStructuredCompatServer()
+			session := NewInProcessSession("s1", nil)
+			ctx := s.WithContext(context.Background(), session)
+			s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+tt.version+`","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+			assert.Equal(t, tt.version, ClientProtocolVersion(ctx))
+
+			response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"weather"}}`))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "unexpected response %#v", response)
+			result := resp.Result.(mcp.CallToolResult)
+			assert.Equal(t, tt.wantContent, result.Content)
+			assert.Equal(t, map[string]any{"temperature": 21.5}, result.StructuredContent)
+		})
+	}
+}
+
+func TestWithStructuredContentCompat_StatelessHTTP(t *testing.T) {
+	server := NewTestStreamableHTTPServer(newStructuredCompatServer(), WithStateLess(true))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		header   string
+		wantText bool
+	}{
+		{"", true},
+		{"2025-03-26", true},
+		{mcp.LATEST_PROTOCOL_VERSION, false},
+	} {
+		t.Run("version="+tt.header, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"weather"}}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set(HeaderKeyProtocolVersion, tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var response struct {
+				Result struct {
+					Content []map[string]any `json:"content"`
+				} `json:"result"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, tt.wantText, len(response.Result.Content) == 1)
+		})
+	}
+}
+
+func TestClientProtocolVersion_NoSession(t *testing.T) {
+	assert.Empty(t, ClientProtocolVersion(context.Background()))
+}
+
+func TestWithStructuredContentCompat_TaskAndPageResults(t *testing.T) {
+	structured := &mcp.CallToolResult{StructuredContent: map[string]any{"temperature": 21.5}}
+	wantText := "{\n  \"temperature\": 21.5\n}"
+	for _, version := range []string{"2025-03-26", mcp.LATEST_PROTOCOL_VERSION} {
+		t.Run(version, func(t *testing.T) {
+			s := NewMCPServer("test", "1.0.0",
+				WithToolCapabilities(false),
+				WithTaskCapabilities(true, true, true),
+				WithResultPagination(),
+				WithStructuredContentCompat(),
+			)
+			s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+			s.AddResultContinuation("weather", func(ctx context.Context, request mcp.ContinueResultRequest) (*mcp.CallToolResult, error) {
+				return structured, nil
+			})
+			session := NewInProcessSession("s1", nil)
+			ctx := s.WithContext(context.Background(), session)
+			s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+version+`","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+			legacy := version != mcp.LATEST_PROTOCOL_VERSION
+
+			entry := s.createTask(ctx, "task-1", nil, nil)
+			require.NoError(t, s.completeTask(entry, structured, nil))
+			response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tasks/result","params":{"taskId":"task-1"}}`))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "unexpected response %#v", response)
+			payload := resp.Result.(mcp.TaskResultResult).Payload
+			result, err := mcp.ParseCallToolResult(&payload)
+			require.NoError(t, err)
+			if legacy {
+				assert.Equal(t, []mcp.Content{mcp.NewTextContent(wantText)}, result.Content, "tasks/result")
+			} else {
+				assert.Empty(t, result.Content, "tasks/result")
+			}
+			assert.Equal(t, map[string]any{"temperature": 21.5}, result.StructuredContent)
+
+			response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/continueResult","params":{"name":"weather","continuationToken":"2"}}`))
+			resp, ok = response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "unexpected response %#v", response)
+			page := resp.Result.(*mcp.CallToolResult)
+			if legacy {
+				assert.Equal(t, []mcp.Content{mcp.NewTextContent(wantText)}, page.Content, "tools/continueResult")
+			} else {
+				assert.Empty(t, page.Content, "tools/continueResult")
+			}
+		})
+	}
+}
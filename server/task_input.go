@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrNoDefaultAnswer is returned by DefaultAnswersInputFallback when a
+// required field of the requested schema has no registered answer.
+var ErrNoDefaultAnswer = errors.New("no default answer")
+
+// InputFallback answers a RequestInput call when the client cannot be asked
+// because it did not advertise the elicitation capability. Returning an
+// error fails the task the call is running in.
+type InputFallback func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+
+// WithInputFallback sets how RequestInput answers when the client does not
+// support elicitation. Without a fallback the task fails immediately.
+func WithInputFallback(fallback InputFallback) ServerOption {
+	return func(s *MCPServer) {
+		s.inputFallback = fallback
+	}
+}
+
+// RequestInput asks the client for input on behalf of the current tool call.
+// When the call runs as a task, the task is input_required while the client
+// is being asked and working again once it answers. A task may ask several
+// questions concurrently; it stays input_required until all are answered.
+//
+// If the client did not advertise elicitation, the fallback configured with
+// WithInputFallback answers instead. When there is no fallback or it fails,
+// the task is marked as failed right away and its context is cancelled, so
+// clients polling the task see why it stopped.
+func (s *MCPServer) RequestInput(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+	if err := request.Params.Validate(); err != nil {
+		return nil, err
+	}
+
+	elicitationSession, ok := session.(SessionWithElicitation)
+	if ok && clientSupportsElicitation(session) {
+		taskID := TaskIDFromContext(ctx)
+		ctx, done := s.clientRequestContext(ctx, mcp.MethodElicitationCreate, session.SessionID())
+		release, err := s.flowControl.acquire(ctx, session.SessionID())
+		if err != nil {
+			return nil, done(err)
+		}
+		defer release()
+		if err := s.setTaskInputRequired(taskID, request.Params.Message); err != nil {
+			return nil, done(err)
+		}
+		result, err := elicitationSession.RequestElicitation(ctx, request)
+		err = done(err)
+		if resumeErr := s.resumeTask(taskID); resumeErr != nil && err == nil {
+			err = resumeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkElicitationAnswer(result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if s.inputFallback != nil {
+		result, err := s.inputFallback(ctx, request)
+		if err == nil {
+			return result, nil
+		}
+		return nil, s.failTaskForInput(ctx, fmt.Errorf("input fallback failed: %w", err))
+	}
+	return nil, s.failTaskForInput(ctx, fmt.Errorf("%w: cannot ask %q", ErrElicitationNotSupported, request.Params.Message))
+}
+
+// clientSupportsElicitation reports whether the client advertised the
+// elicitation capability. Sessions that do not track client capabilities
+// are assumed to support it.
+func clientSupportsElicitation(session ClientSession) bool {
+	if withInfo, ok := session.(SessionWithClientInfo); ok {
+		return withInfo.GetClientCapabilities().Elicitation != nil
+	}
+	return true
+}
+
+// setTaskInputRequired marks a task as waiting for input, for the first of
+// its pending RequestInput calls. It does nothing when the call is not
+// running as a task. Every successful call must be paired with resumeTask.
+func (s *MCPServer) setTaskInputRequired(taskID, message string) error {
+	if taskID == "" {
+		return nil
+	}
+	entry, ok := s.lookupTask(taskID)
+	if !ok {
+		return ErrTaskNotFound
+	}
+	entry.inputMu.Lock()
+	defer entry.inputMu.Unlock()
+	if entry.pendingInputs == 0 {
+		if err := s.setTaskStatus(entry, mcp.TaskStatusInputRequired, message, nil); err != nil {
+			return err
+		}
+	}
+	entry.pendingInputs++
+	return nil
+}
+
+// resumeTask moves a task that was waiting for input back to working once
+// its last pending RequestInput call is answered. A task cancelled in the
+// meantime is left alone.
+func (s *MCPServer) resumeTask(taskID string) error {
+	if taskID == "" {
+		return nil
+	}
+	entry, ok := s.lookupTask(taskID)
+	if !ok {
+		return nil
+	}
+	entry.inputMu.Lock()
+	defer entry.inputMu.Unlock()
+	entry.pendingInputs--
+	if entry.pendingInputs > 0 {
+		return nil
+	}
+	err := s.setTaskStatus(entry, mcp.TaskStatusWorking, "", nil)
+	if errors.Is(err, ErrInvalidTaskTransition) {
+		return nil
+	}
+	return err
+}
+
+// failTaskForInput fails the current task, if any, with err and cancels it
+// so the tool stops waiting for input that will never come. It returns err.
+func (s *MCPServer) failTaskForInput(ctx context.Context, err error) error {
+	entry, ok := s.lookupTask(TaskIDFromContext(ctx))
+	if !ok {
+		return err
+	}
+	_ = s.setTaskStatus(entry, mcp.TaskStatusFailed, err.Error(), func() {
+		entry.resultErr = err
+		if entry.cancelFunc != nil {
+			entry.cancelFunc()
+		}
+	})
+	return err
+}
+
+// lookupTask returns a task entry by ID without session checks, for use by
+// code already running on behalf of the task.
+func (s *MCPServer) lookupTask(taskID string) (*taskEntry, bool) {
+	s.tasksMu.RLock()
+	defer s.tasksMu.RUnlock()
+	entry, ok := s.tasks[taskID]
+	return entry, ok
+}
+
+// DefaultAnswersInputFallback returns an InputFallback that accepts every
+// request with registered answers, keyed by property name of the requested
+// schema. Properties without an answer use the schema's default, if any.
+// Requests whose required properties cannot all be answered fail with
+// ErrNoDefaultAnswer.
+func DefaultAnswersInputFallback(answers map[string]any) InputFallback {
+	return func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		var schema struct {
+			Properties map[string]struct {
+				Default any `json:"default"`
+			} `json:"properties"`
+			Required []string `json:"required"`
+		}
+		if request.Params.RequestedSchema != nil {
+			data, err := json.Marshal(request.Params.RequestedSchema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid requested schema: %w", err)
+			}
+			if err := json.Unmarshal(data, &schema); err != nil {
+				return nil, fmt.Errorf("invalid requested schema: %w", err)
+			}
+		}
+
+		content := make(map[string]any)
+		for name, property := range schema.Properties {
+			if answer, ok := answers[name]; ok {
+				content[name] = answer
+			} else if property.Default != nil {
+				content[name] = property.Default
+			}
+		}
+		for _, name := range schema.Required {
+			if _, ok := content[name]; !ok {
+				return nil, fmt.Errorf("%w for required field %q", ErrNoDefaultAnswer, name)
+			}
+		}
+
+		return &mcp.ElicitationResult{
+			ElicitationResponse: mcp.ElicitationResponse{
+				Action:  mcp.ElicitationResponseActionAccept,
+				Content: content,
+			},
+		}, nil
+	}
+}
+
+// InputWebhookRequest is the body WebhookInputFallback posts to the
+// operator webhook. The webhook replies with an mcp.ElicitationResponse.
+type InputWebhookRequest struct {
+	TaskID          string `json:"taskId,omitempty"`
+	SessionID       string `json:"sessionId,omitempty"`
+	Message         string `json:"message"`
+	RequestedSchema any    `json:"requestedSchema,omitempty"`
+}
+
+// WebhookInputFallback returns an InputFallback that routes questions to an
+// operator webhook at url and waits for its answer. A nil client uses
+// http.DefaultClient; the request is bounded by the tool call's context.
+func WebhookInputFallback(url string, client *http.Client) InputFallback {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		body, err := json.Marshal(InputWebhookRequest{
+			TaskID:          TaskIDFromContext(ctx),
+			SessionID:       getSessionID(ctx),
+			Message:         request.Params.Message,
+			RequestedSchema: request.Params.RequestedSchema,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode webhook request: %w", err)
+		}
+
+		httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook request: %w", err)
+		}
+		httpRequest.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(httpRequest)
+		if err != nil {
+			return nil, fmt.Errorf("webhook request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+
+		var response mcp.ElicitationResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode webhook response: %w", err)
+		}
+		return &mcp.ElicitationResult{ElicitationResponse: response}, nil
+	}
+}
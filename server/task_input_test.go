@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCapabilitiesSession is an elicitation session that reports the
+// capabilities the client advertised.
+type mockCapabilitiesSession struct {
+	mockElicitationSession
+	capabilities mcp.ClientCapabilities
+	onElicit     func()
+}
+
+func (m *mockCapabilitiesSession) GetClientInfo() mcp.Implementation { return mcp.Implementation{} }
+
+func (m *mockCapabilitiesSession) SetClientInfo(mcp.Implementation) {}
+
+func (m *mockCapabilitiesSession) GetClientCapabilities() mcp.ClientCapabilities {
+	return m.capabilities
+}
+
+func (m *mockCapabilitiesSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	m.capabilities = capabilities
+}
+
+func (m *mockCapabilitiesSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	if m.onElicit != nil {
+		m.onElicit()
+	}
+	return m.mockElicitationSession.RequestElicitation(ctx, request)
+}
+
+func newInputRequest() mcp.ElicitationRequest {
+	return mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: "How strong?",
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"strength": map[string]any{"type": "string"},
+					"sugar":    map[string]any{"type": "boolean", "default": false},
+				},
+				"required": []string{"strength"},
+			},
+		},
+	}
+}
+
+// taskContext returns a context for a tool call running as a new task.
+func taskContext(t *testing.T, s *MCPServer, session ClientSession, taskID string) (context.Context, *taskEntry) {
+	t.Helper()
+	ctx := s.WithContext(context.Background(), session)
+	entry := s.createTask(ctx, taskID, nil, nil)
+	taskCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	entry.cancelFunc = cancel
+	return context.WithValue(taskCtx, taskIDKey{}, taskID), entry
+}
+
+func TestMCPServer_RequestInput_Elicits(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+	session := &mockCapabilitiesSession{
+		mockElicitationSession: mockElicitationSession{
+			sessionID: "session-1",
+			result: &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+				Action:  mcp.ElicitationResponseActionAccept,
+				Content: map[string]any{"strength": "double"},
+			}},
+		},
+		capabilities: mcp.ClientCapabilities{Elicitation: &mcp.ElicitationCapability{}},
+	}
+	ctx, entry := taskContext(t, s, session, "task-1")
+
+	var statusWhileAsking mcp.TaskStatus
+	session.onElicit = func() {
+		task, _, err := s.getTask(ctx, "task-1")
+		require.NoError(t, err)
+		statusWhileAsking = task.Status
+	}
+
+	result, err := s.RequestInput(ctx, newInputRequest())
+	require.NoError(t, err)
+	assert.Equal(t, mcp.ElicitationResponseActionAccept, result.Action)
+	assert.Equal(t, mcp.TaskStatusInputRequired, statusWhileAsking)
+	assert.Equal(t, mcp.TaskStatusWorking, entry.task.Status)
+}
+
+func TestMCPServer_RequestInput_Concurrent(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithElicitation(), WithTaskCapabilities(true, true, true))
+	entered := make(chan struct{})
+	answer := make(chan struct{})
+	session := &mockCapabilitiesSession{
+		mockElicitationSession: mockElicitationSession{
+			sessionID: "session-1",
+			result: &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+				Action:  mcp.ElicitationResponseActionAccept,
+				Content: map[string]any{"strength": "double"},
+			}},
+		},
+		capabilities: mcp.ClientCapabilities{Elicitation: &mcp.ElicitationCapability{}},
+		onElicit: func() {
+			entered <- struct{}{}
+			<-answer
+		},
+	}
+	ctx, _ := taskContext(t, s, session, "task-1")
+	status := func() mcp.TaskStatus {
+		task, _, err := s.getTask(ctx, "task-1")
+		require.NoError(t, err)
+		return task.Status
+	}
+
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := s.RequestInput(ctx, newInputRequest())
+			errs <- err
+		}()
+	}
+	<-entered
+	<-entered
+	assert.Equal(t, mcp.TaskStatusInputRequired, status())
+
+	answer <- struct{}{}
+	require.NoError(t, <-errs)
+	assert.Equal(t, mcp.TaskStatusInputRequired, status(), "the other question is still open")
+
+	answer <- struct{}{}
+	require.NoError(t, <-errs)
+	assert.Equal(t, mcp.TaskStatusWorking, status())
+}
+
+func TestMCPServer_RequestInput_WithoutFallbackFailsTask(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithTaskCapabilities(true, true, true))
+	session := &mockCapabilitiesSession{mockElicitationSession: mockElicitationSession{sessionID: "session-1"}}
+	ctx, entry := taskContext(t, s, session, "task-1")
+
+	_, err := s.RequestInput(ctx, newInputRequest())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrElicitationNotSupported)
+
+	assert.Equal(t, mcp.TaskStatusFailed, entry.task.Status)
+	assert.Contains(t, entry.task.StatusMessage, "How strong?")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "the task should be cancelled")
+
+	// The tool giving up afterwards does not change the outcome.
+	assert.ErrorIs(t, s.completeTask(entry, nil, err), ErrInvalidTaskTransition)
+}
+
+func TestMCPServer_RequestInput_OutsideTask(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	ctx := s.WithContext(context.Background(), &mockBasicSession{sessionID: "session-1"})
+
+	_, err := s.RequestInput(ctx, newInputRequest())
+	assert.ErrorIs(t, err, ErrElicitationNotSupported)
+
+	_, err = s.RequestInput(context.Background(), newInputRequest())
+	assert.ErrorIs(t, err, ErrNoActiveSession)
+}
+
+func TestDefaultAnswersInputFallback(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0",
+		WithTaskCapabilities(true, true, true),
+		WithInputFallback(DefaultAnswersInputFallback(map[string]any{"strength": "single"})),
+	)
+	ctx, entry := taskContext(t, s, &mockBasicSession{sessionID: "session-1"}, "task-1")
+
+	result, err := s.RequestInput(ctx, newInputRequest())
+	require.NoError(t, err)
+	assert.Equal(t, mcp.ElicitationResponseActionAccept, result.Action)
+	assert.Equal(t, map[string]any{"strength": "single", "sugar": false}, result.Content)
+	assert.Equal(t, mcp.TaskStatusWorking, entry.task.Status)
+
+	t.Run("missing required answer", func(t *testing.T) {
+		fallback := DefaultAnswersInputFallback(nil)
+		_, err := fallback(context.Background(), newInputRequest())
+		assert.ErrorIs(t, err, ErrNoDefaultAnswer)
+	})
+}
+
+func TestWebhookInputFallback(t *testing.T) {
+	var received InputWebhookRequest
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_ = json.NewEncoder(w).Encode(mcp.ElicitationResponse{
+			Action:  mcp.ElicitationResponseActionAccept,
+			Content: map[string]any{"strength": "ristretto"},
+		})
+	}))
+	defer webhook.Close()
+
+	s := NewMCPServer("test", "1.0.0",
+		WithTaskCapabilities(true, true, true),
+		WithInputFallback(WebhookInputFallback(webhook.URL, nil)),
+	)
+	ctx, _ := taskContext(t, s, &mockBasicSession{sessionID: "session-1"}, "task-1")
+
+	result, err := s.RequestInput(ctx, newInputRequest())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"strength": "ristretto"}, result.Content)
+	assert.Equal(t, "task-1", received.TaskID)
+	assert.Equal(t, "session-1", received.SessionID)
+	assert.Equal(t, "How strong?", received.Message)
+
+	t.Run("webhook failure fails the task", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer failing.Close()
+
+		s := NewMCPServer("test", "1.0.0",
+			WithTaskCapabilities(true, true, true),
+			WithInputFallback(WebhookInputFallback(failing.URL, nil)),
+		)
+		ctx, entry := taskContext(t, s, &mockBasicSession{sessionID: "session-1"}, "task-2")
+
+		_, err := s.RequestInput(ctx, newInputRequest())
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrElicitationNotSupported))
+		assert.Equal(t, mcp.TaskStatusFailed, entry.task.Status)
+	})
+}
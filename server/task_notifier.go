@@ -0,0 +1,350 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultNotifyTimeout bounds how long sending a task notification may take.
+const defaultNotifyTimeout = 30 * time.Second
+
+// TaskNotification describes a task status change for a TaskNotifier.
+type TaskNotification struct {
+	Task      mcp.Task
+	SessionID string
+	// ToolName and Meta come from the tools/call request that started the
+	// task; they are empty for tasks created otherwise.
+	ToolName string
+	Meta     *mcp.Meta
+}
+
+// TaskNotifier sends notifications about tasks to people, e.g. in a chat
+// channel or by email.
+type TaskNotifier interface {
+	NotifyTask(ctx context.Context, notification TaskNotification) error
+}
+
+// TaskNotifierFunc is a function that implements TaskNotifier.
+type TaskNotifierFunc func(ctx context.Context, notification TaskNotification) error
+
+// NotifyTask implements TaskNotifier.
+func (f TaskNotifierFunc) NotifyTask(ctx context.Context, notification TaskNotification) error {
+	return f(ctx, notification)
+}
+
+// TaskNotificationFilter selects the notifications a notifier receives.
+type TaskNotificationFilter func(notification TaskNotification) bool
+
+// ForTools selects notifications about tasks started by the named tools.
+func ForTools(names ...string) TaskNotificationFilter {
+	return func(n TaskNotification) bool {
+		return slices.Contains(names, n.ToolName)
+	}
+}
+
+// ForMeta selects notifications about tasks whose tools/call request has
+// the given _meta value.
+func ForMeta(key string, value any) TaskNotificationFilter {
+	return func(n TaskNotification) bool {
+		if n.Meta == nil {
+			return false
+		}
+		v, ok := n.Meta.AdditionalFields[key]
+		return ok && reflect.DeepEqual(v, value)
+	}
+}
+
+// TaskNotifierHookOption configures NewTaskNotifierHook.
+type TaskNotifierHookOption func(*taskNotifierHook)
+
+// WithNotificationFilter adds a filter; a notification is sent only if
+// every filter selects it.
+func WithNotificationFilter(filter TaskNotificationFilter) TaskNotifierHookOption {
+	return func(h *taskNotifierHook) {
+		h.filters = append(h.filters, filter)
+	}
+}
+
+// WithNotifyStatuses sets which statuses are notified. By default only
+// completed and failed tasks are.
+func WithNotifyStatuses(statuses ...mcp.TaskStatus) TaskNotifierHookOption {
+	return func(h *taskNotifierHook) {
+		h.statuses = statuses
+	}
+}
+
+// WithNotifyTimeout bounds how long sending a notification may take. The
+// default is 30 seconds.
+func WithNotifyTimeout(timeout time.Duration) TaskNotifierHookOption {
+	return func(h *taskNotifierHook) {
+		h.timeout = timeout
+	}
+}
+
+// WithNotifierErrorHandler sets a function called when sending a
+// notification fails. Errors are ignored by default.
+func WithNotifierErrorHandler(fn func(notification TaskNotification, err error)) TaskNotifierHookOption {
+	return func(h *taskNotifierHook) {
+		h.onError = fn
+	}
+}
+
+type taskNotifierHook struct {
+	notifier TaskNotifier
+	statuses []mcp.TaskStatus
+	filters  []TaskNotificationFilter
+	timeout  time.Duration
+	onError  func(notification TaskNotification, err error)
+
+	// sending tracks the notifications being sent, for tests.
+	sending sync.WaitGroup
+}
+
+// NewTaskNotifierHook returns a task status hook that sends notifications
+// through notifier. They are sent in the background, so a slow notifier
+// does not hold up task status changes:
+//
+//	hooks.AddOnTaskStatusChange(server.NewTaskNotifierHook(
+//		server.NewSlackNotifier(webhookURL),
+//		server.WithNotificationFilter(server.ForTools("deploy")),
+//	))
+func NewTaskNotifierHook(notifier TaskNotifier, opts ...TaskNotifierHookOption) OnTaskStatusChangeHookFunc {
+	return newTaskNotifierHook(notifier, opts...).hook
+}
+
+func newTaskNotifierHook(notifier TaskNotifier, opts ...TaskNotifierHookOption) *taskNotifierHook {
+	h := &taskNotifierHook{
+		notifier: notifier,
+		statuses: []mcp.TaskStatus{mcp.TaskStatusCompleted, mcp.TaskStatusFailed},
+		timeout:  defaultNotifyTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *taskNotifierHook) hook(ctx context.Context, task mcp.Task) {
+	if !slices.Contains(h.statuses, task.Status) {
+		return
+	}
+
+	notification := TaskNotification{Task: task, SessionID: getSessionID(ctx)}
+	if request, ok := TaskToolCallFromContext(ctx); ok {
+		notification.ToolName = request.Params.Name
+		notification.Meta = request.Params.Meta
+	}
+
+	for _, filter := range h.filters {
+		if !filter(notification) {
+			return
+		}
+	}
+
+	h.sending.Add(1)
+	go h.send(context.WithoutCancel(ctx), notification)
+}
+
+func (h *taskNotifierHook) send(ctx context.Context, notification TaskNotification) {
+	defer h.sending.Done()
+	ctx, cancel := contextWithTimeout(ctx, ServerFromContext(ctx).Clock(), h.timeout)
+	defer cancel()
+
+	if err := h.notifier.NotifyTask(ctx, notification); err != nil && h.onError != nil {
+		h.onError(notification, err)
+	}
+}
+
+// TaskNotificationText returns a one-line human-readable summary of a
+// notification, used by the reference notifiers.
+func TaskNotificationText(n TaskNotification) string {
+	var b strings.Builder
+	b.WriteString("Task ")
+	b.WriteString(n.Task.TaskId)
+	if n.ToolName != "" {
+		fmt.Fprintf(&b, " (%s)", n.ToolName)
+	}
+	fmt.Fprintf(&b, " %s", n.Task.Status)
+	if n.Task.StatusMessage != "" {
+		fmt.Fprintf(&b, ": %s", n.Task.StatusMessage)
+	}
+	return b.String()
+}
+
+// SlackNotifier posts task notifications to a Slack incoming webhook.
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+	format     func(TaskNotification) string
+}
+
+// SlackNotifierOption configures a SlackNotifier.
+type SlackNotifierOption func(*SlackNotifier)
+
+// WithSlackHTTPClient sets the HTTP client used to post messages. The
+// default one times out after 30 seconds.
+func WithSlackHTTPClient(client *http.Client) SlackNotifierOption {
+	return func(n *SlackNotifier) {
+		n.client = client
+	}
+}
+
+// WithSlackFormat sets how notifications are turned into message text.
+func WithSlackFormat(format func(TaskNotification) string) SlackNotifierOption {
+	return func(n *SlackNotifier) {
+		n.format = format
+	}
+}
+
+// NewSlackNotifier creates a SlackNotifier posting to an incoming webhook.
+func NewSlackNotifier(webhookURL string, opts ...SlackNotifierOption) *SlackNotifier {
+	n := &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: defaultNotifyTimeout},
+		format:     TaskNotificationText,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// NotifyTask implements TaskNotifier.
+func (n *SlackNotifier) NotifyTask(ctx context.Context, notification TaskNotification) error {
+	body, err := json.Marshal(map[string]string{"text": n.format(notification)})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier emails task notifications.
+type SMTPNotifier struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	to       []string
+	format   func(TaskNotification) string
+	sendMail func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPNotifier creates an SMTPNotifier sending mail through the server at
+// addr (host:port) from one address to the given recipients. auth may be nil.
+func NewSMTPNotifier(addr string, auth smtp.Auth, from string, to ...string) *SMTPNotifier {
+	return &SMTPNotifier{
+		addr:     addr,
+		auth:     auth,
+		from:     from,
+		to:       to,
+		format:   TaskNotificationText,
+		sendMail: sendMailContext,
+	}
+}
+
+// NotifyTask implements TaskNotifier. Sending the mail is given up when ctx
+// is done.
+func (n *SMTPNotifier) NotifyTask(ctx context.Context, notification TaskNotification) error {
+	text := n.format(notification)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	// Keep headers on one line whatever the status message contains.
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(text))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(text)
+	msg.WriteString("\r\n")
+
+	if err := n.sendMail(ctx, n.addr, n.auth, n.from, n.to, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send task notification email: %w", err)
+	}
+	return nil
+}
+
+// sendMailContext is smtp.SendMail, with the connection closed when ctx is
+// done.
+func sendMailContext(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) (err error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer func() {
+		if !stop() && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(a); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
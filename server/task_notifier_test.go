@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskNotifierHook(t *testing.T) {
+	var got []TaskNotification
+	notifier := TaskNotifierFunc(func(ctx context.Context, n TaskNotification) error {
+		got = append(got, n)
+		return nil
+	})
+
+	deploy := mcp.CallToolRequest{}
+	deploy.Params.Name = "deploy"
+	deploy.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{"team": "ops", "labels": map[string]any{"env": "prod"}}}
+	other := mcp.CallToolRequest{}
+	other.Params.Name = "search"
+
+	toolCtx := func(request mcp.CallToolRequest) context.Context {
+		return context.WithValue(context.Background(), taskToolCallKey{}, request)
+	}
+	task := func(status mcp.TaskStatus) mcp.Task {
+		return mcp.NewTask("task-1", mcp.WithTaskStatus(status))
+	}
+
+	tests := []struct {
+		name     string
+		opts     []TaskNotifierHookOption
+		ctx      context.Context
+		status   mcp.TaskStatus
+		expected bool
+	}{
+		{"completed by default", nil, toolCtx(other), mcp.TaskStatusCompleted, true},
+		{"failed by default", nil, toolCtx(other), mcp.TaskStatusFailed, true},
+		{"cancelled not by default", nil, toolCtx(other), mcp.TaskStatusCancelled, false},
+		{"working not by default", nil, toolCtx(other), mcp.TaskStatusWorking, false},
+		{
+			"explicit statuses",
+			[]TaskNotifierHookOption{WithNotifyStatuses(mcp.TaskStatusCancelled)},
+			toolCtx(other), mcp.TaskStatusCancelled, true,
+		},
+		{
+			"tool filter match",
+			[]TaskNotifierHookOption{WithNotificationFilter(ForTools("deploy"))},
+			toolCtx(deploy), mcp.TaskStatusCompleted, true,
+		},
+		{
+			"tool filter mismatch",
+			[]TaskNotifierHookOption{WithNotificationFilter(ForTools("deploy"))},
+			toolCtx(other), mcp.TaskStatusCompleted, false,
+		},
+		{
+			"meta filter match",
+			[]TaskNotifierHookOption{WithNotificationFilter(ForMeta("team", "ops"))},
+			toolCtx(deploy), mcp.TaskStatusFailed, true,
+		},
+		{
+			"meta filter on a map value",
+			[]TaskNotifierHookOption{WithNotificationFilter(ForMeta("labels", map[string]any{"env": "prod"}))},
+			toolCtx(deploy), mcp.TaskStatusFailed, true,
+		},
+		{
+			"meta filter on a map value mismatch",
+			[]TaskNotifierHookOption{WithNotificationFilter(ForMeta("labels", map[string]any{"env": "dev"}))},
+			toolCtx(deploy), mcp.TaskStatusFailed, false,
+		},
+		{
+			"meta filter without meta",
+			[]TaskNotifierHookOption{WithNotificationFilter(ForMeta("team", "ops"))},
+			toolCtx(other), mcp.TaskStatusFailed, false,
+		},
+		{
+			"all filters must match",
+			[]TaskNotifierHookOption{
+				WithNotificationFilter(ForTools("deploy")),
+				WithNotificationFilter(ForMeta("team", "dev")),
+			},
+			toolCtx(deploy), mcp.TaskStatusCompleted, false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			h := newTaskNotifierHook(notifier, tc.opts...)
+			h.hook(tc.ctx, task(tc.status))
+			h.sending.Wait()
+			if !tc.expected {
+				assert.Empty(t, got)
+				return
+			}
+			require.Len(t, got, 1)
+			assert.Equal(t, tc.status, got[0].Task.Status)
+		})
+	}
+
+	t.Run("errors are reported", func(t *testing.T) {
+		var reported error
+		failing := TaskNotifierFunc(func(ctx context.Context, n TaskNotification) error {
+			return errors.New("unreachable")
+		})
+		h := newTaskNotifierHook(failing, WithNotifierErrorHandler(func(n TaskNotification, err error) {
+			reported = err
+		}))
+		h.hook(toolCtx(deploy), task(mcp.TaskStatusCompleted))
+		h.sending.Wait()
+		assert.EqualError(t, reported, "unreachable")
+	})
+
+	t.Run("sending times out", func(t *testing.T) {
+		var reported error
+		blocking := TaskNotifierFunc(func(ctx context.Context, n TaskNotification) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		h := newTaskNotifierHook(blocking,
+			WithNotifyTimeout(10*time.Millisecond),
+			WithNotifierErrorHandler(func(n TaskNotification, err error) {
+				reported = err
+			}),
+		)
+		h.hook(toolCtx(deploy), task(mcp.TaskStatusCompleted))
+		h.sending.Wait()
+		assert.ErrorIs(t, reported, context.DeadlineExceeded)
+	})
+}
+
+func TestTaskNotifierHook_ServerIntegration(t *testing.T) {
+	got := make(chan TaskNotification, 1)
+	hooks := &Hooks{}
+	hooks.AddOnTaskStatusChange(NewTaskNotifierHook(TaskNotifierFunc(func(ctx context.Context, n TaskNotification) error {
+		got <- n
+		return nil
+	})))
+
+	s := NewMCPServer("test", "1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+		WithHooks(hooks),
+	)
+	done := make(chan struct{})
+	s.AddTool(mcp.NewTool("deploy"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("deployed"), nil
+	})
+	hooks.AddOnTaskStatusChange(func(ctx context.Context, task mcp.Task) {
+		if task.Status.IsTerminal() {
+			close(done)
+		}
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "deploy"
+	request.Params.Task = &mcp.TaskParams{}
+	_, reqErr := s.handleTaskAugmentedToolCall(context.Background(), 1, request)
+	require.Nil(t, reqErr)
+	<-done
+
+	select {
+	case n := <-got:
+		assert.Equal(t, "deploy", n.ToolName)
+		assert.Equal(t, mcp.TaskStatusCompleted, n.Task.Status)
+	case <-time.After(time.Second):
+		t.Fatal("no notification was sent")
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var text string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		text = body["text"]
+	}))
+	defer slack.Close()
+
+	notifier := NewSlackNotifier(slack.URL)
+	err := notifier.NotifyTask(context.Background(), TaskNotification{
+		Task:     mcp.NewTask("task-1", mcp.WithTaskStatus(mcp.TaskStatusFailed), mcp.WithTaskStatusMessage("disk full")),
+		ToolName: "backup",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Task task-1 (backup) failed: disk full", text)
+
+	t.Run("error status", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer failing.Close()
+		err := NewSlackNotifier(failing.URL).NotifyTask(context.Background(), TaskNotification{})
+		assert.ErrorContains(t, err, "404")
+	})
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	notifier := NewSMTPNotifier("mail.example.com:25", nil, "mcp@example.com", "ops@example.com", "dev@example.com")
+
+	var sentTo []string
+	var message string
+	notifier.sendMail = func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "mail.example.com:25", addr)
+		assert.Equal(t, "mcp@example.com", from)
+		sentTo = to
+		message = string(msg)
+		return nil
+	}
+
+	err := notifier.NotifyTask(context.Background(), TaskNotification{
+		Task:     mcp.NewTask("task-1", mcp.WithTaskStatus(mcp.TaskStatusFailed), mcp.WithTaskStatusMessage("line one\nline two")),
+		ToolName: "backup",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, sentTo)
+	assert.Contains(t, message, "To: ops@example.com, dev@example.com\r\n")
+	assert.Contains(t, message, "Subject: Task task-1 (backup) failed: line one line two\r\n")
+
+	headers, _, found := strings.Cut(message, "\r\n\r\n")
+	require.True(t, found)
+	assert.NotContains(t, headers, "\nline two", "status messages must not inject headers")
+
+	notifier.sendMail = func(context.Context, string, smtp.Auth, string, []string, []byte) error {
+		return errors.New("connection refused")
+	}
+	err = notifier.NotifyTask(context.Background(), TaskNotification{})
+	assert.ErrorContains(t, err, "connection refused")
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		// A server that accepts connections but never greets.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err = NewSMTPNotifier(listener.Addr().String(), nil, "mcp@example.com", "ops@example.com").
+			NotifyTask(ctx, TaskNotification{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
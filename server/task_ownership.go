@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PrincipalFunc identifies the authenticated principal, such as a user or
+// tenant, behind a request. It typically reads a value stored in ctx by an
+// HTTP context function after authenticating the request. An empty string
+// means the request is unauthenticated.
+type PrincipalFunc func(ctx context.Context) string
+
+// TaskOwner identifies who created a task.
+type TaskOwner struct {
+	Principal string
+	SessionID string
+}
+
+// TaskVisibilityFunc reports whether the caller may see and cancel a task
+// created by owner. principal is the caller's principal.
+type TaskVisibilityFunc func(ctx context.Context, principal string, task mcp.Task, owner TaskOwner) bool
+
+// TaskOwnershipOption configures WithTaskOwnership.
+type TaskOwnershipOption func(*taskOwnership)
+
+type taskOwnership struct {
+	principal PrincipalFunc
+	isAdmin   func(ctx context.Context) bool
+	visible   TaskVisibilityFunc
+}
+
+// WithTaskAdmin lets callers for which isAdmin returns true see and cancel
+// every task, regardless of its owner.
+func WithTaskAdmin(isAdmin func(ctx context.Context) bool) TaskOwnershipOption {
+	return func(o *taskOwnership) {
+		o.isAdmin = isAdmin
+	}
+}
+
+// WithTaskVisibilityPolicy replaces the default rule, under which only the
+// owning principal can access a task, with a custom policy. Admins are still
+// allowed everything before the policy is consulted.
+func WithTaskVisibilityPolicy(visible TaskVisibilityFunc) TaskOwnershipOption {
+	return func(o *taskOwnership) {
+		o.visible = visible
+	}
+}
+
+// WithTaskOwnership makes tasks owned by the principal that created them, as
+// identified by principal. tasks/get, tasks/result, tasks/cancel and
+// tasks/list then only expose a task to its owner, whichever session the
+// owner uses, and report other tenants' tasks as not found. Tasks created
+// without a principal, and requests without one, fall back to the scoping
+// by session used without this option.
+func WithTaskOwnership(principal PrincipalFunc, opts ...TaskOwnershipOption) ServerOption {
+	return func(s *MCPServer) {
+		o := &taskOwnership{principal: principal}
+		for _, opt := range opts {
+			opt(o)
+		}
+		s.taskOwnership = o
+	}
+}
+
+// taskPrincipal returns the principal that owns tasks created with ctx.
+func (s *MCPServer) taskPrincipal(ctx context.Context) string {
+	if s.taskOwnership == nil || s.taskOwnership.principal == nil {
+		return ""
+	}
+	return s.taskOwnership.principal(ctx)
+}
+
+// taskVisible reports whether the caller may access a task. task must be a
+// copy taken under tasksMu, as policies run without holding it.
+func (s *MCPServer) taskVisible(ctx context.Context, task mcp.Task, owner TaskOwner) bool {
+	o := s.taskOwnership
+	if o == nil {
+		// Verify session isolation
+		sessionID := getSessionID(ctx)
+		return owner.SessionID == "" || sessionID == "" || owner.SessionID == sessionID
+	}
+	if o.isAdmin != nil && o.isAdmin(ctx) {
+		return true
+	}
+	principal := s.taskPrincipal(ctx)
+	if o.visible != nil {
+		return o.visible(ctx, principal, task, owner)
+	}
+	if owner.Principal == "" || principal == "" {
+		return owner.SessionID == getSessionID(ctx)
+	}
+	return owner.Principal == principal
+}
+
+func (e *taskEntry) owner() TaskOwner {
+	return TaskOwner{Principal: e.principal, SessionID: e.sessionID}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type principalKey struct{}
+
+type adminKey struct{}
+
+func principalContext(s *MCPServer, principal, sessionID string) context.Context {
+	ctx := context.WithValue(context.Background(), principalKey{}, principal)
+	session := &sessionTestClient{sessionID: sessionID}
+	return s.WithContext(ctx, session)
+}
+
+func testPrincipal(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+func taskIDs(tasks []mcp.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.TaskId)
+	}
+	return ids
+}
+
+func TestWithTaskOwnership(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithTaskOwnership(testPrincipal,
+		WithTaskAdmin(func(ctx context.Context) bool { return ctx.Value(adminKey{}) != nil }),
+	))
+
+	s.createTask(principalContext(s, "alice", "s1"), "alice-task", nil, nil)
+	s.createTask(principalContext(s, "bob", "s2"), "bob-task", nil, nil)
+	s.createTask(principalContext(s, "", "s3"), "anonymous-task", nil, nil)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		visible []string
+	}{
+		{
+			name:    "owner from another session",
+			ctx:     principalContext(s, "alice", "s4"),
+			visible: []string{"alice-task"},
+		},
+		{
+			name:    "other principal",
+			ctx:     principalContext(s, "bob", "s1"),
+			visible: []string{"bob-task"},
+		},
+		{
+			name:    "principal in the anonymous task's session",
+			ctx:     principalContext(s, "bob", "s3"),
+			visible: []string{"bob-task", "anonymous-task"},
+		},
+		{
+			name:    "unauthenticated in another session",
+			ctx:     principalContext(s, "", "s9"),
+			visible: nil,
+		},
+		{
+			name:    "unauthenticated in the creating session",
+			ctx:     principalContext(s, "", "s3"),
+			visible: []string{"anonymous-task"},
+		},
+		{
+			name:    "admin",
+			ctx:     context.WithValue(principalContext(s, "carol", "s5"), adminKey{}, true),
+			visible: []string{"alice-task", "bob-task", "anonymous-task"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.visible, taskIDs(s.listTasks(tt.ctx)))
+			for _, id := range []string{"alice-task", "bob-task", "anonymous-task"} {
+				_, _, err := s.getTask(tt.ctx, id)
+				if slices.Contains(tt.visible, id) {
+					assert.NoError(t, err, id)
+				} else {
+					assert.ErrorIs(t, err, ErrTaskNotFound, id)
+				}
+			}
+		})
+	}
+
+	err := s.cancelTask(principalContext(s, "bob", "s2"), "alice-task")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	require.NoError(t, s.cancelTask(principalContext(s, "alice", "s9"), "alice-task"))
+	task, _, err := s.getTask(principalContext(s, "alice", "s1"), "alice-task")
+	require.NoError(t, err)
+	assert.Equal(t, mcp.TaskStatusCancelled, task.Status)
+}
+
+func TestWithTaskVisibilityPolicy(t *testing.T) {
+	// Principals of the form "tenant/user" share tasks within a tenant.
+	tenant := func(principal string) string {
+		tenant, _, _ := strings.Cut(principal, "/")
+		return tenant
+	}
+	var calls int
+	s := NewMCPServer("test", "1.0.0", WithTaskOwnership(testPrincipal,
+		WithTaskVisibilityPolicy(func(ctx context.Context, principal string, task mcp.Task, owner TaskOwner) bool {
+			calls++
+			return tenant(principal) == tenant(owner.Principal)
+		}),
+	))
+
+	s.createTask(principalContext(s, "acme/alice", "s1"), "acme-task", nil, nil)
+
+	_, _, err := s.getTask(principalContext(s, "acme/bob", "s2"), "acme-task")
+	assert.NoError(t, err)
+	_, _, err = s.getTask(principalContext(s, "globex/eve", "s3"), "acme-task")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTaskSessionIsolationWithoutOwnership(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	s.createTask(principalContext(s, "alice", "s1"), "task", nil, nil)
+
+	_, _, err := s.getTask(principalContext(s, "alice", "s2"), "task")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	_, _, err = s.getTask(principalContext(s, "bob", "s1"), "task")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrTaskPayloadNotFound is returned when no payload is stored for a task.
+	ErrTaskPayloadNotFound = errors.New("task payload not found")
+
+	// ErrTaskPayloadTooLarge is returned when a payload exceeds the limits of
+	// every tier of a store.
+	ErrTaskPayloadTooLarge = errors.New("task payload too large")
+)
+
+// TaskPayloadStore holds the encoded results of completed tasks until they
+// are retrieved with tasks/result or the task expires.
+//
+// Implementations must be safe for concurrent use.
+type TaskPayloadStore interface {
+	// Put stores the payload for a task, replacing any previous payload.
+	Put(taskID string, payload []byte) error
+	// Get returns the payload for a task, or ErrTaskPayloadNotFound.
+	Get(taskID string) ([]byte, error)
+	// Delete removes the payload for a task. Deleting a missing payload is
+	// not an error.
+	Delete(taskID string) error
+}
+
+// TieredTaskPayloadStore is a TaskPayloadStore that keeps recently used
+// payloads in memory and, once the memory limits are reached, spills the
+// least recently used ones to disk. Without a spill directory, payloads
+// evicted from memory are lost and Get reports ErrTaskPayloadNotFound.
+type TieredTaskPayloadStore struct {
+	mu sync.Mutex
+
+	maxMemoryBytes int64
+	maxEntries     int
+	spillDir       string
+	maxDiskBytes   int64
+
+	// lru orders in-memory payloads from most (front) to least recently used.
+	lru       *list.List
+	memory    map[string]*list.Element
+	memBytes  int64
+	disk      map[string]int64
+	diskBytes int64
+}
+
+type payloadItem struct {
+	taskID  string
+	payload []byte
+}
+
+// TaskPayloadStoreOption configures a TieredTaskPayloadStore.
+type TaskPayloadStoreOption func(*TieredTaskPayloadStore)
+
+// WithPayloadMemoryLimit caps the total size in bytes of payloads kept in
+// memory. Zero means no limit.
+func WithPayloadMemoryLimit(bytes int64) TaskPayloadStoreOption {
+	return func(s *TieredTaskPayloadStore) {
+		s.maxMemoryBytes = bytes
+	}
+}
+
+// WithPayloadMaxEntries caps the number of payloads kept in memory.
+// Zero means no limit.
+func WithPayloadMaxEntries(n int) TaskPayloadStoreOption {
+	return func(s *TieredTaskPayloadStore) {
+		s.maxEntries = n
+	}
+}
+
+// WithPayloadSpillDir enables spilling payloads evicted from memory to files
+// in dir. The directory is created if it does not exist.
+func WithPayloadSpillDir(dir string) TaskPayloadStoreOption {
+	return func(s *TieredTaskPayloadStore) {
+		s.spillDir = dir
+	}
+}
+
+// WithPayloadDiskLimit caps the total size in bytes of spilled payloads.
+// When a spill would exceed it, the payload is dropped instead.
+// Zero means no limit.
+func WithPayloadDiskLimit(bytes int64) TaskPayloadStoreOption {
+	return func(s *TieredTaskPayloadStore) {
+		s.maxDiskBytes = bytes
+	}
+}
+
+// NewTieredTaskPayloadStore creates a TieredTaskPayloadStore. With no
+// options all payloads are kept in memory.
+func NewTieredTaskPayloadStore(opts ...TaskPayloadStoreOption) (*TieredTaskPayloadStore, error) {
+	s := &TieredTaskPayloadStore{
+		lru:    list.New(),
+		memory: make(map[string]*list.Element),
+		disk:   make(map[string]int64),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.spillDir != "" {
+		if err := os.MkdirAll(s.spillDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create spill directory: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Put implements TaskPayloadStore.
+func (s *TieredTaskPayloadStore) Put(taskID string, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(taskID)
+
+	size := int64(len(payload))
+	if s.maxMemoryBytes > 0 && size > s.maxMemoryBytes {
+		// The payload can never fit in memory, so go straight to disk.
+		return s.spill(taskID, payload)
+	}
+
+	s.memory[taskID] = s.lru.PushFront(&payloadItem{taskID: taskID, payload: payload})
+	s.memBytes += size
+	s.evict()
+	return nil
+}
+
+// Get implements TaskPayloadStore. Payloads read back from disk are promoted
+// to the memory tier.
+func (s *TieredTaskPayloadStore) Get(taskID string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.memory[taskID]; ok {
+		s.lru.MoveToFront(elem)
+		return elem.Value.(*payloadItem).payload, nil
+	}
+
+	if _, ok := s.disk[taskID]; !ok {
+		return nil, ErrTaskPayloadNotFound
+	}
+	payload, err := os.ReadFile(s.spillPath(taskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spilled task payload: %w", err)
+	}
+
+	size := int64(len(payload))
+	if s.maxMemoryBytes > 0 && size > s.maxMemoryBytes {
+		return payload, nil
+	}
+	s.removeFromDisk(taskID)
+	s.memory[taskID] = s.lru.PushFront(&payloadItem{taskID: taskID, payload: payload})
+	s.memBytes += size
+	s.evict()
+	return payload, nil
+}
+
+// Delete implements TaskPayloadStore.
+func (s *TieredTaskPayloadStore) Delete(taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(taskID)
+	return nil
+}
+
+// Len returns the number of payloads held in memory and on disk.
+func (s *TieredTaskPayloadStore) Len() (inMemory, onDisk int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.memory), len(s.disk)
+}
+
+// evict moves least recently used payloads out of memory until the memory
+// limits are satisfied. Must be called with s.mu held.
+func (s *TieredTaskPayloadStore) evict() {
+	for s.overMemoryLimit() {
+		back := s.lru.Back()
+		if back == nil {
+			return
+		}
+		item := back.Value.(*payloadItem)
+		s.lru.Remove(back)
+		delete(s.memory, item.taskID)
+		s.memBytes -= int64(len(item.payload))
+
+		// A failed spill only loses this payload; the store stays usable.
+		_ = s.spill(item.taskID, item.payload)
+	}
+}
+
+func (s *TieredTaskPayloadStore) overMemoryLimit() bool {
+	if s.maxEntries > 0 && len(s.memory) > s.maxEntries {
+		return true
+	}
+	return s.maxMemoryBytes > 0 && s.memBytes > s.maxMemoryBytes
+}
+
+// spill writes a payload to the disk tier. Must be called with s.mu held.
+func (s *TieredTaskPayloadStore) spill(taskID string, payload []byte) error {
+	size := int64(len(payload))
+	if s.spillDir == "" || (s.maxDiskBytes > 0 && s.diskBytes+size > s.maxDiskBytes) {
+		return fmt.Errorf("%w: %d bytes for task %s", ErrTaskPayloadTooLarge, size, taskID)
+	}
+	if err := os.WriteFile(s.spillPath(taskID), payload, 0o600); err != nil {
+		return fmt.Errorf("failed to spill task payload: %w", err)
+	}
+	s.disk[taskID] = size
+	s.diskBytes += size
+	return nil
+}
+
+// remove drops a payload from both tiers. Must be called with s.mu held.
+func (s *TieredTaskPayloadStore) remove(taskID string) {
+	if elem, ok := s.memory[taskID]; ok {
+		s.lru.Remove(elem)
+		delete(s.memory, taskID)
+		s.memBytes -= int64(len(elem.Value.(*payloadItem).payload))
+	}
+	s.removeFromDisk(taskID)
+}
+
+func (s *TieredTaskPayloadStore) removeFromDisk(taskID string) {
+	if size, ok := s.disk[taskID]; ok {
+		_ = os.Remove(s.spillPath(taskID))
+		delete(s.disk, taskID)
+		s.diskBytes -= size
+	}
+}
+
+// spillPath returns the file for a task's payload. Task IDs are chosen by
+// the server, but are escaped anyway so they can never leave spillDir.
+func (s *TieredTaskPayloadStore) spillPath(taskID string) string {
+	return filepath.Join(s.spillDir, fmt.Sprintf("%x.json", taskID))
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredTaskPayloadStore_InMemory(t *testing.T) {
+	store, err := NewTieredTaskPayloadStore()
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("task-1", []byte(`{"a":1}`)))
+	payload, err := store.Get("task-1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(payload))
+
+	require.NoError(t, store.Put("task-1", []byte(`{"a":2}`)))
+	payload, err = store.Get("task-1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2}`, string(payload))
+
+	require.NoError(t, store.Delete("task-1"))
+	_, err = store.Get("task-1")
+	assert.ErrorIs(t, err, ErrTaskPayloadNotFound)
+	require.NoError(t, store.Delete("task-1"), "deleting a missing payload is not an error")
+}
+
+func TestTieredTaskPayloadStore_EvictionWithoutSpill(t *testing.T) {
+	store, err := NewTieredTaskPayloadStore(WithPayloadMaxEntries(2))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("task-1", []byte(`1`)))
+	require.NoError(t, store.Put("task-2", []byte(`2`)))
+	// Touch task-1 so task-2 becomes the least recently used.
+	_, err = store.Get("task-1")
+	require.NoError(t, err)
+	require.NoError(t, store.Put("task-3", []byte(`3`)))
+
+	_, err = store.Get("task-2")
+	assert.ErrorIs(t, err, ErrTaskPayloadNotFound)
+	_, err = store.Get("task-1")
+	assert.NoError(t, err)
+	_, err = store.Get("task-3")
+	assert.NoError(t, err)
+}
+
+func TestTieredTaskPayloadStore_SpillToDisk(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewTieredTaskPayloadStore(
+		WithPayloadMemoryLimit(10),
+		WithPayloadSpillDir(dir),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("task-1", []byte(`"aaaaaa"`)))
+	require.NoError(t, store.Put("task-2", []byte(`"bbbbbb"`)))
+
+	inMemory, onDisk := store.Len()
+	assert.Equal(t, 1, inMemory)
+	assert.Equal(t, 1, onDisk)
+
+	// Reading the spilled payload promotes it and spills the other one.
+	payload, err := store.Get("task-1")
+	require.NoError(t, err)
+	assert.Equal(t, `"aaaaaa"`, string(payload))
+	inMemory, onDisk = store.Len()
+	assert.Equal(t, 1, inMemory)
+	assert.Equal(t, 1, onDisk)
+
+	payload, err = store.Get("task-2")
+	require.NoError(t, err)
+	assert.Equal(t, `"bbbbbb"`, string(payload))
+
+	// Payloads larger than the memory limit go straight to disk.
+	require.NoError(t, store.Put("big", []byte(`"this is larger than ten bytes"`)))
+	payload, err = store.Get("big")
+	require.NoError(t, err)
+	assert.Equal(t, `"this is larger than ten bytes"`, string(payload))
+
+	require.NoError(t, store.Delete("big"))
+	require.NoError(t, store.Delete("task-1"))
+	require.NoError(t, store.Delete("task-2"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "deleted payloads should be removed from disk")
+}
+
+func TestTieredTaskPayloadStore_DiskLimit(t *testing.T) {
+	store, err := NewTieredTaskPayloadStore(
+		WithPayloadMemoryLimit(4),
+		WithPayloadSpillDir(t.TempDir()),
+		WithPayloadDiskLimit(8),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("task-1", []byte(`"aaaaaa"`)))
+	err = store.Put("task-2", []byte(`"bbbbbb"`))
+	assert.ErrorIs(t, err, ErrTaskPayloadTooLarge)
+
+	_, err = store.Get("task-2")
+	assert.ErrorIs(t, err, ErrTaskPayloadNotFound)
+}
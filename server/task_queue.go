@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultTaskQueuePollInterval is how often a server created with
+// WithTaskQueue polls the queue for the outcome of each running task,
+// unless set otherwise.
+const DefaultTaskQueuePollInterval = time.Second
+
+// TaskJobState is the state of a job in a TaskQueue.
+type TaskJobState string
+
+const (
+	// TaskJobPending jobs wait for a worker.
+	TaskJobPending TaskJobState = "pending"
+	// TaskJobRunning jobs are claimed by a worker until their lease ends.
+	TaskJobRunning TaskJobState = "running"
+	// TaskJobCompleted jobs returned a result.
+	TaskJobCompleted TaskJobState = "completed"
+	// TaskJobFailed jobs returned an error.
+	TaskJobFailed TaskJobState = "failed"
+	// TaskJobCancelled jobs were cancelled before they finished.
+	TaskJobCancelled TaskJobState = "cancelled"
+)
+
+// IsFinal reports whether jobs in the state are done.
+func (s TaskJobState) IsFinal() bool {
+	return s == TaskJobCompleted || s == TaskJobFailed || s == TaskJobCancelled
+}
+
+// TaskJob describes the tool call of a task, for a TaskWorkerPool to run.
+type TaskJob struct {
+	// ID is the ID of the task.
+	ID string `json:"id"`
+	// Tool is the name of the tool to call.
+	Tool string `json:"tool"`
+	// Arguments are the JSON-encoded arguments of the call.
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// Principal and SessionID identify the owner of the task.
+	Principal string `json:"principal,omitempty"`
+	SessionID string `json:"sessionId,omitempty"`
+	// EnqueuedAt is when the task was created.
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	// Attempts counts the claims of the job, including the current one.
+	Attempts int `json:"attempts"`
+}
+
+// TaskJobOutcome is what a worker reports for a job it ran.
+type TaskJobOutcome struct {
+	// Result is the JSON-encoded mcp.CallToolResult of a successful call.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error describes why the call failed. Jobs with an error failed.
+	Error string `json:"error,omitempty"`
+}
+
+// TaskJobStatus is the current state of a job.
+type TaskJobStatus struct {
+	State TaskJobState `json:"state"`
+	// Worker is the worker that claimed the job last.
+	Worker   string `json:"worker,omitempty"`
+	Attempts int    `json:"attempts"`
+	// Result and Error are set once the job completed or failed.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// TaskQueue holds the jobs of the tasks of a server created with
+// WithTaskQueue until a TaskWorkerPool, in the same or another process,
+// runs them. Jobs are delivered at least once: a job whose worker does not
+// extend its lease in time is claimed again.
+//
+// Implementations must be safe for concurrent use. MemoryTaskQueue keeps
+// jobs in memory; durable implementations, such as one backed by a SQL
+// database, let jobs survive restarts and be shared between processes.
+type TaskQueue interface {
+	// Enqueue adds a pending job.
+	Enqueue(ctx context.Context, job TaskJob) error
+	// Claim leases the oldest job that is pending, or running with an
+	// expired lease, to worker for lease, and returns it with its Attempts
+	// counting this claim. It returns ErrTaskQueueEmpty if there is none.
+	Claim(ctx context.Context, worker string, lease time.Duration) (TaskJob, error)
+	// Extend renews the lease of worker on a running job. It returns
+	// ErrTaskJobCancelled or ErrTaskJobNotFound if the job was cancelled or
+	// removed meanwhile, and ErrTaskJobLeaseLost if the lease is no longer
+	// the worker's.
+	Extend(ctx context.Context, jobID, worker string, lease time.Duration) error
+	// Finish records the outcome of a job run by worker, with the errors of
+	// Extend.
+	Finish(ctx context.Context, jobID, worker string, outcome TaskJobOutcome) error
+	// Cancel cancels a job that is not done yet. Cancelling a job that is
+	// done or missing is not an error.
+	Cancel(ctx context.Context, jobID string) error
+	// Status returns the state of a job, or ErrTaskJobNotFound.
+	Status(ctx context.Context, jobID string) (TaskJobStatus, error)
+	// Remove deletes a job. Removing a missing job is not an error.
+	Remove(ctx context.Context, jobID string) error
+}
+
+type taskQueue struct {
+	queue        TaskQueue
+	pollInterval time.Duration
+}
+
+// WithTaskQueue runs task-augmented tool calls as jobs of queue instead of
+// in the server process: tools/call enqueues a TaskJob, a TaskWorkerPool
+// with the tool's handler registered runs it, and the server polls the
+// queue every pollInterval, DefaultTaskQueuePollInterval if zero, for the
+// outcome it reports through tasks/get and tasks/result. Cancelling a task
+// cancels its job.
+//
+// Tasks stay in the server's memory as without a queue; a durable queue
+// lets their jobs survive restarts of the workers. Workers run handlers
+// without the client's session or request context: TaskIDFromContext and
+// TaskOwnerFromContext are all they know about the call.
+//
+// The server checks that the tool may be called before enqueuing the job,
+// but workers run the registered handler alone. Tool middlewares, argument
+// and result transformers, the error translator and TouchResource embedding
+// only apply in pools created with WithTaskWorkerServer.
+func WithTaskQueue(queue TaskQueue, pollInterval time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		if pollInterval <= 0 {
+			pollInterval = DefaultTaskQueuePollInterval
+		}
+		s.taskQueue = &taskQueue{queue: queue, pollInterval: pollInterval}
+	}
+}
+
+// enqueueToolCall creates the task of a task-augmented tool call under
+// WithTaskQueue and enqueues its job.
+func (s *MCPServer) enqueueToolCall(
+	ctx context.Context,
+	id any,
+	request mcp.CallToolRequest,
+) (*mcp.CreateTaskResult, *requestError) {
+	arguments, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("failed to encode arguments: %w", err),
+		}
+	}
+
+	ctx = s.withRoutingKey(ctx, request)
+	ctx = context.WithValue(ctx, taskToolCallKey{}, request)
+	pollInterval := s.taskQueue.pollInterval.Milliseconds()
+	entry := s.createTask(ctx, s.newID(IDKindTask), request.Params.Task.TTL, &pollInterval)
+	taskID := entry.task.TaskId
+
+	job := TaskJob{
+		ID:         taskID,
+		Tool:       request.Params.Name,
+		Arguments:  arguments,
+		Principal:  entry.principal,
+		SessionID:  entry.sessionID,
+		EnqueuedAt: s.clock.Now(),
+	}
+	if err := s.taskQueue.queue.Enqueue(ctx, job); err != nil {
+		s.tasksMu.Lock()
+		delete(s.tasks, taskID)
+		s.tasksMu.Unlock()
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  fmt.Errorf("failed to enqueue task %s: %w", taskID, err),
+		}
+	}
+
+	watchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.tasksMu.Lock()
+	entry.cancelFunc = cancel
+	entry.callbackURL = s.taskWebhook.callbackURL(request)
+	task := entry.task
+	s.tasksMu.Unlock()
+
+	go s.watchTaskJob(watchCtx, entry)
+
+	result := mcp.NewCreateTaskResult(task)
+	return &result, nil
+}
+
+// watchTaskJob polls the queue for the outcome of a task's job until the
+// task is done, cancelling the job if the task is cancelled or expires.
+func (s *MCPServer) watchTaskJob(ctx context.Context, entry *taskEntry) {
+	queue := s.taskQueue.queue
+	taskID := entry.task.TaskId
+	background := context.WithoutCancel(ctx)
+	for {
+		if sleepContext(ctx, s.clock, s.taskQueue.pollInterval) != nil || !s.taskTracked(entry) {
+			// The task was cancelled, or expired and was cleaned up.
+			_ = queue.Cancel(background, taskID)
+			_ = queue.Remove(background, taskID)
+			return
+		}
+
+		status, err := queue.Status(ctx, taskID)
+		if errors.Is(err, ErrTaskJobNotFound) {
+			_ = s.completeTask(entry, nil, fmt.Errorf("job of task %s is gone: %w", taskID, err))
+			return
+		}
+		if err != nil || !status.State.IsFinal() {
+			// Retry errors of the queue at the next poll.
+			continue
+		}
+
+		switch status.State {
+		case TaskJobCompleted:
+			var result any
+			if len(status.Result) > 0 {
+				result = status.Result
+			}
+			_ = s.completeTask(entry, result, s.checkTaskJobResult(entry, status.Result))
+		case TaskJobFailed:
+			_ = s.completeTask(entry, nil, errors.New(status.Error))
+		case TaskJobCancelled:
+			_ = s.setTaskStatus(entry, mcp.TaskStatusCancelled, "Task job cancelled", nil)
+		}
+		_ = queue.Remove(background, taskID)
+		return
+	}
+}
+
+// checkTaskJobResult validates the result of a job under
+// WithStrictToolResults.
+func (s *MCPServer) checkTaskJobResult(entry *taskEntry, data json.RawMessage) error {
+	if !s.strictToolResults {
+		return nil
+	}
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("task %s returned an invalid result: %w", entry.task.TaskId, err)
+	}
+	request, _ := TaskToolCallFromContext(entry.ctx)
+	return s.checkToolResult(request.Params.Name, &result)
+}
+
+// taskTracked reports whether entry is still a task of the server.
+func (s *MCPServer) taskTracked(entry *taskEntry) bool {
+	s.tasksMu.RLock()
+	defer s.tasksMu.RUnlock()
+	return s.tasks[entry.task.TaskId] == entry
+}
+
+// MemoryTaskQueue is a TaskQueue that keeps jobs in memory, for workers in
+// the server's process. Jobs are lost when the process exits.
+type MemoryTaskQueue struct {
+	clock Clock
+
+	mu    sync.Mutex
+	jobs  map[string]*memoryTaskJob
+	order []string // IDs of the jobs that are not done, oldest first
+}
+
+type memoryTaskJob struct {
+	job        TaskJob
+	status     TaskJobStatus
+	leaseUntil time.Time
+}
+
+// NewMemoryTaskQueue returns an empty MemoryTaskQueue that expires leases
+// on clock, or the system clock if clock is nil.
+func NewMemoryTaskQueue(clock Clock) *MemoryTaskQueue {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &MemoryTaskQueue{clock: clock, jobs: make(map[string]*memoryTaskJob)}
+}
+
+// Enqueue implements TaskQueue.
+func (q *MemoryTaskQueue) Enqueue(ctx context.Context, job TaskJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, exists := q.jobs[job.ID]; exists {
+		return fmt.Errorf("job %s already enqueued", job.ID)
+	}
+	job.Attempts = 0
+	q.jobs[job.ID] = &memoryTaskJob{job: job, status: TaskJobStatus{State: TaskJobPending}}
+	q.order = append(q.order, job.ID)
+	return nil
+}
+
+// Claim implements TaskQueue.
+func (q *MemoryTaskQueue) Claim(ctx context.Context, worker string, lease time.Duration) (TaskJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.clock.Now()
+	for _, id := range q.order {
+		j := q.jobs[id]
+		if j.status.State == TaskJobRunning && now.Before(j.leaseUntil) {
+			continue
+		}
+		j.job.Attempts++
+		j.status = TaskJobStatus{State: TaskJobRunning, Worker: worker, Attempts: j.job.Attempts}
+		j.leaseUntil = now.Add(lease)
+		return j.job, nil
+	}
+	return TaskJob{}, ErrTaskQueueEmpty
+}
+
+// Extend implements TaskQueue.
+func (q *MemoryTaskQueue) Extend(ctx context.Context, jobID, worker string, lease time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, err := q.leased(jobID, worker)
+	if err != nil {
+		return err
+	}
+	j.leaseUntil = q.clock.Now().Add(lease)
+	return nil
+}
+
+// Finish implements TaskQueue.
+func (q *MemoryTaskQueue) Finish(ctx context.Context, jobID, worker string, outcome TaskJobOutcome) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, err := q.leased(jobID, worker)
+	if err != nil {
+		return err
+	}
+	j.status.State = TaskJobCompleted
+	if outcome.Error != "" {
+		j.status.State = TaskJobFailed
+	}
+	j.status.Result = outcome.Result
+	j.status.Error = outcome.Error
+	q.order = slices.DeleteFunc(q.order, func(id string) bool { return id == jobID })
+	return nil
+}
+
+// leased returns a running job whose lease is still worker's.
+// Must be called with q.mu held.
+func (q *MemoryTaskQueue) leased(jobID, worker string) (*memoryTaskJob, error) {
+	j, ok := q.jobs[jobID]
+	if !ok {
+		return nil, ErrTaskJobNotFound
+	}
+	if j.status.State == TaskJobCancelled {
+		return nil, ErrTaskJobCancelled
+	}
+	if j.status.State != TaskJobRunning || j.status.Worker != worker || !q.clock.Now().Before(j.leaseUntil) {
+		return nil, ErrTaskJobLeaseLost
+	}
+	return j, nil
+}
+
+// Cancel implements TaskQueue.
+func (q *MemoryTaskQueue) Cancel(ctx context.Context, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[jobID]
+	if ok && !j.status.State.IsFinal() {
+		j.status.State = TaskJobCancelled
+		q.order = slices.DeleteFunc(q.order, func(id string) bool { return id == jobID })
+	}
+	return nil
+}
+
+// Status implements TaskQueue.
+func (q *MemoryTaskQueue) Status(ctx context.Context, jobID string) (TaskJobStatus, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[jobID]
+	if !ok {
+		return TaskJobStatus{}, ErrTaskJobNotFound
+	}
+	return j.status, nil
+}
+
+// Remove implements TaskQueue.
+func (q *MemoryTaskQueue) Remove(ctx context.Context, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.jobs, jobID)
+	q.order = slices.DeleteFunc(q.order, func(id string) bool { return id == jobID })
+	return nil
+}
+
+var _ TaskQueue = (*MemoryTaskQueue)(nil)
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMemoryTaskQueue(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_700_000_000, 0)}
+	queue := NewMemoryTaskQueue(clock)
+	ctx := context.Background()
+
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "a", Tool: "build"}))
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "b", Tool: "build"}))
+	assert.Error(t, queue.Enqueue(ctx, TaskJob{ID: "a"}), "duplicate IDs must be rejected")
+
+	job, err := queue.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "a", job.ID)
+	assert.Equal(t, 1, job.Attempts)
+	job, err = queue.Claim(ctx, "w2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", job.ID)
+	_, err = queue.Claim(ctx, "w3", time.Minute)
+	assert.ErrorIs(t, err, ErrTaskQueueEmpty)
+
+	// w1 keeps its lease, w2 loses it to w3.
+	clock.advance(45 * time.Second)
+	require.NoError(t, queue.Extend(ctx, "a", "w1", time.Minute))
+	clock.advance(30 * time.Second)
+	job, err = queue.Claim(ctx, "w3", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", job.ID)
+	assert.Equal(t, 2, job.Attempts)
+	assert.ErrorIs(t, queue.Extend(ctx, "b", "w2", time.Minute), ErrTaskJobLeaseLost)
+	assert.ErrorIs(t, queue.Finish(ctx, "b", "w2", TaskJobOutcome{}), ErrTaskJobLeaseLost)
+
+	require.NoError(t, queue.Finish(ctx, "a", "w1", TaskJobOutcome{Result: json.RawMessage(`{"content":[]}`)}))
+	require.NoError(t, queue.Finish(ctx, "b", "w3", TaskJobOutcome{Error: "boom"}))
+
+	status, err := queue.Status(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, TaskJobStatus{State: TaskJobCompleted, Worker: "w1", Attempts: 1, Result: json.RawMessage(`{"content":[]}`)}, status)
+	status, err = queue.Status(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, TaskJobFailed, status.State)
+	assert.Equal(t, "boom", status.Error)
+
+	// Cancelling a done job changes nothing.
+	require.NoError(t, queue.Cancel(ctx, "a"))
+	status, _ = queue.Status(ctx, "a")
+	assert.Equal(t, TaskJobCompleted, status.State)
+
+	require.NoError(t, queue.Remove(ctx, "a"))
+	_, err = queue.Status(ctx, "a")
+	assert.ErrorIs(t, err, ErrTaskJobNotFound)
+	assert.NoError(t, queue.Cancel(ctx, "a"))
+}
+
+func TestMemoryTaskQueue_Cancel(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	ctx := context.Background()
+
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "pending"}))
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "running"}))
+	require.NoError(t, queue.Cancel(ctx, "pending"))
+	job, err := queue.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "running", job.ID, "cancelled jobs must not be claimed")
+
+	require.NoError(t, queue.Cancel(ctx, "running"))
+	assert.ErrorIs(t, queue.Extend(ctx, "running", "w1", time.Minute), ErrTaskJobCancelled)
+	assert.ErrorIs(t, queue.Finish(ctx, "running", "w1", TaskJobOutcome{}), ErrTaskJobCancelled)
+	require.NoError(t, queue.Remove(ctx, "running"))
+	assert.ErrorIs(t, queue.Extend(ctx, "running", "w1", time.Minute), ErrTaskJobNotFound)
+}
+
+// failingTaskQueue rejects every job.
+type failingTaskQueue struct{ *MemoryTaskQueue }
+
+func (failingTaskQueue) Enqueue(context.Context, TaskJob) error {
+	return errors.New("queue unavailable")
+}
+
+func newQueuedTaskServer(t *testing.T, queue TaskQueue) *MCPServer {
+	t.Helper()
+	s := NewMCPServer("test-server", "1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+		WithTaskQueue(queue, 5*time.Millisecond),
+	)
+	s.AddTool(mcp.NewTool("build"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.Error("queued tasks must not run in the server")
+		return nil, nil
+	})
+	return s
+}
+
+func createQueuedTask(t *testing.T, s *MCPServer, arguments string) mcp.Task {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "build", "arguments": `+arguments+`, "task": {"ttl": 60000}}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %#v", response)
+	created, ok := resp.Result.(mcp.CreateTaskResult)
+	require.True(t, ok, "Expected CreateTaskResult, got %T", resp.Result)
+	return created.Task
+}
+
+func TestWithTaskQueue(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	s := newQueuedTaskServer(t, queue)
+
+	task := createQueuedTask(t, s, `{"target": "release"}`)
+	assert.Equal(t, mcp.TaskStatusWorking, task.Status)
+	require.NotNil(t, task.PollInterval)
+	assert.Equal(t, int64(5), *task.PollInterval)
+
+	pool := NewTaskWorkerPool(queue, WithTaskWorkers(2), WithTaskWorkerPollInterval(time.Millisecond))
+	pool.Register("build", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("built " + request.GetString("target", "") + " as " + TaskIDFromContext(ctx)), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- pool.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.ErrorIs(t, <-stopped, context.Canceled)
+	}()
+
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tasks/result",
+		"params": {"taskId": "`+task.TaskId+`"}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %#v", response)
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+	var result mcp.CallToolResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "built release as "+task.TaskId, result.Content[0].(mcp.TextContent).Text)
+
+	// The job is removed once the task has its outcome.
+	_, err = queue.Status(context.Background(), task.TaskId)
+	assert.ErrorIs(t, err, ErrTaskJobNotFound)
+}
+
+func TestWithTaskQueue_Failed(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	s := newQueuedTaskServer(t, queue)
+	task := createQueuedTask(t, s, `{}`)
+
+	job, err := queue.Claim(context.Background(), "w1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, queue.Finish(context.Background(), job.ID, "w1", TaskJobOutcome{Error: "compiler crashed"}))
+
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tasks/result",
+		"params": {"taskId": "`+task.TaskId+`"}
+	}`))
+	errResp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "Expected JSONRPCError, got %#v", response)
+	assert.Contains(t, errResp.Error.Message, "compiler crashed")
+}
+
+func TestWithTaskQueue_Cancel(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	s := newQueuedTaskServer(t, queue)
+	task := createQueuedTask(t, s, `{}`)
+
+	_, err := queue.Claim(context.Background(), "w1", time.Minute)
+	require.NoError(t, err)
+
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tasks/cancel",
+		"params": {"taskId": "`+task.TaskId+`"}
+	}`))
+	_, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %#v", response)
+
+	require.Eventually(t, func() bool {
+		err := queue.Extend(context.Background(), task.TaskId, "w1", time.Minute)
+		return errors.Is(err, ErrTaskJobCancelled) || errors.Is(err, ErrTaskJobNotFound)
+	}, time.Second, time.Millisecond, "the job must be cancelled with its task")
+}
+
+func TestWithTaskQueue_EnqueueFails(t *testing.T) {
+	s := newQueuedTaskServer(t, failingTaskQueue{NewMemoryTaskQueue(nil)})
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "build", "task": {}}
+	}`))
+	errResp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "Expected JSONRPCError, got %#v", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errResp.Error.Code)
+	assert.Contains(t, errResp.Error.Message, "queue unavailable")
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tasks/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %#v", response)
+	assert.Empty(t, resp.Result.(mcp.ListTasksResult).Tasks)
+}
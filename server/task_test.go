@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -125,7 +126,9 @@ func TestMCPServer_TaskLifecycle(t *testing.T) {
 	server.completeTask(entry, result, nil)
 
 	assert.Equal(t, mcp.TaskStatusCompleted, entry.task.Status)
-	assert.Equal(t, result, entry.result)
+	payload, err := server.taskPayloads.Get("task-123")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"result":"success"}`, string(payload))
 	assert.Nil(t, entry.resultErr)
 
 	// Verify channel is closed
@@ -541,3 +544,174 @@ func TestMCPServer_TaskJSONMarshaling(t *testing.T) {
 	assert.Equal(t, task.Status, unmarshaled.Status)
 	assert.Equal(t, task.StatusMessage, unmarshaled.StatusMessage)
 }
+
+func TestMCPServer_TaskAugmentedToolCall(t *testing.T) {
+	store, err := NewTieredTaskPayloadStore(WithPayloadMaxEntries(1), WithPayloadSpillDir(t.TempDir()))
+	require.NoError(t, err)
+
+	server := NewMCPServer(
+		"test-server",
+		"1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+		WithTaskPayloadStore(store),
+	)
+
+	release := make(chan struct{})
+	server.AddTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-release
+		return mcp.NewToolResultText("ran as " + TaskIDFromContext(ctx)), nil
+	})
+
+	ctx := context.Background()
+	response := server.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "slow", "task": {"ttl": 60000}}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	created, ok := resp.Result.(mcp.CreateTaskResult)
+	require.True(t, ok, "Expected CreateTaskResult, got %T", resp.Result)
+	assert.Equal(t, mcp.TaskStatusWorking, created.Task.Status)
+	require.NotNil(t, created.Task.TTL)
+	assert.Equal(t, int64(60000), *created.Task.TTL)
+	taskID := created.Task.TaskId
+
+	close(release)
+
+	response = server.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tasks/result",
+		"params": {"taskId": "`+taskID+`"}
+	}`))
+	resp, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+	var toolResult mcp.CallToolResult
+	require.NoError(t, json.Unmarshal(data, &toolResult))
+	require.Len(t, toolResult.Content, 1)
+	assert.Equal(t, "ran as "+taskID, toolResult.Content[0].(mcp.TextContent).Text)
+	require.NotNil(t, toolResult.Meta)
+	assert.Equal(t, map[string]any{"taskId": taskID}, toolResult.Meta.AdditionalFields[mcp.RelatedTaskMetaKey])
+
+	task, _, err := server.getTask(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, mcp.TaskStatusCompleted, task.Status)
+}
+
+func TestMCPServer_TaskAugmentedToolCallWithoutTaskSupport(t *testing.T) {
+	server := NewMCPServer(
+		"test-server",
+		"1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, false),
+	)
+	server.AddTool(mcp.NewTool("quick"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	// Without tool call task support the task parameters are ignored.
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "quick", "task": {}}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	_, ok = resp.Result.(mcp.CallToolResult)
+	assert.True(t, ok, "Expected CallToolResult, got %T", resp.Result)
+	assert.Empty(t, server.listTasks(context.Background()))
+}
+
+func TestMCPServer_TaskResultPayloadEvicted(t *testing.T) {
+	store, err := NewTieredTaskPayloadStore(WithPayloadMaxEntries(1))
+	require.NoError(t, err)
+	server := NewMCPServer("test-server", "1.0.0",
+		WithTaskCapabilities(true, true, true),
+		WithTaskPayloadStore(store),
+	)
+
+	ctx := context.Background()
+	first := server.createTask(ctx, "task-1", nil, nil)
+	server.completeTask(first, mcp.NewToolResultText("one"), nil)
+	second := server.createTask(ctx, "task-2", nil, nil)
+	server.completeTask(second, mcp.NewToolResultText("two"), nil)
+
+	_, reqErr := server.handleTaskResult(ctx, 1, mcp.TaskResultRequest{
+		Params: mcp.TaskResultParams{TaskId: "task-1"},
+	})
+	require.NotNil(t, reqErr)
+	assert.ErrorIs(t, reqErr.err, ErrTaskPayloadNotFound)
+
+	result, reqErr := server.handleTaskResult(ctx, 2, mcp.TaskResultRequest{
+		Params: mcp.TaskResultParams{TaskId: "task-2"},
+	})
+	require.Nil(t, reqErr)
+	assert.Contains(t, string(result.Payload), "two")
+}
+
+func TestMCPServer_TaskTransitionEnforcement(t *testing.T) {
+	server := NewMCPServer(
+		"test-server",
+		"1.0.0",
+		WithTaskCapabilities(true, true, true),
+	)
+	ctx := context.Background()
+
+	t.Run("completing a cancelled task", func(t *testing.T) {
+		entry := server.createTask(ctx, "task-cancelled", nil, nil)
+		require.NoError(t, server.cancelTask(ctx, "task-cancelled"))
+
+		err := server.completeTask(entry, mcp.NewToolResultText("late"), nil)
+		assert.ErrorIs(t, err, ErrInvalidTaskTransition)
+		assert.Equal(t, mcp.TaskStatusCancelled, entry.task.Status)
+
+		_, err = server.taskPayloads.Get("task-cancelled")
+		assert.ErrorIs(t, err, ErrTaskPayloadNotFound, "late results should not be stored")
+	})
+
+	t.Run("failing a completed task", func(t *testing.T) {
+		entry := server.createTask(ctx, "task-done", nil, nil)
+		require.NoError(t, server.completeTask(entry, mcp.NewToolResultText("done"), nil))
+
+		err := server.completeTask(entry, nil, errors.New("boom"))
+		assert.ErrorIs(t, err, ErrInvalidTaskTransition)
+		assert.Equal(t, mcp.TaskStatusCompleted, entry.task.Status)
+		assert.Nil(t, entry.resultErr)
+	})
+
+	t.Run("cancelling a failed task", func(t *testing.T) {
+		entry := server.createTask(ctx, "task-failed", nil, nil)
+		require.NoError(t, server.completeTask(entry, nil, errors.New("boom")))
+
+		err := server.cancelTask(ctx, "task-failed")
+		assert.ErrorIs(t, err, ErrInvalidTaskTransition)
+		assert.Equal(t, mcp.TaskStatusFailed, entry.task.Status)
+	})
+
+	t.Run("input required and back", func(t *testing.T) {
+		entry := server.createTask(ctx, "task-input", nil, nil)
+
+		server.tasksMu.Lock()
+		require.NoError(t, server.transitionTask(entry, mcp.TaskStatusInputRequired, "waiting for user"))
+		err := server.transitionTask(entry, mcp.TaskStatusInputRequired, "")
+		require.NoError(t, server.transitionTask(entry, mcp.TaskStatusWorking, ""))
+		server.tasksMu.Unlock()
+
+		assert.ErrorIs(t, err, ErrInvalidTaskTransition, "a status is not a transition to itself")
+		select {
+		case <-entry.done:
+			t.Fatal("non-terminal transitions should not signal completion")
+		default:
+		}
+
+		require.NoError(t, server.completeTask(entry, mcp.NewToolResultText("done"), nil))
+		assert.Equal(t, mcp.TaskStatusCompleted, entry.task.Status)
+	})
+}
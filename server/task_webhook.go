@@ -0,0 +1,277 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// TaskCallbackURLMetaKey is the _meta key of a tools/call request that
+	// asks for the task outcome to be posted to a URL. It is only honored
+	// when the server's TaskWebhook allows request callbacks.
+	TaskCallbackURLMetaKey = "io.github.mark3labs.mcp-go/callback-url"
+
+	// TaskWebhookSignatureHeader carries the hex encoded HMAC-SHA256 of
+	// "<timestamp>.<body>", prefixed with "sha256=".
+	TaskWebhookSignatureHeader = "X-MCP-Signature"
+	// TaskWebhookTimestampHeader carries the Unix time at which the delivery
+	// was signed, so receivers can reject replays.
+	TaskWebhookTimestampHeader = "X-MCP-Timestamp"
+
+	defaultWebhookMaxAttempts    = 5
+	defaultWebhookBackoff        = time.Second
+	defaultWebhookMaxDeadLetters = 100
+)
+
+// TaskWebhookPayload is the JSON body posted when a task reaches a terminal
+// status.
+type TaskWebhookPayload struct {
+	Task      mcp.Task `json:"task"`
+	SessionID string   `json:"sessionId,omitempty"`
+	// Result is the tool result of a completed task.
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// TaskWebhookDelivery is a payload addressed to one URL.
+type TaskWebhookDelivery struct {
+	URL      string
+	Payload  TaskWebhookPayload
+	Attempts int
+	// LastError describes why the final attempt failed.
+	LastError string
+}
+
+// TaskWebhook posts signed task outcomes to a webhook sink and, if allowed,
+// to callback URLs carried by the tools/call requests themselves. Failed
+// deliveries are retried with exponential backoff and then dead-lettered.
+type TaskWebhook struct {
+	url                   string
+	secret                []byte
+	client                *http.Client
+	maxAttempts           int
+	backoff               time.Duration
+	allowRequestCallbacks bool
+	onDeadLetter          func(delivery TaskWebhookDelivery)
+	clock                 Clock
+
+	mu          sync.Mutex
+	deadLetters []TaskWebhookDelivery
+	wg          sync.WaitGroup
+}
+
+// TaskWebhookOption configures a TaskWebhook.
+type TaskWebhookOption func(*TaskWebhook)
+
+// WithWebhookHTTPClient sets the HTTP client used for deliveries.
+func WithWebhookHTTPClient(client *http.Client) TaskWebhookOption {
+	return func(w *TaskWebhook) {
+		w.client = client
+	}
+}
+
+// WithWebhookRetry sets how many times a delivery is attempted and the delay
+// before the first retry, which doubles after every attempt.
+func WithWebhookRetry(maxAttempts int, backoff time.Duration) TaskWebhookOption {
+	return func(w *TaskWebhook) {
+		w.maxAttempts = maxAttempts
+		w.backoff = backoff
+	}
+}
+
+// WithWebhookRequestCallbacks allows tools/call requests to name their own
+// callback URL under TaskCallbackURLMetaKey. Only enable this for trusted
+// clients, since the server will post to any URL they give.
+func WithWebhookRequestCallbacks() TaskWebhookOption {
+	return func(w *TaskWebhook) {
+		w.allowRequestCallbacks = true
+	}
+}
+
+// WithWebhookDeadLetter sets a function called with deliveries that failed
+// every attempt. Without it, the most recent dead letters are kept and can
+// be read with DeadLetters.
+func WithWebhookDeadLetter(fn func(delivery TaskWebhookDelivery)) TaskWebhookOption {
+	return func(w *TaskWebhook) {
+		w.onDeadLetter = fn
+	}
+}
+
+// NewTaskWebhook creates a TaskWebhook posting to url, signing payloads with
+// secret. url may be empty when only request callbacks are used.
+func NewTaskWebhook(url string, secret []byte, opts ...TaskWebhookOption) *TaskWebhook {
+	w := &TaskWebhook{
+		url:         url,
+		secret:      secret,
+		client:      http.DefaultClient,
+		maxAttempts: defaultWebhookMaxAttempts,
+		backoff:     defaultWebhookBackoff,
+		clock:       SystemClock,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WithTaskWebhook posts the outcome of every task that completes, fails or
+// is cancelled through webhook.
+func WithTaskWebhook(webhook *TaskWebhook) ServerOption {
+	return func(s *MCPServer) {
+		s.taskWebhook = webhook
+	}
+}
+
+// DeadLetters returns the deliveries that failed every attempt, if no
+// dead-letter function was configured.
+func (w *TaskWebhook) DeadLetters() []TaskWebhookDelivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]TaskWebhookDelivery(nil), w.deadLetters...)
+}
+
+// Wait blocks until all pending deliveries have succeeded or been
+// dead-lettered.
+func (w *TaskWebhook) Wait() {
+	w.wg.Wait()
+}
+
+// Sign returns the signature of body at timestamp, as sent in
+// TaskWebhookSignatureHeader. Receivers use it to verify deliveries.
+func (w *TaskWebhook) Sign(timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// callbackURL extracts the callback URL from a tools/call request, if the
+// webhook honors them.
+func (w *TaskWebhook) callbackURL(request mcp.CallToolRequest) string {
+	if w == nil || !w.allowRequestCallbacks || request.Params.Meta == nil {
+		return ""
+	}
+	url, _ := request.Params.Meta.AdditionalFields[TaskCallbackURLMetaKey].(string)
+	return url
+}
+
+// notify delivers payload to the sink and the callback URL in the
+// background. If result is not nil, it is called first, in the background
+// too, to fill in the payload's result. Wait covers the deliveries as soon as
+// notify returns.
+func (w *TaskWebhook) notify(payload TaskWebhookPayload, result func() (json.RawMessage, bool), callbackURL string) {
+	var urls []string
+	for _, url := range []string{w.url, callbackURL} {
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+	w.wg.Add(len(urls))
+	go func() {
+		if result != nil {
+			if value, ok := result(); ok {
+				payload.Result = value
+			}
+		}
+		for _, url := range urls {
+			go func(url string) {
+				defer w.wg.Done()
+				w.deliver(TaskWebhookDelivery{URL: url, Payload: payload})
+			}(url)
+		}
+	}()
+}
+
+func (w *TaskWebhook) deliver(delivery TaskWebhookDelivery) {
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		delivery.LastError = err.Error()
+		w.deadLetter(delivery)
+		return
+	}
+
+	backoff := w.backoff
+	for delivery.Attempts < max(w.maxAttempts, 1) {
+		if delivery.Attempts > 0 {
+			_ = sleepContext(context.Background(), w.clock, backoff)
+			backoff *= 2
+		}
+		delivery.Attempts++
+		if err = w.post(delivery.URL, body); err == nil {
+			return
+		}
+		delivery.LastError = err.Error()
+	}
+	w.deadLetter(delivery)
+}
+
+func (w *TaskWebhook) post(url string, body []byte) error {
+	ctx, cancel := contextWithTimeout(context.Background(), w.clock, 30*time.Second)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := w.clock.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(TaskWebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(TaskWebhookSignatureHeader, w.Sign(timestamp, body))
+
+	resp, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *TaskWebhook) deadLetter(delivery TaskWebhookDelivery) {
+	if w.onDeadLetter != nil {
+		w.onDeadLetter(delivery)
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deadLetters = append(w.deadLetters, delivery)
+	if len(w.deadLetters) > defaultWebhookMaxDeadLetters {
+		w.deadLetters = w.deadLetters[len(w.deadLetters)-defaultWebhookMaxDeadLetters:]
+	}
+}
+
+// notifyTaskWebhook posts the outcome of a task that reached a terminal
+// status to the configured webhook, if any. It is called with s.tasksMu
+// held, so the result, which may have to be read from disk, is only fetched
+// by the delivery.
+func (s *MCPServer) notifyTaskWebhook(entry *taskEntry, task mcp.Task) {
+	if s.taskWebhook == nil || !task.Status.IsTerminal() {
+		return
+	}
+
+	payload := TaskWebhookPayload{Task: task, SessionID: entry.sessionID}
+	var result func() (json.RawMessage, bool)
+	if task.Status == mcp.TaskStatusCompleted {
+		result = func() (json.RawMessage, bool) {
+			value, err := s.taskPayloads.Get(task.TaskId)
+			return value, err == nil
+		}
+	}
+	s.taskWebhook.notify(payload, result, entry.callbackURL)
+}
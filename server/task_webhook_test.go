@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRecorder is a webhook endpoint that fails the first failures
+// deliveries and records the rest.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	failures int32
+	calls    atomic.Int32
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.calls.Add(1) <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	r.mu.Unlock()
+}
+
+func TestTaskWebhook_DeliversSignedPayload(t *testing.T) {
+	recorder := &webhookRecorder{failures: 1}
+	sink := httptest.NewServer(recorder)
+	defer sink.Close()
+
+	webhook := NewTaskWebhook(sink.URL, []byte("secret"), WithWebhookRetry(3, time.Millisecond))
+	s := NewMCPServer("test", "1.0.0",
+		WithTaskCapabilities(true, true, true),
+		WithTaskWebhook(webhook),
+	)
+
+	entry := s.createTask(context.Background(), "task-1", nil, nil)
+	require.NoError(t, s.completeTask(entry, mcp.NewToolResultText("done"), nil))
+	webhook.Wait()
+
+	assert.Equal(t, int32(2), recorder.calls.Load(), "the failed delivery should be retried")
+	require.Len(t, recorder.bodies, 1)
+
+	var payload TaskWebhookPayload
+	require.NoError(t, json.Unmarshal(recorder.bodies[0], &payload))
+	assert.Equal(t, "task-1", payload.Task.TaskId)
+	assert.Equal(t, mcp.TaskStatusCompleted, payload.Task.Status)
+	assert.Contains(t, string(payload.Result), "done")
+
+	timestamp, err := strconv.ParseInt(recorder.headers[0].Get(TaskWebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.Sign(timestamp, recorder.bodies[0]), recorder.headers[0].Get(TaskWebhookSignatureHeader))
+	assert.NotEqual(t, NewTaskWebhook("", []byte("other")).Sign(timestamp, recorder.bodies[0]), recorder.headers[0].Get(TaskWebhookSignatureHeader))
+}
+
+// gatedPayloadStore is a TaskPayloadStore whose Get waits for release, like
+// a read of a payload spilled to a slow disk.
+type gatedPayloadStore struct {
+	mu       sync.Mutex
+	payloads map[string][]byte
+	release  chan struct{}
+}
+
+func (g *gatedPayloadStore) Put(taskID string, payload []byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.payloads[taskID] = payload
+	return nil
+}
+
+func (g *gatedPayloadStore) Get(taskID string) ([]byte, error) {
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	payload, ok := g.payloads[taskID]
+	if !ok {
+		return nil, ErrTaskPayloadNotFound
+	}
+	return payload, nil
+}
+
+func (g *gatedPayloadStore) Delete(taskID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.payloads, taskID)
+	return nil
+}
+
+func TestTaskWebhook_ReadsResultOutsideTaskLock(t *testing.T) {
+	recorder := &webhookRecorder{}
+	sink := httptest.NewServer(recorder)
+	defer sink.Close()
+
+	store := &gatedPayloadStore{payloads: make(map[string][]byte), release: make(chan struct{})}
+	webhook := NewTaskWebhook(sink.URL, []byte("secret"))
+	s := NewMCPServer("test", "1.0.0",
+		WithTaskCapabilities(true, true, true),
+		WithTaskWebhook(webhook),
+		WithTaskPayloadStore(store),
+	)
+
+	entry := s.createTask(context.Background(), "task-1", nil, nil)
+	require.NoError(t, s.completeTask(entry, mcp.NewToolResultText("done"), nil))
+	// Tasks stay readable while the webhook waits for the payload.
+	_, ok := s.lookupTask("task-1")
+	assert.True(t, ok)
+	assert.Equal(t, int32(0), recorder.calls.Load())
+
+	close(store.release)
+	webhook.Wait()
+	require.Len(t, recorder.bodies, 1)
+	var payload TaskWebhookPayload
+	require.NoError(t, json.Unmarshal(recorder.bodies[0], &payload))
+	assert.Contains(t, string(payload.Result), "done")
+}
+
+func TestTaskWebhook_DeadLetter(t *testing.T) {
+	recorder := &webhookRecorder{failures: 100}
+	sink := httptest.NewServer(recorder)
+	defer sink.Close()
+
+	t.Run("kept in memory", func(t *testing.T) {
+		webhook := NewTaskWebhook(sink.URL, nil, WithWebhookRetry(2, time.Millisecond))
+		s := NewMCPServer("test", "1.0.0", WithTaskCapabilities(true, true, true), WithTaskWebhook(webhook))
+
+		entry := s.createTask(context.Background(), "task-1", nil, nil)
+		require.NoError(t, s.completeTask(entry, nil, errors.New("boom")))
+		webhook.Wait()
+
+		deadLetters := webhook.DeadLetters()
+		require.Len(t, deadLetters, 1)
+		assert.Equal(t, 2, deadLetters[0].Attempts)
+		assert.Equal(t, mcp.TaskStatusFailed, deadLetters[0].Payload.Task.Status)
+		assert.Contains(t, deadLetters[0].LastError, "503")
+	})
+
+	t.Run("handed to callback", func(t *testing.T) {
+		var got []TaskWebhookDelivery
+		webhook := NewTaskWebhook(sink.URL, nil,
+			WithWebhookRetry(1, time.Millisecond),
+			WithWebhookDeadLetter(func(delivery TaskWebhookDelivery) { got = append(got, delivery) }),
+		)
+		s := NewMCPServer("test", "1.0.0", WithTaskCapabilities(true, true, true), WithTaskWebhook(webhook))
+
+		s.createTask(context.Background(), "task-2", nil, nil)
+		require.NoError(t, s.cancelTask(context.Background(), "task-2"))
+		webhook.Wait()
+
+		require.Len(t, got, 1)
+		assert.Equal(t, mcp.TaskStatusCancelled, got[0].Payload.Task.Status)
+		assert.Empty(t, webhook.DeadLetters())
+	})
+}
+
+func TestTaskWebhook_RequestCallbackURL(t *testing.T) {
+	callback := &webhookRecorder{}
+	callbackServer := httptest.NewServer(callback)
+	defer callbackServer.Close()
+
+	for _, allow := range []bool{true, false} {
+		t.Run("allowed="+strconv.FormatBool(allow), func(t *testing.T) {
+			callback.calls.Store(0)
+			var opts []TaskWebhookOption
+			if allow {
+				opts = append(opts, WithWebhookRequestCallbacks())
+			}
+			webhook := NewTaskWebhook("", []byte("secret"), opts...)
+			s := NewMCPServer("test", "1.0.0",
+				WithToolCapabilities(false),
+				WithTaskCapabilities(true, true, true),
+				WithTaskWebhook(webhook),
+			)
+			s.AddTool(mcp.NewTool("quick"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("done"), nil
+			})
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "quick"
+			request.Params.Task = &mcp.TaskParams{}
+			request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{TaskCallbackURLMetaKey: callbackServer.URL}}
+			created, reqErr := s.handleTaskAugmentedToolCall(context.Background(), 1, request)
+			require.Nil(t, reqErr)
+
+			require.Eventually(t, func() bool {
+				task, _, _ := s.getTask(context.Background(), created.Task.TaskId)
+				return task.Status.IsTerminal()
+			}, time.Second, time.Millisecond)
+			webhook.Wait()
+
+			if allow {
+				assert.Equal(t, int32(1), callback.calls.Load())
+			} else {
+				assert.Zero(t, callback.calls.Load())
+			}
+		})
+	}
+}
+
+func TestHooks_TaskStatusChange(t *testing.T) {
+	var mu sync.Mutex
+	var statuses []mcp.TaskStatus
+	hooks := &Hooks{}
+	hooks.AddOnTaskStatusChange(func(ctx context.Context, task mcp.Task) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, task.Status)
+	})
+	s := NewMCPServer("test", "1.0.0", WithTaskCapabilities(true, true, true), WithHooks(hooks))
+
+	ctx, entry := taskContext(t, s, &mockElicitationSession{
+		sessionID: "session-1",
+		result:    &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}},
+	}, "task-1")
+	_, err := s.RequestInput(ctx, newInputRequest())
+	require.NoError(t, err)
+	require.NoError(t, s.completeTask(entry, mcp.NewToolResultText("done"), nil))
+
+	assert.Equal(t, []mcp.TaskStatus{
+		mcp.TaskStatusInputRequired,
+		mcp.TaskStatusWorking,
+		mcp.TaskStatusCompleted,
+	}, statuses)
+}
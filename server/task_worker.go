@@ -0,0 +1,291 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// TaskWorkerPool runs the jobs of a TaskQueue with the tool handlers
+// registered for them, in the server's process or a separate one sharing a
+// durable queue. Each worker claims a job, keeps extending its lease while
+// the handler runs, and reports the outcome to the queue, from which the
+// server completes the task.
+//
+// Jobs are delivered at least once, so handlers should be idempotent.
+type TaskWorkerPool struct {
+	queue        TaskQueue
+	workers      int
+	lease        time.Duration
+	pollInterval time.Duration
+	maxAttempts  int
+	id           string
+	clock        Clock
+	logger       util.Logger
+	server       *MCPServer
+
+	mu       sync.RWMutex
+	handlers map[string]ToolHandlerFunc
+}
+
+// TaskWorkerOption configures a TaskWorkerPool.
+type TaskWorkerOption func(*TaskWorkerPool)
+
+// WithTaskWorkers sets how many jobs the pool runs at once, 4 by default.
+func WithTaskWorkers(n int) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		if n > 0 {
+			p.workers = n
+		}
+	}
+}
+
+// WithTaskWorkerLease sets for how long a worker claims a job, 30 seconds
+// by default. The lease is extended at half of it while the job runs, and
+// a job whose worker died is claimed again once its lease ends.
+func WithTaskWorkerLease(d time.Duration) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		if d > 0 {
+			p.lease = d
+		}
+	}
+}
+
+// WithTaskWorkerPollInterval sets how long idle workers wait before
+// claiming again, one second by default.
+func WithTaskWorkerPollInterval(d time.Duration) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		if d > 0 {
+			p.pollInterval = d
+		}
+	}
+}
+
+// WithTaskWorkerMaxAttempts sets how often a job is claimed before it
+// fails, 3 by default, so a job that crashes its workers does not run
+// forever. Zero means no limit.
+func WithTaskWorkerMaxAttempts(n int) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		p.maxAttempts = n
+	}
+}
+
+// WithTaskWorkerID sets the prefix of the names the pool's workers claim
+// jobs with, a random UUID by default.
+func WithTaskWorkerID(id string) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		p.id = id
+	}
+}
+
+// WithTaskWorkerClock sets the clock of the pool's leases and polling.
+func WithTaskWorkerClock(clock Clock) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		if clock != nil {
+			p.clock = clock
+		}
+	}
+}
+
+// WithTaskWorkerLogger sets the logger of queue errors.
+func WithTaskWorkerLogger(logger util.Logger) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		p.logger = logger
+	}
+}
+
+// WithTaskWorkerServer runs jobs the way server runs tool calls: through its
+// tool middlewares and the transformers of its tool of the same name, with
+// TouchResource embedding, its error translator and its result checks. Use
+// it when the pool runs in the server's process, or with a server configured
+// like it. Middlewares see no client session, only TaskIDFromContext and
+// TaskOwnerFromContext.
+func WithTaskWorkerServer(server *MCPServer) TaskWorkerOption {
+	return func(p *TaskWorkerPool) {
+		p.server = server
+	}
+}
+
+// NewTaskWorkerPool returns a pool running the jobs of queue. Register the
+// handlers of the tools whose jobs it runs, then start it with Run.
+func NewTaskWorkerPool(queue TaskQueue, opts ...TaskWorkerOption) *TaskWorkerPool {
+	p := &TaskWorkerPool{
+		queue:        queue,
+		workers:      4,
+		lease:        30 * time.Second,
+		pollInterval: time.Second,
+		maxAttempts:  3,
+		id:           uuid.New().String(),
+		clock:        SystemClock,
+		logger:       util.DefaultLogger(),
+		handlers:     make(map[string]ToolHandlerFunc),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Register sets the handler running the jobs of tool.
+func (p *TaskWorkerPool) Register(tool string, handler ToolHandlerFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[tool] = handler
+}
+
+// RegisterTools registers the handlers of tools, e.g. the same ones
+// registered with the server.
+func (p *TaskWorkerPool) RegisterTools(tools ...ServerTool) {
+	for _, tool := range tools {
+		p.Register(tool.Tool.Name, tool.Handler)
+	}
+}
+
+// Run runs the pool's workers until ctx is done and returns once they
+// stopped. Jobs still running then are cancelled and, as their lease is no
+// longer extended, claimed again later.
+func (p *TaskWorkerPool) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			p.work(ctx, worker)
+		}(fmt.Sprintf("%s-%d", p.id, i+1))
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// work claims and runs jobs until ctx is done.
+func (p *TaskWorkerPool) work(ctx context.Context, worker string) {
+	for ctx.Err() == nil {
+		job, err := p.queue.Claim(ctx, worker, p.lease)
+		if err != nil {
+			if !errors.Is(err, ErrTaskQueueEmpty) && ctx.Err() == nil {
+				p.logger.Errorf("Failed to claim task job: %v", err)
+			}
+			_ = sleepContext(ctx, p.clock, p.pollInterval)
+			continue
+		}
+		p.runJob(ctx, worker, job)
+	}
+}
+
+// runJob runs a claimed job, extending its lease meanwhile, and reports its
+// outcome.
+func (p *TaskWorkerPool) runJob(ctx context.Context, worker string, job TaskJob) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobCtx = context.WithValue(jobCtx, taskIDKey{}, job.ID)
+	jobCtx = context.WithValue(jobCtx, taskOwnerKey{}, TaskOwner{Principal: job.Principal, SessionID: job.SessionID})
+
+	heartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeat)
+		for sleepContext(jobCtx, p.clock, p.lease/2) == nil {
+			if err := p.queue.Extend(jobCtx, job.ID, worker, p.lease); err != nil && jobCtx.Err() == nil {
+				if !errors.Is(err, ErrTaskJobCancelled) && !errors.Is(err, ErrTaskJobNotFound) {
+					p.logger.Errorf("Lost lease on task job %s: %v", job.ID, err)
+				}
+				cancel()
+				return
+			}
+		}
+	}()
+
+	outcome := p.call(jobCtx, job)
+	cancel()
+	<-heartbeat
+
+	if ctx.Err() != nil {
+		// Shutting down: leave the job to be claimed again.
+		return
+	}
+	err := p.queue.Finish(context.WithoutCancel(ctx), job.ID, worker, outcome)
+	if err != nil && !errors.Is(err, ErrTaskJobCancelled) && !errors.Is(err, ErrTaskJobNotFound) {
+		p.logger.Errorf("Failed to finish task job %s: %v", job.ID, err)
+	}
+}
+
+// taskOwnerKey is the context key for the owner of the job a worker runs.
+type taskOwnerKey struct{}
+
+// TaskOwnerFromContext returns who created the task a TaskWorkerPool
+// handler is running, as recorded in its TaskJob. It returns false outside
+// of task workers.
+func TaskOwnerFromContext(ctx context.Context) (TaskOwner, bool) {
+	owner, ok := ctx.Value(taskOwnerKey{}).(TaskOwner)
+	return owner, ok
+}
+
+// run calls handler, through the tool call chain of the pool's server if it
+// has one.
+func (p *TaskWorkerPool) run(ctx context.Context, handler ToolHandlerFunc, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s := p.server
+	if s == nil {
+		return handler(ctx, request)
+	}
+	var tool mcp.Tool
+	s.toolsMu.RLock()
+	if registered, ok := s.tools[request.Params.Name]; ok {
+		tool = registered.Tool
+	}
+	s.toolsMu.RUnlock()
+
+	result, err := s.wrapToolHandler(tool, handler)(ctx, request)
+	if translated, ok := s.translateToolError(ctx, nil, request, err); ok {
+		return translated, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkToolResult(request.Params.Name, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// call runs the handler of a job's tool.
+func (p *TaskWorkerPool) call(ctx context.Context, job TaskJob) TaskJobOutcome {
+	if p.maxAttempts > 0 && job.Attempts > p.maxAttempts {
+		return TaskJobOutcome{Error: fmt.Sprintf("task job %s gave up after %d attempts", job.ID, p.maxAttempts)}
+	}
+	p.mu.RLock()
+	handler, ok := p.handlers[job.Tool]
+	p.mu.RUnlock()
+	if !ok {
+		return TaskJobOutcome{Error: fmt.Sprintf("tool '%s' has no task worker: %v", job.Tool, ErrToolNotFound)}
+	}
+
+	var request mcp.CallToolRequest
+	request.Method = string(mcp.MethodToolsCall)
+	request.Params.Name = job.Tool
+	if len(job.Arguments) > 0 {
+		var arguments any
+		if err := json.Unmarshal(job.Arguments, &arguments); err != nil {
+			return TaskJobOutcome{Error: fmt.Sprintf("invalid arguments: %v", err)}
+		}
+		request.Params.Arguments = arguments
+	}
+
+	result, err := p.run(ctx, handler, request)
+	if err != nil {
+		return TaskJobOutcome{Error: err.Error()}
+	}
+	if result == nil {
+		return TaskJobOutcome{}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return TaskJobOutcome{Error: fmt.Sprintf("failed to encode task result: %v", err)}
+	}
+	return TaskJobOutcome{Result: data}
+}
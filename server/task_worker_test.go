@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// runPool runs pool until the test ends.
+func runPool(t *testing.T, pool *TaskWorkerPool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = pool.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func waitForJob(t *testing.T, queue TaskQueue, id string) TaskJobStatus {
+	t.Helper()
+	var status TaskJobStatus
+	require.Eventually(t, func() bool {
+		var err error
+		status, err = queue.Status(context.Background(), id)
+		return err == nil && status.State.IsFinal()
+	}, 2*time.Second, time.Millisecond)
+	return status
+}
+
+func TestTaskWorkerPool_Outcomes(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	pool := NewTaskWorkerPool(queue, WithTaskWorkerPollInterval(time.Millisecond))
+	pool.RegisterTools(
+		ServerTool{Tool: mcp.NewTool("ok"), Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("done"), nil
+		}},
+		ServerTool{Tool: mcp.NewTool("fails"), Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("disk full")
+		}},
+	)
+	runPool(t, pool)
+
+	ctx := context.Background()
+	for _, id := range []string{"ok", "fails", "unknown"} {
+		require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: id, Tool: id}))
+	}
+
+	status := waitForJob(t, queue, "ok")
+	assert.Equal(t, TaskJobCompleted, status.State)
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"done"}]}`, string(status.Result))
+
+	status = waitForJob(t, queue, "fails")
+	assert.Equal(t, TaskJobFailed, status.State)
+	assert.Equal(t, "disk full", status.Error)
+
+	status = waitForJob(t, queue, "unknown")
+	assert.Equal(t, TaskJobFailed, status.State)
+	assert.Contains(t, status.Error, "has no task worker")
+}
+
+func TestTaskWorkerPool_Identity(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	pool := NewTaskWorkerPool(queue)
+	var owner TaskOwner
+	var taskID string
+	pool.Register("whoami", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var ok bool
+		owner, ok = TaskOwnerFromContext(ctx)
+		assert.True(t, ok)
+		taskID = TaskIDFromContext(ctx)
+		return nil, nil
+	})
+
+	ctx := context.Background()
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "t1", Tool: "whoami", Principal: "alice", SessionID: "s1"}))
+	job, err := queue.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	pool.runJob(ctx, "w1", job)
+
+	assert.Equal(t, TaskOwner{Principal: "alice", SessionID: "s1"}, owner)
+	assert.Equal(t, "t1", taskID)
+	_, ok := TaskOwnerFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestTaskWorkerPool_Server(t *testing.T) {
+	errBusy := errors.New("busy")
+	var seen []string
+	s := NewMCPServer("test", "1.0.0",
+		WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				seen = append(seen, request.Params.Name)
+				return next(ctx, request)
+			}
+		}),
+		WithErrorTranslator(NewErrorTranslator().Register(errBusy, "busy", "Try again later.")),
+	)
+	queue := NewMemoryTaskQueue(nil)
+	pool := NewTaskWorkerPool(queue, WithTaskWorkerServer(s))
+	pool.Register("flaky", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errBusy
+	})
+
+	ctx := context.Background()
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "t1", Tool: "flaky"}))
+	job, err := queue.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	pool.runJob(ctx, "w1", job)
+
+	assert.Equal(t, []string{"flaky"}, seen, "jobs run through the tool middlewares")
+	status, err := queue.Status(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, TaskJobCompleted, status.State)
+	assert.Empty(t, status.Error)
+	assert.Contains(t, string(status.Result), "Try again later.", "errors are translated")
+}
+
+func TestTaskWorkerPool_MaxAttempts(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_700_000_000, 0)}
+	queue := NewMemoryTaskQueue(clock)
+	ctx := context.Background()
+	require.NoError(t, queue.Enqueue(ctx, TaskJob{ID: "crashy", Tool: "crashy"}))
+
+	// Two workers died while running the job.
+	for i := 0; i < 2; i++ {
+		_, err := queue.Claim(ctx, "dead", time.Second)
+		require.NoError(t, err)
+		clock.advance(time.Second)
+	}
+
+	pool := NewTaskWorkerPool(queue, WithTaskWorkerMaxAttempts(2), WithTaskWorkerClock(clock))
+	pool.Register("crashy", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.Error("jobs past their attempts must not run")
+		return nil, nil
+	})
+	job, err := queue.Claim(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	pool.runJob(ctx, "w1", job)
+
+	status, err := queue.Status(ctx, "crashy")
+	require.NoError(t, err)
+	assert.Equal(t, TaskJobFailed, status.State)
+	assert.Contains(t, status.Error, "gave up after 2 attempts")
+}
+
+func TestTaskWorkerPool_CancelledJob(t *testing.T) {
+	queue := NewMemoryTaskQueue(nil)
+	started := make(chan struct{})
+	var once sync.Once
+	stopped := make(chan error, 10)
+	pool := NewTaskWorkerPool(queue,
+		WithTaskWorkerPollInterval(time.Millisecond),
+		WithTaskWorkerLease(100*time.Millisecond),
+	)
+	pool.Register("long", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// A starved heartbeat may lose the lease and the job run again.
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	runPool(t, pool)
+
+	require.NoError(t, queue.Enqueue(context.Background(), TaskJob{ID: "long", Tool: "long"}))
+	<-started
+	// The lease is extended while the job runs.
+	time.Sleep(250 * time.Millisecond)
+	status, err := queue.Status(context.Background(), "long")
+	require.NoError(t, err)
+	assert.Equal(t, TaskJobRunning, status.State)
+	assert.Equal(t, 1, status.Attempts)
+
+	require.NoError(t, queue.Cancel(context.Background(), "long"))
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the handler of a cancelled job must be cancelled")
+	}
+}
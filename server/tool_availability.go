@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolDowntime is a period in which tools are unavailable: they are left
+// out of tools/list and calls to them fail with mcp.ToolUnavailableError.
+type ToolDowntime struct {
+	// From is when the downtime starts; the zero time starts it now.
+	From time.Time
+	// Until is when the tools are available again; with the zero time they
+	// stay unavailable until EnableTools.
+	Until time.Time
+	// Reason is told to clients calling the tools, e.g. "maintenance".
+	Reason string
+}
+
+// toolDowntimes holds the downtimes of tools by name.
+type toolDowntimes struct {
+	mu        sync.Mutex
+	downtimes map[string]*scheduledDowntime
+}
+
+type scheduledDowntime struct {
+	ToolDowntime
+	// stop ends the goroutine waiting for the downtime's boundaries.
+	stop chan struct{}
+}
+
+func (d ToolDowntime) active(now time.Time) bool {
+	return !now.Before(d.From) && (d.Until.IsZero() || now.Before(d.Until))
+}
+
+// DisableTools makes tools unavailable until EnableTools is called for them,
+// without unregistering them.
+func (s *MCPServer) DisableTools(names ...string) {
+	_ = s.ScheduleToolDowntime(ToolDowntime{}, names...)
+}
+
+// EnableTools ends or cancels the downtimes of tools.
+func (s *MCPServer) EnableTools(names ...string) {
+	now := s.Clock().Now()
+	changed := false
+	s.toolDowntimes.mu.Lock()
+	for _, name := range names {
+		if previous, ok := s.toolDowntimes.downtimes[name]; ok {
+			changed = changed || previous.active(now)
+			close(previous.stop)
+			delete(s.toolDowntimes.downtimes, name)
+		}
+	}
+	s.toolDowntimes.mu.Unlock()
+
+	if changed {
+		s.toolAvailabilityChanged()
+	}
+}
+
+// ScheduleToolDowntime makes tools unavailable during downtime, replacing
+// downtimes scheduled for them before. Downtimes are kept by tool name, so
+// they also apply to session tools and to tools registered later under the
+// names. Clients are sent notifications/tools/list_changed as the downtime
+// starts and ends.
+func (s *MCPServer) ScheduleToolDowntime(downtime ToolDowntime, names ...string) error {
+	now := s.Clock().Now()
+	if downtime.From.IsZero() {
+		downtime.From = now
+	}
+	if !downtime.Until.IsZero() && !downtime.Until.After(downtime.From) {
+		return fmt.Errorf("%w: ends at %v, before it starts at %v", ErrInvalidToolDowntime, downtime.Until, downtime.From)
+	}
+
+	d := &s.toolDowntimes
+	changed := false
+	d.mu.Lock()
+	for _, name := range names {
+		wasActive := false
+		if previous, ok := d.downtimes[name]; ok {
+			wasActive = previous.active(now)
+			close(previous.stop)
+			delete(d.downtimes, name)
+		}
+		changed = changed || wasActive != downtime.active(now)
+		if !downtime.Until.IsZero() && !downtime.Until.After(now) {
+			continue
+		}
+
+		scheduled := &scheduledDowntime{ToolDowntime: downtime, stop: make(chan struct{})}
+		if d.downtimes == nil {
+			d.downtimes = make(map[string]*scheduledDowntime)
+		}
+		d.downtimes[name] = scheduled
+		if downtime.From.After(now) || !downtime.Until.IsZero() {
+			go s.awaitDowntimeBoundaries(name, scheduled)
+		}
+	}
+	d.mu.Unlock()
+
+	if changed {
+		s.toolAvailabilityChanged()
+	}
+	return nil
+}
+
+// awaitDowntimeBoundaries notifies clients when a downtime starts and ends,
+// and drops it once it ended.
+func (s *MCPServer) awaitDowntimeBoundaries(name string, scheduled *scheduledDowntime) {
+	for _, boundary := range []time.Time{scheduled.From, scheduled.Until} {
+		wait := boundary.Sub(s.Clock().Now())
+		if boundary.IsZero() || wait <= 0 {
+			continue
+		}
+		timer := s.Clock().NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-scheduled.stop:
+			timer.Stop()
+			return
+		}
+
+		s.toolDowntimes.mu.Lock()
+		current := s.toolDowntimes.downtimes[name] == scheduled
+		if current && boundary.Equal(scheduled.Until) {
+			delete(s.toolDowntimes.downtimes, name)
+		}
+		s.toolDowntimes.mu.Unlock()
+		if !current {
+			return
+		}
+		s.toolAvailabilityChanged()
+	}
+}
+
+// toolUnavailable reports whether the tool is in a downtime.
+func (s *MCPServer) toolUnavailable(name string) (mcp.ToolUnavailableError, bool) {
+	now := s.Clock().Now()
+	s.toolDowntimes.mu.Lock()
+	scheduled, ok := s.toolDowntimes.downtimes[name]
+	s.toolDowntimes.mu.Unlock()
+	if !ok || !scheduled.active(now) {
+		return mcp.ToolUnavailableError{}, false
+	}
+
+	unavailable := mcp.ToolUnavailableError{Tool: name, Reason: scheduled.Reason}
+	if !scheduled.Until.IsZero() {
+		unavailable.RetryAfter = scheduled.Until.Sub(now)
+	}
+	return unavailable, true
+}
+
+// withoutUnavailableTools drops the tools that are in a downtime.
+func (s *MCPServer) withoutUnavailableTools(tools []mcp.Tool) []mcp.Tool {
+	s.toolDowntimes.mu.Lock()
+	empty := len(s.toolDowntimes.downtimes) == 0
+	s.toolDowntimes.mu.Unlock()
+	if empty {
+		return tools
+	}
+
+	available := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if _, unavailable := s.toolUnavailable(tool.Name); !unavailable {
+			available = append(available, tool)
+		}
+	}
+	return available
+}
+
+func (s *MCPServer) toolAvailabilityChanged() {
+	if s.capabilities.tools != nil && s.capabilities.tools.listChanged {
+		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	}
+}
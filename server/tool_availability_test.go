@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func availabilityTestServer(t *testing.T, clock Clock) (*MCPServer, context.Context, chan mcp.JSONRPCNotification) {
+	t.Helper()
+	s := NewMCPServer("test", "1.0.0", WithToolCapabilities(true), WithClock(clock))
+	for _, name := range []string{"deploy", "status"} {
+		s.AddTool(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	return s, s.WithContext(context.Background(), session), session.notificationChannel
+}
+
+func listedToolNames(t *testing.T, s *MCPServer, ctx context.Context) []string {
+	t.Helper()
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	var names []string
+	for _, tool := range resp.Result.(mcp.ListToolsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func callDeploy(s *MCPServer, ctx context.Context) mcp.JSONRPCMessage {
+	return s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"deploy"}}`))
+}
+
+func requireListChanged(t *testing.T, notifications chan mcp.JSONRPCNotification) {
+	t.Helper()
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, mcp.MethodNotificationToolsListChanged, notification.Method)
+	case <-time.After(time.Second):
+		t.Fatal("tools/list_changed was not sent")
+	}
+}
+
+func TestDisableTools(t *testing.T) {
+	s, ctx, notifications := availabilityTestServer(t, SystemClock)
+
+	s.DisableTools("deploy")
+	requireListChanged(t, notifications)
+	assert.Equal(t, []string{"status"}, listedToolNames(t, s, ctx))
+
+	response := callDeploy(s, ctx)
+	errResp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.TOOL_UNAVAILABLE, errResp.Error.Code)
+	assert.Equal(t, map[string]any{"tool": "deploy"}, errResp.Error.Data)
+	assert.ErrorIs(t, errResp.Error.AsError(), mcp.ErrToolUnavailable)
+
+	// Disabling again does not change what clients see.
+	s.DisableTools("deploy")
+	assert.Empty(t, notifications)
+
+	s.EnableTools("deploy")
+	requireListChanged(t, notifications)
+	assert.Equal(t, []string{"deploy", "status"}, listedToolNames(t, s, ctx))
+	_, ok = callDeploy(s, ctx).(mcp.JSONRPCResponse)
+	assert.True(t, ok)
+
+	s.EnableTools("deploy", "status")
+	assert.Empty(t, notifications, "enabling available tools sends nothing")
+}
+
+func TestDisableTools_KeptAcrossRegistration(t *testing.T) {
+	s, ctx, notifications := availabilityTestServer(t, SystemClock)
+
+	s.DisableTools("deploy")
+	requireListChanged(t, notifications)
+	s.DeleteTools("deploy")
+	requireListChanged(t, notifications)
+	s.AddTool(mcp.NewTool("deploy"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	assert.Equal(t, []string{"status"}, listedToolNames(t, s, ctx))
+}
+
+func TestScheduleToolDowntime(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clock := &manualClock{now: start}
+	s, ctx, notifications := availabilityTestServer(t, clock)
+
+	require.NoError(t, s.ScheduleToolDowntime(ToolDowntime{
+		From:   start.Add(time.Hour),
+		Until:  start.Add(90 * time.Minute),
+		Reason: "maintenance",
+	}, "deploy"))
+	assert.Empty(t, notifications, "the downtime has not started")
+	assert.Equal(t, []string{"deploy", "status"}, listedToolNames(t, s, ctx))
+	require.Eventually(t, func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+
+	clock.advance(time.Hour)
+	requireListChanged(t, notifications)
+	assert.Equal(t, []string{"status"}, listedToolNames(t, s, ctx))
+
+	clock.advance(10*time.Minute + 500*time.Millisecond)
+	response := callDeploy(s, ctx)
+	errResp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.TOOL_UNAVAILABLE, errResp.Error.Code)
+	assert.Equal(t, map[string]any{"tool": "deploy", "reason": "maintenance", "retryAfter": int64(1200)}, errResp.Error.Data)
+
+	var unavailable mcp.ToolUnavailableError
+	require.True(t, errors.As(errResp.Error.AsError(), &unavailable))
+	assert.Equal(t, "maintenance", unavailable.Reason)
+
+	require.Eventually(t, func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+	clock.advance(20 * time.Minute)
+	requireListChanged(t, notifications)
+	assert.Equal(t, []string{"deploy", "status"}, listedToolNames(t, s, ctx))
+	_, ok = callDeploy(s, ctx).(mcp.JSONRPCResponse)
+	assert.True(t, ok)
+}
+
+func TestScheduleToolDowntime_Cancelled(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clock := &manualClock{now: start}
+	s, ctx, notifications := availabilityTestServer(t, clock)
+
+	require.NoError(t, s.ScheduleToolDowntime(ToolDowntime{From: start.Add(time.Hour)}, "deploy"))
+	require.Eventually(t, func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+	s.EnableTools("deploy")
+
+	clock.advance(2 * time.Hour)
+	assert.Equal(t, []string{"deploy", "status"}, listedToolNames(t, s, ctx))
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, notifications)
+}
+
+func TestScheduleToolDowntime_Invalid(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	s, _, _ := availabilityTestServer(t, &manualClock{now: start})
+
+	err := s.ScheduleToolDowntime(ToolDowntime{From: start.Add(time.Hour), Until: start}, "deploy")
+	assert.ErrorIs(t, err, ErrInvalidToolDowntime)
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithStrictToolResults checks every tool result with
+// mcp.CallToolResult.Validate before it is sent, including the results of
+// task-augmented calls. An invalid result fails the call with an internal
+// error describing the problems, which wraps mcp.ErrInvalidToolResult,
+// instead of reaching clients that would fail on it without explanation.
+func WithStrictToolResults() ServerOption {
+	return func(s *MCPServer) {
+		s.strictToolResults = true
+	}
+}
+
+// checkToolResult validates a tool's result under WithStrictToolResults.
+func (s *MCPServer) checkToolResult(name string, result *mcp.CallToolResult) error {
+	if !s.strictToolResults {
+		return nil
+	}
+	if err := result.Validate(); err != nil {
+		return fmt.Errorf("tool '%s' returned an invalid result: %w", name, err)
+	}
+	return nil
+}
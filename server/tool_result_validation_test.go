@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func invalidResultHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewImageContent("not base64!", "image/png")}}, nil
+}
+
+func TestWithStrictToolResults(t *testing.T) {
+	call := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "broken"}}`)
+
+	t.Run("strict", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithStrictToolResults())
+		server.AddTool(mcp.NewTool("broken"), invalidResultHandler)
+		server.AddTool(mcp.NewTool("fine"), noopToolHandler)
+
+		response := server.HandleMessage(context.Background(), call)
+		errResp, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok, "Expected JSONRPCError, got %T", response)
+		assert.Equal(t, mcp.INTERNAL_ERROR, errResp.Error.Code)
+		assert.Contains(t, errResp.Error.Message, "tool 'broken' returned an invalid result")
+		assert.Contains(t, errResp.Error.Message, "content[0]: image content: invalid base64 data")
+
+		response = server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "fine"}}`))
+		_, ok = response.(mcp.JSONRPCResponse)
+		assert.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	})
+
+	t.Run("not strict", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddTool(mcp.NewTool("broken"), invalidResultHandler)
+
+		response := server.HandleMessage(context.Background(), call)
+		_, ok := response.(mcp.JSONRPCResponse)
+		assert.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	})
+}
+
+func TestWithStrictToolResults_TaskAugmentedCall(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+		WithStrictToolResults(),
+	)
+	server.AddTool(mcp.NewTool("broken"), invalidResultHandler)
+
+	ctx := context.Background()
+	response := server.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "broken", "task": {}}
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	created, ok := resp.Result.(mcp.CreateTaskResult)
+	require.True(t, ok, "Expected CreateTaskResult, got %T", resp.Result)
+
+	require.Eventually(t, func() bool {
+		task, _, err := server.getTask(ctx, created.Task.TaskId)
+		return err == nil && task.Status == mcp.TaskStatusFailed
+	}, time.Second, time.Millisecond)
+	task, _, err := server.getTask(ctx, created.Task.TaskId)
+	require.NoError(t, err)
+	assert.Contains(t, task.StatusMessage, "invalid tool result")
+}
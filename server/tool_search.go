@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithToolSearch enables the tools/search extension method and the search
+// filters of tools/list, for clients that cannot list a large tool catalog
+// in full. Both return the tools visible to the session that match a query,
+// ranked with mcp.SearchTools, or by similarity with
+// WithSemanticToolSearch. tools/list applies the filters when its _meta
+// holds mcp.ToolsListQueryMeta, and optionally mcp.ToolsListLimitMeta.
+func WithToolSearch() ServerOption {
+	return func(s *MCPServer) {
+		s.toolSearch = true
+		s.setExperimentalCapability(mcp.ExperimentalToolSearch, map[string]any{})
+		s.handleExtension(mcp.MethodToolsSearch, s.handleSearchTools)
+	}
+}
+
+func (s *MCPServer) handleSearchTools(ctx context.Context, id any, message json.RawMessage, header http.Header) (any, *requestError) {
+	var request mcp.SearchToolsRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  &UnparsableMessageError{message: message, err: err, method: mcp.MethodToolsSearch},
+		}
+	}
+	request.Header = header
+	if request.Params.Limit < 0 {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("limit must not be negative"),
+		}
+	}
+
+	tools, err := s.searchTools(ctx, s.visibleTools(ctx), request.Params.Query, request.Params.Limit)
+	if err == nil {
+		s.addSchemaHashes(tools)
+		tools, err = s.resolveDynamicEnums(ctx, tools)
+	}
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  err,
+		}
+	}
+	return &mcp.SearchToolsResult{Tools: tools}, nil
+}
+
+// toolsListQuery returns the search filters of a tools/list request.
+func toolsListQuery(request mcp.ListToolsRequest) (query string, limit int, ok bool) {
+	if request.Params.Meta == nil {
+		return "", 0, false
+	}
+	query, ok = request.Params.Meta.AdditionalFields[mcp.ToolsListQueryMeta].(string)
+	if !ok {
+		return "", 0, false
+	}
+	if n, isNumber := request.Params.Meta.AdditionalFields[mcp.ToolsListLimitMeta].(float64); isNumber && n > 0 {
+		limit = int(n)
+	}
+	return query, limit, true
+}
+
+// offsetCursorPrefix marks the cursors of listByOffset.
+const offsetCursorPrefix = "offset:"
+
+// listByOffset is listByPagination for elements that are not sorted by
+// name, such as ranked search results: its cursors hold the position of
+// the next page.
+func listByOffset[T any](
+	_ context.Context,
+	s *MCPServer,
+	cursor mcp.Cursor,
+	allElements []T,
+) ([]T, mcp.Cursor, error) {
+	startPos := 0
+	if cursor != "" {
+		c, err := base64.StdEncoding.DecodeString(string(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		offset, ok := strings.CutPrefix(string(c), offsetCursorPrefix)
+		if !ok {
+			return nil, "", fmt.Errorf("invalid cursor for a search")
+		}
+		if startPos, err = strconv.Atoi(offset); err != nil || startPos < 0 {
+			return nil, "", fmt.Errorf("invalid cursor for a search")
+		}
+		startPos = min(startPos, len(allElements))
+	}
+	endPos := len(allElements)
+	if s.paginationLimit != nil && endPos > startPos+*s.paginationLimit {
+		endPos = startPos + *s.paginationLimit
+	}
+
+	var nextCursor mcp.Cursor
+	if endPos < len(allElements) {
+		nextCursor = mcp.Cursor(base64.StdEncoding.EncodeToString([]byte(offsetCursorPrefix + strconv.Itoa(endPos))))
+	}
+	return allElements[startPos:endPos], nextCursor, nil
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func toolNames(tools []mcp.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestWithToolSearch(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolSearch())
+	s.AddTool(mcp.NewTool("weather", mcp.WithDescription("Current weather")), noopToolHandler)
+	s.AddTool(mcp.NewTool("get_forecast", mcp.WithDescription("Weather forecast")), noopToolHandler)
+	s.AddTool(mcp.NewTool("translate", mcp.WithDescription("Translate text")), noopToolHandler)
+	s.AddTool(mcp.NewTool("interview", mcp.WithDescription("Weather survey"), mcp.WithRequiredClientCapabilities(mcp.ClientCapabilityElicitation)), noopToolHandler)
+
+	session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+	ctx := s.WithContext(context.Background(), session)
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+	initialize := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+	assert.Contains(t, initialize.Capabilities.Experimental, mcp.ExperimentalToolSearch)
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/search","params":{"query":"weather"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, []string{"weather", "get_forecast"}, toolNames(resp.Result.(*mcp.SearchToolsResult).Tools))
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/search","params":{"query":"weather","limit":-1}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.INVALID_PARAMS, rpcErr.Error.Code)
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":4,"method":"tools/list","params":{"_meta":{"query":"weather","limit":1}}}`))
+	assert.Equal(t, []string{"weather"}, toolNames(response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools))
+
+	response = s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":5,"method":"tools/list"}`))
+	assert.Equal(t, []string{"get_forecast", "translate", "weather"}, toolNames(response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools))
+}
+
+func TestToolSearchDisabled(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+	s.AddTool(mcp.NewTool("translate"), noopToolHandler)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/search","params":{"query":"weather"}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, rpcErr.Error.Code)
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{"_meta":{"query":"weather"}}}`))
+	assert.Len(t, response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools, 2)
+}
+
+func TestToolSearchPagination(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolSearch(), WithPaginationLimit(1), WithToolSchemaHashes())
+	s.AddTool(mcp.NewTool("weather", mcp.WithDescription("Current weather")), noopToolHandler)
+	s.AddTool(mcp.NewTool("get_forecast", mcp.WithDescription("Weather forecast")), noopToolHandler)
+	s.AddTool(mcp.NewTool("translate", mcp.WithDescription("Translate text")), noopToolHandler)
+
+	// Ranked results are paged in rank order, which is not name order.
+	var pages [][]string
+	cursor := ""
+	for range 3 {
+		params := `{"_meta":{"query":"weather"}}`
+		if cursor != "" {
+			params = `{"_meta":{"query":"weather"},"cursor":"` + cursor + `"}`
+		}
+		response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":`+params+`}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		result := resp.Result.(mcp.ListToolsResult)
+		pages = append(pages, toolNames(result.Tools))
+		cursor = string(result.NextCursor)
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, [][]string{{"weather"}, {"get_forecast"}}, pages)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{"_meta":{"query":"weather"},"cursor":"d2VhdGhlcg=="}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "name cursors are rejected for searches, got %#v", response)
+	assert.Equal(t, mcp.INVALID_PARAMS, rpcErr.Error.Code)
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/search","params":{"query":"translate"}}`))
+	tools := response.(mcp.JSONRPCResponse).Result.(*mcp.SearchToolsResult).Tools
+	require.Len(t, tools, 1)
+	hash, err := mcp.ToolSchemaHash(tools[0])
+	require.NoError(t, err)
+	assert.Equal(t, hash, tools[0].Meta.AdditionalFields[mcp.ToolSchemaHashMetaKey])
+}
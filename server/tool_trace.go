@@ -0,0 +1,330 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// TraceRecentURI is the resource listing the most recent tool calls
+	// recorded by WithToolCallTracing, newest first.
+	TraceRecentURI = "trace://recent"
+	// TraceCallURITemplate is the resource template of a single recorded
+	// tool call, by ID.
+	TraceCallURITemplate = "trace://recent/{id}"
+
+	// defaultTraceCapacity is how many tool calls are kept by default.
+	defaultTraceCapacity = 50
+	// redactedValue replaces redacted argument values.
+	redactedValue = "[REDACTED]"
+	// maxTraceErrorLength is how many bytes of a handler error are recorded.
+	maxTraceErrorLength = 200
+)
+
+// defaultRedactedKeys are the argument names whose values are redacted by
+// default, matched case-insensitively as substrings.
+var defaultRedactedKeys = []string{"password", "secret", "token", "apikey", "api_key", "authorization", "credential"}
+
+// ToolCallTrace is a tool call recorded by WithToolCallTracing.
+type ToolCallTrace struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	SessionID string         `json:"sessionId,omitempty"`
+	TaskID    string         `json:"taskId,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	StartedAt time.Time      `json:"startedAt"`
+	// DurationMs is how long the handler ran, in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+	// IsError is set if the handler failed or returned an error result.
+	IsError bool `json:"isError"`
+	// Error describes the error the handler returned, if any, as the
+	// TraceErrorRedactor recorded it, truncated to 200 bytes.
+	Error string `json:"error,omitempty"`
+}
+
+// TraceRedactor rewrites the arguments of a tool call before they are
+// recorded, e.g. to remove personal data.
+type TraceRedactor func(tool string, arguments map[string]any) map[string]any
+
+// TraceErrorRedactor returns what is recorded of the error a tool handler
+// returned. Error messages often quote arguments, so the default only
+// records the code of the server's ErrorTranslator for the error or,
+// without one, its Go type.
+type TraceErrorRedactor func(tool string, err error) string
+
+// ToolTraceOption configures WithToolCallTracing.
+type ToolTraceOption func(*toolTracer)
+
+// WithTraceCapacity sets how many tool calls are kept. It defaults to 50.
+func WithTraceCapacity(n int) ToolTraceOption {
+	return func(t *toolTracer) {
+		if n > 0 {
+			t.capacity = n
+		}
+	}
+}
+
+// WithTraceRedactor replaces the default redaction, which hides the values
+// of arguments whose names contain password, secret, token, apikey,
+// api_key, authorization or credential, at any depth.
+func WithTraceRedactor(redactor TraceRedactor) ToolTraceOption {
+	return func(t *toolTracer) {
+		t.redact = redactor
+	}
+}
+
+// WithTraceErrorRedactor replaces how handler errors are recorded, e.g.
+// with a function returning err.Error() for tools whose errors are known to
+// be safe.
+func WithTraceErrorRedactor(redactor TraceErrorRedactor) ToolTraceOption {
+	return func(t *toolTracer) {
+		t.redactError = redactor
+	}
+}
+
+// WithTraceRedactedKeys adds argument names to the default redaction.
+func WithTraceRedactedKeys(keys ...string) ToolTraceOption {
+	return func(t *toolTracer) {
+		for _, key := range keys {
+			t.redactedKeys = append(t.redactedKeys, strings.ToLower(key))
+		}
+	}
+}
+
+// WithTraceAdmin lets callers for which isAdmin returns true read the tool
+// calls of every session through the trace resources.
+func WithTraceAdmin(isAdmin func(ctx context.Context) bool) ToolTraceOption {
+	return func(t *toolTracer) {
+		t.isAdmin = isAdmin
+	}
+}
+
+// WithToolCallTracing records the most recent tool calls and exposes them
+// as resources, so connected agents and operators can inspect recent
+// activity: TraceRecentURI lists them and TraceCallURITemplate reads one.
+// Clients only read the calls made in their own session, unless
+// WithTraceAdmin allows them more. Arguments and errors are redacted, and
+// errors truncated, before they are recorded. After each call, a resources/updated
+// notification for TraceRecentURI is sent to clients.
+func WithToolCallTracing(opts ...ToolTraceOption) ServerOption {
+	return func(s *MCPServer) {
+		t := &toolTracer{
+			server:       s,
+			capacity:     defaultTraceCapacity,
+			redactedKeys: append([]string(nil), defaultRedactedKeys...),
+		}
+		for _, opt := range opts {
+			opt(t)
+		}
+		if t.redact == nil {
+			t.redact = t.redactKeys
+		}
+		if t.redactError == nil {
+			t.redactError = t.errorCode
+		}
+		s.toolTracer = t
+
+		WithToolHandlerMiddleware(t.middleware)(s)
+		s.AddResource(
+			mcp.NewResource(TraceRecentURI, "Recent tool calls",
+				mcp.WithResourceDescription("The most recent tool calls, newest first, with redacted arguments"),
+				mcp.WithMIMEType("application/json"),
+			),
+			t.readRecent,
+		)
+		s.AddResourceTemplate(
+			mcp.NewResourceTemplate(TraceCallURITemplate, "Recorded tool call",
+				mcp.WithTemplateDescription("A recent tool call by ID"),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			t.readCall,
+		)
+	}
+}
+
+// ToolCallTraces returns the recorded tool calls, newest first, or nil if
+// tracing is not enabled.
+func (s *MCPServer) ToolCallTraces() []ToolCallTrace {
+	if s.toolTracer == nil {
+		return nil
+	}
+	return s.toolTracer.recent()
+}
+
+type toolTracer struct {
+	server       *MCPServer
+	capacity     int
+	redactedKeys []string
+	redact       TraceRedactor
+	redactError  TraceErrorRedactor
+	isAdmin      func(ctx context.Context) bool
+
+	mu     sync.Mutex
+	nextID uint64
+	traces []ToolCallTrace // oldest first
+}
+
+func (t *toolTracer) middleware(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		clock := t.server.Clock()
+		start := clock.Now()
+		result, err := next(ctx, request)
+
+		trace := ToolCallTrace{
+			Tool:       request.Params.Name,
+			SessionID:  getSessionID(ctx),
+			TaskID:     TaskIDFromContext(ctx),
+			Arguments:  t.redact(request.Params.Name, request.GetArguments()),
+			StartedAt:  start,
+			DurationMs: clock.Now().Sub(start).Milliseconds(),
+			IsError:    err != nil || (result != nil && result.IsError),
+		}
+		if err != nil {
+			trace.Error = truncateTraceError(t.redactError(request.Params.Name, err))
+		}
+		t.record(trace)
+		return result, err
+	}
+}
+
+func (t *toolTracer) record(trace ToolCallTrace) {
+	t.mu.Lock()
+	t.nextID++
+	trace.ID = strconv.FormatUint(t.nextID, 10)
+	t.traces = append(t.traces, trace)
+	if len(t.traces) > t.capacity {
+		t.traces = append(t.traces[:0], t.traces[len(t.traces)-t.capacity:]...)
+	}
+	t.mu.Unlock()
+
+	t.server.notifyResourceUpdated(TraceRecentURI)
+}
+
+func (t *toolTracer) recent() []ToolCallTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	recent := make([]ToolCallTrace, len(t.traces))
+	for i, trace := range t.traces {
+		recent[len(t.traces)-1-i] = trace
+	}
+	return recent
+}
+
+// visible returns the recorded tool calls the caller may read, newest
+// first: those of its own session, or all of them for admins.
+func (t *toolTracer) visible(ctx context.Context) []ToolCallTrace {
+	recent := t.recent()
+	if t.isAdmin != nil && t.isAdmin(ctx) {
+		return recent
+	}
+	sessionID := getSessionID(ctx)
+	visible := make([]ToolCallTrace, 0, len(recent))
+	for _, trace := range recent {
+		if trace.SessionID == sessionID {
+			visible = append(visible, trace)
+		}
+	}
+	return visible
+}
+
+func (t *toolTracer) readRecent(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return traceContents(request.Params.URI, t.visible(ctx))
+}
+
+func (t *toolTracer) readCall(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id := strings.TrimPrefix(request.Params.URI, TraceRecentURI+"/")
+	for _, trace := range t.visible(ctx) {
+		if trace.ID == id {
+			return traceContents(request.Params.URI, trace)
+		}
+	}
+	return nil, fmt.Errorf("tool call %q is not among the recent calls: %w", id, ErrResourceNotFound)
+}
+
+// errorCode is the default TraceErrorRedactor: the translated code of err,
+// or the type of the error it wraps.
+func (t *toolTracer) errorCode(_ string, err error) string {
+	if translated, ok := t.server.errorTranslator.Translate(err); ok {
+		return translated.Code
+	}
+	for unwrapped := errors.Unwrap(err); unwrapped != nil; unwrapped = errors.Unwrap(err) {
+		err = unwrapped
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// truncateTraceError cuts msg to maxTraceErrorLength bytes, on a rune
+// boundary.
+func truncateTraceError(msg string) string {
+	if len(msg) <= maxTraceErrorLength {
+		return msg
+	}
+	cut := maxTraceErrorLength
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + "…"
+}
+
+func traceContents(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}}, nil
+}
+
+// redactKeys is the default TraceRedactor.
+func (t *toolTracer) redactKeys(_ string, arguments map[string]any) map[string]any {
+	if arguments == nil {
+		return nil
+	}
+	redacted, _ := t.redactValue(arguments).(map[string]any)
+	return redacted
+}
+
+func (t *toolTracer) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if t.sensitive(key) {
+				out[key] = redactedValue
+				continue
+			}
+			out[key] = t.redactValue(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = t.redactValue(value)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (t *toolTracer) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, redacted := range t.redactedKeys {
+		if strings.Contains(key, redacted) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func readTrace(t *testing.T, s *MCPServer, ctx context.Context, uri string, v any) *mcp.JSONRPCError {
+	t.Helper()
+	response := s.HandleMessage(ctx, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)))
+	if rpcErr, ok := response.(mcp.JSONRPCError); ok {
+		return &rpcErr
+	}
+	result := response.(mcp.JSONRPCResponse).Result.(mcp.ReadResourceResult)
+	require.Len(t, result.Contents, 1)
+	contents := result.Contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "application/json", contents.MIMEType)
+	require.NoError(t, json.Unmarshal([]byte(contents.Text), v))
+	return nil
+}
+
+func TestWithToolCallTracing(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolCallTracing(WithTraceCapacity(2), WithTraceRedactedKeys("ssn")))
+	s.AddTool(mcp.NewTool("login"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("user", "") == "mallory" {
+			return nil, errors.New("locked out")
+		}
+		return mcp.NewToolResultText("welcome"), nil
+	})
+
+	session := &sessionTestClient{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	session.Initialize()
+	ctx := s.WithContext(context.Background(), session)
+
+	for _, arguments := range []string{
+		`{"user":"alice","password":"hunter2"}`,
+		`{"user":"bob","profile":{"ApiKey":"k","ssn":"123"},"tags":[{"token":"t"}]}`,
+		`{"user":"mallory"}`,
+	} {
+		s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"login","arguments":`+arguments+`}}`))
+	}
+
+	var recent []ToolCallTrace
+	require.Nil(t, readTrace(t, s, ctx, TraceRecentURI, &recent))
+	require.Len(t, recent, 2, "only the last two calls are kept")
+	assert.Equal(t, "3", recent[0].ID)
+	assert.True(t, recent[0].IsError)
+	assert.Equal(t, "*errors.errorString", recent[0].Error, "only the type of errors is recorded by default")
+	assert.Equal(t, "s1", recent[0].SessionID)
+	assert.Equal(t, "2", recent[1].ID)
+	assert.False(t, recent[1].IsError)
+	assert.Equal(t, map[string]any{
+		"user":    "bob",
+		"profile": map[string]any{"ApiKey": "[REDACTED]", "ssn": "[REDACTED]"},
+		"tags":    []any{map[string]any{"token": "[REDACTED]"}},
+	}, recent[1].Arguments)
+	traces := s.ToolCallTraces()
+	require.Len(t, traces, 2)
+	assert.Equal(t, "3", traces[0].ID)
+
+	var call ToolCallTrace
+	require.Nil(t, readTrace(t, s, ctx, "trace://recent/2", &call))
+	assert.Equal(t, "bob", call.Arguments["user"])
+	rpcErr := readTrace(t, s, ctx, "trace://recent/1", &call)
+	require.NotNil(t, rpcErr, "evicted calls are gone")
+
+	var updates int
+	for len(session.notificationChannel) > 0 {
+		notification := <-session.notificationChannel
+		if notification.Method == mcp.MethodNotificationResourceUpdated {
+			assert.Equal(t, TraceRecentURI, notification.Params.AdditionalFields["uri"])
+			updates++
+		}
+	}
+	assert.Equal(t, 3, updates)
+}
+
+func TestToolCallTracingCustomRedactor(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithToolCallTracing(WithTraceRedactor(func(tool string, arguments map[string]any) map[string]any {
+		return map[string]any{"keys": len(arguments)}
+	})))
+	s.AddTool(mcp.NewTool("echo"), noopToolHandler)
+	s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"password":"x","a":1}}}`))
+
+	traces := s.ToolCallTraces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, map[string]any{"keys": 2}, traces[0].Arguments)
+
+	assert.Nil(t, NewMCPServer("test", "1.0.0").ToolCallTraces())
+}
+
+func TestToolCallTracingErrorRedaction(t *testing.T) {
+	errLockedOut := errors.New("locked out")
+	s := NewMCPServer("test", "1.0.0",
+		WithErrorTranslator(NewErrorTranslator().Register(errLockedOut, "locked_out", "The account is locked.")),
+		WithToolCallTracing(),
+	)
+	s.AddTool(mcp.NewTool("login"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		password := request.GetString("password", "")
+		if password == "hunter2" {
+			return nil, fmt.Errorf("password %s: %w", password, errLockedOut)
+		}
+		return nil, fmt.Errorf("password %s: %w", password, &json.SyntaxError{})
+	})
+	for _, password := range []string{"hunter2", "letmein"} {
+		s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"login","arguments":{"password":"`+password+`"}}}`))
+	}
+
+	traces := s.ToolCallTraces()
+	require.Len(t, traces, 2)
+	assert.Equal(t, "*json.SyntaxError", traces[0].Error, "errors without a code are recorded by type")
+	assert.Equal(t, "locked_out", traces[1].Error, "translated errors are recorded by code")
+}
+
+func TestToolCallTracingScopedToSession(t *testing.T) {
+	type adminKey struct{}
+	s := NewMCPServer("test", "1.0.0", WithToolCallTracing(
+		WithTraceAdmin(func(ctx context.Context) bool { return ctx.Value(adminKey{}) != nil }),
+		WithTraceErrorRedactor(func(tool string, err error) string { return err.Error() }),
+	))
+	s.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New(strings.Repeat("é", 150))
+	})
+
+	sessionContext := func(id string) context.Context {
+		session := &sessionTestClient{sessionID: id, notificationChannel: make(chan mcp.JSONRPCNotification, 10)}
+		require.NoError(t, s.RegisterSession(context.Background(), session))
+		session.Initialize()
+		return s.WithContext(context.Background(), session)
+	}
+	alice, bob := sessionContext("alice"), sessionContext("bob")
+	s.HandleMessage(alice, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fail"}}`))
+	s.HandleMessage(bob, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fail"}}`))
+
+	var recent []ToolCallTrace
+	require.Nil(t, readTrace(t, s, bob, TraceRecentURI, &recent))
+	require.Len(t, recent, 1)
+	assert.Equal(t, "2", recent[0].ID)
+	assert.Equal(t, strings.Repeat("é", 100)+"…", recent[0].Error, "errors are truncated")
+
+	var call ToolCallTrace
+	assert.NotNil(t, readTrace(t, s, bob, "trace://recent/1", &call), "other sessions' calls are hidden")
+	require.Nil(t, readTrace(t, s, alice, "trace://recent/1", &call))
+	assert.Equal(t, "alice", call.SessionID)
+
+	admin := context.WithValue(bob, adminKey{}, true)
+	require.Nil(t, readTrace(t, s, admin, TraceRecentURI, &recent))
+	assert.Len(t, recent, 2)
+	require.Nil(t, readTrace(t, s, admin, "trace://recent/1", &call))
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolTransformers(t *testing.T) {
+	type greetArgs struct {
+		Name string `json:"name"`
+	}
+
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("greet",
+		mcp.WithString("name"),
+		mcp.WithArgumentTransformer(func(ctx context.Context, arguments map[string]any) (map[string]any, error) {
+			if name, ok := arguments["name"].(string); ok {
+				arguments["name"] = strings.TrimSpace(name)
+			}
+			return arguments, nil
+		}),
+		mcp.WithArgumentTransformer(func(ctx context.Context, arguments map[string]any) (map[string]any, error) {
+			if arguments["name"] == "" {
+				return nil, errors.New("name is empty")
+			}
+			return arguments, nil
+		}),
+		mcp.WithResultTransformer(func(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+			result.Content = append(result.Content, mcp.NewTextContent("[1] greeting"))
+			return result, nil
+		}),
+	), mcp.NewTypedToolHandler(func(ctx context.Context, request mcp.CallToolRequest, args greetArgs) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("hello " + args.Name), nil
+	}))
+
+	var seen []string
+	s.AddTool(mcp.NewTool("plain"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		seen = append(seen, "plain")
+		return mcp.NewToolResultText("plain"), nil
+	})
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"greet","arguments":{"name":"  Ada "}}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result, ok := resp.Result.(mcp.CallToolResult)
+	require.True(t, ok)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "hello Ada", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, "[1] greeting", result.Content[1].(mcp.TextContent).Text)
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"greet","arguments":{"name":" "}}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Contains(t, rpcErr.Error.Message, "name is empty")
+
+	response = s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"plain"}}`))
+	_, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, []string{"plain"}, seen)
+}
+
+func TestToolTransformersAreNotSerialized(t *testing.T) {
+	tool := mcp.NewTool("greet", mcp.WithResultTransformer(func(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+		return result, nil
+	}))
+	data, err := tool.MarshalJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Transformer")
+}
+
+func TestTransformArgumentsDecodesArguments(t *testing.T) {
+	tool := mcp.NewTool("greet", mcp.WithArgumentTransformer(func(ctx context.Context, arguments map[string]any) (map[string]any, error) {
+		arguments["name"] = strings.ToUpper(arguments["name"].(string))
+		return arguments, nil
+	}))
+
+	tests := []struct {
+		name      string
+		arguments any
+	}{
+		{name: "map", arguments: map[string]any{"name": "ada"}},
+		{name: "raw JSON", arguments: json.RawMessage(`{"name":"ada"}`)},
+		{name: "struct", arguments: struct {
+			Name string `json:"name"`
+		}{Name: "ada"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.arguments
+			request, err := tool.TransformArguments(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"name": "ADA"}, request.GetArguments())
+		})
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = json.RawMessage(`["ada"]`)
+	_, err := tool.TransformArguments(context.Background(), request)
+	assert.ErrorContains(t, err, "must be an object")
+}
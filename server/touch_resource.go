@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResourceInvalidatorFunc drops what is cached about the resource with the
+// URI, e.g. by a caching resource middleware or in front of the store
+// backing the resource.
+type ResourceInvalidatorFunc func(ctx context.Context, uri string)
+
+// WithResourceInvalidator registers a function TouchResource calls before
+// notifying clients, so that their next read sees the change.
+func WithResourceInvalidator(invalidate ResourceInvalidatorFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.resourceInvalidators = append(s.resourceInvalidators, invalidate)
+	}
+}
+
+// TouchOption configures TouchResource.
+type TouchOption func(*touchOptions)
+
+type touchOptions struct {
+	embed bool
+}
+
+// WithEmbeddedContents makes TouchResource read the fresh contents of the
+// resource and embed them in the result of the tool call it is made from,
+// so the client sees the new state without reading the resource again. It
+// is ignored outside of tool calls.
+func WithEmbeddedContents() TouchOption {
+	return func(o *touchOptions) {
+		o.embed = true
+	}
+}
+
+// TouchResource tells the server that the data backing the resource with
+// the URI changed, typically from a tool that just wrote it. It runs the
+// WithResourceInvalidator functions and then sends
+// notifications/resources/updated to the clients, or only to the sessions
+// subscribed to the URI under WithResourceSubscriptions, so that reads
+// following the notification observe the write.
+//
+// With WithEmbeddedContents, the resource is read after the caches were
+// invalidated and its contents are added to the tool's result as embedded
+// resources; touching the same URI again in the call replaces them. The
+// read goes through the resource middlewares. If the resource cannot be
+// read, clients are still notified and TouchResource returns the error.
+func (s *MCPServer) TouchResource(ctx context.Context, uri string, opts ...TouchOption) error {
+	var options touchOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for _, invalidate := range s.resourceInvalidators {
+		invalidate(ctx, uri)
+	}
+
+	var err error
+	if touched, ok := ctx.Value(touchedResourcesKey{}).(*touchedResources); ok && options.embed {
+		var request mcp.ReadResourceRequest
+		request.Method = string(mcp.MethodResourcesRead)
+		request.Params.URI = uri
+		if result, reqErr := s.handleReadResource(ctx, nil, request); reqErr != nil {
+			err = reqErr.err
+		} else {
+			touched.set(uri, result.Contents)
+		}
+	}
+
+	// The resource changed whether or not it could be read back.
+	s.notifyResourceUpdated(uri)
+	return err
+}
+
+type touchedResourcesKey struct{}
+
+// touchedResources collects the contents embedded by TouchResource during a
+// tool call, in the order the resources were first touched.
+type touchedResources struct {
+	mu       sync.Mutex
+	uris     []string
+	contents map[string][]mcp.ResourceContents
+}
+
+func (t *touchedResources) set(uri string, contents []mcp.ResourceContents) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.contents == nil {
+		t.contents = make(map[string][]mcp.ResourceContents)
+	}
+	if _, ok := t.contents[uri]; !ok {
+		t.uris = append(t.uris, uri)
+	}
+	t.contents[uri] = contents
+}
+
+// embedTouchedResources wraps a tool handler so that the contents embedded
+// by TouchResource are added to its result, leaving the handler's result
+// unchanged.
+func embedTouchedResources(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		touched := &touchedResources{}
+		result, err := next(context.WithValue(ctx, touchedResourcesKey{}, touched), request)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		touched.mu.Lock()
+		defer touched.mu.Unlock()
+		if len(touched.uris) == 0 {
+			return result, nil
+		}
+		embedded := *result
+		embedded.Content = slices.Clone(result.Content)
+		for _, uri := range touched.uris {
+			for _, contents := range touched.contents[uri] {
+				embedded.Content = append(embedded.Content, mcp.NewEmbeddedResource(contents))
+			}
+		}
+		return &embedded, nil
+	}
+}
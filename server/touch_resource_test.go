@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTouchResource(t *testing.T) {
+	var counter atomic.Int32
+	session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	// The notifications already sent when each invalidation ran.
+	var notifiedBefore []int
+	s := NewMCPServer("test", "1.0.0",
+		WithResourceCapabilities(true, false),
+		WithResourceInvalidator(func(ctx context.Context, uri string) {
+			notifiedBefore = append(notifiedBefore, len(session.notificationChannel))
+		}),
+	)
+	s.AddResource(mcp.NewResource("counter://value", "counter"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: string(rune('0' + counter.Load()))}}, nil
+	})
+
+	var opts []TouchOption
+	s.AddTool(mcp.NewTool("increment"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		counter.Add(1)
+		if err := s.TouchResource(ctx, "counter://value", opts...); err != nil {
+			return nil, err
+		}
+		counter.Add(1)
+		if err := s.TouchResource(ctx, "counter://value", opts...); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("incremented"), nil
+	})
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := s.WithContext(context.Background(), session)
+
+	call := func() []mcp.Content {
+		response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"increment"}}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		return resp.Result.(mcp.CallToolResult).Content
+	}
+
+	content := call()
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("incremented")}, content)
+	assert.Equal(t, []int{0, 1}, notifiedBefore, "caches are invalidated before clients are notified")
+	require.Len(t, session.notificationChannel, 2)
+	notification := <-session.notificationChannel
+	assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+	assert.Equal(t, "counter://value", notification.Params.AdditionalFields["uri"])
+	<-session.notificationChannel
+
+	opts = []TouchOption{WithEmbeddedContents()}
+	content = call()
+	require.Len(t, content, 2, "touching a resource again replaces its embedded contents")
+	assert.Equal(t, mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "counter://value", Text: "4"}), content[1])
+
+	err := s.TouchResource(ctx, "counter://missing", WithEmbeddedContents())
+	assert.NoError(t, err, "embedding is ignored outside of tool calls")
+}
+
+func TestTouchResource_NotFound(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithResourceCapabilities(true, false))
+	s.AddTool(mcp.NewTool("write"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		err := s.TouchResource(ctx, "file:///missing", WithEmbeddedContents())
+		assert.ErrorIs(t, err, ErrResourceNotFound)
+		return mcp.NewToolResultText("done"), nil
+	})
+	session := &sessionTestClientWithClientInfo{sessionID: "s1", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"write"}}`))
+	_, ok := response.(mcp.JSONRPCResponse)
+	assert.True(t, ok, "unexpected response %#v", response)
+
+	require.Len(t, session.notificationChannel, 1, "clients are notified even if the resource cannot be read")
+	notification := <-session.notificationChannel
+	assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+	assert.Equal(t, "file:///missing", notification.Params.AdditionalFields["uri"])
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// duplicateRegistrations records tools, prompts and resources registered
+// under a name that was already taken, which silently replaces the earlier
+// registration. Deleting or replacing them clears the record.
+type duplicateRegistrations struct {
+	mu    sync.Mutex
+	found map[duplicateKey]struct{}
+}
+
+type duplicateKey struct {
+	kind, name string
+}
+
+func (d *duplicateRegistrations) add(kind, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.found == nil {
+		d.found = make(map[duplicateKey]struct{})
+	}
+	d.found[duplicateKey{kind, name}] = struct{}{}
+}
+
+// forget clears the record of the named registrations of kind.
+func (d *duplicateRegistrations) forget(kind string, names ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, name := range names {
+		delete(d.found, duplicateKey{kind, name})
+	}
+}
+
+// reset clears the record of every registration of kind.
+func (d *duplicateRegistrations) reset(kind string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key := range d.found {
+		if key.kind == kind {
+			delete(d.found, key)
+		}
+	}
+}
+
+func (d *duplicateRegistrations) list() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	list := make([]string, 0, len(d.found))
+	for key := range d.found {
+		list = append(list, fmt.Sprintf("%s %q is registered more than once", key.kind, key.name))
+	}
+	sort.Strings(list)
+	return list
+}
+
+// WithStrictStartup runs Validate when the server starts, so ServeStdio and
+// the Start methods of the HTTP servers fail fast on invalid registrations
+// instead of failing the affected calls.
+func WithStrictStartup() ServerOption {
+	return func(s *MCPServer) {
+		s.strictStartup = true
+	}
+}
+
+// NewTypedTool returns a ServerTool whose handler binds the arguments to T,
+// as with mcp.NewTypedToolHandler. It records T, so that Validate can check
+// it against the tool's input schema.
+func NewTypedTool[T any](tool mcp.Tool, handler mcp.TypedToolHandlerFunc[T]) ServerTool {
+	return ServerTool{
+		Tool:          tool,
+		Handler:       mcp.NewTypedToolHandler(handler),
+		ArgumentsType: reflect.TypeFor[T](),
+	}
+}
+
+// NewStructuredTool returns a ServerTool whose handler binds the arguments to
+// TArgs and returns structured output, as with mcp.NewStructuredToolHandler.
+// It records TArgs, so that Validate can check it against the tool's input
+// schema.
+func NewStructuredTool[TArgs, TResult any](tool mcp.Tool, handler mcp.StructuredToolHandlerFunc[TArgs, TResult]) ServerTool {
+	return ServerTool{
+		Tool:          tool,
+		Handler:       mcp.NewStructuredToolHandler(handler),
+		ArgumentsType: reflect.TypeFor[TArgs](),
+	}
+}
+
+// Validate checks the registered tools, prompts and resources for problems
+// that would otherwise only show at call time: duplicate or missing names,
+// missing handlers, schemas that are not usable JSON Schemas, typed handlers
+// whose argument struct does not match the input schema, and tools that
+// require tasks on a server without tool call task support. The returned
+// error wraps ErrInvalidRegistration and lists every problem; it is nil when
+// there is none.
+func (s *MCPServer) Validate() error {
+	var errs []error
+	report := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidRegistration}, args...)...))
+	}
+
+	for _, duplicate := range s.duplicates.list() {
+		report("%s", duplicate)
+	}
+
+	s.capabilitiesMu.RLock()
+	toolCallTasks := s.capabilities.tasks != nil && s.capabilities.tasks.toolCallTasks
+	s.capabilitiesMu.RUnlock()
+
+	s.toolsMu.RLock()
+	tools := make([]ServerTool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		tools = append(tools, tool)
+	}
+	s.toolsMu.RUnlock()
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Tool.Name < tools[j].Tool.Name })
+
+	for _, tool := range tools {
+		name := tool.Tool.Name
+		if name == "" {
+			report("tool without a name")
+		}
+		if tool.Handler == nil {
+			report("tool %s: no handler", name)
+		}
+		if problems := mcp.CheckSchema(toolInputSchema(tool.Tool)); len(problems) > 0 {
+			for _, problem := range problems {
+				report("tool %s: input %s", name, problem)
+			}
+		} else if tool.ArgumentsType != nil {
+			for _, problem := range mcp.CheckSchemaBinding(toolInputSchema(tool.Tool), tool.ArgumentsType) {
+				report("tool %s: %s", name, problem)
+			}
+		}
+		if outputSchema := toolOutputSchema(tool.Tool); outputSchema != nil {
+			for _, problem := range mcp.CheckSchema(outputSchema) {
+				report("tool %s: output %s", name, problem)
+			}
+		}
+		if tool.RequiresTask && !toolCallTasks {
+			report("tool %s requires tasks, but the server does not support task-augmented tool calls", name)
+		}
+	}
+
+	s.promptsMu.RLock()
+	prompts := make([]mcp.Prompt, 0, len(s.prompts))
+	for _, prompt := range s.prompts {
+		prompts = append(prompts, prompt)
+	}
+	handlers := make(map[string]bool, len(s.promptHandlers))
+	for name, handler := range s.promptHandlers {
+		handlers[name] = handler != nil
+	}
+	s.promptsMu.RUnlock()
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+
+	for _, prompt := range prompts {
+		if prompt.Name == "" {
+			report("prompt without a name")
+		}
+		if !handlers[prompt.Name] {
+			report("prompt %s: no handler", prompt.Name)
+		}
+		seen := make(map[string]bool, len(prompt.Arguments))
+		for _, argument := range prompt.Arguments {
+			switch {
+			case argument.Name == "":
+				report("prompt %s: argument without a name", prompt.Name)
+			case seen[argument.Name]:
+				report("prompt %s: argument %s is declared more than once", prompt.Name, argument.Name)
+			}
+			seen[argument.Name] = true
+		}
+	}
+
+	s.resourcesMu.RLock()
+	uris := make([]string, 0, len(s.resources))
+	resourceHandlers := make(map[string]bool, len(s.resources))
+	for uri, entry := range s.resources {
+		uris = append(uris, uri)
+		resourceHandlers[uri] = entry.handler != nil
+	}
+	templates := make([]string, 0, len(s.resourceTemplates))
+	templateHandlers := make(map[string]bool, len(s.resourceTemplates))
+	for raw, entry := range s.resourceTemplates {
+		templates = append(templates, raw)
+		templateHandlers[raw] = entry.handler != nil
+	}
+	s.resourcesMu.RUnlock()
+	sort.Strings(uris)
+	sort.Strings(templates)
+
+	for _, uri := range uris {
+		if parsed, err := url.Parse(uri); err != nil || parsed.Scheme == "" {
+			report("resource %q: not an absolute URI", uri)
+		}
+		if !resourceHandlers[uri] {
+			report("resource %s: no handler", uri)
+		}
+	}
+	for _, template := range templates {
+		if template == "" {
+			report("resource template without a URI template")
+		}
+		if !templateHandlers[template] {
+			report("resource template %s: no handler", template)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkStartup runs the checks that must pass before a transport starts
+// serving the server.
+func (s *MCPServer) checkStartup() error {
+	var errs []error
+	if s.schemaBaseline != nil && s.schemaBaseline.enforce {
+		errs = append(errs, s.CheckSchemaBaseline())
+	}
+	if s.strictStartup {
+		errs = append(errs, s.Validate())
+	}
+	return errors.Join(errs...)
+}
+
+// toolOutputSchema returns the tool's output schema, or nil if it has none.
+func toolOutputSchema(tool mcp.Tool) any {
+	if tool.RawOutputSchema != nil {
+		return tool.RawOutputSchema
+	}
+	if tool.OutputSchema.Type == "" && tool.OutputSchema.Properties == nil {
+		return nil
+	}
+	return tool.OutputSchema
+}
+
+// requireTaskCall rejects a direct call to a tool that must run as a task.
+func (s *MCPServer) requireTaskCall(ctx context.Context, id any, name string) *requestError {
+	tool, ok := s.lookupTool(ctx, name)
+	if !ok || !tool.RequiresTask {
+		return nil
+	}
+	return &requestError{
+		id:   id,
+		code: mcp.INVALID_PARAMS,
+		err:  fmt.Errorf("tool '%s' must be called as a task", name),
+	}
+}
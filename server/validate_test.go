@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type validateArgs struct {
+	City string `json:"city"`
+	Days int    `json:"days"`
+}
+
+func validateHandler(ctx context.Context, request mcp.CallToolRequest, args validateArgs) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText(args.City), nil
+}
+
+func TestMCPServer_Validate(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	s.AddTools(NewTypedTool(mcp.NewTool("weather", mcp.WithString("city", mcp.Required()), mcp.WithNumber("days")), validateHandler))
+	s.AddPrompt(mcp.NewPrompt("greet", mcp.WithArgument("name")), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return nil, nil
+	})
+	s.AddResource(mcp.NewResource("file:///readme", "readme"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	})
+	require.NoError(t, s.Validate())
+
+	tests := []struct {
+		name    string
+		setup   func(s *MCPServer)
+		problem string
+	}{
+		{
+			name: "duplicate tool",
+			setup: func(s *MCPServer) {
+				s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+			},
+			problem: `tool "weather" is registered more than once`,
+		},
+		{
+			name: "duplicate prompt",
+			setup: func(s *MCPServer) {
+				s.AddPrompt(mcp.NewPrompt("greet"), nil)
+			},
+			problem: `prompt "greet" is registered more than once`,
+		},
+		{
+			name: "invalid schema",
+			setup: func(s *MCPServer) {
+				s.AddTool(mcp.NewToolWithRawSchema("broken", "", json.RawMessage(`{"type":"object","required":["x"],"properties":{}}`)), noopToolHandler)
+			},
+			problem: `tool broken: input schema: required property "x" is not defined`,
+		},
+		{
+			name: "typed handler mismatch",
+			setup: func(s *MCPServer) {
+				s.AddTools(NewTypedTool(mcp.NewTool("forecast", mcp.WithString("city"), mcp.WithString("days")), validateHandler))
+			},
+			problem: "tool forecast: property days of type string cannot be bound to field of type int",
+		},
+		{
+			name: "task tool without task capabilities",
+			setup: func(s *MCPServer) {
+				s.AddTools(ServerTool{Tool: mcp.NewTool("report"), Handler: noopToolHandler, RequiresTask: true})
+			},
+			problem: "tool report requires tasks",
+		},
+		{
+			name: "missing handler",
+			setup: func(s *MCPServer) {
+				s.AddTool(mcp.NewTool("nothing"), nil)
+			},
+			problem: "tool nothing: no handler",
+		},
+		{
+			name: "relative resource URI",
+			setup: func(s *MCPServer) {
+				s.AddResource(mcp.NewResource("readme.md", "readme"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+					return nil, nil
+				})
+			},
+			problem: `resource "readme.md": not an absolute URI`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMCPServer("test", "1.0.0")
+			s.AddTools(NewTypedTool(mcp.NewTool("weather", mcp.WithString("city", mcp.Required()), mcp.WithNumber("days")), validateHandler))
+			s.AddPrompt(mcp.NewPrompt("greet"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return nil, nil
+			})
+			tt.setup(s)
+
+			err := s.Validate()
+			assert.ErrorIs(t, err, ErrInvalidRegistration)
+			assert.ErrorContains(t, err, tt.problem)
+		})
+	}
+}
+
+func TestMCPServer_ValidateAfterUpdates(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	readme := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	greet := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return nil, nil
+	}
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+	s.AddPrompt(mcp.NewPrompt("greet"), greet)
+	s.AddPrompt(mcp.NewPrompt("greet"), greet)
+	s.AddResource(mcp.NewResource("file:///readme", "readme"), readme)
+	s.AddResource(mcp.NewResource("file:///readme", "readme"), readme)
+	require.ErrorIs(t, s.Validate(), ErrInvalidRegistration)
+
+	// Deleting or replacing a registration clears its duplicates.
+	s.DeleteTools("weather")
+	s.AddTool(mcp.NewTool("weather"), noopToolHandler)
+	s.SetPrompts(ServerPrompt{Prompt: mcp.NewPrompt("greet"), Handler: greet})
+	s.RemoveResource("file:///readme")
+	assert.NoError(t, s.Validate())
+
+	s.SetTools(ServerTool{Tool: mcp.NewTool("a"), Handler: noopToolHandler}, ServerTool{Tool: mcp.NewTool("a"), Handler: noopToolHandler})
+	assert.ErrorContains(t, s.Validate(), `tool "a" is registered more than once`)
+}
+
+func TestMCPServer_ValidateTaskTool(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithTaskCapabilities(true, true, true))
+	s.AddTools(ServerTool{Tool: mcp.NewTool("report"), Handler: noopToolHandler, RequiresTask: true})
+	require.NoError(t, s.Validate())
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"report"}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.Equal(t, mcp.INVALID_PARAMS, rpcErr.Error.Code)
+	assert.Contains(t, rpcErr.Error.Message, "must be called as a task")
+}
+
+func TestWithStrictStartup(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithStrictStartup())
+	s.AddTool(mcp.NewTool("nothing"), nil)
+
+	stdio := NewStdioServer(s)
+	err := stdio.Listen(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+	assert.ErrorIs(t, NewStreamableHTTPServer(s).Start("127.0.0.1:0"), ErrInvalidRegistration)
+
+	// Without strict startup, the same server starts.
+	lenient := NewMCPServer("test", "1.0.0")
+	lenient.AddTool(mcp.NewTool("nothing"), nil)
+	assert.NoError(t, lenient.checkStartup())
+}